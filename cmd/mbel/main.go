@@ -2,15 +2,20 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +26,7 @@ import (
 const version = "1.2.0"
 
 type compileResult struct {
+	index     int // position in the file list
 	file      string
 	namespace string
 	data      map[string]interface{}
@@ -61,10 +67,34 @@ func main() {
 		statsCmd(os.Args[2:])
 	case "diff":
 		diffCmd(os.Args[2:])
+	case "sync":
+		syncCmd(os.Args[2:])
+	case "approve":
+		approveCmd(os.Args[2:])
+	case "refactor-placeholders":
+		refactorPlaceholdersCmd(os.Args[2:])
+	case "mv":
+		mvCmd(os.Args[2:])
 	case "import":
 		importCmd(os.Args[2:])
+	case "export":
+		exportCmd(os.Args[2:])
 	case "translate":
 		translateCmd(os.Args[2:])
+	case "qa":
+		qaCmd(os.Args[2:])
+	case "migrate-bundle":
+		migrateBundleCmd(os.Args[2:])
+	case "generate":
+		generateCmd(os.Args[2:])
+	case "preview":
+		previewCmd(os.Args[2:])
+	case "changelog":
+		changelogCmd(os.Args[2:])
+	case "roundtrip":
+		roundtripCmd(os.Args[2:])
+	case "gen-plural-tests":
+		genPluralTestsCmd(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", arg1)
 		printUsage()
@@ -93,13 +123,25 @@ Core Commands:
   init      ✨ Start here! Interactive project setup
   watch     👁  Watch mode (hot-reload for development)
   compile   📦 Compile .mbel files to JSON (for production)
+  generate  ⚙  Generate typed Go accessors from mbel.toml (for go generate)
   lint      🔍 Validate syntax and AI rules
 
 Helpers:
   fmt       🎨 Auto-format .mbel files
   stats     📊 Show project statistics
-  diff      ↔  Compare locales (find missing keys)
-  import    📥 Import from JSON/YAML
+  diff      ↔  Compare locales (find missing and stale keys)
+  sync      🔒 Update mbel.lock and report stale translations
+  approve   ✅ Set the review status (@status) of keys
+  refactor-placeholders  ✏  Rename a {placeholder} in every locale
+  mv        🚚 Move a key or namespace in every locale (auth.* account.auth.*)
+  import    📥 Import from JSON/YAML, or apply a review sheet
+  export    📤 Export a reviewer spreadsheet (xlsx, csv)
+  migrate-bundle  ⬆  Upgrade compiled JSON to the current schema
+  preview   🖼  Render a key in every locale with its sample variables
+  changelog 📰 Report added, removed and changed keys between two bundles
+  roundtrip ♻  Check a catalog survives export/import (po, json)
+  gen-plural-tests  🔢 Write a block exercising a locale's plural categories
+  qa        🧐 Review translations with an LLM (meaning, tone, placeholders)
   version   ℹ  Show version info
 
 Flags:
@@ -234,6 +276,35 @@ func discoverFiles(paths []string) ([]string, error) {
 	return files, nil
 }
 
+// keyFilterFlags registers -include and -exclude on fs; the returned
+// function reads them once fs is parsed, exiting on invalid patterns
+func keyFilterFlags(fs *flag.FlagSet) func() mbel.KeyFilter {
+	include := fs.String("include", "", "Comma-separated key patterns to operate on (e.g. auth.*,checkout.*)")
+	exclude := fs.String("exclude", "", "Comma-separated key patterns to leave out (e.g. internal.*)")
+	return func() mbel.KeyFilter {
+		f, err := mbel.ParseKeyFilter(*include, *exclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return f
+	}
+}
+
+// interpolationFlags registers -interpolation on fs; the returned
+// function parses it once fs is parsed, exiting on an unknown style
+func interpolationFlags(fs *flag.FlagSet) func() mbel.Interpolation {
+	style := fs.String("interpolation", "", "Placeholder style of files without @interpolation: single {name}, double {{name}} or percent %{name}")
+	return func() mbel.Interpolation {
+		s, err := mbel.ParseInterpolation(*style)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return s
+	}
+}
+
 // deriveNamespace extracts namespace from file path relative to base
 // e.g., locales/en/features/auth/login.mbel -> features.auth
 func deriveNamespace(filePath, basePath string) string {
@@ -252,6 +323,220 @@ func deriveNamespace(filePath, basePath string) string {
 	return ns
 }
 
+// ============================================================================
+// WORKSPACES
+// ============================================================================
+
+// parseFlags parses args into fs, then gives the flags left unset the
+// values of the enclosing mbel.work, which it returns (nil outside one)
+func parseFlags(fs *flag.FlagSet, args []string) *mbel.Workspace {
+	parseInterspersed(fs, args)
+	ws := findWorkspace()
+	if ws == nil {
+		return nil
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, value := range ws.Settings {
+		if fs.Lookup(name) == nil || set[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %s: %v\n", mbel.WorkspaceFileName, name, err)
+			os.Exit(1)
+		}
+	}
+	return ws
+}
+
+// parseInterspersed parses args allowing flags after positional
+// arguments, as in "mbel compile locales -o out.json"; everything after
+// "--" is positional
+func parseInterspersed(fs *flag.FlagSet, args []string) {
+	var positional []string
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	fs.Parse(append([]string{"--"}, positional...))
+}
+
+// findWorkspace reads the mbel.work of the current directory or its
+// parents, or the one $MBEL_WORK names; MBEL_WORK=off disables it
+func findWorkspace() *mbel.Workspace {
+	path := os.Getenv("MBEL_WORK")
+	switch path {
+	case "off":
+		return nil
+	case "":
+		if path = mbel.FindWorkspace("."); path == "" {
+			return nil
+		}
+	}
+	ws, err := mbel.ReadWorkspace(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return ws
+}
+
+// workspaceRoot returns the root of p relative to the current directory
+// where possible, for shorter output
+func workspaceRoot(ws *mbel.Workspace, p mbel.WorkspaceProject) string {
+	root := ws.Path(p)
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, root); err == nil {
+			return rel
+		}
+	}
+	return root
+}
+
+// runWorkspace runs "mbel <cmd> <args> <root>" for every project of ws,
+// each in a process of its own so one failing project does not stop the
+// rest, then prints a summary line per project (with detail(root) when
+// given); it exits non-zero if any project failed
+func runWorkspace(ws *mbel.Workspace, cmd string, args []string, detail func(root string) string) {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var summary []string
+	failed := 0
+	for _, p := range ws.Projects {
+		root := workspaceRoot(ws, p)
+		fmt.Printf("━━ %s (%s)\n", p.Name, root)
+		c := exec.Command(exe, append(append([]string{cmd}, args...), root)...)
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+		mark := "✓"
+		if err := c.Run(); err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			mark = "✗"
+			failed++
+		}
+		line := fmt.Sprintf("  %s %-20s %s", mark, p.Name, root)
+		if detail != nil {
+			line += "  " + detail(root)
+		}
+		summary = append(summary, line)
+		fmt.Println()
+	}
+	printWorkspaceSummary(ws, summary, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printWorkspaceSummary(ws *mbel.Workspace, summary []string, failed int) {
+	fmt.Printf("📦 Workspace: %d projects", len(ws.Projects))
+	if failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	fmt.Println()
+	for _, line := range summary {
+		fmt.Println(line)
+	}
+}
+
+// diffWorkspace compares every locale of each workspace project with
+// its source locale
+func diffWorkspace(ws *mbel.Workspace, source string) {
+	var summary []string
+	failed := 0
+	for _, p := range ws.Projects {
+		root := workspaceRoot(ws, p)
+		fmt.Printf("━━ %s (%s)\n", p.Name, root)
+		locales, err := localePaths(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		src, ok := locales[source]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "✗ no %s locale in %s\n", source, root)
+			summary = append(summary, fmt.Sprintf("  ✗ %-20s %s  no %s locale", p.Name, root, source))
+			failed++
+			fmt.Println()
+			continue
+		}
+		var langs, differ []string
+		for lang := range locales {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			if lang == source || lang == mbel.CommonLocale {
+				continue
+			}
+			if !printDiff(src, locales[lang]) {
+				differ = append(differ, lang)
+			}
+			fmt.Println()
+		}
+		if len(differ) == 0 {
+			summary = append(summary, fmt.Sprintf("  ✓ %-20s %s", p.Name, root))
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("  ⚠ %-20s %s  differs: %s", p.Name, root, strings.Join(differ, ", ")))
+	}
+	printWorkspaceSummary(ws, summary, failed)
+}
+
+// localePaths maps the locales of a locale directory to their folder or
+// single .mbel file
+func localePaths(root string) (map[string]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string)
+	for _, e := range entries {
+		switch {
+		case e.IsDir():
+			paths[e.Name()] = filepath.Join(root, e.Name())
+		case strings.HasSuffix(e.Name(), ".mbel"):
+			paths[strings.TrimSuffix(e.Name(), ".mbel")] = filepath.Join(root, e.Name())
+		}
+	}
+	return paths, nil
+}
+
+// completenessSummary returns the completeness of every locale of root
+// against source, e.g. "pl 95%, de 80%"
+func completenessSummary(root, source string) string {
+	repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler)}
+	langData, _ := repo.LoadAll()
+	src, ok := langData[source]
+	if !ok {
+		return "no " + source + " locale"
+	}
+	var parts []string
+	for _, lang := range sortedLangs(langData) {
+		if lang != source && lang != mbel.CommonLocale {
+			parts = append(parts, fmt.Sprintf("%s %d%%", lang, int(mbel.Completeness(src, langData[lang])*100)))
+		}
+	}
+	summary := fmt.Sprintf("%d keys", len(mbel.SortedKeys(src)))
+	if len(parts) > 0 {
+		summary += "; " + strings.Join(parts, ", ")
+	}
+	return summary
+}
+
 // ============================================================================
 // LINT COMMAND
 // ============================================================================
@@ -260,15 +545,75 @@ func lintCmd(args []string) {
 	fs := flag.NewFlagSet("lint", flag.ExitOnError)
 	verbose := fs.Bool("v", false, "Verbose output")
 	parallel := fs.Int("j", runtime.NumCPU(), "Parallel workers")
-	fs.Parse(args)
+	pluginPaths := fs.String("plugin", "", "Comma-separated plugin .so files registering lint rules (also $MBEL_PLUGINS)")
+	untranslated := fs.String("untranslated", "", "Source locale; warn about values identical to it in other locales")
+	allow := fs.String("allow", "", "Comma-separated key patterns exempt from -untranslated (e.g. brand.*,*.url)")
+	lengths := fs.Bool("lengths", false, "Report translations over their AI_MaxLength budget, per locale")
+	maxSize := fs.String("max-size", "", "Fail when a locale's compiled JSON exceeds this size, e.g. 200KB")
+	maxGzip := fs.String("max-gzip", "", "Fail when a locale's compiled JSON exceeds this size gzipped, e.g. 50KB")
+	snakeCase := fs.Bool("snake-case", false, "Require snake_case key and section names")
+	maxDepth := fs.Int("max-depth", 0, "Maximum segments per key, folder namespace included (0 = unlimited)")
+	keyPrefixes := fs.String("key-prefixes", "", "Allowed key prefixes per directory, e.g. 'checkout=checkout.,cart.;=common.'")
+	fix := fs.Bool("fix", false, "Apply the fixes suggested by lint rules in place")
+	requireStatus := fs.String("require-status", "", "Fail on keys below this review status (reviewed, final)")
+	keyFilter := keyFilterFlags(fs)
+	interpolation := interpolationFlags(fs)
+	ws := parseFlags(fs, args)
+	filter := keyFilter()
+	style := interpolation()
 
 	paths := fs.Args()
+	if len(paths) == 0 && ws != nil {
+		runWorkspace(ws, "lint", args, nil)
+		return
+	}
 	if len(paths) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: No files or directories specified")
 		fmt.Fprintln(os.Stderr, "Usage: mbel lint <path> [path2 ...]")
 		os.Exit(1)
 	}
 
+	err := loadPlugins(*pluginPaths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var budget mbel.SizeBudget
+	for _, f := range []struct {
+		value string
+		max   *int
+	}{{*maxSize, &budget.MaxBytes}, {*maxGzip, &budget.MaxGzip}} {
+		if f.value == "" {
+			continue
+		}
+		if *f.max, err = mbel.ParseByteSize(f.value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	minStatus := mbel.KeyStatus("")
+	if *requireStatus != "" {
+		if minStatus, err = mbel.ParseKeyStatus(*requireStatus); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	mbel.RegisterLintRule("key-status", mbel.KeyStatusRule(minStatus))
+	mbel.RegisterLintRule("context-url", mbel.ContextURLRule())
+	mbel.RegisterLintRule("schedule", mbel.ScheduleRule(time.Now()))
+	mbel.RegisterLintRule("audience", mbel.AudienceRule())
+	mbel.RegisterLintRule("interpolation", mbel.InterpolationRule())
+	mbel.RegisterLintRule("icu", mbel.ICURule())
+	mbel.RegisterLintRule("sample-vars", mbel.SampleVarsRule())
+	mbel.RegisterLintRule("copy-rules", mbel.CopyRulesRule())
+	if *snakeCase || *maxDepth > 0 || *keyPrefixes != "" {
+		mbel.RegisterLintRule("key-naming", mbel.KeyNamingRule(mbel.KeyNaming{
+			SnakeCase: *snakeCase,
+			MaxDepth:  *maxDepth,
+			Prefixes:  parseKeyPrefixes(*keyPrefixes),
+		}))
+	}
+
 	files, err := discoverFiles(paths)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -288,6 +633,7 @@ func lintCmd(args []string) {
 	type lintResult struct {
 		file  string
 		err   error
+		diags []mbel.Diagnostic // from registered lint rules
 		stats struct {
 			statements  int
 			annotations int
@@ -319,23 +665,10 @@ func lintCmd(args []string) {
 				if errs := p.Errors(); len(errs) > 0 {
 					res.err = fmt.Errorf("syntax errors:\n  %s", strings.Join(errs, "\n  "))
 				} else {
-					// Validation Rules
-					for _, ann := range program.AIAnnotations {
-						if ann.Type == "MaxLength" && ann.ForKey != "" {
-							for _, stmt := range program.Statements {
-								if assign, ok := stmt.(*mbel.AssignStatement); ok && assign.Name == ann.ForKey {
-									if sl, ok := assign.Value.(*mbel.StringLiteral); ok {
-										if limit, err := strconv.Atoi(ann.Value); err == nil {
-											if len(sl.Value) > limit {
-												res.err = fmt.Errorf("validation error: %s exceeds max length of %d (got %d)", ann.ForKey, limit, len(sl.Value))
-											}
-										}
-									}
-								}
-							}
-						}
-					}
+					ns := lintNamespace(paths, file)
+					program = filter.Program(program, ns)
 
+					res.diags = mbel.RunLintRules(program, mbel.LintContext{File: file, Namespace: ns, Interpolation: style})
 					res.stats.statements = len(program.Statements)
 					res.stats.annotations = len(program.AIAnnotations)
 				}
@@ -358,19 +691,72 @@ func lintCmd(args []string) {
 
 	hasErrors := false
 	successCount := 0
-	for res := range results {
+	for _, res := range inFileOrder(results, files, func(r lintResult) string { return r.file }) {
+		failed := res.err != nil
 		if res.err != nil {
 			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", res.file, res.err)
-			hasErrors = true
-		} else {
-			successCount++
-			if *verbose {
-				fmt.Printf("✓ %s (%d statements, %d AI annotations)\n",
-					res.file, res.stats.statements, res.stats.annotations)
+		}
+		if *fix {
+			fixed, err := fixFile(res.file, res.diags)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %s: %v\n", res.file, err)
+				failed = true
+			}
+			if fixed > 0 {
+				fmt.Printf("🔧 %s: applied %d fixes\n", res.file, fixed)
+				res.diags = unfixed(res.diags)
+			}
+		}
+		for _, d := range res.diags {
+			mark := "✗"
+			if d.Severity == mbel.SeverityWarning {
+				mark = "⚠"
+			} else {
+				failed = true
 			}
+			fmt.Fprintf(os.Stderr, "%s %s:%s\n", mark, res.file, d)
+		}
+
+		if failed {
+			hasErrors = true
+			continue
+		}
+		successCount++
+		if *verbose {
+			fmt.Printf("✓ %s (%d statements, %d AI annotations)\n",
+				res.file, res.stats.statements, res.stats.annotations)
+		}
+	}
+
+	if *untranslated != "" {
+		var patterns []string
+		if *allow != "" {
+			patterns = strings.Split(*allow, ",")
+		}
+		if err := lintUntranslated(paths, filter, *untranslated, patterns); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
+	if *lengths {
+		over, err := lintLengths(paths, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		hasErrors = hasErrors || over
+	}
+
+	if budget != (mbel.SizeBudget{}) {
+		over, err := lintSizes(paths, filter, budget)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		hasErrors = hasErrors || over
+	}
+
 	if hasErrors {
 		os.Exit(1)
 	}
@@ -378,6 +764,191 @@ func lintCmd(args []string) {
 	fmt.Printf("✓ %d files valid\n", successCount)
 }
 
+// parseKeyPrefixes parses -key-prefixes: "dir=prefix,prefix;dir=prefix",
+// an empty dir standing for files directly in the locale folder
+func parseKeyPrefixes(s string) map[string][]string {
+	if s == "" {
+		return nil
+	}
+	prefixes := make(map[string][]string)
+	for _, group := range strings.Split(s, ";") {
+		dir, list, _ := strings.Cut(group, "=")
+		for _, p := range strings.Split(list, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				dir := strings.TrimSpace(dir)
+				prefixes[dir] = append(prefixes[dir], p)
+			}
+		}
+	}
+	return prefixes
+}
+
+// lintNamespace returns the folder namespace of file below the linted
+// directory containing it
+func lintNamespace(paths []string, file string) string {
+	for _, root := range paths {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			if ns := mbel.FileNamespace(root, file); ns != "" {
+				return ns
+			}
+		}
+	}
+	return ""
+}
+
+// fixFile applies the suggested fixes of diags to file
+func fixFile(file string, diags []mbel.Diagnostic) (int, error) {
+	if len(unfixed(diags)) == len(diags) {
+		return 0, nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	out, n := mbel.ApplySuggestions(content, diags)
+	return n, ioutil.WriteFile(file, out, 0644)
+}
+
+// unfixed returns the diagnostics without a suggested fix
+func unfixed(diags []mbel.Diagnostic) []mbel.Diagnostic {
+	var out []mbel.Diagnostic
+	for _, d := range diags {
+		if d.Suggestion == "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// lintLengths prints, per locale, every translation in the given
+// directories exceeding its AI_MaxLength budget, and reports whether
+// there were any
+func lintLengths(paths []string, filter mbel.KeyFilter) (bool, error) {
+	found := false
+	for _, root := range paths {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+
+		repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler)}
+		langData, err := repo.LoadAll()
+		if err != nil {
+			return found, err
+		}
+		for lang, data := range langData {
+			langData[lang] = filter.Apply(data)
+		}
+		overruns, err := mbel.CheckLengths(langData, repo)
+		if err != nil {
+			return found, err
+		}
+
+		lang := ""
+		for _, o := range overruns {
+			if o.Lang != lang {
+				lang = o.Lang
+				fmt.Fprintf(os.Stderr, "\n📏 %s: over length budget\n", lang)
+			}
+			fmt.Fprintf(os.Stderr, "✗ %s: %s is %d/%d characters (+%d%%)\n", o.Source, o.Key, o.Length, o.Budget, o.PercentOver())
+		}
+		found = found || len(overruns) > 0
+	}
+	return found, nil
+}
+
+// lintSizes reports each locale directory's compiled bundles over
+// budget, with the namespaces taking the most room
+func lintSizes(paths []string, filter mbel.KeyFilter, budget mbel.SizeBudget) (bool, error) {
+	found := false
+	for _, root := range paths {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+
+		repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler)}
+		langData, err := repo.LoadAll()
+		if err != nil {
+			return found, err
+		}
+		for lang, data := range langData {
+			langData[lang] = filter.Apply(data)
+		}
+		sizes, err := mbel.MeasureBundles(langData)
+		if err != nil {
+			return found, err
+		}
+
+		for _, s := range sizes {
+			if !s.Over(budget) {
+				continue
+			}
+			found = true
+			limit := func(n int) string {
+				if n == 0 {
+					return ""
+				}
+				return " (limit " + mbel.FormatByteSize(n) + ")"
+			}
+			fmt.Fprintf(os.Stderr, "\n📦 %s: bundle over budget: %s%s, %s gzipped%s\n",
+				s.Lang, mbel.FormatByteSize(s.Bytes), limit(budget.MaxBytes), mbel.FormatByteSize(s.Gzip), limit(budget.MaxGzip))
+			for i, ns := range s.Namespaces {
+				if i == 5 {
+					break
+				}
+				name := ns.Namespace
+				if name == "" {
+					name = "(no namespace)"
+				}
+				fmt.Fprintf(os.Stderr, "  %-24s %9s  %3d%%\n", name, mbel.FormatByteSize(ns.Bytes), ns.Bytes*100/s.Bytes)
+			}
+		}
+	}
+	return found, nil
+}
+
+// lintUntranslated warns about values in each locale directory that are
+// byte-identical to the source locale's, i.e. probably never translated
+func lintUntranslated(paths []string, filter mbel.KeyFilter, source string, allow []string) error {
+	for _, root := range paths {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+
+		// Syntax errors were already reported per file
+		repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler)}
+		langData, err := repo.LoadAll()
+		if err != nil {
+			return err
+		}
+		for lang, data := range langData {
+			langData[lang] = filter.Apply(data)
+		}
+		src, ok := langData[source]
+		if !ok {
+			return fmt.Errorf("%s: source locale %q not found", root, source)
+		}
+
+		langs := make([]string, 0, len(langData))
+		for lang := range langData {
+			if lang != source && lang != mbel.CommonLocale {
+				langs = append(langs, lang)
+			}
+		}
+		sort.Strings(langs)
+
+		for _, lang := range langs {
+			for _, key := range mbel.Untranslated(src, langData[lang], allow) {
+				where := root
+				if loc, ok := repo.Locate(lang, key); ok {
+					where = loc.String()
+				}
+				fmt.Fprintf(os.Stderr, "⚠ %s: %s (%s) is identical to %s, probably untranslated\n", where, key, lang, source)
+			}
+		}
+	}
+	return nil
+}
+
 // ============================================================================
 // COMPILE COMMAND
 // ============================================================================
@@ -389,7 +960,24 @@ func compileCmd(args []string) {
 	parallel := fs.Int("j", runtime.NumCPU(), "Parallel workers")
 	withNamespace := fs.Bool("ns", true, "Derive namespace from folder path")
 	sourcemap := fs.Bool("sourcemap", false, "Generate sourcemap.json alongside compiled output")
-	fs.Parse(args)
+	useCache := fs.Bool("cache", true, "Reuse compiled output of unchanged files (~/.cache/mbel)")
+	format := fs.String("format", "json", "Output format: json or bundle (binary, loadable with mbel.OpenBundle)")
+	target := fs.String("target", "", "Generate code instead of data: go (typed accessors for the -source locale of a locales root)")
+	source := fs.String("source", "en", "Source locale, for -target go")
+	goPackage := fs.String("package", "", "Go package of -target go output (default: $GOPACKAGE, else the output directory's name)")
+	stream := fs.Bool("stream", false, "Compile files one at a time, writing JSON as keys are parsed (for very large files)")
+	pluginPaths := fs.String("plugin", "", "Comma-separated plugin .so files registering compile transforms (also $MBEL_PLUGINS)")
+	validate := fs.Bool("validate", false, "Fail on values breaking the copy rules of their annotations (AI_MinLength, AI_Pattern, ...)")
+	keyFilter := keyFilterFlags(fs)
+	interpolation := interpolationFlags(fs)
+	parseFlags(fs, args)
+	filter := keyFilter()
+	style := interpolation()
+
+	if err := loadPlugins(*pluginPaths); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	paths := fs.Args()
 	if len(paths) == 0 {
@@ -398,6 +986,23 @@ func compileCmd(args []string) {
 		os.Exit(1)
 	}
 
+	switch *target {
+	case "":
+	case "go":
+		n, err := generateGo(paths[0], *source, *output, *goPackage, style)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *output != "" {
+			fmt.Printf("✓ Generated %s (%d keys)\n", *output, n)
+		}
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown target %q (want go)\n", *target)
+		os.Exit(1)
+	}
+
 	files, err := discoverFiles(paths)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -420,9 +1025,33 @@ func compileCmd(args []string) {
 		}
 	}
 
-	// Parallel compilation
-	results := make(chan compileResult, len(files))
-	fileChan := make(chan string, len(files))
+	if *stream {
+		if err := streamCompile(files, basePath, filter, style, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *output != "" {
+			fmt.Printf("✓ Compiled %d files to %s\n", len(files), *output)
+		}
+		return
+	}
+
+	// Sourcemaps need the parsed program, which the cache does not keep
+	var cache *mbel.CompileCache
+	if *useCache && !*sourcemap {
+		cache, _ = mbel.DefaultCompileCache()
+	}
+
+	// Parallel compilation. Files are memory-mapped and compiled with
+	// pooled parsers; the feeder stays at most window files ahead of the
+	// merge, so only that many results are ever held at once.
+	window := 4 * *parallel
+	if window < 1 {
+		window = 1
+	}
+	results := make(chan compileResult, window)
+	fileChan := make(chan int)
+	slots := make(chan struct{}, window)
 
 	// Start workers
 	var wg sync.WaitGroup
@@ -430,46 +1059,46 @@ func compileCmd(args []string) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for file := range fileChan {
-				res := compileResult{file: file}
+			for idx := range fileChan {
+				file := files[idx]
+				res := compileResult{index: idx, file: file}
 
 				if *withNamespace && basePath != "" {
 					res.namespace = deriveNamespace(file, basePath)
 				}
 
-				content, err := ioutil.ReadFile(file)
+				if !*sourcemap {
+					data, errs, err := mbel.CompileFileWithStyle(file, cache, style)
+					if len(errs) > 0 {
+						res.err = fmt.Errorf("syntax errors:\n  %s", strings.Join(errs, "\n  "))
+					} else if err != nil {
+						res.err = err
+					}
+					res.data = data
+					results <- res
+					continue
+				}
+
+				program, errs, err := mbel.ParseFile(file)
 				if err != nil {
 					res.err = err
 					results <- res
 					continue
 				}
-
-				l := mbel.NewLexer(string(content))
-				p := mbel.NewParser(l)
-				program := p.ParseProgram()
-
-				if errs := p.Errors(); len(errs) > 0 {
+				if len(errs) > 0 {
 					res.err = fmt.Errorf("syntax errors:\n  %s", strings.Join(errs, "\n  "))
 					results <- res
 					continue
 				}
 
-				c := mbel.NewCompiler()
-				result, err := c.Compile(program)
+				result, err := (&mbel.Compiler{Interpolation: style}).Compile(program)
 				if err != nil {
 					res.err = err
 					results <- res
 					continue
 				}
 
-				resultMap, ok := result.(map[string]interface{})
-				if !ok {
-					res.err = fmt.Errorf("unexpected result type: %T", result)
-					results <- res
-					continue
-				}
-
-				res.data = resultMap
+				res.data = mbel.ApplyCompileTransforms(result.Data)
 				// Store program for sourcemap generation
 				res.program = program
 				results <- res
@@ -478,10 +1107,13 @@ func compileCmd(args []string) {
 	}
 
 	// Feed files
-	for _, f := range files {
-		fileChan <- f
-	}
-	close(fileChan)
+	go func() {
+		for i := range files {
+			slots <- struct{}{}
+			fileChan <- i
+		}
+		close(fileChan)
+	}()
 
 	// Wait and collect
 	go func() {
@@ -489,33 +1121,78 @@ func compileCmd(args []string) {
 		close(results)
 	}()
 
-	// Merge all results & collect for sourcemap
+	// Merge all results & collect for sourcemap. Workers finish in any
+	// order; merge in file order so the output is reproducible.
 	merged := make(map[string]interface{})
 	hasErrors := false
 	var allResults []compileResult // Keep results for sourcemap
 
-	for res := range results {
-		if res.err != nil {
-			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", res.file, res.err)
-			hasErrors = true
-			continue
-		}
+	pending := make(map[int]compileResult, window)
+	next := 0
+	for r := range results {
+		pending[r.index] = r
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+
+			if res.err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %s: %v\n", res.file, res.err)
+				hasErrors = true
+				continue
+			}
+			if *validate {
+				if violations := mbel.ValidateCopy(res.data); len(violations) > 0 {
+					for _, v := range violations {
+						fmt.Fprintf(os.Stderr, "✗ %s: %v\n", res.file, v)
+					}
+					hasErrors = true
+					continue
+				}
+			}
 
-		allResults = append(allResults, res) // Store for sourcemap
+			// Merge with namespace prefix
+			for k, v := range res.data {
+				key := k
+				if res.namespace != "" && !strings.HasPrefix(k, "__") {
+					key = res.namespace + "." + k
+				}
+				merged[key] = v
+			}
 
-		// Merge with namespace prefix
-		for k, v := range res.data {
-			key := k
-			if res.namespace != "" && !strings.HasPrefix(k, "__") {
-				key = res.namespace + "." + k
+			if *sourcemap {
+				res.data = nil
+				allResults = append(allResults, res) // Store for sourcemap
 			}
-			merged[key] = v
 		}
 	}
 
 	if hasErrors {
 		os.Exit(1)
 	}
+	merged = filter.Apply(merged)
+
+	if *format == "bundle" {
+		if *output == "" {
+			fmt.Fprintln(os.Stderr, "Error: -format bundle requires -o")
+			os.Exit(1)
+		}
+		var buf bytes.Buffer
+		if err := mbel.WriteBundle(&buf, merged); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding bundle: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ioutil.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Compiled %d files to %s\n", len(files), *output)
+		return
+	}
 
 	var jsonData []byte
 	if *pretty {
@@ -538,7 +1215,7 @@ func compileCmd(args []string) {
 		// Generate sourcemap if requested
 		if *sourcemap {
 			sourcemapPath := strings.TrimSuffix(*output, filepath.Ext(*output)) + ".sourcemap.json"
-			sourcemapData := generateSourcemap(allResults)
+			sourcemapData := filter.Apply(generateSourcemap(allResults))
 
 			sourcemapJSON, err := json.MarshalIndent(sourcemapData, "", "  ")
 			if err != nil {
@@ -557,6 +1234,91 @@ func compileCmd(args []string) {
 	}
 }
 
+// streamCompile writes one JSON object with the keys of all files,
+// encoding each value as soon as it is compiled. Nothing is merged in
+// memory, so a key defined in several files appears more than once
+// (JSON readers keep the last one, as the merging compile does). Keys
+// filter does not select are skipped.
+func streamCompile(files []string, basePath string, filter mbel.KeyFilter, style mbel.Interpolation, output string) error {
+	out := os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := bufio.NewWriter(out)
+	w.WriteString("{")
+	first := true
+
+	for _, file := range files {
+		namespace := ""
+		if basePath != "" {
+			namespace = deriveNamespace(file, basePath)
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		errs, err := mbel.CompileStreamWithStyle(f, style, func(key string, value interface{}) error {
+			if namespace != "" && !strings.HasPrefix(key, "__") {
+				key = namespace + "." + key
+			}
+			if strings.HasPrefix(key, "__") {
+				value = filter.Apply(map[string]interface{}{key: value})[key]
+			} else if !filter.Match(key) {
+				return nil
+			}
+			k, _ := json.Marshal(key)
+			v, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if !first {
+				w.WriteString(",")
+			}
+			first = false
+			w.WriteString("\n  ")
+			w.Write(k)
+			w.WriteString(": ")
+			w.Write(v)
+			return nil
+		})
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%s: syntax errors:\n  %s", file, strings.Join(errs, "\n  "))
+		}
+	}
+
+	w.WriteString("\n}\n")
+	return w.Flush()
+}
+
+// inFileOrder drains results from parallel workers and returns them in
+// the order of files
+func inFileOrder[R any](results <-chan R, files []string, fileOf func(R) string) []R {
+	index := make(map[string]int, len(files))
+	for i, f := range files {
+		index[f] = i
+	}
+
+	var out []R
+	for res := range results {
+		out = append(out, res)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return index[fileOf(out[i])] < index[fileOf(out[j])]
+	})
+	return out
+}
+
 // generateSourcemap builds a sourcemap from compilation results
 func generateSourcemap(results []compileResult) map[string]interface{} {
 	sourcemap := make(map[string]interface{})
@@ -593,6 +1355,95 @@ func generateSourcemap(results []compileResult) map[string]interface{} {
 	return sourcemap
 }
 
+// ============================================================================
+// GENERATE COMMAND
+// ============================================================================
+
+// generateCmd runs the [[generate]] targets of the nearest mbel.toml,
+// for a "//go:generate mbel generate" line
+func generateCmd(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	fs.Parse(args)
+
+	path := mbel.FindProject(".")
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "Error: no %s in this directory or its parents\n", mbel.ProjectFileName)
+		os.Exit(1)
+	}
+	project, err := mbel.ReadProject(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(project.Generate) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s has no [[generate]] targets\n", path)
+		return
+	}
+
+	failed := false
+	for _, t := range project.Generate {
+		output := filepath.Join(project.Dir, filepath.FromSlash(t.Output))
+		source := t.Source
+		if source == "" {
+			source = "en"
+		}
+		n, err := generateGo(filepath.Join(project.Dir, filepath.FromSlash(t.Locales)), source, output, t.Package, mbel.InterpolationSingle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", t.Output, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("✓ Generated %s (%d keys)\n", t.Output, n)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// generateGo writes typed accessors for the source locale of the catalog
+// at root to output (stdout when ""), and returns the number of keys.
+// The package defaults to $GOPACKAGE, set by go generate, and then to
+// the output directory's name. Files without @interpolation are read in
+// style.
+func generateGo(root, source, output, pkg string, style mbel.Interpolation) (int, error) {
+	repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler), Interpolation: style}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		return 0, err
+	}
+	data, ok := langData[source]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s in %s", mbel.ErrLocaleNotFound, source, root)
+	}
+
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	if pkg == "" && output != "" {
+		abs, err := filepath.Abs(output)
+		if err != nil {
+			return 0, err
+		}
+		pkg = filepath.Base(filepath.Dir(abs))
+	}
+	if pkg == "" {
+		pkg = "i18n"
+	}
+
+	var buf bytes.Buffer
+	if err := mbel.GenerateGo(&buf, data, pkg); err != nil {
+		return 0, err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return len(mbel.SortedKeys(data)), err
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return 0, err
+	}
+	return len(mbel.SortedKeys(data)), ioutil.WriteFile(output, buf.Bytes(), 0644)
+}
+
 // ============================================================================
 // WATCH COMMAND
 // ============================================================================
@@ -601,7 +1452,9 @@ func watchCmd(args []string) {
 	fs := flag.NewFlagSet("watch", flag.ExitOnError)
 	output := fs.String("o", "", "Output file")
 	interval := fs.Int("i", 1000, "Poll interval in milliseconds")
-	fs.Parse(args)
+	interpolation := interpolationFlags(fs)
+	parseFlags(fs, args)
+	style := interpolation()
 
 	paths := fs.Args()
 	if len(paths) == 0 {
@@ -659,12 +1512,15 @@ func watchCmd(args []string) {
 					continue
 				}
 
-				c := mbel.NewCompiler()
-				compiled, _ := c.Compile(program)
-				if compMap, ok := compiled.(map[string]interface{}); ok {
-					for k, v := range compMap {
-						result[k] = v
-					}
+				c := &mbel.Compiler{Interpolation: style}
+				compiled, err := c.Compile(program)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "  ✗ %s: %v\n", filepath.Base(file), err)
+					hasErrors = true
+					continue
+				}
+				for k, v := range mbel.ApplyCompileTransforms(compiled.Data) {
+					result[k] = v
 				}
 			}
 
@@ -685,13 +1541,21 @@ func watchCmd(args []string) {
 
 func fmtCmd(args []string) {
 	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
-	dryRun := fs.Bool("n", false, "Dry run (show changes without writing)")
-	fs.Parse(args)
+	dryRun := fs.Bool("n", false, "Dry run (list files that would change)")
+	preview := fs.Bool("dry-run", false, "Print the diff of every file that would change, without writing")
+	eol := fs.String("eol", "lf", "Line endings: lf, crlf or auto (keep each file's)")
+	interpolation := interpolationFlags(fs)
+	parseFlags(fs, args)
+	style := interpolation()
 
 	paths := fs.Args()
 	if len(paths) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: No files specified")
-		fmt.Fprintln(os.Stderr, "Usage: mbel fmt <files...> [-n]")
+		fmt.Fprintln(os.Stderr, "Usage: mbel fmt [-n|-dry-run] [-eol lf|crlf|auto] <files...>")
+		os.Exit(1)
+	}
+	if *eol != "lf" && *eol != "crlf" && *eol != "auto" {
+		fmt.Fprintf(os.Stderr, "Error: invalid -eol %q (want lf, crlf or auto)\n", *eol)
 		os.Exit(1)
 	}
 
@@ -709,20 +1573,22 @@ func fmtCmd(args []string) {
 			continue
 		}
 
-		// Parse and re-emit formatted
-		l := mbel.NewLexer(string(content))
-		p := mbel.NewParser(l)
-		program := p.ParseProgram()
-
-		if len(p.Errors()) > 0 {
+		newContent, err := mbel.FormatSourceWithStyle(string(content), style)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "✗ %s: syntax errors\n", file)
 			continue
 		}
-
-		newContent := formatProgram(program)
+		switch *eol {
+		case "crlf":
+			newContent = mbel.NormalizeLineEndings(newContent, "\r\n")
+		case "auto":
+			newContent = mbel.NormalizeLineEndings(newContent, mbel.DetectLineEnding(string(content)))
+		}
 
 		if string(content) != newContent {
-			if *dryRun {
+			if *preview {
+				fmt.Print(mbel.UnifiedDiff(file, string(content), newContent))
+			} else if *dryRun {
 				fmt.Printf("Would format: %s\n", file)
 			} else {
 				ioutil.WriteFile(file, []byte(newContent), 0644)
@@ -735,58 +1601,32 @@ func fmtCmd(args []string) {
 	fmt.Printf("✓ %d files formatted\n", formatted)
 }
 
-func formatProgram(p *mbel.Program) string {
-	var b strings.Builder
-
-	// Metadata first
-	for _, stmt := range p.Statements {
-		if ms, ok := stmt.(*mbel.MetadataStatement); ok {
-			b.WriteString(fmt.Sprintf("@%s: %s\n", ms.Key, ms.Value))
-		}
-	}
-
-	// Then sections and assignments
-	currentSection := ""
-	for _, stmt := range p.Statements {
-		switch s := stmt.(type) {
-		case *mbel.SectionStatement:
-			if b.Len() > 0 {
-				b.WriteString("\n")
-			}
-			b.WriteString(fmt.Sprintf("[%s]\n", s.Name))
-			currentSection = s.Name
-		case *mbel.AssignStatement:
-			if currentSection == "" && b.Len() > 0 {
-				b.WriteString("\n")
-			}
-			if sl, ok := s.Value.(*mbel.StringLiteral); ok {
-				if strings.Contains(sl.Value, "\n") {
-					b.WriteString(fmt.Sprintf("%s = \"\"\"\n%s\"\"\"\n", s.Name, sl.Value))
-				} else {
-					b.WriteString(fmt.Sprintf("%s = \"%s\"\n", s.Name, sl.Value))
-				}
-			} else if _, ok := s.Value.(*mbel.BlockExpression); ok {
-				b.WriteString(fmt.Sprintf("%s(...) { ... }\n", s.Name))
-			}
-		}
-	}
-
-	return b.String()
-}
-
 // ============================================================================
 // STATS COMMAND
 // ============================================================================
 
 func statsCmd(args []string) {
 	fs := flag.NewFlagSet("stats", flag.ExitOnError)
-	fs.Parse(args)
+	source := fs.String("source", "en", "Source locale completeness is measured against")
+	badge := fs.String("badge", "", "Write an SVG completeness badge of this locale instead of statistics")
+	output := fs.String("o", "", "Badge output file (default: stdout)")
+	ws := parseFlags(fs, args)
 
 	paths := fs.Args()
+	if len(paths) == 0 && ws != nil && *badge == "" {
+		runWorkspace(ws, "stats", args, func(root string) string {
+			return completenessSummary(root, *source)
+		})
+		return
+	}
 	if len(paths) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: No path specified")
 		os.Exit(1)
 	}
+	if *badge != "" {
+		writeBadge(paths[0], *source, *badge, *output)
+		return
+	}
 
 	files, err := discoverFiles(paths)
 	if err != nil {
@@ -799,6 +1639,7 @@ func statsCmd(args []string) {
 	totalBlocks := 0
 	totalAnnotations := 0
 	keyCount := make(map[string]int)
+	statusCount := make(map[mbel.KeyStatus]int)
 
 	for _, file := range files {
 		content, err := ioutil.ReadFile(file)
@@ -811,6 +1652,9 @@ func statsCmd(args []string) {
 		program := p.ParseProgram()
 
 		totalAnnotations += len(program.AIAnnotations)
+		for _, status := range mbel.KeyStatuses(program) {
+			statusCount[status]++
+		}
 
 		for _, stmt := range program.Statements {
 			if as, ok := stmt.(*mbel.AssignStatement); ok {
@@ -841,6 +1685,10 @@ func statsCmd(args []string) {
 	fmt.Printf("  Strings:      %d\n", totalStrings)
 	fmt.Printf("  Logic blocks: %d\n", totalBlocks)
 	fmt.Printf("AI annotations: %d\n", totalAnnotations)
+	if statusCount[mbel.StatusReviewed]+statusCount[mbel.StatusFinal] > 0 {
+		fmt.Printf("Review status:  %d final, %d reviewed, %d draft\n",
+			statusCount[mbel.StatusFinal], statusCount[mbel.StatusReviewed], statusCount[mbel.StatusDraft])
+	}
 
 	if len(duplicates) > 0 {
 		fmt.Printf("\n⚠️  Duplicate keys (%d):\n", len(duplicates))
@@ -848,6 +1696,52 @@ func statsCmd(args []string) {
 			fmt.Printf("  - %s\n", d)
 		}
 	}
+
+	if info, err := os.Stat(paths[0]); err == nil && info.IsDir() {
+		repo := &mbel.FileRepository{RootPath: paths[0], Logger: slog.New(slog.DiscardHandler)}
+		langData, _ := repo.LoadAll()
+		if src, ok := langData[*source]; ok && len(langData) > 1 {
+			fmt.Printf("\nCompleteness (against %s):\n", *source)
+			for _, lang := range sortedLangs(langData) {
+				if lang != *source && lang != mbel.CommonLocale {
+					fmt.Printf("  %-12s %3d%%\n", lang, int(mbel.Completeness(src, langData[lang])*100))
+				}
+			}
+		}
+	}
+}
+
+// writeBadge writes the completeness badge of lang in the locale
+// directory root to output, or stdout
+func writeBadge(root, source, lang, output string) {
+	repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler)}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	src, ok := langData[source]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: source locale %s not found\n", source)
+		os.Exit(1)
+	}
+	data, ok := langData[lang]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: no locale %s\n", lang)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	mbel.WriteCompletenessBadge(&buf, lang, mbel.Completeness(src, data))
+	if output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Wrote %s badge to %s\n", lang, output)
 }
 
 // ============================================================================
@@ -856,165 +1750,1192 @@ func statsCmd(args []string) {
 
 func diffCmd(args []string) {
 	fs := flag.NewFlagSet("diff", flag.ExitOnError)
-	fs.Parse(args)
+	source := fs.String("source", "en", "Source locale of each project, in workspace mode")
+	ws := parseFlags(fs, args)
 
 	paths := fs.Args()
+	if len(paths) == 0 && ws != nil {
+		diffWorkspace(ws, *source)
+		return
+	}
 	if len(paths) < 2 {
 		fmt.Fprintln(os.Stderr, "Error: Need two paths to compare")
 		fmt.Fprintln(os.Stderr, "Usage: mbel diff <path1> <path2>")
 		os.Exit(1)
 	}
+	printDiff(paths[0], paths[1])
+}
 
-	keys1 := collectKeys(paths[0])
-	keys2 := collectKeys(paths[1])
-
-	// Find missing in path2
-	missing := []string{}
-	for key := range keys1 {
-		if _, exists := keys2[key]; !exists {
-			missing = append(missing, key)
-		}
-	}
-	sort.Strings(missing)
+// printDiff prints the keys of b missing, extra, mismatched or stale
+// against a, and reports whether there were none
+func printDiff(a, b string) bool {
+	report := mbel.DiffCatalogs(compilePath(a), compilePath(b))
+	missing, extra, mismatched := report.Missing, report.Extra, report.Placeholders
 
-	// Find extra in path2
-	extra := []string{}
-	for key := range keys2 {
-		if _, exists := keys1[key]; !exists {
-			extra = append(extra, key)
-		}
-	}
-	sort.Strings(extra)
+	lang, stale := diffStale(b)
 
-	fmt.Printf("🔍 Comparing %s ↔ %s\n", paths[0], paths[1])
+	fmt.Printf("🔍 Comparing %s ↔ %s\n", a, b)
 	fmt.Println("──────────────────────────")
 
-	if len(missing) == 0 && len(extra) == 0 {
+	if len(missing) == 0 && len(extra) == 0 && len(mismatched) == 0 && len(stale) == 0 {
 		fmt.Println("✓ All keys match!")
-		return
+		return true
 	}
 
 	if len(missing) > 0 {
-		fmt.Printf("\n❌ Missing in %s (%d):\n", paths[1], len(missing))
+		fmt.Printf("\n❌ Missing in %s (%d):\n", b, len(missing))
 		for _, k := range missing {
 			fmt.Printf("  - %s\n", k)
 		}
 	}
 
 	if len(extra) > 0 {
-		fmt.Printf("\n➕ Extra in %s (%d):\n", paths[1], len(extra))
+		fmt.Printf("\n➕ Extra in %s (%d):\n", b, len(extra))
 		for _, k := range extra {
 			fmt.Printf("  + %s\n", k)
 		}
 	}
-}
-
-func collectKeys(path string) map[string]bool {
-	keys := make(map[string]bool)
-
-	files, err := discoverFiles([]string{path})
-	if err != nil {
-		return keys
-	}
 
-	for _, file := range files {
-		content, err := ioutil.ReadFile(file)
-		if err != nil {
-			continue
+	if len(mismatched) > 0 {
+		fmt.Printf("\n⚠ Placeholder mismatches in %s (%d):\n", b, len(mismatched))
+		for _, m := range mismatched {
+			var parts []string
+			if len(m.Missing) > 0 {
+				parts = append(parts, "missing "+strings.Join(m.Missing, ", "))
+			}
+			if len(m.Extra) > 0 {
+				parts = append(parts, "unexpected "+strings.Join(m.Extra, ", "))
+			}
+			fmt.Printf("  ! %s: %s\n", m.Key, strings.Join(parts, "; "))
 		}
+	}
 
-		l := mbel.NewLexer(string(content))
-		p := mbel.NewParser(l)
-		program := p.ParseProgram()
-
-		for _, stmt := range program.Statements {
-			if as, ok := stmt.(*mbel.AssignStatement); ok {
-				keys[as.Name] = true
-			}
+	if len(stale) > 0 {
+		fmt.Printf("\n⏳ Stale in %s (%d, source changed since translated):\n", lang, len(stale))
+		for _, k := range stale {
+			fmt.Printf("  ~ %s\n", k)
 		}
 	}
+	return false
+}
 
-	return keys
+// diffStale returns the locale of target and its stale translations,
+// according to the lockfile in target's parent directory (none without)
+func diffStale(target string) (string, []string) {
+	root := filepath.Dir(filepath.Clean(target))
+	lock, err := mbel.ReadLockFile(filepath.Join(root, mbel.LockFileName))
+	if err != nil {
+		return "", nil
+	}
+	repo := &mbel.FileRepository{RootPath: root, Logger: slog.New(slog.DiscardHandler)}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		return "", nil
+	}
+	lang := strings.TrimSuffix(filepath.Base(target), ".mbel")
+	return lang, lock.Stale(langData, lang)
 }
 
 // ============================================================================
-// IMPORT COMMAND
+// APPROVE COMMAND
 // ============================================================================
 
-func importCmd(args []string) {
-	fs := flag.NewFlagSet("import", flag.ExitOnError)
-	output := fs.String("o", "", "Output .mbel file")
-	namespace := fs.String("ns", "", "Namespace for imported keys")
+func approveCmd(args []string) {
+	fs := flag.NewFlagSet("approve", flag.ExitOnError)
+	status := fs.String("status", "reviewed", "Status to set (draft, reviewed, final)")
+	lang := fs.String("lang", "", "Only approve keys of this locale")
+	dryRun := fs.Bool("dry-run", false, "Print the diff of every file that would change, without writing")
 	fs.Parse(args)
 
-	files := fs.Args()
-	if len(files) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: No JSON file specified")
-		fmt.Fprintln(os.Stderr, "Usage: mbel import <file.json> [-o output.mbel]")
+	rest := fs.Args()
+	if len(rest) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: mbel approve [-status reviewed|final|draft] [-lang pl] [-dry-run] <dir> <key patterns...>")
 		os.Exit(1)
 	}
-
-	content, err := ioutil.ReadFile(files[0])
+	target, err := mbel.ParseKeyStatus(*status)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	root, patterns := rest[0], rest[1:]
 
-	var data map[string]interface{}
-	if err := json.Unmarshal(content, &data); err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing JSON: %v\n", err)
+	files, err := discoverFiles([]string{root})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	var b strings.Builder
-
-	if *namespace != "" {
-		b.WriteString(fmt.Sprintf("@namespace: %s\n\n", *namespace))
-	}
-
-	// Sort keys for consistent output
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+	total := 0
+	for _, file := range files {
+		if *lang != "" && !inLocale(root, file, *lang) {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			os.Exit(1)
+		}
 
-	for _, key := range keys {
-		val := data[key]
-		switch v := val.(type) {
-		case string:
-			if strings.Contains(v, "\n") {
-				b.WriteString(fmt.Sprintf("%s = \"\"\"\n%s\"\"\"\n", key, v))
-			} else {
-				b.WriteString(fmt.Sprintf("%s = \"%s\"\n", key, v))
+		ns := mbel.FileNamespace(root, file)
+		out, changed := mbel.SetKeyStatus(content, target, func(key string) bool {
+			if ns != "" {
+				key = ns + "." + key
 			}
-		default:
-			// Skip non-string values
+			for _, p := range patterns {
+				if ok, _ := path.Match(p, key); ok {
+					return true
+				}
+			}
+			return false
+		})
+		if len(changed) == 0 {
+			continue
 		}
-	}
-
-	result := b.String()
-
-	if *output != "" {
-		if err := ioutil.WriteFile(*output, []byte(result), 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		if err := writeFileOrDiff(file, out, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
 			os.Exit(1)
 		}
-		fmt.Printf("✓ Converted %d keys to %s\n", len(keys), *output)
-	} else {
-		fmt.Print(result)
+		if !*dryRun {
+			fmt.Printf("✓ %s: %d keys marked %s\n", file, len(changed), target)
+		}
+		total += len(changed)
+	}
+	if total == 0 {
+		fmt.Println("No keys changed")
 	}
 }
 
-// ============================================================================
-// TRANSLATE COMMAND (SCAFFOLD)
-// ============================================================================
-
-func translateCmd(args []string) {
-	fs := flag.NewFlagSet("translate", flag.ExitOnError)
-	toLang := fs.String("to", "", "Target language code (e.g. pl, de)")
+// inLocale reports whether file belongs to lang: its top-level folder or
+// file name below root, or else its @lang
+func inLocale(root, file, lang string) bool {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return false
+	}
+	top := strings.TrimSuffix(strings.Split(filepath.ToSlash(rel), "/")[0], ".mbel")
+	if top == lang {
+		return true
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	return mbel.Metadata(mbel.NewParser(mbel.NewLexer(string(content))).ParseProgram())["lang"] == lang
+}
+
+// writeFileOrDiff writes content to path, or with dryRun prints the
+// unified diff of the change instead
+func writeFileOrDiff(path string, content []byte, dryRun bool) error {
+	if !dryRun {
+		return ioutil.WriteFile(path, content, 0644)
+	}
+	before, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	fmt.Print(mbel.UnifiedDiff(path, string(before), string(content)))
+	return nil
+}
+
+// ============================================================================
+// REFACTOR-PLACEHOLDERS COMMAND
+// ============================================================================
+
+func refactorPlaceholdersCmd(args []string) {
+	fs := flag.NewFlagSet("refactor-placeholders", flag.ExitOnError)
+	rename := fs.String("rename", "", "Placeholders to rename, e.g. name=userName,n=count")
+	keys := fs.String("keys", "", "Comma-separated key patterns to touch (default: all keys)")
+	dryRun := fs.Bool("dry-run", false, "Print the diff of every file that would change, without writing")
+	fs.Parse(args)
+
+	roots := fs.Args()
+	if *rename == "" || len(roots) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: mbel refactor-placeholders -rename old=new [-keys 'auth.*'] [-dry-run] <dir> [dir2 ...]")
+		os.Exit(1)
+	}
+	renames, err := mbel.ParsePlaceholderRenames(*rename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var patterns []string
+	if *keys != "" {
+		patterns = strings.Split(*keys, ",")
+	}
+
+	total := 0
+	for _, root := range roots {
+		files, err := discoverFiles([]string{root})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+				os.Exit(1)
+			}
+
+			ns := mbel.FileNamespace(root, file)
+			out, changed := mbel.RenamePlaceholders(content, renames, func(key string) bool {
+				if ns != "" {
+					key = ns + "." + key
+				}
+				if len(patterns) == 0 {
+					return true
+				}
+				for _, p := range patterns {
+					if ok, _ := path.Match(p, key); ok {
+						return true
+					}
+				}
+				return false
+			})
+			if len(changed) == 0 {
+				continue
+			}
+			if err := writeFileOrDiff(file, out, *dryRun); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+				os.Exit(1)
+			}
+			if !*dryRun {
+				fmt.Printf("✓ %s: %d keys updated\n", file, len(changed))
+			}
+			total += len(changed)
+		}
+	}
+	if total == 0 {
+		fmt.Println("No keys changed")
+	}
+}
+
+// ============================================================================
+// MV COMMAND
+// ============================================================================
+
+func mvCmd(args []string) {
+	fs := flag.NewFlagSet("mv", flag.ExitOnError)
+	mapFile := fs.String("map", "key-map.json", "Write the old -> new key mapping here, for code and cached bundles (\"\" = none)")
+	dryRun := fs.Bool("dry-run", false, "Print the diff of every file that would change, without writing")
+	parseInterspersed(fs, args)
+
+	if fs.NArg() != 3 {
+		fmt.Fprintln(os.Stderr, "Usage: mbel mv [-map key-map.json] [-dry-run] <from> <to> <dir>")
+		fmt.Fprintln(os.Stderr, "  e.g. mbel mv 'auth.*' 'account.auth.*' ./locales")
+		os.Exit(1)
+	}
+	mv, err := mbel.ParseKeyMove(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	res, err := mbel.PlanMove(fs.Arg(2), mv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(res.Moved) == 0 {
+		fmt.Println("No keys matched")
+		return
+	}
+	if *dryRun {
+		for _, c := range res.Changes {
+			fmt.Print(c.Diff())
+		}
+		for _, k := range res.SortedMoves() {
+			fmt.Printf("%s -> %s\n", k, res.Moved[k])
+		}
+		return
+	}
+
+	if err := mbel.WriteChanges(res.Changes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, c := range res.Changes {
+		if c.Removed {
+			os.Remove(filepath.Dir(c.Path)) // only if the move emptied it
+		}
+	}
+	if *mapFile != "" {
+		data, _ := json.MarshalIndent(res.Moved, "", "  ")
+		if err := ioutil.WriteFile(*mapFile, append(data, '\n'), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *mapFile, err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("✓ Moved %d keys in %d files\n", len(res.Moved), len(res.Changes))
+	if *mapFile != "" {
+		fmt.Printf("✓ Wrote key mapping to %s\n", *mapFile)
+	}
+}
+
+// ============================================================================
+// SYNC COMMAND
+// ============================================================================
+
+func syncCmd(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	source := fs.String("source", "", "Source locale (default: the lockfile's, else en)")
+	accept := fs.String("accept", "", "Comma-separated key patterns whose stale translations are still correct")
+	lang := fs.String("lang", "", "Locale -accept applies to (default: all)")
+	dryRun := fs.Bool("dry-run", false, "Print the diff of mbel.lock instead of writing it")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mbel sync [-source en] [-accept patterns [-lang pl]] [-dry-run] <dir>")
+		os.Exit(1)
+	}
+
+	repo := &mbel.FileRepository{RootPath: paths[0]}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	lockPath := filepath.Join(paths[0], mbel.LockFileName)
+	lock, err := mbel.ReadLockFile(lockPath)
+	switch {
+	case os.IsNotExist(err):
+		lock = mbel.NewLockFile("en")
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *source != "" && *source != lock.Source {
+		lock = mbel.NewLockFile(*source) // hashes of another source are meaningless
+	}
+	if _, ok := langData[lock.Source]; !ok {
+		fmt.Fprintf(os.Stderr, "Error: source locale %s not found in %s\n", lock.Source, paths[0])
+		os.Exit(1)
+	}
+
+	lock.Update(langData)
+	for _, l := range sortedLangs(langData) {
+		if *accept == "" || l == lock.Source || (*lang != "" && l != *lang) {
+			continue
+		}
+		if n := len(lock.Accept(langData, l, strings.Split(*accept, ","))); n > 0 {
+			fmt.Printf("✓ %s: accepted %d stale translations\n", l, n)
+		}
+	}
+
+	out, err := lock.Bytes()
+	if err == nil {
+		err = writeFileOrDiff(lockPath, out, *dryRun)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", lockPath, err)
+		os.Exit(1)
+	}
+
+	for _, l := range sortedLangs(langData) {
+		if l == lock.Source || l == mbel.CommonLocale {
+			continue
+		}
+		stale := lock.Stale(langData, l)
+		if len(stale) == 0 {
+			fmt.Printf("✓ %s: %d translations up to date\n", l, len(lock.Locales[l]))
+			continue
+		}
+		fmt.Printf("⏳ %s: %d stale (source changed since translated)\n", l, len(stale))
+		for _, k := range stale {
+			fmt.Printf("  ~ %s\n", k)
+		}
+	}
+	if !*dryRun {
+		fmt.Printf("✓ Wrote %s\n", lockPath)
+	}
+}
+
+// compilePath compiles the .mbel files under path into one catalog,
+// skipping files that cannot be read or compiled
+func compilePath(path string) map[string]interface{} {
+	catalog := make(map[string]interface{})
+
+	files, err := discoverFiles([]string{path})
+	if err != nil {
+		return catalog
+	}
+
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		data, _, err := mbel.CompileSource(content, nil)
+		if err != nil {
+			continue
+		}
+		for k, v := range data {
+			catalog[k] = v
+		}
+	}
+
+	return catalog
+}
+
+// ============================================================================
+// IMPORT COMMAND
+// ============================================================================
+
+// jsonToMBEL converts flat key/value JSON to MBEL source. Non-string
+// values are skipped.
+func jsonToMBEL(data map[string]interface{}, namespace string) string {
+	var b strings.Builder
+
+	if namespace != "" {
+		b.WriteString(fmt.Sprintf("@namespace: %s\n\n", namespace))
+	}
+
+	// Sort keys for consistent output
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val := data[key]
+		switch v := val.(type) {
+		case string:
+			if strings.Contains(v, "\n") {
+				b.WriteString(fmt.Sprintf("%s = \"\"\"\n%s\"\"\"\n", key, v))
+			} else {
+				b.WriteString(fmt.Sprintf("%s = \"%s\"\n", key, v))
+			}
+		default:
+			// Skip non-string values
+		}
+	}
+
+	return b.String()
+}
+
+func importCmd(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	output := fs.String("o", "", "Output .mbel file")
+	namespace := fs.String("ns", "", "Namespace for imported keys")
+	into := fs.String("into", ".", "Locale directory to apply a review sheet to")
+	format := fs.String("format", "json", "Source format (json: flat key/value, i18next: nested with plural suffixes, rails: Rails i18n YAML)")
+	lang := fs.String("lang", "", "Locale to import from a Rails file with several")
+	dryRun := fs.Bool("dry-run", false, "Print the diff of every file that would change, without writing")
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 || (*format != "json" && *format != "i18next" && *format != "rails") {
+		fmt.Fprintln(os.Stderr, "Error: No JSON or YAML file specified")
+		fmt.Fprintln(os.Stderr, "Usage: mbel import [-format json|i18next|rails] [-ns namespace] [-lang locale] [-o output.mbel] [-dry-run] <file.json|file.yml>")
+		fmt.Fprintln(os.Stderr, "       mbel import [-into dir] [-dry-run] <review.xlsx|review.csv>")
+		os.Exit(1)
+	}
+
+	switch strings.ToLower(filepath.Ext(files[0])) {
+	case ".xlsx", ".csv":
+		importReview(files[0], *into, *dryRun)
+		return
+	case ".yml", ".yaml":
+		*format = "rails"
+	}
+
+	content, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var result string
+	var count int
+	if *format == "rails" {
+		data, err := importRails(content, *lang)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing YAML: %v\n", err)
+			os.Exit(1)
+		}
+		if *namespace != "" {
+			data["__meta"].(map[string]string)["namespace"] = *namespace
+		}
+		result, count = mbel.FormatData(data), len(mbel.SortedKeys(data))
+	} else if *format == "i18next" {
+		data, err := mbel.ReadI18next(bytes.NewReader(content))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing JSON: %v\n", err)
+			os.Exit(1)
+		}
+		if *namespace != "" {
+			data["__meta"] = map[string]string{"namespace": *namespace}
+		}
+		result, count = mbel.FormatData(data), len(mbel.SortedKeys(data))
+	} else {
+		var data map[string]interface{}
+		if err := json.Unmarshal(content, &data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing JSON: %v\n", err)
+			os.Exit(1)
+		}
+		result, count = jsonToMBEL(data, *namespace), len(data)
+	}
+
+	if *output != "" {
+		if err := writeFileOrDiff(*output, []byte(result), *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+			os.Exit(1)
+		}
+		if !*dryRun {
+			fmt.Printf("✓ Converted %d keys to %s\n", count, *output)
+		}
+	} else {
+		fmt.Print(result)
+	}
+}
+
+// importRails picks one locale of a Rails i18n YAML file: lang, or the
+// only one the file has
+func importRails(content []byte, lang string) (map[string]interface{}, error) {
+	locales, err := mbel.ReadRailsYAML(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if lang == "" {
+		if len(locales) != 1 {
+			names := make([]string, 0, len(locales))
+			for l := range locales {
+				names = append(names, l)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("file has locales %s; pick one with -lang", strings.Join(names, ", "))
+		}
+		for l := range locales {
+			lang = l
+		}
+	}
+	data, ok := locales[lang]
+	if !ok {
+		return nil, fmt.Errorf("no locale %s in file", lang)
+	}
+	data["__meta"] = map[string]string{"lang": lang}
+	return data, nil
+}
+
+// importReview applies a reviewer sheet written by `mbel export` to the
+// target locale's files under dir
+func importReview(file, dir string, dryRun bool) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var sheet *mbel.ReviewSheet
+	if strings.EqualFold(filepath.Ext(file), ".xlsx") {
+		sheet, err = mbel.ReadReviewXLSX(bytes.NewReader(content), int64(len(content)))
+	} else {
+		sheet, err = mbel.ReadReviewCSV(bytes.NewReader(content))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", file, err)
+		os.Exit(1)
+	}
+
+	res, err := mbel.PlanReview(&mbel.FileRepository{RootPath: dir}, sheet)
+	if err == nil && !dryRun {
+		err = mbel.WriteChanges(res.Changes)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if dryRun {
+		for _, c := range res.Changes {
+			fmt.Print(c.Diff())
+		}
+	}
+	for _, s := range res.Skipped {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", s)
+	}
+	fmt.Printf("✓ %s: %d updated, %d added, %d skipped\n", sheet.TargetLang, len(res.Updated), len(res.Added), len(res.Skipped))
+}
+
+// ============================================================================
+// EXPORT COMMAND
+// ============================================================================
+
+func exportCmd(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "xlsx", "Output format (xlsx, csv: reviewer sheet; i18next: JSON catalog; ai-context: JSONL for LLM pipelines)")
+	source := fs.String("source", "en", "Source locale")
+	target := fs.String("target", "", "Locale under review (or to export as i18next)")
+	output := fs.String("o", "", "Output file (default: review_<target>.<format>, <target>.json for i18next, ai_context_<target>.jsonl)")
+	audience := fs.String("audience", "public", "Comma-separated @audience tags to include in i18next catalogs")
+	keyFilter := keyFilterFlags(fs)
+	fs.Parse(args)
+	filter := keyFilter()
+
+	paths := fs.Args()
+	if len(paths) != 1 || *target == "" || (*format != "xlsx" && *format != "csv" && *format != "i18next" && *format != "ai-context") {
+		fmt.Fprintln(os.Stderr, "Usage: mbel export [-format xlsx|csv|i18next|ai-context] [-source en] -target <locale> [-audience public] [-include patterns] [-exclude patterns] [-o file] <dir>")
+		os.Exit(1)
+	}
+
+	repo := &mbel.FileRepository{RootPath: paths[0]}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for lang, data := range langData {
+		langData[lang] = filter.Apply(data)
+	}
+	if *format == "i18next" {
+		audiences, err := mbel.ParseAudiences(*audience)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		exportI18next(langData, *target, audiences, *output)
+		return
+	}
+	if *format == "ai-context" {
+		exportAIContext(langData, repo, *source, *target, *output)
+		return
+	}
+	sheet, err := mbel.NewReviewSheet(langData, repo, *source, *target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if *format == "xlsx" {
+		err = mbel.WriteReviewXLSX(&buf, sheet)
+	} else {
+		err = mbel.WriteReviewCSV(&buf, sheet)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	out := *output
+	if out == "" {
+		out = fmt.Sprintf("review_%s.%s", *target, *format)
+	}
+	if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+
+	counts := make(map[string]int)
+	for _, r := range sheet.Rows {
+		counts[r.Status]++
+	}
+	fmt.Printf("✓ Exported %d rows to %s (%d missing, %d untranslated, %d too long)\n",
+		len(sheet.Rows), out, counts[mbel.ReviewMissing], counts[mbel.ReviewUntranslated], counts[mbel.ReviewTooLong])
+}
+
+// exportAIContext writes the source locale's messages with their
+// annotations as a JSONL context pack for translating into target
+func exportAIContext(langData map[string]map[string]interface{}, repo *mbel.FileRepository, source, target, output string) {
+	pack, err := mbel.NewAIContextPack(langData, repo, source, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if err := mbel.WriteAIContext(&buf, pack); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if output == "" {
+		output = fmt.Sprintf("ai_context_%s.jsonl", target)
+	}
+	if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Exported %d messages to %s\n", len(pack), output)
+}
+
+// exportI18next writes the keys of one locale visible to audiences as an
+// i18next JSON catalog
+func exportI18next(langData map[string]map[string]interface{}, lang string, audiences []mbel.Audience, output string) {
+	// A catalog stands alone: resolve @inherits
+	langData, err := mbel.ResolveInheritance(langData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	data, ok := mbel.ShareAudiences(langData)[lang]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: no locale %s\n", lang)
+		os.Exit(1)
+	}
+	data = mbel.FilterAudience(data, audiences)
+
+	var buf bytes.Buffer
+	if err := mbel.WriteI18next(&buf, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if output == "" {
+		output = lang + ".json"
+	}
+	if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Exported %s to %s\n", lang, output)
+}
+
+// ============================================================================
+// MIGRATE-BUNDLE COMMAND
+// ============================================================================
+
+func migrateBundleCmd(args []string) {
+	fs := flag.NewFlagSet("migrate-bundle", flag.ExitOnError)
+	dryRun := fs.Bool("n", false, "Dry run (report versions without writing)")
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: No compiled JSON files specified")
+		fmt.Fprintln(os.Stderr, "Usage: mbel migrate-bundle [-n] <files...>")
+		os.Exit(1)
+	}
+
+	failed := false
+	migrated := 0
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			failed = true
+			continue
+		}
+
+		var raw map[string]interface{}
+		if err := json.Unmarshal(content, &raw); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			failed = true
+			continue
+		}
+		from, err := mbel.SchemaOf(raw)
+		if err == nil && from == mbel.SchemaVersion {
+			fmt.Printf("  %s: already at schema %d\n", file, from)
+			continue
+		}
+
+		data, err := mbel.DecodeCompiled(content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			failed = true
+			continue
+		}
+
+		if *dryRun {
+			fmt.Printf("Would migrate: %s (schema %d → %d)\n", file, from, mbel.SchemaVersion)
+			continue
+		}
+
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			failed = true
+			continue
+		}
+		if err := ioutil.WriteFile(file, append(out, '\n'), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("✓ %s: schema %d → %d\n", file, from, mbel.SchemaVersion)
+		migrated++
+	}
+
+	if !*dryRun {
+		fmt.Printf("✓ %d files migrated\n", migrated)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// ============================================================================
+// GEN-PLURAL-TESTS COMMAND
+// ============================================================================
+
+func genPluralTestsCmd(args []string) {
+	fs := flag.NewFlagSet("gen-plural-tests", flag.ExitOnError)
+	output := fs.String("o", "", "Output .mbel file (default: stdout)")
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Error: Need a locale")
+		fmt.Fprintln(os.Stderr, "Usage: mbel gen-plural-tests <lang> [-o pl_plural_test.mbel]")
+		os.Exit(1)
+	}
+	lang := fs.Arg(0)
+
+	if *output == "" {
+		if err := mbel.WritePluralTest(os.Stdout, lang); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	var b bytes.Buffer
+	mbel.WritePluralTest(&b, lang)
+	if err := os.WriteFile(*output, b.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ Generated %s (%d categories)\n", *output, len(mbel.PluralSamples(lang)))
+}
+
+// ============================================================================
+// PREVIEW COMMAND
+// ============================================================================
+
+func previewCmd(args []string) {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	key := fs.String("key", "", "Key to render (folder namespace included, e.g. checkout.title)")
+	varsFlag := fs.String("vars", "", "Variables, e.g. n=3,name=Alice (default: the key's AI_SampleVars)")
+	source := fs.String("source", "en", "Locale whose AI_SampleVars other locales fall back to")
+	parseFlags(fs, args)
+
+	rest := fs.Args()
+	if len(rest) == 2 && *key == "" {
+		*key = rest[1]
+		rest = rest[:1]
+	}
+	if len(rest) != 1 || *key == "" {
+		fmt.Fprintln(os.Stderr, "Error: Need a locales directory and a key")
+		fmt.Fprintln(os.Stderr, "Usage: mbel preview <dir> -key <key> [-vars n=3,name=Alice] [-source en]")
+		os.Exit(1)
+	}
+
+	var vars mbel.Vars
+	if *varsFlag != "" {
+		var err error
+		if vars, err = mbel.ParseSampleFlag(*varsFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	repo := &mbel.FileRepository{RootPath: rest[0], Logger: slog.New(slog.DiscardHandler)}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	previews, err := mbel.PreviewKey(langData, *source, *key, vars)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("🖼  %s\n", *key)
+	found := false
+	for _, p := range previews {
+		if p.Missing {
+			fmt.Printf("  %-6s (missing)\n", p.Lang)
+			continue
+		}
+		found = true
+		fmt.Printf("  %-6s %s\n", p.Lang, p.Text)
+		for _, c := range p.Cases {
+			fmt.Printf("           [%s] %s\n", c.Cond, c.Text)
+		}
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "✗ %s is not defined in any locale\n", *key)
+		os.Exit(1)
+	}
+}
+
+// ============================================================================
+// CHANGELOG COMMAND
+// ============================================================================
+
+func changelogCmd(args []string) {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	check := fs.Bool("check", false, "Exit 1 on breaking changes (removed keys, changed placeholders)")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "Error: Need the old and the new compiled bundle")
+		fmt.Fprintln(os.Stderr, "Usage: mbel changelog [-json] [-check] <old.json> <new.json>")
+		os.Exit(1)
+	}
+
+	var bundles [2]map[string]interface{}
+	for i, file := range fs.Args() {
+		content, err := ioutil.ReadFile(file)
+		if err == nil {
+			bundles[i], err = mbel.ReadCompiled(content)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", file, err)
+			os.Exit(1)
+		}
+	}
+	report := mbel.NewKeyChangelog(bundles[0], bundles[1])
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		report.WriteMarkdown(os.Stdout)
+	}
+	if *check && report.Breaking() {
+		fmt.Fprintf(os.Stderr, "✗ %d keys removed, %d with changed placeholders\n", len(report.Removed), len(report.Placeholders))
+		os.Exit(1)
+	}
+}
+
+// ============================================================================
+// ROUNDTRIP COMMAND
+// ============================================================================
+
+// roundTripFormats export one locale's compiled data to a format and
+// import it back, the way a vendor round trip would
+var roundTripFormats = map[string]func(map[string]interface{}) (map[string]interface{}, error){
+	"po":      roundTripPO,
+	"json":    roundTripJSON,
+	"i18next": roundTripI18next,
+}
+
+func roundTripPO(data map[string]interface{}) (map[string]interface{}, error) {
+	var buf bytes.Buffer
+	if err := mbel.WritePO(&buf, data); err != nil {
+		return nil, err
+	}
+	return mbel.ReadPO(&buf)
+}
+
+// roundTripJSON exports flat JSON and re-imports it as `mbel import` does
+func roundTripJSON(data map[string]interface{}) (map[string]interface{}, error) {
+	flat := make(map[string]interface{})
+	for _, k := range mbel.SortedKeys(data) {
+		flat[k] = data[k]
+	}
+	raw, err := json.Marshal(flat)
+	if err != nil {
+		return nil, err
+	}
+
+	var imported map[string]interface{}
+	if err := json.Unmarshal(raw, &imported); err != nil {
+		return nil, err
+	}
+	back, errs, err := mbel.CompileSource([]byte(jsonToMBEL(imported, "")), nil)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("re-import produced invalid MBEL:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return back, err
+}
+
+// roundTripI18next exports i18next JSON and re-imports it as
+// `mbel import -format i18next` does
+func roundTripI18next(data map[string]interface{}) (map[string]interface{}, error) {
+	var buf bytes.Buffer
+	if err := mbel.WriteI18next(&buf, data); err != nil {
+		return nil, err
+	}
+	imported, err := mbel.ReadI18next(&buf)
+	if err != nil {
+		return nil, err
+	}
+	back, errs, err := mbel.CompileSource([]byte(mbel.FormatData(imported)), nil)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("re-import produced invalid MBEL:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return back, err
+}
+
+func roundtripCmd(args []string) {
+	fs := flag.NewFlagSet("roundtrip", flag.ExitOnError)
+	format := fs.String("format", "po", "Format to round-trip through (po, json, i18next)")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	convert, ok := roundTripFormats[*format]
+	if len(paths) != 1 || !ok {
+		fmt.Fprintln(os.Stderr, "Usage: mbel roundtrip [-format po|json|i18next] <dir>")
+		os.Exit(1)
+	}
+
+	repo := &mbel.FileRepository{RootPath: paths[0]}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	lossy := false
+	for _, lang := range sortedLangs(langData) {
+		back, err := convert(langData[lang])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", lang, err)
+			lossy = true
+			continue
+		}
+
+		diffs := roundTripDiff(langData[lang], back)
+		if len(diffs) == 0 {
+			fmt.Printf("✓ %s: %d keys survive %s round trip\n", lang, len(mbel.SortedKeys(back)), *format)
+			continue
+		}
+		lossy = true
+		fmt.Fprintf(os.Stderr, "✗ %s: %d lossy conversions through %s\n", lang, len(diffs), *format)
+		for _, d := range diffs {
+			fmt.Fprintf(os.Stderr, "    %s\n", d)
+		}
+	}
+
+	if lossy {
+		os.Exit(1)
+	}
+}
+
+// roundTripDiff describes every key lost, added or changed between a
+// catalog and its round-tripped copy (the schema stamp aside)
+func roundTripDiff(before, after map[string]interface{}) []string {
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	delete(keys, "__schema")
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, k := range sorted {
+		a, inBefore := before[k]
+		b, inAfter := after[k]
+		switch {
+		case !inAfter:
+			diffs = append(diffs, fmt.Sprintf("lost    %s = %s", k, displayValue(a)))
+		case !inBefore:
+			diffs = append(diffs, fmt.Sprintf("added   %s = %s", k, displayValue(b)))
+		case !reflect.DeepEqual(a, b):
+			diffs = append(diffs, fmt.Sprintf("changed %s: %s → %s", k, displayValue(a), displayValue(b)))
+		}
+	}
+	return diffs
+}
+
+func displayValue(v interface{}) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
+
+func sortedLangs(langData map[string]map[string]interface{}) []string {
+	langs := make([]string, 0, len(langData))
+	for lang := range langData {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// ============================================================================
+// QA COMMAND
+// ============================================================================
+
+func qaCmd(args []string) {
+	fs := flag.NewFlagSet("qa", flag.ExitOnError)
+	from := fs.String("from", "en", "Source locale")
+	toLang := fs.String("to", "", "Locale to review (e.g. pl)")
+	model := fs.String("model", "gpt-4o-mini", "AI model to use")
+	batch := fs.Int("batch", 20, "Messages per model request")
+	asJSON := fs.Bool("json", false, "Print findings as JSON")
+	fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) != 1 || *toLang == "" {
+		fmt.Fprintln(os.Stderr, "Usage: mbel qa [-from en] -to <locale> [-model name] [-batch n] [-json] <dir>")
+		os.Exit(1)
+	}
+
+	repo := &mbel.FileRepository{RootPath: paths[0]}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	sheet, err := mbel.NewReviewSheet(langData, repo, *from, *toLang)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Without an API key only the local placeholder checks run
+	var reviewer mbel.QAModel
+	if key := os.Getenv("MBEL_OPENAI_KEY"); key != "" {
+		reviewer = &mbel.OpenAIModel{BaseURL: os.Getenv("MBEL_OPENAI_URL"), APIKey: key, Model: *model}
+	} else if !*asJSON {
+		fmt.Fprintln(os.Stderr, "⚠ MBEL_OPENAI_KEY not set: checking placeholders only")
+	}
+
+	findings, err := mbel.RunQA(context.Background(), sheet, reviewer, *batch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		if findings == nil {
+			findings = []mbel.QAFinding{}
+		}
+		out, _ := json.MarshalIndent(findings, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, f := range findings {
+			fmt.Printf("  [%s] %s: %s\n", f.Kind, f.Key, f.Message)
+			if f.Suggestion != "" {
+				fmt.Printf("      → %s\n", f.Suggestion)
+			}
+		}
+		if len(findings) == 0 {
+			fmt.Printf("✓ %s: no issues found\n", *toLang)
+		} else {
+			fmt.Printf("✗ %s: %d findings\n", *toLang, len(findings))
+		}
+	}
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+}
+
+// ============================================================================
+// TRANSLATE COMMAND (SCAFFOLD)
+// ============================================================================
+
+func translateCmd(args []string) {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	toLang := fs.String("to", "", "Target language code (e.g. pl, de)")
 	model := fs.String("model", "gpt-4", "AI model to use")
 	output := fs.String("o", "", "Output file")
+	verify := fs.Bool("verify", false, "Back-translate the target locale of <dir> and flag diverging keys")
+	from := fs.String("from", "en", "Source locale (with -verify)")
+	threshold := fs.Float64("threshold", 0.5, "Minimum similarity of a back-translation to its source (with -verify)")
+	dryRun := fs.Bool("dry-run", false, "Print the diff of the output file instead of writing it")
 	fs.Parse(args)
 
 	if *toLang == "" {
@@ -1028,6 +2949,11 @@ func translateCmd(args []string) {
 		os.Exit(1)
 	}
 
+	if *verify {
+		verifyTranslations(files, *from, *toLang, *model, *threshold)
+		return
+	}
+
 	fmt.Printf("🤖 Translating %d files to %s using %s...\n", len(files), *toLang, *model)
 
 	// Simulation
@@ -1037,10 +2963,56 @@ func translateCmd(args []string) {
 	}
 
 	if *output != "" {
-		ioutil.WriteFile(*output, []byte("# Translated content would go here\n"), 0644)
-		fmt.Printf("✓ Check %s for results (Placeholder)\n", *output)
+		writeFileOrDiff(*output, []byte("# Translated content would go here\n"), *dryRun)
+		if !*dryRun {
+			fmt.Printf("✓ Check %s for results (Placeholder)\n", *output)
+		}
 	} else {
 		fmt.Println("✓ Done (Placeholder mode - no API key configured)")
 		fmt.Println("  To enable real translation, configure MBEL_OPENAI_KEY")
 	}
 }
+
+// verifyTranslations back-translates the target locale of the directory
+// in paths and reports keys whose meaning drifted from the source
+func verifyTranslations(paths []string, from, to, model string, threshold float64) {
+	if len(paths) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mbel translate -verify [-from en] -to <locale> [-threshold 0.5] [-model name] <dir>")
+		os.Exit(1)
+	}
+	key := os.Getenv("MBEL_OPENAI_KEY")
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Error: -verify needs a model; configure MBEL_OPENAI_KEY")
+		os.Exit(1)
+	}
+
+	repo := &mbel.FileRepository{RootPath: paths[0]}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	sheet, err := mbel.NewReviewSheet(langData, repo, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("🔁 Back-translating %s to %s using %s...\n", to, from, model)
+	tr := &mbel.OpenAIModel{BaseURL: os.Getenv("MBEL_OPENAI_URL"), APIKey: key, Model: model}
+	diverged, err := mbel.VerifyBackTranslation(context.Background(), sheet, tr, threshold, 20)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, d := range diverged {
+		fmt.Printf("  ✗ %s (similarity %.2f)\n", d.Key, d.Similarity)
+		fmt.Printf("      source: %s\n      target: %s\n      back:   %s\n", d.Source, d.Target, d.Back)
+	}
+	if len(diverged) > 0 {
+		fmt.Printf("✗ %d keys diverge below %.2f\n", len(diverged), threshold)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ %s: every back-translation matches its source\n", to)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+)
+
+// loadPlugins opens Go plugins (built with -buildmode=plugin) whose init
+// functions call mbel.RegisterLintRule or mbel.RegisterCompileTransform.
+// Paths come from the comma-separated flag value and $MBEL_PLUGINS
+// (a list separated like $PATH).
+func loadPlugins(flagValue string) error {
+	var paths []string
+	for _, p := range strings.Split(flagValue, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	for _, p := range filepath.SplitList(os.Getenv("MBEL_PLUGINS")) {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+
+	for _, p := range paths {
+		if _, err := plugin.Open(p); err != nil {
+			return fmt.Errorf("loading plugin %s: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+module github.com/makkiattooo/MBEL/contrib/mbelcollate
+
+go 1.25
+
+require golang.org/x/text v0.25.0
@@ -0,0 +1,61 @@
+// Package mbelcollate sorts localized lists (country names, user-generated
+// labels) in the order readers of a locale expect, with
+// golang.org/x/text/collate.
+//
+//	names := []string{"Österreich", "Zypern", "Oman"}
+//	mbelcollate.Sort("de", names)  // Oman, Österreich, Zypern
+//	mbelcollate.Sort("sv", names)  // Oman, Zypern, Österreich
+//
+// Locales are written as in MBEL catalogs: "pt-BR" and "pt_BR" both work.
+package mbelcollate
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Collator returns a collator for lang; opts such as collate.IgnoreCase
+// or collate.Numeric are passed on. Tags that do not parse get the
+// language-neutral root order. A Collator is not safe for concurrent use.
+func Collator(lang string, opts ...collate.Option) *collate.Collator {
+	tag, err := language.Parse(strings.ReplaceAll(lang, "_", "-"))
+	if err != nil {
+		tag = language.Und
+	}
+	return collate.New(tag, opts...)
+}
+
+// Sort sorts s in place in lang's order
+func Sort(lang string, s []string, opts ...collate.Option) {
+	Collator(lang, opts...).SortStrings(s)
+}
+
+// SortFunc sorts items in place by the text of each in lang's order,
+// keeping equal items in their original order
+func SortFunc[T any](lang string, items []T, text func(T) string, opts ...collate.Option) {
+	c := Collator(lang, opts...)
+	keys := make([]string, len(items))
+	for i, it := range items {
+		keys[i] = text(it)
+	}
+	sort.Stable(&byText[T]{c, items, keys})
+}
+
+// byText sorts items alongside their precomputed texts
+type byText[T any] struct {
+	c     *collate.Collator
+	items []T
+	keys  []string
+}
+
+func (b *byText[T]) Len() int { return len(b.items) }
+
+func (b *byText[T]) Less(i, j int) bool { return b.c.CompareString(b.keys[i], b.keys[j]) < 0 }
+
+func (b *byText[T]) Swap(i, j int) {
+	b.items[i], b.items[j] = b.items[j], b.items[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
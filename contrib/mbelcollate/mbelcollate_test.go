@@ -0,0 +1,47 @@
+package mbelcollate
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/text/collate"
+)
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		lang string
+		in   []string
+		want []string
+	}{
+		{"de", []string{"Zypern", "Österreich", "Oman"}, []string{"Oman", "Österreich", "Zypern"}},
+		{"sv", []string{"Zypern", "Österreich", "Oman"}, []string{"Oman", "Zypern", "Österreich"}},
+		{"es", []string{"ñu", "nube", "oso"}, []string{"nube", "ñu", "oso"}},
+		{"not a tag!", []string{"b", "a"}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		got := slices.Clone(tt.in)
+		Sort(tt.lang, got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Sort(%s) = %v, want %v", tt.lang, got, tt.want)
+		}
+	}
+
+	files := []string{"file10", "file9", "File1"}
+	Sort("en_US", files, collate.Numeric, collate.IgnoreCase)
+	if !slices.Equal(files, []string{"File1", "file9", "file10"}) {
+		t.Errorf("numeric, case-insensitive: %v", files)
+	}
+}
+
+func TestSortFunc(t *testing.T) {
+	type country struct{ code, name string }
+	cs := []country{{"CY", "Zypern"}, {"AT", "Österreich"}, {"OM", "Oman"}}
+	SortFunc("de", cs, func(c country) string { return c.name })
+	var codes []string
+	for _, c := range cs {
+		codes = append(codes, c.code)
+	}
+	if !slices.Equal(codes, []string{"OM", "AT", "CY"}) {
+		t.Errorf("SortFunc = %v", codes)
+	}
+}
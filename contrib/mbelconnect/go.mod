@@ -0,0 +1,12 @@
+module github.com/makkiattooo/MBEL/contrib/mbelconnect
+
+go 1.25.0
+
+require (
+	connectrpc.com/connect v1.21.0
+	github.com/makkiattooo/MBEL v1.2.1
+)
+
+require google.golang.org/protobuf v1.36.11 // indirect
+
+replace github.com/makkiattooo/MBEL => ../..
@@ -0,0 +1,65 @@
+// Package mbelconnect injects the MBEL locale into Connect RPC handlers.
+//
+//	interceptor := mbelconnect.NewInterceptor(mbel.Options{Manager: m})
+//	path, handler := greetv1connect.NewGreetServiceHandler(
+//		&GreetServer{},
+//		connect.WithInterceptors(interceptor),
+//	)
+//
+// Inside the handler, mbel.T(ctx, ...) then uses the negotiated locale.
+package mbelconnect
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"connectrpc.com/connect"
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+// Interceptor negotiates the locale from request headers using the
+// standard resolver chain. Client-side calls pass through untouched.
+type Interceptor struct {
+	opts mbel.Options
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor creates an Interceptor. Header-based resolvers
+// (mbel.Header, mbel.Cookie) work as with net/http; query resolvers
+// never match since RPCs carry no query string.
+func NewInterceptor(opts mbel.Options) *Interceptor {
+	return &Interceptor{opts: opts.WithNegotiationCache()}
+}
+
+// WrapUnary implements connect.Interceptor
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		return next(i.withLocale(ctx, req.Header()), req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(i.withLocale(ctx, conn.RequestHeader()), conn)
+	}
+}
+
+func (i *Interceptor) withLocale(ctx context.Context, header http.Header) context.Context {
+	r := &http.Request{Header: header, URL: &url.URL{}}
+	ctx = mbel.WithLocale(ctx, i.opts.Negotiate(r))
+	if i.opts.Manager != nil {
+		ctx = mbel.WithManager(ctx, i.opts.Manager)
+	}
+	return ctx
+}
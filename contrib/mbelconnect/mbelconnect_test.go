@@ -0,0 +1,28 @@
+package mbelconnect
+
+import (
+	"context"
+	"testing"
+
+	"connectrpc.com/connect"
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+func TestWrapUnaryInjectsLocale(t *testing.T) {
+	var got string
+	handler := NewInterceptor(mbel.Options{Default: "en"}).WrapUnary(
+		func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			got = mbel.LocaleFromContext(ctx)
+			return nil, nil
+		})
+
+	req := connect.NewRequest(&struct{}{})
+	req.Header().Set("Accept-Language", "pl-PL,pl;q=0.9")
+	if _, err := handler(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "pl-PL" {
+		t.Errorf("expected locale %q, got %q", "pl-PL", got)
+	}
+}
@@ -0,0 +1,49 @@
+// Package mbelecho adapts MBEL locale negotiation to the Echo web framework.
+//
+//	e := echo.New()
+//	e.Use(mbelecho.Middleware(mbel.Options{
+//		Resolvers: []mbel.LocaleResolver{mbel.Cookie("lang"), mbel.Header()},
+//	}))
+//	e.GET("/", func(c echo.Context) error {
+//		return c.String(http.StatusOK, mbelecho.T(c, "title"))
+//	})
+package mbelecho
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+// Middleware runs the standard MBEL resolver chain and stores the
+// negotiated locale in the request context of echo.Context
+func Middleware(opts mbel.Options) echo.MiddlewareFunc {
+	mw := mbel.MiddlewareWithOptions(opts)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var err error
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				err = next(c)
+			})).ServeHTTP(c.Response(), c.Request())
+			return err
+		}
+	}
+}
+
+// T translates a key using the locale negotiated for this request
+func T(c echo.Context, key string, args ...interface{}) string {
+	return mbel.T(c.Request().Context(), key, args...)
+}
+
+// Locale returns the locale negotiated for this request
+func Locale(c echo.Context) string {
+	return mbel.LocaleFromContext(c.Request().Context())
+}
+
+// SetLocale overrides the locale for the rest of the request
+func SetLocale(c echo.Context, lang string) {
+	c.SetRequest(c.Request().WithContext(mbel.WithLocale(c.Request().Context(), lang)))
+}
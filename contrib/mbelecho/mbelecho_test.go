@@ -0,0 +1,31 @@
+package mbelecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+func TestMiddlewareInjectsLocale(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(mbel.Options{
+		Resolvers: []mbel.LocaleResolver{mbel.Query("lang"), mbel.Header()},
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, Locale(c))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?lang=pl", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "pl" {
+		t.Errorf("expected locale %q, got %q", "pl", rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Language"); got != "pl" {
+		t.Errorf("expected Content-Language %q, got %q", "pl", got)
+	}
+}
@@ -0,0 +1,67 @@
+// Package mbelfiber adapts MBEL locale negotiation to the Fiber web framework.
+//
+// Fiber is built on fasthttp and does not use net/http handlers, so the
+// core mbel.Middleware cannot be mounted directly. This adapter runs the
+// same resolver chain and stores the locale in fiber.Ctx locals.
+//
+//	app := fiber.New()
+//	app.Use(mbelfiber.New(mbel.Options{
+//		Resolvers: []mbel.LocaleResolver{mbel.Cookie("lang"), mbel.Header()},
+//	}))
+//	app.Get("/", func(c *fiber.Ctx) error {
+//		return c.SendString(mbelfiber.T(c, "title"))
+//	})
+package mbelfiber
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+// LocalsKey is the fiber.Ctx locals key holding the negotiated locale
+const LocalsKey = "mbel.locale"
+
+// New returns a Fiber handler that negotiates the locale, sets the
+// Content-Language and Vary response headers, and stores the locale
+// both in c.Locals(LocalsKey) and in c.UserContext()
+func New(opts mbel.Options) fiber.Handler {
+	opts = opts.WithNegotiationCache()
+	vary := strings.Join(opts.VaryHeaders(), ", ")
+
+	return func(c *fiber.Ctx) error {
+		// A request that cannot be converted falls back to the default locale
+		r, _ := adaptor.ConvertRequest(c, false)
+		lang := opts.Negotiate(r)
+
+		c.Set(fiber.HeaderContentLanguage, lang)
+		c.Append(fiber.HeaderVary, vary)
+
+		SetLocale(c, lang)
+		if opts.Manager != nil {
+			c.SetUserContext(mbel.WithManager(c.UserContext(), opts.Manager))
+		}
+		return c.Next()
+	}
+}
+
+// T translates a key using the locale negotiated for this request
+func T(c *fiber.Ctx, key string, args ...interface{}) string {
+	return mbel.T(c.UserContext(), key, args...)
+}
+
+// Locale returns the locale negotiated for this request
+func Locale(c *fiber.Ctx) string {
+	if lang, ok := c.Locals(LocalsKey).(string); ok {
+		return lang
+	}
+	return mbel.LocaleFromContext(c.UserContext())
+}
+
+// SetLocale overrides the locale for the rest of the request
+func SetLocale(c *fiber.Ctx, lang string) {
+	c.Locals(LocalsKey, lang)
+	c.SetUserContext(mbel.WithLocale(c.UserContext(), lang))
+}
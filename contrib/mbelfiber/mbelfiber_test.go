@@ -0,0 +1,38 @@
+package mbelfiber
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+func TestNewStoresLocaleInLocals(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(mbel.Options{
+		Resolvers: []mbel.LocaleResolver{mbel.Cookie("lang"), mbel.Header()},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(Locale(c) + "|" + mbel.LocaleFromContext(c.UserContext()))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "pl-PL,pl;q=0.9")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	if string(body) != "pl-PL|pl-PL" {
+		t.Errorf("expected locale %q in locals and context, got %q", "pl-PL", body)
+	}
+	if got := resp.Header.Get("Content-Language"); got != "pl-PL" {
+		t.Errorf("expected Content-Language %q, got %q", "pl-PL", got)
+	}
+	if got := resp.Header.Get("Vary"); got != "Accept-Language, Cookie" {
+		t.Errorf("unexpected Vary header: %q", got)
+	}
+}
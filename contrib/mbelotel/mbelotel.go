@@ -0,0 +1,102 @@
+// Package mbelotel records MBEL lookup and reload events with OpenTelemetry.
+//
+//	obs, err := mbelotel.New(mbelotel.Options{})
+//	if err != nil { ... }
+//	m, err := mbel.NewManager("locales", mbel.Config{Observer: obs})
+//
+// Missing keys and fallback-locale resolutions become events on the span
+// found in the context passed to mbel.T, and are counted by the
+// mbel.missing_keys and mbel.fallbacks counters. Every (re)load is
+// wrapped in an "mbel.reload" span.
+package mbelotel
+
+import (
+	"context"
+
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/makkiattooo/MBEL/contrib/mbelotel"
+
+// Options configures the observer. Nil providers default to the global ones.
+type Options struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// Observer implements mbel.Observer on top of OpenTelemetry
+type Observer struct {
+	tracer    trace.Tracer
+	missing   metric.Int64Counter
+	fallbacks metric.Int64Counter
+}
+
+var _ mbel.Observer = (*Observer)(nil)
+
+// New creates an Observer from the given providers
+func New(opts Options) (*Observer, error) {
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	mp := opts.MeterProvider
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+
+	meter := mp.Meter(instrumentationName)
+	missing, err := meter.Int64Counter("mbel.missing_keys",
+		metric.WithDescription("Lookups of keys missing in every candidate locale"))
+	if err != nil {
+		return nil, err
+	}
+	fallbacks, err := meter.Int64Counter("mbel.fallbacks",
+		metric.WithDescription("Lookups served from a fallback locale"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Observer{
+		tracer:    tp.Tracer(instrumentationName),
+		missing:   missing,
+		fallbacks: fallbacks,
+	}, nil
+}
+
+// MissingKey records a span event and increments mbel.missing_keys
+func (o *Observer) MissingKey(ctx context.Context, lang, key string) {
+	attrs := []attribute.KeyValue{
+		attribute.String("mbel.locale", lang),
+		attribute.String("mbel.key", key),
+	}
+	trace.SpanFromContext(ctx).AddEvent("mbel.missing_key", trace.WithAttributes(attrs...))
+	o.missing.Add(ctx, 1, metric.WithAttributes(attrs[0]))
+}
+
+// Fallback records a span event and increments mbel.fallbacks
+func (o *Observer) Fallback(ctx context.Context, requested, resolved, key string) {
+	attrs := []attribute.KeyValue{
+		attribute.String("mbel.locale", requested),
+		attribute.String("mbel.resolved_locale", resolved),
+		attribute.String("mbel.key", key),
+	}
+	trace.SpanFromContext(ctx).AddEvent("mbel.fallback", trace.WithAttributes(attrs...))
+	o.fallbacks.Add(ctx, 1, metric.WithAttributes(attrs[0], attrs[1]))
+}
+
+// Reload starts an "mbel.reload" span, ended with the reload result
+func (o *Observer) Reload(ctx context.Context) func(err error) {
+	_, span := o.tracer.Start(ctx, "mbel.reload")
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
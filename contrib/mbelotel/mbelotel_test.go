@@ -0,0 +1,47 @@
+package mbelotel
+
+import (
+	"context"
+	"testing"
+
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type staticRepo map[string]map[string]interface{}
+
+func (r staticRepo) LoadAll() (map[string]map[string]interface{}, error) { return r, nil }
+
+func TestObserverRecordsEvents(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	obs, err := New(Options{TracerProvider: tp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mbel.NewManagerWithRepo(staticRepo{
+		"en": {"title": "Hello"},
+		"pl": {},
+	}, mbel.Config{DefaultLocale: "en", Observer: obs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	ctx = mbel.WithManager(mbel.WithLocale(ctx, "pl"), m)
+	mbel.T(ctx, "title")
+	mbel.T(ctx, "nope")
+	span.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "mbel.reload" {
+		t.Fatalf("expected reload and request spans, got %v", spans)
+	}
+
+	events := spans[1].Events
+	if len(events) != 2 || events[0].Name != "mbel.fallback" || events[1].Name != "mbel.missing_key" {
+		t.Errorf("unexpected span events: %v", events)
+	}
+}
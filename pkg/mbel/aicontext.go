@@ -0,0 +1,258 @@
+package mbel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AI context packs. Every message of the source locale is written as one
+// JSON line with what an LLM needs to translate or review it on its own:
+// the source (and current target) text, the AI_ annotations, the
+// placeholders to keep and its neighbours in the same section. Cases of
+// logic blocks are entries keyed "key[condition]", as in review sheets.
+
+// aiContextNeighbors is how many keys of the same section an entry lists
+const aiContextNeighbors = 10
+
+// AIContextEntry is one message of an AI context pack
+type AIContextEntry struct {
+	Key          string            `json:"key"`
+	SourceLang   string            `json:"source_lang"`
+	TargetLang   string            `json:"target_lang"`
+	Source       string            `json:"source"`
+	Target       string            `json:"target,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"` // by type: "Context", "Tone", "MaxLength", ...
+	Placeholders []string          `json:"placeholders,omitempty"`
+	Section      string            `json:"section,omitempty"`   // key prefix, e.g. "checkout" for "checkout.pay"
+	Neighbors    map[string]string `json:"neighbors,omitempty"` // source texts of nearby keys of the section
+}
+
+// NewAIContextPack builds the entries for every key of the source
+// locale, sorted by key. Annotations are read from the files found
+// through loc, the target's own AI_MaxLength winning over the source's.
+func NewAIContextPack(langData map[string]map[string]interface{}, loc SourceLocator, source, target string) ([]AIContextEntry, error) {
+	src, ok := langData[source]
+	if !ok {
+		return nil, fmt.Errorf("source locale %q: %w", source, ErrLocaleNotFound)
+	}
+	tgt := langData[target]
+	ix := newAnnotationIndex(loc)
+
+	keys := SortedKeys(src)
+	sections := make(map[string][]string)
+	for _, key := range keys {
+		s := keySection(key)
+		sections[s] = append(sections[s], key)
+	}
+
+	var pack []AIContextEntry
+	for _, key := range keys {
+		anns, err := ix.of(source, key)
+		if err != nil {
+			return nil, err
+		}
+		own, err := ix.of(target, key)
+		if err != nil {
+			return nil, err
+		}
+		annotations := make(map[string]string)
+		for _, ann := range anns {
+			if prev, ok := annotations[ann.Type]; ok {
+				annotations[ann.Type] = prev + "\n" + ann.Value
+			} else {
+				annotations[ann.Type] = ann.Value
+			}
+		}
+		if b, ok := maxLength(own); ok {
+			annotations["MaxLength"] = strconv.Itoa(b)
+		}
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		section := keySection(key)
+		neighbors := nearbyMessages(src, sections[section], key)
+
+		add := func(key, text, translation string) {
+			pack = append(pack, AIContextEntry{
+				Key:          key,
+				SourceLang:   source,
+				TargetLang:   target,
+				Source:       text,
+				Target:       translation,
+				Annotations:  annotations,
+				Placeholders: placeholders(text),
+				Section:      section,
+				Neighbors:    neighbors,
+			})
+		}
+		switch v := src[key].(type) {
+		case string:
+			t, _ := tgt[key].(string)
+			add(key, v, t)
+		case *RuntimeBlock:
+			targets := map[string]string{}
+			if tb, ok := tgt[key].(*RuntimeBlock); ok {
+				for _, e := range blockEntries(tb) {
+					targets[e.cond] = e.value
+				}
+			}
+			for _, e := range blockEntries(v) {
+				add(key+"["+e.cond+"]", e.value, targets[e.cond])
+			}
+		}
+	}
+	return pack, nil
+}
+
+// WriteAIContext writes a pack as JSON Lines, one entry per line
+func WriteAIContext(w io.Writer, pack []AIContextEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range pack {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keySection returns the key up to its last dot ("" for top-level keys)
+func keySection(key string) string {
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// nearbyMessages returns the source texts of up to aiContextNeighbors
+// keys of section around key, blocks by their [other] case
+func nearbyMessages(src map[string]interface{}, section []string, key string) map[string]string {
+	at := 0
+	for i, k := range section {
+		if k == key {
+			at = i
+			break
+		}
+	}
+	lo := max(0, at-aiContextNeighbors/2)
+	hi := min(len(section), lo+aiContextNeighbors+1)
+	lo = max(0, hi-aiContextNeighbors-1)
+
+	out := make(map[string]string)
+	for _, k := range section[lo:hi] {
+		if k == key {
+			continue
+		}
+		switch v := src[k].(type) {
+		case string:
+			out[k] = v
+		case *RuntimeBlock:
+			if other, ok := v.Cases["other"]; ok {
+				out[k] = other
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// AIContext is the AI_ annotations of one key, as compiled into __ai, for
+// services that prompt a model themselves (e.g. to machine-translate
+// user-generated text shown next to the message)
+type AIContext struct {
+	Context   string
+	Tone      string
+	MaxLength int               // 0 = none
+	Links     []string          // AI_Screenshot and AI_Figma URLs
+	Other     map[string]string // remaining annotations by type, e.g. "Constraints"
+}
+
+// AIContext returns the annotations of key, and false when it has none.
+// Runtimes backed by a binary bundle carry no annotations.
+func (r *Runtime) AIContext(key string) (AIContext, bool) {
+	entries := r.aiEntries(key)
+	if len(entries) == 0 {
+		return AIContext{}, false
+	}
+
+	var c AIContext
+	for _, e := range entries {
+		typ, value := e["type"], e["value"]
+		// The first Context, Tone and MaxLength win, as in review sheets
+		switch {
+		case typ == "Context":
+			if c.Context == "" {
+				c.Context = value
+			}
+		case typ == "Tone":
+			if c.Tone == "" {
+				c.Tone = value
+			}
+		case typ == "MaxLength":
+			if c.MaxLength == 0 {
+				c.MaxLength, _ = strconv.Atoi(value)
+			}
+		case contextURLTypes[typ]:
+			c.Links = append(c.Links, value)
+		default:
+			if c.Other == nil {
+				c.Other = make(map[string]string)
+			}
+			if prev, ok := c.Other[typ]; ok {
+				value = prev + "\n" + value
+			}
+			c.Other[typ] = value
+		}
+	}
+	return c, true
+}
+
+// aiEntries returns the AI_ annotations of key as compiled into __ai,
+// whether in compiled form or decoded from JSON
+func (r *Runtime) aiEntries(key string) []map[string]string {
+	var entries []map[string]string
+	switch ai := r.Data["__ai"].(type) {
+	case map[string][]map[string]string:
+		entries = ai[key]
+	case map[string]interface{}: // decoded with encoding/json
+		list, _ := ai[key].([]interface{})
+		for _, item := range list {
+			m, _ := item.(map[string]interface{})
+			typ, _ := m["type"].(string)
+			value, _ := m["value"].(string)
+			entries = append(entries, map[string]string{"type": typ, "value": value})
+		}
+	}
+	return entries
+}
+
+// String renders c as prompt lines ("Context: ...", "Tone: ...",
+// "Max length: 20 characters", then other annotations by type)
+func (c AIContext) String() string {
+	var b strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label, value)
+		}
+	}
+	line("Context", c.Context)
+	line("Tone", c.Tone)
+	if c.MaxLength > 0 {
+		line("Max length", fmt.Sprintf("%d characters", c.MaxLength))
+	}
+	types := make([]string, 0, len(c.Other))
+	for typ := range c.Other {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		line(typ, c.Other[typ])
+	}
+	return b.String()
+}
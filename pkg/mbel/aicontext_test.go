@@ -0,0 +1,114 @@
+package mbel
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAIContextPack(t *testing.T) {
+	repo := &FileRepository{RootPath: writeReviewFixture(t)}
+	data, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack, err := NewAIContextPack(data, repo, "en", "pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, e := range pack {
+		keys = append(keys, e.Key)
+	}
+	if want := []string{"app.brand", "app.items[one]", "app.items[other]", "app.login", "app.welcome"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+
+	login := pack[3]
+	if login.Source != "Sign in" || login.Target != "Zaloguj się teraz" || login.Section != "app" ||
+		login.Annotations["Context"] != "Login button" || login.Annotations["MaxLength"] != "10" {
+		t.Errorf("login = %+v", login)
+	}
+	if login.Neighbors["app.items"] != "{n} items" || login.Neighbors["app.brand"] != "Acme" {
+		t.Errorf("neighbors = %v", login.Neighbors)
+	}
+	if _, self := login.Neighbors["app.login"]; self {
+		t.Error("an entry lists itself as neighbour")
+	}
+	if other := pack[2]; other.Target != "" || !reflect.DeepEqual(other.Placeholders, []string{"{n}"}) {
+		t.Errorf("items[other] = %+v", other)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAIContext(&buf, pack); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(pack) {
+		t.Fatalf("%d lines for %d entries", len(lines), len(pack))
+	}
+	var back AIContextEntry
+	if err := json.Unmarshal([]byte(lines[3]), &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, login) {
+		t.Errorf("decoded = %+v\nwant %+v", back, login)
+	}
+}
+
+func TestRuntimeAIContext(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "en"), 0755)
+	os.WriteFile(filepath.Join(dir, "en", "checkout.mbel"), []byte(`[payment]
+# AI_Context: Pay button
+# AI_Tone: Friendly
+# AI_MaxLength: 12
+# AI_Constraints: No exclamation marks
+# AI_Screenshot: https://cdn.example.com/pay.png
+pay = "Pay now"
+cancel = "Cancel"
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "en", "app.mbel"), []byte(`# AI_Context: Page title
+title = "Shop"
+`), 0644)
+
+	m, err := NewManager(dir, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := m.runtime("en")
+
+	c, ok := r.AIContext("checkout.payment.pay")
+	want := AIContext{
+		Context:   "Pay button",
+		Tone:      "Friendly",
+		MaxLength: 12,
+		Links:     []string{"https://cdn.example.com/pay.png"},
+		Other:     map[string]string{"Constraints": "No exclamation marks"},
+	}
+	if !ok || !reflect.DeepEqual(c, want) {
+		t.Fatalf("AIContext = %+v, %v", c, ok)
+	}
+	if got := c.String(); got != "Context: Pay button\nTone: Friendly\nMax length: 12 characters\nConstraints: No exclamation marks\n" {
+		t.Errorf("String() = %q", got)
+	}
+	// Annotations of every file survive the merge
+	if c, ok := r.AIContext("app.title"); !ok || c.Context != "Page title" {
+		t.Errorf("app.title = %+v, %v", c, ok)
+	}
+	if _, ok := r.AIContext("checkout.payment.cancel"); ok {
+		t.Error("cancel has no annotations")
+	}
+
+	// Compiled JSON decoded without the schema
+	var data map[string]interface{}
+	json.Unmarshal([]byte(`{"__ai": {"k": [{"type": "Tone", "value": "Formal"}]}}`), &data)
+	if c, ok := NewRuntime(data).AIContext("k"); !ok || c.Tone != "Formal" {
+		t.Errorf("decoded AIContext = %+v, %v", c, ok)
+	}
+}
@@ -2,63 +2,92 @@ package mbel
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"sync"
 	"sync/atomic"
 )
 
-// Global instance
-var std *Manager
+// Global instance, swapped atomically by Init
+var std atomic.Pointer[Manager]
 
 // Vars is a shortcut for map[string]interface{}, useful for template interpolation
 type Vars map[string]interface{}
 
 // Metrics holds basic telemetry for runtime
 type Metrics struct {
-	mu              sync.RWMutex
-	GetCalls        int64 // Total calls to Get
-	InterpolateOps  int64 // Total interpolation operations
-	CacheHits       int64 // Cache hits (future)
-	CacheMisses     int64 // Cache misses (future)
+	mu             sync.RWMutex
+	GetCalls       int64 // Total calls to Get
+	InterpolateOps int64 // Total interpolation operations
+	CacheHits      int64 // Compile cache hits
+	CacheMisses    int64 // Compile cache misses
+
+	// Manager lookups broken down by locale and top-level key namespace;
+	// copy-on-write so the hot path never locks
+	byLocale    atomic.Pointer[map[string]*LabelMetrics]
+	byNamespace atomic.Pointer[map[string]*LabelMetrics]
+}
+
+// LabelMetrics counts Manager lookups for one locale or namespace
+type LabelMetrics struct {
+	Gets      int64 // Lookups
+	Misses    int64 // Lookups of keys missing in every candidate locale
+	Fallbacks int64 // Lookups served from a fallback locale
 }
 
+// rootNamespace labels keys without a dot
+const rootNamespace = "(root)"
+
 // Global metrics instance
 var metrics = &Metrics{}
 
 // Init initializes the global MBEL manager
-// Call this at the start of your application
+// Call this at the start of your application. Calling it again (after a
+// config reload, or in tests) replaces the global manager and closes the
+// previous one; on error the previous manager stays in place.
 func Init(rootPath string, cfg Config) error {
 	m, err := NewManager(rootPath, cfg)
 	if err != nil {
 		return err
 	}
-	std = m
+	if old := std.Swap(m); old != nil {
+		old.Close()
+	}
 	return nil
 }
 
+// Default returns the global manager, or nil if Init was not called
+func Default() *Manager {
+	return std.Load()
+}
+
 // GlobalT translates a key using the global manager and default language.
 // Useful for system messages or when context is not available.
 func GlobalT(key string, args ...interface{}) string {
-	if std == nil {
+	m := Default()
+	if m == nil {
 		return key
 	}
-	return std.Get(std.defaultLang, key, args...)
+	return m.Get(m.defaultLang, key, args...)
 }
 
 // T translates a key using the locale found in context
 // This is the primary API for localized applications
 func T(ctx context.Context, key string, args ...interface{}) string {
-	if std == nil {
+	m := ManagerFromContext(ctx)
+	if m == nil {
 		return key
 	}
 	lang := LocaleFromContext(ctx)
-	return std.Get(lang, key, args...)
+	return m.get(ctx, lang, key, args...)
 }
 
 // Context handling
 
 type contextKey struct{}
 
+type managerContextKey struct{}
+
 // WithLocale injects the locale code into the context
 func WithLocale(ctx context.Context, lang string) context.Context {
 	return context.WithValue(ctx, contextKey{}, lang)
@@ -70,39 +99,57 @@ func LocaleFromContext(ctx context.Context) string {
 	if val, ok := ctx.Value(contextKey{}).(string); ok {
 		return val
 	}
-	if std != nil {
-		return std.defaultLang
+	if m := ManagerFromContext(ctx); m != nil {
+		return m.defaultLang
 	}
 	return "en"
 }
 
+// WithManager binds a Manager to the context, so T resolves keys
+// against it instead of the global instance
+func WithManager(ctx context.Context, m *Manager) context.Context {
+	return context.WithValue(ctx, managerContextKey{}, m)
+}
+
+// ManagerFromContext retrieves the Manager bound to the context
+// Returns the global manager if none was bound (nil if Init was not called)
+func ManagerFromContext(ctx context.Context) *Manager {
+	if m, ok := ctx.Value(managerContextKey{}).(*Manager); ok && m != nil {
+		return m
+	}
+	return Default()
+}
+
 // ============================================================================
 // CONVENIENCE HELPERS
 // ============================================================================
 
 // TDefault translates using an explicit language and default locale fallback
 func TDefault(key, lang string, args ...interface{}) string {
-	if std == nil {
+	m := Default()
+	if m == nil {
 		return key
 	}
-	return std.Get(lang, key, args...)
+	return m.Get(lang, key, args...)
 }
 
-// MustT translates and panics if key is not found (for critical strings)
+// MustT translates and panics if key is not found (for critical strings);
+// the panic value is an error wrapping ErrKeyNotFound
 func MustT(ctx context.Context, key string, args ...interface{}) string {
 	result := T(ctx, key, args...)
 	if result == key {
-		panic(fmt.Sprintf("translation not found for key: %s", key))
+		panic(fmt.Errorf("%w: %s", ErrKeyNotFound, key))
 	}
 	return result
 }
 
 // TWithLocale is a convenience wrapper for T with explicit context and locale override
 func TWithLocale(ctx context.Context, lang, key string, args ...interface{}) string {
-	if std == nil {
+	m := Default()
+	if m == nil {
 		return key
 	}
-	return std.Get(lang, key, args...)
+	return m.Get(lang, key, args...)
 }
 
 // ============================================================================
@@ -119,14 +166,106 @@ func recordInterpolate() {
 	atomic.AddInt64(&metrics.InterpolateOps, 1)
 }
 
-// GetMetrics returns a copy of current metrics
+// recordCacheHit increments the compile cache hit counter
+func recordCacheHit() {
+	atomic.AddInt64(&metrics.CacheHits, 1)
+}
+
+// recordCacheMiss increments the compile cache miss counter
+func recordCacheMiss() {
+	atomic.AddInt64(&metrics.CacheMisses, 1)
+}
+
+// recordLookup counts a Manager lookup for its locale and namespace
+// labels, which callers keep to a bounded set
+func recordLookup(locale, namespace string, missing, fallback bool) {
+	for _, c := range []*LabelMetrics{labelCounters(&metrics.byLocale, locale), labelCounters(&metrics.byNamespace, namespace)} {
+		atomic.AddInt64(&c.Gets, 1)
+		if missing {
+			atomic.AddInt64(&c.Misses, 1)
+		}
+		if fallback {
+			atomic.AddInt64(&c.Fallbacks, 1)
+		}
+	}
+}
+
+// labelCounters returns the counters for label, adding them on first use
+func labelCounters(p *atomic.Pointer[map[string]*LabelMetrics], label string) *LabelMetrics {
+	if m := p.Load(); m != nil {
+		if c, ok := (*m)[label]; ok {
+			return c
+		}
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	old := p.Load()
+	if old != nil {
+		if c, ok := (*old)[label]; ok {
+			return c
+		}
+	}
+	next := make(map[string]*LabelMetrics)
+	if old != nil {
+		for k, v := range *old {
+			next[k] = v
+		}
+	}
+	c := &LabelMetrics{}
+	next[label] = c
+	p.Store(&next)
+	return c
+}
+
+// snapshotLabels copies the counters behind p
+func snapshotLabels(p *atomic.Pointer[map[string]*LabelMetrics]) map[string]LabelMetrics {
+	out := make(map[string]LabelMetrics)
+	if m := p.Load(); m != nil {
+		for label, c := range *m {
+			out[label] = LabelMetrics{
+				Gets:      atomic.LoadInt64(&c.Gets),
+				Misses:    atomic.LoadInt64(&c.Misses),
+				Fallbacks: atomic.LoadInt64(&c.Fallbacks),
+			}
+		}
+	}
+	return out
+}
+
+// LocaleMetrics returns lookup counters per locale. Locales that are not
+// loaded are counted under their base language when it is, else "other".
+func LocaleMetrics() map[string]LabelMetrics {
+	return snapshotLabels(&metrics.byLocale)
+}
+
+// NamespaceMetrics returns lookup counters per top-level key namespace
+// (the part before the first dot; "(root)" for keys without one).
+// Namespaces no loaded key has are counted as "other", so lookups of
+// arbitrary keys cannot grow the label set.
+func NamespaceMetrics() map[string]LabelMetrics {
+	return snapshotLabels(&metrics.byNamespace)
+}
+
+// GetMetrics returns a copy of current metrics. Per-locale and
+// per-namespace counters are included as "gets.locale.<lang>",
+// "misses.namespace.<ns>", etc.
 func GetMetrics() map[string]int64 {
-	return map[string]int64{
-		"get_calls":        atomic.LoadInt64(&metrics.GetCalls),
-		"interpolate_ops":  atomic.LoadInt64(&metrics.InterpolateOps),
-		"cache_hits":       atomic.LoadInt64(&metrics.CacheHits),
-		"cache_misses":     atomic.LoadInt64(&metrics.CacheMisses),
+	out := map[string]int64{
+		"get_calls":       atomic.LoadInt64(&metrics.GetCalls),
+		"interpolate_ops": atomic.LoadInt64(&metrics.InterpolateOps),
+		"cache_hits":      atomic.LoadInt64(&metrics.CacheHits),
+		"cache_misses":    atomic.LoadInt64(&metrics.CacheMisses),
+	}
+	for dim, labels := range map[string]map[string]LabelMetrics{"locale": LocaleMetrics(), "namespace": NamespaceMetrics()} {
+		for label, c := range labels {
+			out["gets."+dim+"."+label] = c.Gets
+			out["misses."+dim+"."+label] = c.Misses
+			out["fallbacks."+dim+"."+label] = c.Fallbacks
+		}
 	}
+	return out
 }
 
 // ResetMetrics clears all metrics counters
@@ -135,4 +274,22 @@ func ResetMetrics() {
 	atomic.StoreInt64(&metrics.InterpolateOps, 0)
 	atomic.StoreInt64(&metrics.CacheHits, 0)
 	atomic.StoreInt64(&metrics.CacheMisses, 0)
+
+	metrics.mu.Lock()
+	metrics.byLocale.Store(nil)
+	metrics.byNamespace.Store(nil)
+	metrics.mu.Unlock()
+}
+
+var publishOnce sync.Once
+
+// PublishExpvar registers the runtime metrics under the "mbel" expvar, so
+// they appear at /debug/vars (served by importing net/http and expvar).
+// Safe to call more than once.
+func PublishExpvar() {
+	publishOnce.Do(func() {
+		expvar.Publish("mbel", expvar.Func(func() interface{} {
+			return GetMetrics()
+		}))
+	})
 }
@@ -0,0 +1,60 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInitReplacesAndClosesPrevious(t *testing.T) {
+	defer std.Store(nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.mbel"), []byte("@lang: en\ntitle = \"One\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{DefaultLocale: "en", Watch: true, WatchInterval: 5 * time.Millisecond}
+
+	if err := Init(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	first := Default()
+	waitFor(t, first.watching.Load)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := GlobalT("title"); got != "One" {
+					t.Errorf("GlobalT = %q", got)
+					return
+				}
+			}
+		}()
+	}
+	if err := Init(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if Default() == first {
+		t.Fatal("Init did not replace the global manager")
+	}
+	if first.watching.Load() {
+		t.Error("previous manager still watching after re-Init")
+	}
+	if err := first.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	if err := Init(filepath.Join(dir, "missing"), Config{}); err == nil {
+		t.Fatal("Init with a missing root succeeded")
+	}
+	if Default() == nil || GlobalT("title") != "One" {
+		t.Error("failed Init dropped the previous manager")
+	}
+	Default().Close()
+}
@@ -29,6 +29,13 @@ type Program struct {
 	AIAnnotations []*AIAnnotation            // Extracted AI_Context, AI_Tone, etc.
 	Terms         map[string]*TermDefinition // -term-name definitions
 	Imports       []string                   // @import namespaces
+	Comments      []*Comment                 // Plain (non-AI) comments, kept for formatting
+}
+
+// Comment represents a plain # comment line
+type Comment struct {
+	Text string // without the leading "#"
+	Line int
 }
 
 // AIAnnotation represents structured AI metadata from comments
@@ -98,9 +105,10 @@ func (p *Program) String() string {
 
 // MetadataStatement represents @key: value
 type MetadataStatement struct {
-	Token Token // The '@' token
-	Key   string
-	Value string // e.g., "pl", "1.0"
+	Token      Token // The '@' token
+	Key        string
+	Value      string // e.g., "pl", "1.0"
+	ValueToken Token  // The value's token
 }
 
 func (ms *MetadataStatement) statementNode()       {}
@@ -111,8 +119,9 @@ func (ms *MetadataStatement) String() string {
 
 // SectionStatement represents [section_name]
 type SectionStatement struct {
-	Token Token // The '[' token
-	Name  string
+	Token     Token // The '[' token
+	Name      string
+	NameToken Token // The name's IDENT token
 }
 
 func (ss *SectionStatement) statementNode()       {}
@@ -149,13 +158,18 @@ type BlockExpression struct {
 	Token    Token  // The '{' token
 	Argument string // The variable name, e.g. "n" in count(n)
 	Cases    []*BlockCase
+	EndLine  int // Line of the closing '}'
 }
 
 func (be *BlockExpression) expressionNode()      {}
 func (be *BlockExpression) TokenLiteral() string { return be.Token.Literal }
 func (be *BlockExpression) String() string {
 	var out bytes.Buffer
-	out.WriteString("(" + be.Argument + ") {\n")
+	if be.Argument == variantArgument {
+		out.WriteString(variantKeyword + " {\n")
+	} else {
+		out.WriteString("(" + be.Argument + ") {\n")
+	}
 	for _, c := range be.Cases {
 		out.WriteString(c.String())
 	}
@@ -166,11 +180,13 @@ func (be *BlockExpression) String() string {
 type BlockCase struct {
 	Condition  string // "0", "other", "male", "one", "few", "many"
 	Value      string // The resulting string
+	ValueToken Token  // The value's string token (with its span)
 	IsRange    bool   // true if this is a numeric range [2..4]
 	RangeStart int    // Start of range (inclusive)
 	RangeEnd   int    // End of range (inclusive)
 }
 
+func (bc *BlockCase) TokenLiteral() string { return bc.Condition }
 func (bc *BlockCase) String() string {
 	return fmt.Sprintf("\t[%s] => \"%s\"\n", bc.Condition, bc.Value)
 }
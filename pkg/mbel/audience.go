@@ -0,0 +1,176 @@
+package mbel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Audience restricts who may see a key outside the server, set with an
+// @audience line directly above it:
+//
+//	@audience: beta
+//	checkout.one_click = "Buy with one click"
+//
+// Untagged keys are public. The server renders every key; BundleHandler,
+// Subset and frontend exports leave out keys of audiences not listed in
+// Config.Audiences (or the export's -audience flag). A tag applies in
+// every locale, whichever locale's file carries it (see ShareAudiences).
+type Audience string
+
+const (
+	AudienceInternal Audience = "internal"
+	AudienceBeta     Audience = "beta"
+	AudiencePublic   Audience = "public"
+)
+
+const audienceMeta = "audience"
+
+// audienceDataKey holds the compiled tags as key -> audience
+const audienceDataKey = "__audience"
+
+// ParseAudience validates an audience name
+func ParseAudience(s string) (Audience, error) {
+	switch a := Audience(s); a {
+	case AudienceInternal, AudienceBeta, AudiencePublic:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid audience %q (want internal, beta or public)", s)
+}
+
+// ParseAudiences parses a comma-separated list such as "public,beta"
+func ParseAudiences(s string) ([]Audience, error) {
+	var out []Audience
+	for _, name := range strings.Split(s, ",") {
+		a, err := ParseAudience(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// compileAudiences returns the @audience tags of p by key, or nil when
+// there is none
+func compileAudiences(p *Program) map[string]string {
+	var out map[string]string
+	walkKeyMeta(p, func(key string, a *AssignStatement, meta map[string]*MetadataStatement) {
+		if ms := meta[audienceMeta]; ms != nil {
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[key] = ms.Value
+		}
+	})
+	return out
+}
+
+// FilterAudience returns data without the keys whose audience is not in
+// allowed (nil = public only). Internal "__" entries are kept.
+func FilterAudience(data map[string]interface{}, allowed []Audience) map[string]interface{} {
+	tags, _ := data[audienceDataKey].(map[string]string)
+	if len(tags) == 0 {
+		return data
+	}
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if strings.HasPrefix(k, "__") || audienceAllowed(tags, k, allowed) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// ShareAudiences gives every locale of langData the @audience tags of all
+// of them, the most restrictive tag winning (internal over beta over
+// public), so a key tagged in the source locale only stays hidden in
+// translations that lack the line. Locales whose tags change are copied;
+// langData is not modified.
+func ShareAudiences(langData map[string]map[string]interface{}) map[string]map[string]interface{} {
+	var union map[string]string
+	for _, data := range langData {
+		tags, _ := data[audienceDataKey].(map[string]string)
+		for k, a := range tags {
+			if union == nil {
+				union = make(map[string]string)
+			}
+			if cur, ok := union[k]; !ok || audienceRank(a) > audienceRank(cur) {
+				union[k] = a
+			}
+		}
+	}
+	if union == nil {
+		return langData
+	}
+
+	out := make(map[string]map[string]interface{}, len(langData))
+	for lang, data := range langData {
+		tags, _ := data[audienceDataKey].(map[string]string)
+		if reflect.DeepEqual(tags, union) {
+			out[lang] = data
+			continue
+		}
+		d := make(map[string]interface{}, len(data)+1)
+		for k, v := range data {
+			d[k] = v
+		}
+		d[audienceDataKey] = union
+		out[lang] = d
+	}
+	return out
+}
+
+// audienceRank orders audiences from public to internal; unknown tags
+// rank as internal so they stay hidden
+func audienceRank(a string) int {
+	switch Audience(a) {
+	case AudiencePublic:
+		return 0
+	case AudienceBeta:
+		return 1
+	}
+	return 2
+}
+
+// audienceAllowed reports whether key, tagged by tags, is visible to
+// allowed (nil = public only)
+func audienceAllowed(tags map[string]string, key string, allowed []Audience) bool {
+	a, ok := tags[key]
+	if !ok {
+		a = string(AudiencePublic)
+	}
+	if allowed == nil {
+		return a == string(AudiencePublic)
+	}
+	for _, x := range allowed {
+		if string(x) == a {
+			return true
+		}
+	}
+	return false
+}
+
+// AudienceRule returns a lint rule reporting invalid or misplaced
+// @audience lines
+func AudienceRule() LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		used := make(map[*MetadataStatement]bool)
+
+		walkKeyMeta(p, func(key string, a *AssignStatement, meta map[string]*MetadataStatement) {
+			if ms := meta[audienceMeta]; ms != nil {
+				used[ms] = true
+				if _, err := ParseAudience(ms.Value); err != nil {
+					out = append(out, DiagnosticAt(ms.ValueToken, SeverityError, err.Error()))
+				}
+			}
+		})
+		for _, stmt := range p.Statements {
+			if ms, ok := stmt.(*MetadataStatement); ok && ms.Key == audienceMeta && !used[ms] {
+				out = append(out, DiagnosticAt(ms.Token, SeverityError, "@audience must be directly above a key"))
+			}
+		}
+		return out
+	}
+}
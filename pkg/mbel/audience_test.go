@@ -0,0 +1,134 @@
+package mbel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const audienceSource = `[checkout]
+title = "Checkout"
+@audience: beta
+one_click = "Buy with one click"
+@status: reviewed
+@audience: internal
+debug = "Order {id}"
+`
+
+func TestAudiences(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "en"), 0755)
+	os.WriteFile(filepath.Join(dir, "en", "shop.mbel"), []byte(audienceSource), 0644)
+
+	m, err := NewManager(dir, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Get("en", "shop.checkout.one_click"); got != "Buy with one click" {
+		t.Errorf("server lookup of a beta key = %q", got)
+	}
+	if got := m.Subset("en", nil); !reflect.DeepEqual(got, map[string]string{"shop.checkout.title": "Checkout"}) {
+		t.Errorf("public Subset = %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	BundleHandler(m).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/i18n/en.json", nil))
+	if body := rec.Body.String(); body != `{"shop.checkout.title":"Checkout"}` {
+		t.Errorf("public bundle = %s", body)
+	}
+
+	// Translations rarely copy the @audience line; the source's tag holds
+	os.MkdirAll(filepath.Join(dir, "pl"), 0755)
+	os.WriteFile(filepath.Join(dir, "pl", "shop.mbel"), []byte("[checkout]\ntitle = \"Kasa\"\none_click = \"Kup jednym kliknięciem\"\n"), 0644)
+	if err := m.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Subset("pl", nil); !reflect.DeepEqual(got, map[string]string{"shop.checkout.title": "Kasa"}) {
+		t.Errorf("public pl Subset = %v", got)
+	}
+	rec = httptest.NewRecorder()
+	BundleHandler(m).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/i18n/pl.json", nil))
+	if body := rec.Body.String(); body != `{"shop.checkout.title":"Kasa"}` {
+		t.Errorf("public pl bundle = %s", body)
+	}
+
+	m, err = NewManager(dir, Config{DefaultLocale: "en", Audiences: []Audience{AudiencePublic, AudienceBeta}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Subset("en", nil); len(got) != 2 || got["shop.checkout.one_click"] == "" {
+		t.Errorf("beta Subset = %v", got)
+	}
+
+	data, _, _ := CompileSource([]byte(audienceSource), nil)
+	want := map[string]string{"checkout.one_click": "beta", "checkout.debug": "internal"}
+	if !reflect.DeepEqual(data[audienceDataKey], want) {
+		t.Errorf("compiled audiences = %v", data[audienceDataKey])
+	}
+	if meta, _ := data["__meta"].(map[string]string); len(meta) != 0 {
+		t.Errorf("audience lines leaked into __meta: %v", meta)
+	}
+	var streamed map[string]string
+	CompileStream(strings.NewReader(audienceSource), func(key string, v interface{}) error {
+		if key == audienceDataKey {
+			streamed = v.(map[string]string)
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(streamed, want) {
+		t.Errorf("CompileStream audiences = %v", streamed)
+	}
+	back, _, _ := CompileSource([]byte(FormatData(data)), nil)
+	if !reflect.DeepEqual(back[audienceDataKey], want) {
+		t.Errorf("FormatData lost audiences:\n%s", FormatData(data))
+	}
+
+	internal := FilterAudience(data, []Audience{AudienceInternal})
+	if _, ok := internal["checkout.title"]; ok {
+		t.Error("FilterAudience kept an untagged key for internal only")
+	}
+	if _, ok := internal["checkout.debug"]; !ok {
+		t.Error("FilterAudience dropped an internal key")
+	}
+}
+
+func TestAudienceRule(t *testing.T) {
+	src := audienceSource + "@audience: staff\nbad = \"x\"\n@audience: beta\n"
+	diags := AudienceRule()(NewParser(NewLexer(src)).ParseProgram(), LintContext{})
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Message)
+	}
+	want := []string{
+		`invalid audience "staff" (want internal, beta or public)`,
+		"@audience must be directly above a key",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %q", got)
+	}
+	if _, err := ParseAudiences("public, beta"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShareAudiences(t *testing.T) {
+	en := map[string]interface{}{"a": "A", audienceDataKey: map[string]string{"a": "beta", "b": "beta"}}
+	pl := map[string]interface{}{"a": "A", "b": "B", audienceDataKey: map[string]string{"b": "internal"}}
+	got := ShareAudiences(map[string]map[string]interface{}{"en": en, "pl": pl})
+
+	want := map[string]string{"a": "beta", "b": "internal"}
+	for _, lang := range []string{"en", "pl"} {
+		if !reflect.DeepEqual(got[lang][audienceDataKey], want) {
+			t.Errorf("%s tags = %v", lang, got[lang][audienceDataKey])
+		}
+	}
+	if !reflect.DeepEqual(pl[audienceDataKey], map[string]string{"b": "internal"}) {
+		t.Error("ShareAudiences modified its input")
+	}
+}
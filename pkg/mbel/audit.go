@@ -0,0 +1,131 @@
+package mbel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Change sources of a CatalogChange
+const (
+	ChangeUpdate = "update" // Manager.Update
+	ChangeReload = "reload" // a (hot) reload from the repository
+)
+
+// CatalogChange is one message of a served catalog that changed, for
+// audit trails ("who changed this legal disclaimer and when")
+type CatalogChange struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`          // ChangeUpdate or ChangeReload
+	Actor  string    `json:"actor,omitempty"` // set with WithActor on the context of the change
+	Lang   string    `json:"lang"`
+	Key    string    `json:"key"`            // block cases are "key[condition]"
+	Old    string    `json:"old"`            // "" when added
+	New    string    `json:"new"`            // "" when removed
+	File   string    `json:"file,omitempty"` // file defining the new value, when known
+}
+
+// AuditSink receives catalog changes; set it via Config.Audit. It is
+// called synchronously while the manager applies the change, so it must
+// not call Update or Load itself.
+type AuditSink interface {
+	RecordChange(ctx context.Context, c CatalogChange)
+}
+
+// AuditFunc adapts a function to an AuditSink
+type AuditFunc func(ctx context.Context, c CatalogChange)
+
+func (f AuditFunc) RecordChange(ctx context.Context, c CatalogChange) { f(ctx, c) }
+
+// NewJSONAuditLog returns a sink writing every change to w as a line of
+// JSON, for an append-only audit file or log shipper
+func NewJSONAuditLog(w io.Writer) AuditSink {
+	return &jsonAuditLog{enc: json.NewEncoder(w)}
+}
+
+type jsonAuditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (l *jsonAuditLog) RecordChange(_ context.Context, c CatalogChange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc.Encode(c)
+}
+
+type actorContextKey struct{}
+
+// WithActor records who is making changes through ctx (a user name or
+// ID, a deploy job), for the CatalogChange records of Update and Load
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, or ""
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// audit reports every message that differs between before and after
+// (data per language) to the sink
+func (m *Manager) audit(ctx context.Context, source string, before, after map[string]map[string]interface{}) {
+	now := time.Now()
+	actor := ActorFromContext(ctx)
+	for _, lang := range unionKeys(before, after) {
+		old, cur := before[lang], after[lang]
+		for _, key := range unionKeys(old, cur) {
+			if strings.HasPrefix(key, "__") {
+				continue
+			}
+			oldMsgs, newMsgs := messageEntries(old[key]), messageEntries(cur[key])
+			for _, sub := range unionKeys(oldMsgs, newMsgs) {
+				if oldMsgs[sub] == newMsgs[sub] {
+					continue
+				}
+				c := CatalogChange{Time: now, Source: source, Actor: actor, Lang: lang, Key: key + sub, Old: oldMsgs[sub], New: newMsgs[sub]}
+				if c.New != "" {
+					loc, _ := m.Locate(lang, key)
+					c.File = loc.File
+				}
+				m.auditSink.RecordChange(ctx, c)
+			}
+		}
+	}
+}
+
+// messageEntries flattens a value into its messages: "" for a string,
+// "[condition]" for each case of a block
+func messageEntries(v interface{}) map[string]string {
+	switch val := v.(type) {
+	case string:
+		return map[string]string{"": val}
+	case *RuntimeBlock:
+		out := make(map[string]string)
+		for _, e := range blockEntries(val) {
+			out["["+e.cond+"]"] = e.value
+		}
+		return out
+	}
+	return nil
+}
+
+// unionKeys returns the keys of a and b, sorted
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
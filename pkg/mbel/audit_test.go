@@ -0,0 +1,67 @@
+package mbel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAuditChanges(t *testing.T) {
+	var changes []CatalogChange
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {
+			"legal": "Terms apply",
+			"items": &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} item", "other": "{n} items"}},
+		},
+	})
+	m, err := NewManagerWithRepo(repo, Config{Audit: AuditFunc(func(_ context.Context, c CatalogChange) {
+		changes = append(changes, c)
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("first load audited: %+v", changes)
+	}
+
+	ctx := WithActor(context.Background(), "ola@example.com")
+	if err := m.UpdateContext(ctx, "en", "legal", "Terms and conditions apply"); err != nil {
+		t.Fatal(err)
+	}
+	repo.Set("en", "items", &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} item", "other": "{n} things"}})
+	repo.Set("en", "promo", "Sale!")
+	if err := m.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	type rec struct{ Source, Actor, Key, Old, New string }
+	var got []rec
+	for _, c := range changes {
+		if c.Lang != "en" || c.Time.IsZero() {
+			t.Errorf("change = %+v", c)
+		}
+		got = append(got, rec{c.Source, c.Actor, c.Key, c.Old, c.New})
+	}
+	want := []rec{
+		{ChangeUpdate, "ola@example.com", "legal", "Terms apply", "Terms and conditions apply"},
+		{ChangeReload, "", "items[other]", "{n} items", "{n} things"},
+		{ChangeReload, "", "promo", "", "Sale!"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestJSONAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	NewJSONAuditLog(&buf).RecordChange(context.Background(), CatalogChange{Source: ChangeUpdate, Lang: "pl", Key: "legal", New: "Regulamin"})
+	var c map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c["key"] != "legal" || c["new"] != "Regulamin" || c["old"] != "" || c["source"] != "update" {
+		t.Errorf("record = %s", buf.String())
+	}
+}
@@ -0,0 +1,120 @@
+package mbel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Back-translation verification. Translated messages are translated back
+// to the source language and compared with the source text; a low
+// similarity points at hallucinated, dropped or invented content.
+
+// Translator translates texts between locales; result i translates
+// texts[i]
+type Translator interface {
+	Translate(ctx context.Context, from, to string, texts []string) ([]string, error)
+}
+
+// BackTranslation is a translated message and its translation back to
+// the source language
+type BackTranslation struct {
+	Key        string
+	Source     string
+	Target     string
+	Back       string
+	Similarity float64 // TextSimilarity of Source and Back
+}
+
+// VerifyBackTranslation back-translates the translated rows of sheet,
+// batchSize at a time, and returns those whose similarity to the source
+// is below threshold
+func VerifyBackTranslation(ctx context.Context, sheet *ReviewSheet, tr Translator, threshold float64, batchSize int) ([]BackTranslation, error) {
+	var rows []ReviewRow
+	for _, r := range sheet.Rows {
+		if r.Status == ReviewOK || r.Status == ReviewTooLong {
+			rows = append(rows, r)
+		}
+	}
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+
+	var diverged []BackTranslation
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+		texts := make([]string, len(batch))
+		for i, r := range batch {
+			texts[i] = r.Target
+		}
+		back, err := tr.Translate(ctx, sheet.TargetLang, sheet.SourceLang, texts)
+		if err != nil {
+			return diverged, err
+		}
+		if len(back) != len(texts) {
+			return diverged, fmt.Errorf("back-translation returned %d texts for %d", len(back), len(texts))
+		}
+		for i, r := range batch {
+			if sim := TextSimilarity(r.Source, back[i]); sim < threshold {
+				diverged = append(diverged, BackTranslation{Key: r.Key, Source: r.Source, Target: r.Target, Back: back[i], Similarity: sim})
+			}
+		}
+	}
+	return diverged, nil
+}
+
+// TextSimilarity compares the words of a and b, case-insensitively: 1
+// for the same words, 0 for none in common (Dice coefficient). Two empty
+// texts are identical.
+func TextSimilarity(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+
+	counts := make(map[string]int, len(wa))
+	for _, w := range wa {
+		counts[w]++
+	}
+	common := 0
+	for _, w := range wb {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
+
+// words splits s into lower-case words; {placeholders} count as words
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '{' && r != '}' && r != '_' && r != '-'
+	})
+}
+
+const translatePrompt = `Translate software UI strings from %s to %s.
+Keep {placeholders} and {-terms} verbatim and translate literally: do not improve, shorten or complete the text.
+Answer with a JSON object {"translations": ["...", ...]} holding one translation per input string, in order.`
+
+// Translate translates texts with the chat completions endpoint
+func (m *OpenAIModel) Translate(ctx context.Context, from, to string, texts []string) ([]string, error) {
+	user, err := json.Marshal(map[string]interface{}{"strings": texts})
+	if err != nil {
+		return nil, err
+	}
+	content, err := m.complete(ctx, fmt.Sprintf(translatePrompt, from, to), string(user))
+	if err != nil {
+		return nil, err
+	}
+
+	var answer struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(content), &answer); err != nil {
+		return nil, fmt.Errorf("model: answer is not the requested JSON: %w", err)
+	}
+	return answer.Translations, nil
+}
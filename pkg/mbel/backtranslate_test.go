@@ -0,0 +1,53 @@
+package mbel
+
+import (
+	"context"
+	"testing"
+)
+
+// mapTranslator translates through a fixed dictionary
+type mapTranslator map[string]string
+
+func (m mapTranslator) Translate(ctx context.Context, from, to string, texts []string) ([]string, error) {
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		out[i] = m[t]
+	}
+	return out, nil
+}
+
+func TestVerifyBackTranslation(t *testing.T) {
+	sheet := &ReviewSheet{SourceLang: "en", TargetLang: "pl", Rows: []ReviewRow{
+		{Key: "cart", Source: "Your cart is empty", Target: "Twój koszyk jest pusty", Status: ReviewOK},
+		{Key: "promo", Source: "Free shipping over {amount}", Target: "Darmowa dostawa i prezent gratis", Status: ReviewOK},
+		{Key: "todo", Source: "Later", Status: ReviewMissing},
+	}}
+	tr := mapTranslator{
+		"Twój koszyk jest pusty":           "Your basket is empty",
+		"Darmowa dostawa i prezent gratis": "Free delivery and a free gift",
+	}
+
+	got, err := VerifyBackTranslation(context.Background(), sheet, tr, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Key != "promo" || got[0].Back != "Free delivery and a free gift" {
+		t.Errorf("diverged = %+v", got)
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want float64
+	}{
+		{"Your cart is empty", "your cart is EMPTY!", 1},
+		{"Your cart is empty", "Your basket is empty", 0.75},
+		{"Hello {name}", "Goodbye", 0},
+		{"", "", 1},
+	} {
+		if got := TextSimilarity(tc.a, tc.b); got != tc.want {
+			t.Errorf("TextSimilarity(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package mbel
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// Completeness returns the share (0 to 1) of the keys of source that
+// target has. An empty source counts as complete.
+func Completeness(source, target map[string]interface{}) float64 {
+	keys := SortedKeys(source)
+	if len(keys) == 0 {
+		return 1
+	}
+	have := 0
+	for _, key := range keys {
+		if _, ok := target[key]; ok {
+			have++
+		}
+	}
+	return float64(have) / float64(len(keys))
+}
+
+// Completeness returns the share of the default locale's keys lang has,
+// and whether lang is loaded
+func (m *Manager) Completeness(lang string) (float64, bool) {
+	allData := m.state.Load().allData
+	data, ok := allData[lang]
+	if !ok {
+		return 0, false
+	}
+	return Completeness(allData[m.defaultLang], data), true
+}
+
+// WriteBadge writes a shields.io-style SVG badge reading "label | message"
+// with the message on a background of color (e.g. "#4c1")
+func WriteBadge(w io.Writer, label, message, color string) error {
+	lw, mw := badgeTextWidth(label)+10, badgeTextWidth(message)+10
+	title := html.EscapeString(label + ": " + message)
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s">`, lw+mw, title)
+	fmt.Fprintf(&b, `<title>%s</title>`, title)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, lw+mw)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		lw, lw, mw, html.EscapeString(color), lw+mw)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, t := range []struct {
+		x    int
+		text string
+	}{{lw / 2, label}, {lw + mw/2, message}} {
+		fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, t.x, t.text, t.x, t.text)
+	}
+	b.WriteString("</g></svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCompletenessBadge writes the badge of a locale's completeness,
+// e.g. "pl | 87%", red below 50% and green at 100%
+func WriteCompletenessBadge(w io.Writer, lang string, completeness float64) error {
+	// Round down, so a catalog only reads 100% when it is complete
+	pct := int(completeness * 100)
+	color := "#e05d44" // red
+	switch {
+	case pct >= 100:
+		color = "#4c1" // bright green
+	case pct >= 90:
+		color = "#97ca00" // green
+	case pct >= 75:
+		color = "#dfb317" // yellow
+	case pct >= 50:
+		color = "#fe7d37" // orange
+	}
+	return WriteBadge(w, lang, fmt.Sprintf("%d%%", pct), color)
+}
+
+// badgeTextWidth estimates the width in pixels of s in 11px Verdana
+func badgeTextWidth(s string) int {
+	return utf8.RuneCountInString(s) * 7
+}
+
+// BadgeHandler serves completeness badges of the loaded locales against
+// the default locale, for READMEs and dashboards:
+//
+//	mux.Handle("/badge/", mbel.BadgeHandler(m))
+//
+// GET /badge/pl.svg returns the badge of "pl"; unknown locales get 404.
+func BadgeHandler(m *Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Base(r.URL.Path)
+		if !strings.HasSuffix(name, ".svg") {
+			http.NotFound(w, r)
+			return
+		}
+		lang := strings.TrimSuffix(name, ".svg")
+		c, ok := m.Completeness(lang)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		var buf bytes.Buffer
+		WriteCompletenessBadge(&buf, lang, c)
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(buf.Bytes())
+	})
+}
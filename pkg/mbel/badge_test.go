@@ -0,0 +1,47 @@
+package mbel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompletenessBadge(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"a": "A", "b": "B", "c": "C", "__meta": map[string]string{"lang": "en"}},
+		"pl": {"a": "A", "b": "B", "extra": "X"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := m.Completeness("pl"); !ok || c < 0.66 || c > 0.67 {
+		t.Errorf("Completeness(pl) = %v, %v", c, ok)
+	}
+	if c, _ := m.Completeness("en"); c != 1 {
+		t.Errorf("Completeness(en) = %v", c)
+	}
+
+	h := BadgeHandler(m)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/badge/pl.svg", nil))
+	body := rec.Body.String()
+	if rec.Header().Get("Content-Type") != "image/svg+xml" || !strings.HasPrefix(body, "<svg") {
+		t.Fatalf("badge = %q", body)
+	}
+	if !strings.Contains(body, "<title>pl: 66%</title>") || !strings.Contains(body, `fill="#fe7d37"`) {
+		t.Errorf("badge should read 66%% on orange: %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/badge/de.svg", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown locale: got %d", rec.Code)
+	}
+
+	var b strings.Builder
+	WriteBadge(&b, "a<b", "ok", "#4c1")
+	if strings.Contains(b.String(), "a<b") {
+		t.Error("label not escaped")
+	}
+}
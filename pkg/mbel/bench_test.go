@@ -0,0 +1,136 @@
+package mbel
+
+import (
+	"testing"
+)
+
+func benchRuntime(tb testing.TB) *Runtime {
+	tb.Helper()
+	src := `@lang: pl
+title = "Witaj w aplikacji"
+greeting = "Cześć, {name}!"
+files(n) {
+	[one]   => "{n} plik"
+	[few]   => "{n} pliki"
+	[many]  => "{n} plików"
+	[other] => "{n} pliku"
+}
+`
+	p := NewParser(NewLexer(src))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		tb.Fatal(errs)
+	}
+	data, err := NewCompiler().Compile(program)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return NewRuntime(data.Data)
+}
+
+func BenchmarkRuntimeGetPlain(b *testing.B) {
+	r := benchRuntime(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Get("title")
+	}
+}
+
+func BenchmarkRuntimeGetInterpolated(b *testing.B) {
+	r := benchRuntime(b)
+	vars := Vars{"name": "Ala"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Get("greeting", vars)
+	}
+}
+
+func BenchmarkRuntimeGetPlural(b *testing.B) {
+	r := benchRuntime(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Get("files", i%30)
+	}
+}
+
+// Hot counters (notification badges) resolve the same block over and over
+func BenchmarkBlockResolveHotCounter(b *testing.B) {
+	r := benchRuntime(b)
+	rb := r.Data["files"].(*RuntimeBlock)
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rb.ResolveWithLang(i%30, "pl")
+		}
+	})
+	b.Run("runtime", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r.resolveBlock(rb, i%30)
+		}
+	})
+}
+
+func BenchmarkManagerGetParallel(b *testing.B) {
+	m, err := NewManagerWithRepo(&staticRepo{data: map[string]map[string]interface{}{
+		"en": {"title": "Welcome", "greeting": "Hello, {name}!"},
+		"pl": {"title": "Witaj"},
+	}}, Config{DefaultLocale: "en"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.Get("pl", "title")
+		}
+	})
+}
+
+func TestGetPlainKeyDoesNotAllocate(t *testing.T) {
+	r := NewRuntime(map[string]interface{}{"title": "Hello"})
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Get("title")
+	})
+	if allocs != 0 {
+		t.Errorf("expected zero allocations for parameterless key, got %v", allocs)
+	}
+}
+
+func TestCountTableMatchesRules(t *testing.T) {
+	r := benchRuntime(t)
+	rb := r.Data["files"].(*RuntimeBlock)
+	for _, n := range []int{0, 1, 2, 5, 12, 22, 101, 127, 128, 1002, -3} {
+		if got, want := r.resolveBlock(rb, Vars{"n": n}), rb.ResolveWithLang(n, "pl"); got != want {
+			t.Errorf("%d: cached %q, rules %q", n, got, want)
+		}
+	}
+
+	// Changing the language invalidates the table
+	r.Language = "en"
+	if got := r.resolveBlock(rb, 0); got != rb.ResolveWithLang(0, "en") {
+		t.Errorf("stale table after language change: %q", got)
+	}
+}
+
+func TestManagerPreload(t *testing.T) {
+	m, err := NewManagerWithRepo(&staticRepo{data: map[string]map[string]interface{}{
+		"en": {"greeting": "Hello, {name}!"},
+		"pl": {"title": "Witaj {name}"},
+	}}, Config{DefaultLocale: "en", LazyLoad: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Preload("pl", []string{"title", "greeting"})
+
+	for lang, msg := range map[string]string{"pl": "Witaj {name}", "en": "Hello, {name}!"} {
+		r, ok := m.runtime(lang)
+		if !ok {
+			t.Fatalf("expected %s runtime to be created by Preload", lang)
+		}
+		if _, cached := r.templates.Load(msg); !cached {
+			t.Errorf("expected %q to be preloaded in %s", msg, lang)
+		}
+	}
+}
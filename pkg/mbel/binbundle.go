@@ -0,0 +1,345 @@
+package mbel
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Binary bundle layout (all integers little-endian):
+//
+//	magic   "MBELBND1"
+//	count   uint32
+//	index   count × { keyLen uint32, key, offset uint64, length uint32 }  (sorted by key)
+//	values  encoded values, addressed by index offsets (relative to values start)
+//
+// The index is small and read at open; values stay in the (memory-mapped)
+// file and are decoded only when a key is first requested.
+const bundleMagic = "MBELBND1"
+
+// Value tags
+const (
+	bundleString byte = 's'
+	bundleBlock  byte = 'b'
+	bundleMap    byte = 'm' // map[string]string (__meta, __terms)
+	bundleList   byte = 'l' // []string (__imports)
+	bundleInt    byte = 'i' // int (__schema)
+)
+
+// ErrInvalidBundle is returned when a file is not a valid binary bundle
+var ErrInvalidBundle = errors.New("mbel: invalid binary bundle")
+
+// WriteBundle encodes compiled data (one language) in the binary bundle
+// format. Values of unsupported types (e.g. __ai) are skipped.
+func WriteBundle(w io.Writer, data map[string]interface{}) error {
+	keys := make([]string, 0, len(data))
+	var values [][]byte
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	encoded := keys[:0]
+	for _, k := range keys {
+		if v, ok := encodeBundleValue(data[k]); ok {
+			encoded = append(encoded, k)
+			values = append(values, v)
+		}
+	}
+	keys = encoded
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(bundleMagic)
+	binary.Write(bw, binary.LittleEndian, uint32(len(keys)))
+
+	var offset uint64
+	for i, k := range keys {
+		binary.Write(bw, binary.LittleEndian, uint32(len(k)))
+		bw.WriteString(k)
+		binary.Write(bw, binary.LittleEndian, offset)
+		binary.Write(bw, binary.LittleEndian, uint32(len(values[i])))
+		offset += uint64(len(values[i]))
+	}
+	for _, v := range values {
+		bw.Write(v)
+	}
+	return bw.Flush()
+}
+
+func encodeBundleValue(v interface{}) ([]byte, bool) {
+	var b []byte
+	switch val := v.(type) {
+	case string:
+		b = append(b, bundleString)
+		b = appendBundleString(b, val)
+	case *RuntimeBlock:
+		b = append(b, bundleBlock)
+		b = appendBundleString(b, val.Argument)
+		b = binary.AppendUvarint(b, uint64(len(val.Cases)))
+		conds := make([]string, 0, len(val.Cases))
+		for c := range val.Cases {
+			conds = append(conds, c)
+		}
+		sort.Strings(conds)
+		for _, c := range conds {
+			b = appendBundleString(b, c)
+			b = appendBundleString(b, val.Cases[c])
+		}
+		b = binary.AppendUvarint(b, uint64(len(val.RangeCases)))
+		for _, rc := range val.RangeCases {
+			b = binary.AppendVarint(b, int64(rc.Start))
+			b = binary.AppendVarint(b, int64(rc.End))
+			b = appendBundleString(b, rc.Value)
+		}
+	case map[string]string:
+		b = append(b, bundleMap)
+		b = binary.AppendUvarint(b, uint64(len(val)))
+		names := make([]string, 0, len(val))
+		for k := range val {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			b = appendBundleString(b, k)
+			b = appendBundleString(b, val[k])
+		}
+	case int:
+		b = append(b, bundleInt)
+		b = binary.AppendVarint(b, int64(val))
+	case []string:
+		b = append(b, bundleList)
+		b = binary.AppendUvarint(b, uint64(len(val)))
+		for _, s := range val {
+			b = appendBundleString(b, s)
+		}
+	default:
+		return nil, false
+	}
+	return b, true
+}
+
+func appendBundleString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// Bundle is an opened binary bundle. Messages are decoded on first
+// access and memoized; the rest of the file is never deserialized.
+// A Bundle is safe for concurrent use.
+type Bundle struct {
+	mu      sync.RWMutex // guards raw and release against Close
+	raw     []byte       // nil once closed
+	release func() error
+	keys    []string
+	offsets []bundleEntry
+	values  int // start of the values section in raw
+	decoded sync.Map
+}
+
+type bundleEntry struct {
+	offset uint64
+	length uint32
+}
+
+// bundleEntryMin is the size of an index entry with an empty key
+const bundleEntryMin = 4 + 8 + 4
+
+// OpenBundle memory-maps the bundle at path (on platforms without mmap
+// support the file is read into memory instead)
+func OpenBundle(path string) (*Bundle, error) {
+	raw, release, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := parseBundle(raw)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	b.release = release
+	return b, nil
+}
+
+// ParseBundle reads a bundle from an in-memory buffer
+func ParseBundle(raw []byte) (*Bundle, error) {
+	return parseBundle(raw)
+}
+
+func parseBundle(raw []byte) (*Bundle, error) {
+	if len(raw) < len(bundleMagic)+4 || string(raw[:len(bundleMagic)]) != bundleMagic {
+		return nil, ErrInvalidBundle
+	}
+	pos := len(bundleMagic)
+	count := int(binary.LittleEndian.Uint32(raw[pos:]))
+	pos += 4
+	// Every index entry takes at least bundleEntryMin bytes; a count the
+	// file cannot hold must not size the allocations below
+	if count > (len(raw)-pos)/bundleEntryMin {
+		return nil, ErrInvalidBundle
+	}
+
+	b := &Bundle{raw: raw, keys: make([]string, 0, count), offsets: make([]bundleEntry, 0, count)}
+	for i := 0; i < count; i++ {
+		if pos+4 > len(raw) {
+			return nil, ErrInvalidBundle
+		}
+		keyLen := int(binary.LittleEndian.Uint32(raw[pos:]))
+		pos += 4
+		if keyLen < 0 || pos+keyLen+12 > len(raw) {
+			return nil, ErrInvalidBundle
+		}
+		b.keys = append(b.keys, string(raw[pos:pos+keyLen]))
+		pos += keyLen
+		b.offsets = append(b.offsets, bundleEntry{
+			offset: binary.LittleEndian.Uint64(raw[pos:]),
+			length: binary.LittleEndian.Uint32(raw[pos+8:]),
+		})
+		pos += 12
+	}
+	b.values = pos
+	return b, nil
+}
+
+// Keys returns all keys in the bundle, sorted
+func (b *Bundle) Keys() []string {
+	return append([]string(nil), b.keys...)
+}
+
+// Len returns the number of keys in the bundle
+func (b *Bundle) Len() int {
+	return len(b.keys)
+}
+
+// Get decodes (once) and returns the value stored under key; after Close
+// it finds nothing
+func (b *Bundle) Get(key string) (interface{}, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.raw == nil {
+		return nil, false
+	}
+	if v, ok := b.decoded.Load(key); ok {
+		return v, true
+	}
+
+	i := sort.SearchStrings(b.keys, key)
+	if i == len(b.keys) || b.keys[i] != key {
+		return nil, false
+	}
+
+	e := b.offsets[i]
+	start := uint64(b.values) + e.offset
+	end := start + uint64(e.length)
+	if end > uint64(len(b.raw)) {
+		return nil, false
+	}
+	v, ok := decodeBundleValue(b.raw[start:end])
+	if !ok {
+		return nil, false
+	}
+
+	b.decoded.Store(key, v)
+	return v, true
+}
+
+// Close unmaps the bundle file. Values already returned by Get stay
+// valid; later calls to Get find nothing.
+func (b *Bundle) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.raw = nil
+	if b.release == nil {
+		return nil
+	}
+	release := b.release
+	b.release = nil
+	return release()
+}
+
+// bundleReader decodes varint-prefixed fields, copying strings out of
+// the mapped file so they outlive Close
+type bundleReader struct {
+	buf []byte
+	err bool
+}
+
+func (r *bundleReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = true
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *bundleReader) varint() int64 {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		r.err = true
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *bundleReader) string() string {
+	n := r.uvarint()
+	if r.err || n > uint64(len(r.buf)) {
+		r.err = true
+		return ""
+	}
+	s := strings.Clone(string(r.buf[:n]))
+	r.buf = r.buf[n:]
+	return s
+}
+
+func decodeBundleValue(raw []byte) (interface{}, bool) {
+	if len(raw) == 0 {
+		return nil, false
+	}
+	r := &bundleReader{buf: raw[1:]}
+
+	var v interface{}
+	switch raw[0] {
+	case bundleString:
+		v = r.string()
+	case bundleBlock:
+		rb := &RuntimeBlock{Argument: r.string(), Cases: make(map[string]string), RangeCases: []RangeCase{}}
+		for n := r.uvarint(); n > 0 && !r.err; n-- {
+			c := r.string()
+			rb.Cases[c] = r.string()
+		}
+		for n := r.uvarint(); n > 0 && !r.err; n-- {
+			rb.RangeCases = append(rb.RangeCases, RangeCase{Start: int(r.varint()), End: int(r.varint()), Value: r.string()})
+		}
+		v = rb
+	case bundleMap:
+		m := make(map[string]string)
+		for n := r.uvarint(); n > 0 && !r.err; n-- {
+			k := r.string()
+			m[k] = r.string()
+		}
+		v = m
+	case bundleInt:
+		v = int(r.varint())
+	case bundleList:
+		var l []string
+		for n := r.uvarint(); n > 0 && !r.err; n-- {
+			l = append(l, r.string())
+		}
+		v = l
+	default:
+		return nil, false
+	}
+
+	if r.err {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,89 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryBundleRoundTrip(t *testing.T) {
+	src := `@lang: pl
+title = "Witaj w {-brand}"
+greeting = "Cześć {name}"
+items(n) {
+    [one] => "{n} przedmiot"
+    [few] => "{n} przedmioty"
+    [other] => "{n} przedmiotów"
+}
+`
+	p := NewParser(NewLexer(src))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	res, err := NewCompiler().Compile(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := res.Data
+	data["__terms"] = map[string]string{"brand": "Acme"}
+
+	path := filepath.Join(t.TempDir(), "pl.mbelb")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteBundle(f, data); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	b, err := OpenBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	for k, want := range data {
+		if k == "__ai" {
+			continue
+		}
+		got, ok := b.Get(k)
+		if !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %#v, want %#v", k, got, want)
+		}
+	}
+
+	r := NewRuntimeFromBundle(b)
+	if r.Language != "pl" {
+		t.Errorf("language from bundle meta: got %q", r.Language)
+	}
+	if got := r.Get("title"); got != "Witaj w Acme" {
+		t.Errorf("title: got %q", got)
+	}
+	if got := r.Get("items", 3); got != "3 przedmioty" {
+		t.Errorf("items: got %q", got)
+	}
+	if got := r.Get("missing"); got != "missing" {
+		t.Errorf("missing: got %q", got)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := b.Get("greeting"); ok || v != nil {
+		t.Errorf("Get after Close: %v, %v", v, ok)
+	}
+	if got := r.Get("greeting"); got != "greeting" {
+		t.Errorf("runtime after Close: got %q", got)
+	}
+
+	if _, err := ParseBundle([]byte("not a bundle")); err == nil {
+		t.Error("expected error for invalid bundle")
+	}
+	// A count far beyond the file must fail, not size an allocation
+	if _, err := ParseBundle([]byte("MBELBND1\xff\xff\xff\x7f")); err != ErrInvalidBundle {
+		t.Errorf("oversized count: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package mbel
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Literal braces. In message text "{{" stands for "{" and "}}" for "}",
+// so JSON samples and code can appear in translations:
+//
+//	hint = """Send {{"id": {id}}}"""   // Send {"id": 42}
+//
+// Compiled data keeps the escapes; the runtime resolves them when it
+// renders a message, and tools looking for placeholders skip them.
+
+// placeholderIndexes is re.FindAllStringSubmatchIndex(s, -1) without the
+// matches inside escaped braces ("{{name}}" is text, not a placeholder)
+func placeholderIndexes(re *regexp.Regexp, s string) [][]int {
+	all := re.FindAllStringSubmatchIndex(s, -1)
+	out := all[:0]
+	for _, loc := range all {
+		if !escapedBrace(s, loc[0]) {
+			out = append(out, loc)
+		}
+	}
+	return out
+}
+
+// escapedBrace reports whether the '{' at i is the second of a "{{"
+// pair, i.e. preceded by an odd run of '{'
+func escapedBrace(s string, i int) bool {
+	n := 0
+	for i > 0 && s[i-1] == '{' {
+		n++
+		i--
+	}
+	return n%2 == 1
+}
+
+// replacePlaceholders is re.ReplaceAllStringFunc for unescaped matches
+func replacePlaceholders(re *regexp.Regexp, s string, repl func(string) string) string {
+	locs := placeholderIndexes(re, s)
+	if len(locs) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(repl(s[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// UnescapeBraces turns the "{{" and "}}" of plain text (no placeholders)
+// into "{" and "}"
+func UnescapeBraces(s string) string {
+	if !strings.Contains(s, "{{") && !strings.Contains(s, "}}") {
+		return s
+	}
+	return strings.NewReplacer("{{", "{", "}}", "}").Replace(s)
+}
+
+// EscapeBraces doubles the braces of plain text, for writing text that
+// must render as is (e.g. imported from formats without placeholders)
+func EscapeBraces(s string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(s)
+}
+
+// canonicalBraces escapes the lone braces of message text that do not
+// start a placeholder or term reference, or close one, so the source
+// reads the same as it renders: "a { b" becomes "a {{ b"
+func canonicalBraces(s string) string {
+	if !strings.ContainsAny(s, "{}") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "}}"):
+			b.WriteString(s[i : i+2])
+			i += 2
+		case s[i] == '{':
+			if m := placeholderAt(s[i:]); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+			b.WriteString("{{")
+			i++
+		case s[i] == '}':
+			b.WriteString("}}")
+			i++
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// placeholderAt returns the placeholder or term reference s starts with
+func placeholderAt(s string) string {
+	for _, re := range []*regexp.Regexp{argRe, termRe} {
+		if loc := re.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			return s[:loc[1]]
+		}
+	}
+	return ""
+}
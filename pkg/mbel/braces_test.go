@@ -0,0 +1,129 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEscapedBraces(t *testing.T) {
+	src := `sample = """Send {{"id": {id}}} to {-api}"""
+code = "if (x) {{ return; }}"
+literal = "Use {{name}} in templates"
+items(n) {
+    [one] => "{{one}} {n}"
+    [other] => "{{many}} {n}"
+}
+`
+	data, _, err := CompileSource([]byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["__terms"] = map[string]string{"api": "the {{API}}"}
+	r := NewRuntime(data)
+	for key, want := range map[string]string{
+		"sample":  `Send {"id": 42} to the {API}`,
+		"code":    "if (x) { return; }",
+		"literal": "Use {name} in templates",
+	} {
+		if got := r.Get(key, Vars{"id": 42, "name": "x"}); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if got := r.Get("literal"); got != "Use {name} in templates" {
+		t.Errorf("literal without vars = %q", got)
+	}
+	// Closing braces alone take the slow path too, so a formatted file
+	// renders as before
+	closing := NewRuntime(map[string]interface{}{"close": "x }} y", "smile": "smile :}}"})
+	if got := closing.Get("close"); got != "x } y" {
+		t.Errorf("close = %q", got)
+	}
+	if got := closing.Get("smile"); got != "smile :}" {
+		t.Errorf("smile = %q", got)
+	}
+	if got := r.Get("items", 3); got != "{many} 3" {
+		t.Errorf("items = %q", got)
+	}
+
+	if got := placeholders(data["sample"].(string)); !reflect.DeepEqual(got, []string{"{id}", "{-api}"}) {
+		t.Errorf("placeholders(sample) = %v", got)
+	}
+	if got := placeholders(data["literal"].(string)); got != nil {
+		t.Errorf("placeholders(literal) = %v", got)
+	}
+	if f := CheckPlaceholders([]ReviewRow{{Key: "code", Source: "if (x) {{ return; }}", Target: "jeśli (x) {{ wróć; }}"}}); len(f) != 0 {
+		t.Errorf("escaped braces flagged: %v", f)
+	}
+}
+
+func TestFormatEscapesLoneBraces(t *testing.T) {
+	got, err := FormatSource(`a = "x { y } {name} {-term} {{ok}}"
+b(n) {
+    [other] => "{n} }"
+}
+c = "{n, plural, one {# file} other {# files}}"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `a = "x {{ y }} {name} {-term} {{ok}}"
+b(n) {
+    [other] => "{n} }}"
+}
+c = "{n, plural, one {# file} other {# files}}"
+`
+	if got != want {
+		t.Errorf("FormatSource =\n%s\nwant\n%s", got, want)
+	}
+	if again, _ := FormatSource(got); again != got {
+		t.Errorf("not idempotent:\n%s", again)
+	}
+}
+
+func TestI18nextBraces(t *testing.T) {
+	if got := toI18next("{{a}} {n} }}", "n"); got != "{a} {{count}} }" {
+		t.Errorf("toI18next = %q", got)
+	}
+	if got := fromI18next("{a} {{count}}"); got != "{{a}} {count}" {
+		t.Errorf("fromI18next = %q", got)
+	}
+}
+
+func TestEscapedBracesKeptForClients(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {
+			"hint":    "Type {{name}}, hi {name} at {-brand}",
+			"plain":   "Type {name}, hi {name} at {-brand}",
+			"__terms": map[string]string{"brand": "Acme"},
+		},
+	})
+	m, err := NewManagerWithRepo(repo, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Subset("en", []string{"hint"})["hint"]; got != "Type {{name}}, hi {name} at Acme" {
+		t.Errorf("Subset hint = %q", got)
+	}
+	if mustKeyHash(t, m, "en", "hint") == mustKeyHash(t, m, "en", "plain") {
+		t.Error("{{name}} and {name} share a hash")
+	}
+}
+
+func TestMigrateLiteralBraces(t *testing.T) {
+	data := map[string]interface{}{
+		"__schema": 3,
+		"__terms":  map[string]string{"api": "the {API}"},
+		"json":     `Send {"id": {id}} to {-api}`,
+		"items":    &RuntimeBlock{Argument: "n", Cases: map[string]string{"other": "{n} } {{n}}"}},
+	}
+	if _, err := MigrateCompiled(data); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRuntime(data)
+	if got := r.Get("json", Vars{"id": 42}); got != `Send {"id": 42} to the {API}` {
+		t.Errorf("json = %q", got)
+	}
+	if got := r.Get("items", Vars{"n": 2}); got != "2 } {2}" {
+		t.Errorf("items = %q", got)
+	}
+}
@@ -0,0 +1,145 @@
+package mbel
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// BundleHandler serves the compiled catalog of one locale as JSON, so
+// client-side code fetches exactly the strings the backend is using.
+//
+//	mux.Handle("/i18n/", mbel.BundleHandler(m))
+//
+// GET /i18n/pl.json returns every key of "pl"; ?prefix=auth. limits the
+// bundle to keys starting with "auth.". Keys tagged with an @audience
+// outside Config.Audiences are never served, nor scheduled keys outside
+// their window (their @alternate is, if any). Responses carry an ETag
+// and are revalidated on every use, so clients never hold a stale
+// catalog.
+func BundleHandler(m *Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := path.Base(r.URL.Path)
+		if !strings.HasSuffix(name, ".json") {
+			http.NotFound(w, r)
+			return
+		}
+		lang := strings.TrimSuffix(name, ".json")
+
+		bundle, ok := m.bundle(lang, r.URL.Query().Get("prefix"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, err := json.Marshal(bundle)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		etag := fmt.Sprintf("\"%x\"", sha256.Sum256(body))
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, no-cache")
+		w.Header().Set("Content-Language", lang)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(body)
+	})
+}
+
+// bundle returns the public keys of lang that start with prefix. A
+// scheduled key outside its window is left out, or carries the value of
+// its @alternate, as Get serves it.
+func (m *Manager) bundle(lang, prefix string) (map[string]interface{}, bool) {
+	data, ok := m.state.Load().allData[lang]
+	if !ok {
+		return nil, false
+	}
+
+	tags, _ := data[audienceDataKey].(map[string]string)
+	schedules := Schedules(data)
+	now := time.Now()
+	active := func(key string) bool {
+		s, ok := schedules[key]
+		return !ok || s.Active(now)
+	}
+
+	out := make(map[string]interface{})
+	for k, v := range data {
+		if strings.HasPrefix(k, "__") || !strings.HasPrefix(k, prefix) || !audienceAllowed(tags, k, m.audiences) {
+			continue
+		}
+		if !active(k) {
+			alt := schedules[k].Alternate
+			if v, ok = data[alt]; alt == "" || !ok || strings.HasPrefix(alt, "__") || !active(alt) {
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out, true
+}
+
+// Subset returns the messages of lang whose keys start with one of
+// prefixes (e.g. "checkout."; none = every key), so SSR handlers can
+// embed exactly the strings a page needs. Keys lang lacks come from its
+// fallback locales, like Get. Term references are inlined;
+// {placeholders} and {{ }} escapes are left for the client. Logic block
+// cases become "key[condition]" entries. Keys outside Config.Audiences
+// are left out.
+func (m *Manager) Subset(lang string, prefixes []string) map[string]string {
+	type served struct {
+		r *Runtime
+		v interface{}
+	}
+	cat := m.state.Load()
+	found := make(map[string]served)
+	var buf [3]string
+	for _, c := range m.candidates(buf[:0], lang) {
+		r, ok := m.runtimeIn(cat, c)
+		if !ok {
+			continue
+		}
+		tags, _ := cat.allData[c][audienceDataKey].(map[string]string)
+		for _, key := range r.OrderedKeys() {
+			if _, done := found[key]; done || len(prefixes) > 0 && !hasAnyPrefix(key, prefixes) {
+				continue
+			}
+			if !audienceAllowed(tags, key, m.audiences) {
+				continue
+			}
+			if v, ok := r.value(key); ok {
+				found[key] = served{r, v}
+			}
+		}
+	}
+
+	out := make(map[string]string, len(found))
+	for key, s := range found {
+		switch v := s.v.(type) {
+		case string:
+			out[key] = s.r.template(v).source
+		case *RuntimeBlock:
+			for _, e := range blockEntries(v) {
+				out[key+"["+e.cond+"]"] = s.r.template(e.value).source
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package mbel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SizeBudget caps the compiled JSON bundle of each locale, as served to
+// browsers (0 = no limit)
+type SizeBudget struct {
+	MaxBytes int // uncompressed
+	MaxGzip  int // gzip-compressed
+}
+
+// BundleSize is the size of one locale's compiled JSON bundle
+type BundleSize struct {
+	Lang       string
+	Bytes      int
+	Gzip       int
+	Namespaces []NamespaceSize // largest first
+}
+
+// NamespaceSize is the share of a bundle taken by the keys of one
+// top-level namespace ("" for keys without one), uncompressed
+type NamespaceSize struct {
+	Namespace string
+	Bytes     int
+}
+
+// Over reports whether s exceeds b
+func (s BundleSize) Over(b SizeBudget) bool {
+	return b.MaxBytes > 0 && s.Bytes > b.MaxBytes || b.MaxGzip > 0 && s.Gzip > b.MaxGzip
+}
+
+// MeasureBundles returns the size of every locale's bundle in langData,
+// encoded as `mbel compile` writes it (compact), sorted by locale
+func MeasureBundles(langData map[string]map[string]interface{}) ([]BundleSize, error) {
+	var out []BundleSize
+	for _, lang := range sortedLocales(langData) {
+		if lang == CommonLocale {
+			continue
+		}
+		data := langData[lang]
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("mbel: encoding %s: %w", lang, err)
+		}
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		zw.Write(raw)
+		zw.Close()
+
+		s := BundleSize{Lang: lang, Bytes: len(raw), Gzip: gz.Len()}
+		byNS := make(map[string]int)
+		for _, key := range SortedKeys(data) {
+			entry, err := json.Marshal(map[string]interface{}{key: data[key]})
+			if err != nil {
+				return nil, fmt.Errorf("mbel: encoding %s: %s: %w", lang, key, err)
+			}
+			ns, _, _ := strings.Cut(key, ".")
+			if ns == key {
+				ns = ""
+			}
+			byNS[ns] += len(entry) - 1 // without the braces, with a separator
+		}
+		for ns, n := range byNS {
+			s.Namespaces = append(s.Namespaces, NamespaceSize{Namespace: ns, Bytes: n})
+		}
+		sort.Slice(s.Namespaces, func(i, j int) bool {
+			a, b := s.Namespaces[i], s.Namespaces[j]
+			if a.Bytes != b.Bytes {
+				return a.Bytes > b.Bytes
+			}
+			return a.Namespace < b.Namespace
+		})
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// ParseByteSize parses a size such as "512", "200KB" or "1.5MB"
+// (KB = 1024 bytes; KiB and MiB are accepted too)
+func ParseByteSize(s string) (int, error) {
+	num := strings.TrimSpace(s)
+	unit := 1
+	upper := strings.ToUpper(num)
+	for _, u := range []struct {
+		suffix string
+		size   int
+	}{{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"KB", 1 << 10}, {"MB", 1 << 20}, {"K", 1 << 10}, {"M", 1 << 20}, {"B", 1}} {
+		if strings.HasSuffix(upper, u.suffix) {
+			num, unit = strings.TrimSpace(num[:len(num)-len(u.suffix)]), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (want e.g. 200KB)", s)
+	}
+	return int(n * float64(unit)), nil
+}
+
+// FormatByteSize renders n as "512 B", "12.3 KB" or "1.5 MB"
+func FormatByteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + " MB"
+	case n >= 1<<10:
+		return strconv.FormatFloat(float64(n)/(1<<10), 'f', 1, 64) + " KB"
+	}
+	return strconv.Itoa(n) + " B"
+}
@@ -0,0 +1,66 @@
+package mbel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeasureBundles(t *testing.T) {
+	langData := map[string]map[string]interface{}{
+		"en": {
+			"__schema":       2,
+			"checkout.title": strings.Repeat("Checkout ", 100),
+			"checkout.pay":   "Pay",
+			"home.title":     "Home",
+			"ok":             "OK",
+		},
+		"de": {
+			"home.title": "Startseite",
+		},
+		CommonLocale: {"brand": "Acme"},
+	}
+
+	sizes, err := MeasureBundles(langData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 2 || sizes[0].Lang != "de" || sizes[1].Lang != "en" {
+		t.Fatalf("sizes = %+v", sizes)
+	}
+	en := sizes[1]
+	if en.Gzip >= en.Bytes {
+		t.Errorf("gzip %d not smaller than %d", en.Gzip, en.Bytes)
+	}
+	ns := en.Namespaces
+	if len(ns) != 3 || ns[0].Namespace != "checkout" || ns[1].Namespace != "home" || ns[2].Namespace != "" {
+		t.Errorf("namespaces = %+v", ns)
+	}
+	sum := 0
+	for _, n := range ns {
+		sum += n.Bytes
+	}
+	if sum >= en.Bytes {
+		t.Errorf("namespaces add up to %d, bundle is %d", sum, en.Bytes)
+	}
+
+	if !en.Over(SizeBudget{MaxBytes: 500}) || en.Over(SizeBudget{MaxBytes: 5000}) {
+		t.Error("MaxBytes not enforced")
+	}
+	if !en.Over(SizeBudget{MaxGzip: 10}) || en.Over(SizeBudget{}) {
+		t.Error("MaxGzip not enforced")
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int{"512": 512, "200KB": 200 << 10, "1.5mb": 3 << 19, "64 KiB": 64 << 10, "10B": 10} {
+		if got, err := ParseByteSize(in); err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseByteSize("big"); err == nil {
+		t.Error("ParseByteSize accepted big")
+	}
+	if got := FormatByteSize(1536); got != "1.5 KB" {
+		t.Errorf("FormatByteSize(1536) = %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package mbel
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Locale-aware case mapping. strings.ToUpper("i") is "I" everywhere, but
+// Turkish and Azeri pair i with İ and ı with I, Greek capitals drop their
+// accents and a word-final Σ lowers to ς. ß uppercases to SS, and Dutch
+// titles capitalize the "ij" digraph as one letter.
+
+// caseLanguage returns the primary language subtag of lang ("tr" for
+// "tr-TR")
+func caseLanguage(lang string) string {
+	base, _, _ := strings.Cut(localeKey(lang), "-")
+	return base
+}
+
+// specialCase returns the case mapping of lang's dotted and dotless i
+func specialCase(lang string) unicode.SpecialCase {
+	switch caseLanguage(lang) {
+	case "tr":
+		return unicode.TurkishCase
+	case "az":
+		return unicode.AzeriCase
+	}
+	return nil
+}
+
+// greekUnaccented maps accented Greek capitals (and the lower-case
+// letters with no single-letter capital) to the unaccented capital
+var greekUnaccented = map[rune]rune{
+	'Ά': 'Α', 'Έ': 'Ε', 'Ή': 'Η', 'Ί': 'Ι', 'Ό': 'Ο', 'Ύ': 'Υ', 'Ώ': 'Ω',
+	'ΐ': 'Ϊ', 'ΰ': 'Ϋ',
+}
+
+// Upper returns s in upper case by the rules of lang:
+//
+//	mbel.Upper("tr", "istanbul") // "İSTANBUL"
+//	mbel.Upper("el", "Καλημέρα") // "ΚΑΛΗΜΕΡΑ"
+func Upper(lang, s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	sc := specialCase(lang)
+	greek := caseLanguage(lang) == "el"
+	for _, r := range s {
+		switch {
+		case r == 'ß':
+			b.WriteString("SS")
+			continue
+		case greek && (r == '\u0301' || r == '\u0342'):
+			continue // combining tonos and perispomeni
+		case sc != nil:
+			r = sc.ToUpper(r)
+		default:
+			r = unicode.ToUpper(r)
+		}
+		if greek {
+			if base, ok := greekUnaccented[r]; ok {
+				r = base
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Lower returns s in lower case by the rules of lang:
+//
+//	mbel.Lower("tr", "KIRMIZI") // "kırmızı"
+//	mbel.Lower("el", "ΟΔΟΣ")    // "οδος"
+func Lower(lang, s string) string {
+	rs := []rune(s)
+	sc := specialCase(lang)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range rs {
+		switch {
+		case r == 'Σ' && i > 0 && unicode.IsLetter(rs[i-1]) && (i+1 == len(rs) || !unicode.IsLetter(rs[i+1])):
+			r = 'ς' // final sigma
+		case sc != nil:
+			r = sc.ToLower(r)
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Title returns s with the first letter of every word in title case and
+// the rest in lower case, by the rules of lang:
+//
+//	mbel.Title("tr", "iyi günler")   // "İyi Günler"
+//	mbel.Title("nl", "ijsselmeer")   // "IJsselmeer"
+func Title(lang, s string) string {
+	sc := specialCase(lang)
+	dutch := caseLanguage(lang) == "nl"
+	rs := []rune(Lower(lang, s))
+	inWord := false
+	for i, r := range rs {
+		if !isWordRune(r) {
+			inWord = false
+			continue
+		}
+		if inWord {
+			continue
+		}
+		inWord = true
+		if sc != nil {
+			rs[i] = sc.ToTitle(r)
+		} else {
+			rs[i] = unicode.ToTitle(r)
+		}
+		if dutch && r == 'i' && i+1 < len(rs) && rs[i+1] == 'j' {
+			rs[i+1] = 'J'
+		}
+	}
+	return string(rs)
+}
+
+// isWordRune reports whether r continues a word for Title: letters,
+// marks, digits and apostrophes ("o'clock" is one word)
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '\'' || r == '’'
+}
@@ -0,0 +1,33 @@
+package mbel
+
+import "testing"
+
+func TestCasing(t *testing.T) {
+	for _, tc := range []struct {
+		fn       func(lang, s string) string
+		name     string
+		lang, in string
+		want     string
+	}{
+		{Upper, "Upper", "en", "istanbul", "ISTANBUL"},
+		{Upper, "Upper", "tr", "istanbul ılık", "İSTANBUL ILIK"},
+		{Upper, "Upper", "tr-TR", "i", "İ"},
+		{Upper, "Upper", "az", "bir", "BİR"},
+		{Upper, "Upper", "el", "Καλημέρα όλοι", "ΚΑΛΗΜΕΡΑ ΟΛΟΙ"},
+		{Upper, "Upper", "el", "Άννα", "ΑΝΝΑ"},
+		{Upper, "Upper", "el", "Καλημε\u0301ρα", "ΚΑΛΗΜΕΡΑ"},
+		{Upper, "Upper", "de", "Straße", "STRASSE"},
+		{Lower, "Lower", "en", "KIRMIZI", "kirmizi"},
+		{Lower, "Lower", "tr", "KIRMIZI İZ", "kırmızı iz"},
+		{Lower, "Lower", "el", "ΟΔΟΣ ΣΑΣ", "οδος σας"},
+		{Lower, "Lower", "el", "Σ", "σ"},
+		{Title, "Title", "en", "hello wORLD, it's o'clock", "Hello World, It's O'clock"},
+		{Title, "Title", "tr", "iyi günler", "İyi Günler"},
+		{Title, "Title", "nl", "het ijsselmeer", "Het IJsselmeer"},
+		{Title, "Title", "el", "ΚΑΛΗΜΕΡΑ ΚΟΣΜΟΣ", "Καλημερα Κοσμος"},
+	} {
+		if got := tc.fn(tc.lang, tc.in); got != tc.want {
+			t.Errorf("%s(%q, %q) = %q, want %q", tc.name, tc.lang, tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package mbel
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KeyChangelog lists how the keys of a compiled bundle changed between
+// two releases, for release notes and for the teams consuming the bundle
+type KeyChangelog struct {
+	Added        []string              `json:"added,omitempty"`
+	Removed      []string              `json:"removed,omitempty"`
+	Changed      []string              `json:"changed,omitempty"`      // text or cases differ
+	Placeholders []PlaceholderMismatch `json:"placeholders,omitempty"` // Missing: dropped; Extra: new
+}
+
+// NewKeyChangelog compares the bundle of an older release with a newer
+// one. All lists are sorted by key.
+func NewKeyChangelog(old, new map[string]interface{}) KeyChangelog {
+	d := DiffCatalogs(old, new)
+	return KeyChangelog{Added: d.Extra, Removed: d.Missing, Changed: d.Changed, Placeholders: d.Placeholders}
+}
+
+// Breaking reports whether clients of the older bundle may break: keys
+// were removed or placeholders they pass changed
+func (c KeyChangelog) Breaking() bool {
+	return len(c.Removed)+len(c.Placeholders) > 0
+}
+
+// WriteMarkdown writes the changelog as a Markdown section, breaking
+// changes first
+func (c KeyChangelog) WriteMarkdown(w io.Writer) error {
+	var buf bytes.Buffer
+	list := func(title string, keys []string) {
+		if len(keys) == 0 {
+			return
+		}
+		fmt.Fprintf(&buf, "\n### %s (%d)\n\n", title, len(keys))
+		for _, k := range keys {
+			fmt.Fprintf(&buf, "- `%s`\n", k)
+		}
+	}
+
+	buf.WriteString("## Translation keys\n")
+	if len(c.Added)+len(c.Removed)+len(c.Changed)+len(c.Placeholders) == 0 {
+		buf.WriteString("\nNo changes.\n")
+	}
+	list("Removed (breaking)", c.Removed)
+	if len(c.Placeholders) > 0 {
+		fmt.Fprintf(&buf, "\n### Placeholders changed (breaking) (%d)\n\n", len(c.Placeholders))
+		for _, m := range c.Placeholders {
+			var parts []string
+			if len(m.Missing) > 0 {
+				parts = append(parts, "dropped "+strings.Join(m.Missing, ", "))
+			}
+			if len(m.Extra) > 0 {
+				parts = append(parts, "added "+strings.Join(m.Extra, ", "))
+			}
+			fmt.Fprintf(&buf, "- `%s`: %s\n", m.Key, strings.Join(parts, "; "))
+		}
+	}
+	list("Added", c.Added)
+	list("Changed", c.Changed)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadCompiled decodes a compiled bundle of one locale, either JSON
+// written by `mbel compile` or a binary bundle (-format bundle)
+func ReadCompiled(raw []byte) (map[string]interface{}, error) {
+	if !bytes.HasPrefix(raw, []byte(bundleMagic)) {
+		return DecodeCompiled(raw)
+	}
+	b, err := ParseBundle(raw)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{}, b.Len())
+	for _, k := range b.Keys() {
+		if v, ok := b.Get(k); ok {
+			data[k] = v
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,90 @@
+package mbel
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeyChangelog(t *testing.T) {
+	old := map[string]interface{}{
+		"__schema":     2,
+		"cart.title":   "Cart",
+		"cart.total":   "Total: {amount}",
+		"promo.banner": "Sale!",
+		"items":        &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}},
+	}
+	new := map[string]interface{}{
+		"__schema":   2,
+		"cart.title": "Your cart",
+		"cart.total": "Total: {amount} ({currency})",
+		"items":      &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}},
+		"checkout":   "Checkout",
+	}
+
+	c := NewKeyChangelog(old, new)
+	want := KeyChangelog{
+		Added:        []string{"checkout"},
+		Removed:      []string{"promo.banner"},
+		Changed:      []string{"cart.title", "cart.total"},
+		Placeholders: []PlaceholderMismatch{{Key: "cart.total", Extra: []string{"{currency}"}}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("NewKeyChangelog = %+v\nwant %+v", c, want)
+	}
+	if !c.Breaking() {
+		t.Error("removed key not breaking")
+	}
+	if NewKeyChangelog(old, old).Breaking() {
+		t.Error("identical bundles breaking")
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, s := range []string{
+		"### Removed (breaking) (1)\n\n- `promo.banner`",
+		"- `cart.total`: added {currency}",
+		"### Added (1)\n\n- `checkout`",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("markdown lacks %q:\n%s", s, md)
+		}
+	}
+	if strings.Index(md, "Removed") > strings.Index(md, "Added") {
+		t.Errorf("breaking changes not listed first:\n%s", md)
+	}
+}
+
+func TestReadCompiled(t *testing.T) {
+	data := map[string]interface{}{
+		"title": "Hello {name}",
+		"items": &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}, RangeCases: []RangeCase{}},
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bin bytes.Buffer
+	if err := WriteBundle(&bin, data); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, raw := range map[string][]byte{"json": raw, "binary": bin.Bytes()} {
+		got, err := ReadCompiled(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c := NewKeyChangelog(data, got); len(c.Added)+len(c.Removed)+len(c.Changed) > 0 {
+			t.Errorf("%s: read back differs: %+v", name, c)
+		}
+	}
+	if _, err := ReadCompiled([]byte("not a bundle")); err == nil {
+		t.Error("garbage accepted")
+	}
+}
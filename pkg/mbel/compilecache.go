@@ -0,0 +1,154 @@
+package mbel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// compileCacheVersion is mixed into every cache key, with the style of
+// files without @interpolation; bump it whenever compiler output changes
+// so stale entries are never served
+const compileCacheVersion = "5"
+
+func init() {
+	// Concrete types stored in compiled maps
+	gob.Register(&RuntimeBlock{})
+	gob.Register(map[string]string{})
+	gob.Register([]string{})
+	gob.Register(map[string][]map[string]string{})
+}
+
+// CompileCache stores compiled output on disk keyed by the SHA-256 of the
+// source, so unchanged files are never re-lexed across runs. Only files
+// that compile without syntax errors are cached.
+type CompileCache struct {
+	Dir string
+}
+
+// DefaultCompileCache returns the per-user cache (e.g. ~/.cache/mbel)
+func DefaultCompileCache() (*CompileCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &CompileCache{Dir: filepath.Join(dir, "mbel")}, nil
+}
+
+func (c *CompileCache) path(content []byte, style Interpolation) string {
+	h := sha256.New()
+	h.Write([]byte(compileCacheVersion))
+	h.Write([]byte(style))
+	h.Write(content)
+	sum := hex.EncodeToString(h.Sum(nil))
+	return filepath.Join(c.Dir, sum[:2], sum+".gob")
+}
+
+// Get returns the cached compile result for content, if any.
+// Unreadable or corrupt entries are treated as misses.
+func (c *CompileCache) Get(content []byte) (map[string]interface{}, bool) {
+	return c.get(content, InterpolationSingle)
+}
+
+// get is Get for content whose default interpolation style is style
+func (c *CompileCache) get(content []byte, style Interpolation) (map[string]interface{}, bool) {
+	raw, err := os.ReadFile(c.path(content, style))
+	if err != nil {
+		return nil, false
+	}
+
+	var data map[string]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&data); err != nil {
+		return nil, false
+	}
+
+	restoreEmptyCollections(data)
+	return data, true
+}
+
+// restoreEmptyCollections undoes gob dropping empty collections, so
+// decoded data matches a fresh compile
+func restoreEmptyCollections(data map[string]interface{}) {
+	for _, v := range data {
+		if rb, ok := v.(*RuntimeBlock); ok {
+			if rb.Cases == nil {
+				rb.Cases = make(map[string]string)
+			}
+			if rb.RangeCases == nil {
+				rb.RangeCases = []RangeCase{}
+			}
+		}
+	}
+}
+
+// Put stores the compile result for content. The entry is written to a
+// temp file and renamed, so concurrent readers never see partial data.
+func (c *CompileCache) Put(content []byte, data map[string]interface{}) error {
+	return c.put(content, InterpolationSingle, data)
+}
+
+// put is Put for content whose default interpolation style is style
+func (c *CompileCache) put(content []byte, style Interpolation, data map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+
+	path := c.path(content, style)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CompileSource lexes, parses and compiles src, consulting cache first
+// when it is non-nil. Syntax errors are returned alongside the (partial)
+// result, as the loader has always tolerated them. Registered compile
+// transforms are applied to the result; the cache holds untransformed data.
+func CompileSource(src []byte, cache *CompileCache) (map[string]interface{}, []string, error) {
+	return CompileSourceWithStyle(src, cache, InterpolationSingle)
+}
+
+// CompileSourceWithStyle is CompileSource for a project whose files
+// without an @interpolation line are written in style
+func CompileSourceWithStyle(src []byte, cache *CompileCache, style Interpolation) (map[string]interface{}, []string, error) {
+	if style == "" {
+		style = InterpolationSingle
+	}
+	if cache != nil {
+		if data, ok := cache.get(src, style); ok {
+			recordCacheHit()
+			return ApplyCompileTransforms(data), nil, nil
+		}
+		recordCacheMiss()
+	}
+
+	p := NewParser(NewLexer(string(src)))
+	program := p.ParseProgram()
+	errs := p.Errors()
+	data, err := (&Compiler{Interpolation: style}).compileProgram(program)
+	if err != nil {
+		return nil, errs, err
+	}
+
+	if cache != nil && len(errs) == 0 {
+		cache.put(src, style, data)
+	}
+	return ApplyCompileTransforms(data), errs, nil
+}
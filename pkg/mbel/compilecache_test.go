@@ -0,0 +1,31 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompileCacheRoundTrip(t *testing.T) {
+	cache := &CompileCache{Dir: t.TempDir()}
+	src := []byte("@lang: pl\n# AI_Context: Header\ntitle = \"Witaj\"\nitems(n) {\n    [one] => \"1\"\n    [2..4] => \"{n}\"\n}\nname(x) {\n    [other] => \"{x}\"\n}\n")
+
+	first, errs, err := CompileSource(src, cache)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("compile failed: %v %v", err, errs)
+	}
+
+	hits := GetMetrics()["cache_hits"]
+	second, _, err := CompileSource(src, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetMetrics()["cache_hits"] != hits+1 {
+		t.Errorf("expected second compile to hit the cache")
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("cached result differs:\nwant %#v\ngot  %#v", first, second)
+	}
+	if _, ok := second["items"].(*RuntimeBlock); !ok {
+		t.Errorf("expected *RuntimeBlock from cache, got %T", second["items"])
+	}
+}
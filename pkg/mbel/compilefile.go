@@ -0,0 +1,32 @@
+package mbel
+
+// CompileFile is CompileSource for the file at path. The file is
+// memory-mapped where the platform allows it, so a cache hit never copies
+// it to the heap and a miss copies it once.
+func CompileFile(path string, cache *CompileCache) (map[string]interface{}, []string, error) {
+	return CompileFileWithStyle(path, cache, InterpolationSingle)
+}
+
+// CompileFileWithStyle is CompileFile for a project whose files without
+// an @interpolation line are written in style
+func CompileFileWithStyle(path string, cache *CompileCache, style Interpolation) (map[string]interface{}, []string, error) {
+	src, release, err := mapFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	return CompileSourceWithStyle(src, cache, style)
+}
+
+// ParseFile parses the file at path, read as CompileFile reads it, and
+// returns the program with its syntax errors
+func ParseFile(path string) (*Program, []string, error) {
+	src, release, err := mapFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	p := NewParser(NewLexer(string(src)))
+	program := p.ParseProgram()
+	return program, p.Errors(), nil
+}
@@ -0,0 +1,88 @@
+package mbel
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCompileFileMatchesCompileSource(t *testing.T) {
+	src := []byte("@lang: pl\ntitle = \"Witaj {name}\"\nitems(n) {\n    [one] => \"1\"\n    [other] => \"{n}\"\n}\n")
+	path := filepath.Join(t.TempDir(), "pl.mbel")
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _, err := CompileSource(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, errs, err := CompileFile(path, nil)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("compile failed: %v %v", err, errs)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompileFile = %#v, want %#v", got, want)
+	}
+
+	// Values must outlive the mapping of the file
+	if got["title"] != "Witaj {name}" {
+		t.Errorf("title = %q", got["title"])
+	}
+}
+
+func TestCompileFileConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("f%d.mbel", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("key%d = \"value %d\"\n", i, i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, errs, err := CompileFile(path, nil)
+			if err != nil || len(errs) > 0 {
+				t.Errorf("%s: %v %v", path, err, errs)
+				return
+			}
+			if want := fmt.Sprintf("value %d", i); data[fmt.Sprintf("key%d", i)] != want {
+				t.Errorf("%s: got %#v, want key%d = %q", path, data, i, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.mbel")
+	bad := filepath.Join(dir, "bad.mbel")
+	empty := filepath.Join(dir, "empty.mbel")
+	os.WriteFile(good, []byte("a = \"A\"\n"), 0644)
+	os.WriteFile(bad, []byte("a = \n"), 0644)
+	os.WriteFile(empty, nil, 0644)
+
+	program, errs, err := ParseFile(good)
+	if err != nil || len(errs) > 0 || len(program.Statements) != 1 {
+		t.Errorf("good: %v %v %#v", err, errs, program)
+	}
+	if _, errs, _ := ParseFile(bad); len(errs) == 0 {
+		t.Error("bad: expected syntax errors")
+	}
+	if program, errs, err := ParseFile(empty); err != nil || len(errs) > 0 || len(program.Statements) != 0 {
+		t.Errorf("empty: %v %v %#v", err, errs, program)
+	}
+	if _, _, err := ParseFile(filepath.Join(dir, "missing.mbel")); err == nil {
+		t.Error("missing: expected an error")
+	}
+}
@@ -1,38 +1,130 @@
 package mbel
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 )
 
+var errReservedKey = errors.New(`keys starting with "__" are reserved`)
+
 // Compiler transforms AST into a runtime map
 type Compiler struct {
+	// Interpolation is the style of files without an @interpolation
+	// line ("" = InterpolationSingle)
+	Interpolation Interpolation
+
+	icu   bool          // @syntax: icu, every string value is an ICU message
+	style Interpolation // @interpolation, "" = Interpolation
 }
 
 func NewCompiler() *Compiler {
 	return &Compiler{}
 }
 
-func (c *Compiler) Compile(node Node) (interface{}, error) {
+// CompiledCatalog is the compiled form of one file
+type CompiledCatalog struct {
+	// Data maps keys to a string or *RuntimeBlock, next to "__meta",
+	// "__terms" and the other "__" entries; it is what repositories
+	// return per language
+	Data map[string]interface{}
+}
+
+// Meta returns the file-level metadata (@namespace, @lang, ...)
+func (cc *CompiledCatalog) Meta() map[string]string {
+	meta, _ := cc.Data["__meta"].(map[string]string)
+	return meta
+}
+
+// CompileError is a compile error of one key, with the position of its
+// value in the source
+type CompileError struct {
+	Key    string // full key, including the section
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("line %d, column %d: key %s: %v", e.Line, e.Column, e.Key, e.Err)
+}
+
+func (e *CompileError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrCompile) true
+func (e *CompileError) Is(target error) bool { return target == ErrCompile }
+
+// Compile compiles a parsed file. Errors match ErrCompile; those of a
+// key are *CompileError.
+func (c *Compiler) Compile(p *Program) (*CompiledCatalog, error) {
+	if err := checkProgram(p); err != nil {
+		return nil, err
+	}
+	data, err := c.compileProgram(p)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledCatalog{Data: data}, nil
+}
+
+// checkProgram rejects ASTs the parser never builds, nil nodes and
+// foreign statement types, so hand-built or transformed programs fail
+// with an error instead of a panic
+func checkProgram(p *Program) error {
+	if p == nil {
+		return fmt.Errorf("%w: nil program", ErrCompile)
+	}
+	for i, stmt := range p.Statements {
+		known, isNil := true, false
+		switch s := stmt.(type) {
+		case *AssignStatement:
+			isNil = s == nil
+		case *MetadataStatement:
+			isNil = s == nil
+		case *SectionStatement:
+			isNil = s == nil
+		case *TermDefinition:
+			isNil = s == nil
+		case *ImportStatement:
+			isNil = s == nil
+		case nil:
+			isNil = true
+		default:
+			known = false
+		}
+		switch {
+		case isNil:
+			return fmt.Errorf("%w: statement %d is nil", ErrCompile, i+1)
+		case !known:
+			return fmt.Errorf("%w: statement %d: unexpected %T", ErrCompile, i+1, stmt)
+		}
+	}
+	for name, def := range p.Terms {
+		if def == nil {
+			return fmt.Errorf("%w: term -%s has no definition", ErrCompile, name)
+		}
+	}
+	for _, ann := range p.AIAnnotations {
+		if ann == nil {
+			return fmt.Errorf("%w: nil AI annotation", ErrCompile)
+		}
+	}
+	return nil
+}
+
+// compileNode compiles the value of an assignment
+func (c *Compiler) compileNode(node Node) (interface{}, error) {
 	switch n := node.(type) {
-	case *Program:
-		return c.compileProgram(n)
-	case *MetadataStatement:
-		return n, nil // Metadata handled by Program
-	case *SectionStatement:
-		return n, nil // Sections handled by Program
-	case *AssignStatement:
-		return c.compileAssign(n)
 	case *StringLiteral:
-		return n.Value, nil
+		if n != nil {
+			return n.Value, nil
+		}
 	case *BlockExpression:
-		return c.compileBlock(n)
-	case *ImportStatement:
-		return n, nil // Imports handled by Program
-	case *TermDefinition:
-		return n, nil // Terms handled by Program
-	default:
-		return nil, fmt.Errorf("unknown node type: %T", n)
+		if n != nil {
+			return c.compileBlock(n)
+		}
 	}
+	return nil, fmt.Errorf("unexpected node %T", node)
 }
 
 func (c *Compiler) compileProgram(p *Program) (map[string]interface{}, error) {
@@ -41,10 +133,16 @@ func (c *Compiler) compileProgram(p *Program) (map[string]interface{}, error) {
 
 	// First pass to get metadata (especially namespace)
 	for _, stmt := range p.Statements {
-		if ms, ok := stmt.(*MetadataStatement); ok {
+		if ms, ok := stmt.(*MetadataStatement); ok && !keyMetaKeys[ms.Key] {
 			metadata[ms.Key] = ms.Value
 		}
 	}
+	c.icu = metadata[syntaxMetaKey] == "icu"
+	style, err := interpolationOf(metadata, c.Interpolation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: @%s: %v", ErrCompile, interpolationMetaKey, err)
+	}
+	c.style = style
 
 	currentSection := ""
 
@@ -53,15 +151,14 @@ func (c *Compiler) compileProgram(p *Program) (map[string]interface{}, error) {
 		case *SectionStatement:
 			currentSection = s.Name
 		case *AssignStatement:
-			val, err := c.Compile(s)
-			if err != nil {
-				return nil, err
-			}
-
 			key := s.Name
 			if currentSection != "" {
 				key = currentSection + "." + s.Name
 			}
+			val, err := c.compileAssign(key, s)
+			if err != nil {
+				return nil, err
+			}
 			result[key] = val
 		}
 	}
@@ -69,17 +166,25 @@ func (c *Compiler) compileProgram(p *Program) (map[string]interface{}, error) {
 	if len(metadata) > 0 {
 		result["__meta"] = metadata
 	}
+	if schedules := compileSchedules(p); schedules != nil {
+		result[scheduleDataKey] = schedules
+	}
+	if audiences := compileAudiences(p); audiences != nil {
+		result[audienceDataKey] = audiences
+	}
+	result["__schema"] = SchemaVersion
 
 	// Export AI annotations
 	if len(p.AIAnnotations) > 0 {
 		aiMap := make(map[string][]map[string]string)
+		keys := annotationKeys(p)
 		for _, ann := range p.AIAnnotations {
 			entry := map[string]string{
 				"type":  ann.Type,
 				"value": ann.Value,
 			}
-			if ann.ForKey != "" {
-				aiMap[ann.ForKey] = append(aiMap[ann.ForKey], entry)
+			if key, ok := keys[ann]; ok {
+				aiMap[key] = append(aiMap[key], entry)
 			} else {
 				aiMap["__global"] = append(aiMap["__global"], entry)
 			}
@@ -97,7 +202,7 @@ func (c *Compiler) compileProgram(p *Program) (map[string]interface{}, error) {
 		terms := make(map[string]string)
 		for name, def := range p.Terms {
 			if sl, ok := def.Value.(*StringLiteral); ok {
-				terms[name] = sl.Value
+				terms[name] = style.Canonical(sl.Value)
 			}
 		}
 		result["__terms"] = terms
@@ -106,8 +211,46 @@ func (c *Compiler) compileProgram(p *Program) (map[string]interface{}, error) {
 	return result, nil
 }
 
-func (c *Compiler) compileAssign(node *AssignStatement) (interface{}, error) {
-	return c.Compile(node.Value)
+// compileAssign compiles the value of key, wrapping errors in a
+// *CompileError
+func (c *Compiler) compileAssign(key string, node *AssignStatement) (interface{}, error) {
+	if strings.HasPrefix(key, "__") {
+		// "__meta", "__schedule", ... hold the compiled data of the file
+		return nil, &CompileError{Key: key, Line: node.Token.Line, Column: node.Token.Column, Err: errReservedKey}
+	}
+	val, err := c.compileNode(node.Value)
+	icu := false
+	if s, ok := val.(string); ok && err == nil && (c.icu || looksLikeICU(s)) {
+		// Outside @syntax: icu a malformed message stays plain text, as
+		// does one using ICU features blocks lack; ICURule warns about it
+		if v, icuErr := ParseICU(s); icuErr == nil {
+			val, icu = v, true
+		} else if c.icu && !errors.Is(icuErr, errICUUnsupported) {
+			err = icuErr
+		}
+	}
+	if err == nil && !icu {
+		style := c.style
+		if style == "" {
+			style = c.Interpolation
+		}
+		val = style.canonicalValue(val)
+	}
+	if err != nil {
+		pos := node.Token
+		switch v := node.Value.(type) {
+		case *StringLiteral:
+			if v != nil {
+				pos = v.Token
+			}
+		case *BlockExpression:
+			if v != nil {
+				pos = v.Token
+			}
+		}
+		return nil, &CompileError{Key: key, Line: pos.Line, Column: pos.Column, Err: err}
+	}
+	return val, nil
 }
 
 // RangeCase represents a compiled numeric range condition
@@ -230,5 +373,10 @@ func (c *Compiler) compileBlock(node *BlockExpression) (*RuntimeBlock, error) {
 		}
 	}
 
+	if rb.IsVariant() {
+		if _, err := variants(rb); err != nil {
+			return nil, err
+		}
+	}
 	return rb, nil
 }
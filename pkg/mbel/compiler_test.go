@@ -0,0 +1,63 @@
+package mbel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompileErrorPosition(t *testing.T) {
+	src := "[shop]\ntitle = \"Shop\"\ncta = variant {\n    [a:0] => \"Buy\"\n}\n"
+
+	_, err := NewCompiler().Compile(NewParser(NewLexer(src)).ParseProgram())
+	var ce *CompileError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Compile error = %v, want *CompileError", err)
+	}
+	if !errors.Is(err, ErrCompile) {
+		t.Error("CompileError does not match ErrCompile")
+	}
+	if ce.Key != "shop.cta" || ce.Line != 3 || ce.Column == 0 {
+		t.Errorf("CompileError = %+v", ce)
+	}
+	if !strings.HasPrefix(err.Error(), "line 3, column ") || !strings.Contains(err.Error(), "key shop.cta: invalid variant [a:0]") {
+		t.Errorf("message = %q", err)
+	}
+
+	_, err = CompileStream(strings.NewReader(src), func(string, interface{}) error { return nil })
+	if !errors.As(err, &ce) || ce.Key != "shop.cta" {
+		t.Errorf("CompileStream error = %v", err)
+	}
+
+	res, err := NewCompiler().Compile(NewParser(NewLexer("@lang: pl\ntitle = \"Sklep\"\n")).ParseProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Data["title"] != "Sklep" || res.Meta()["lang"] != "pl" {
+		t.Errorf("CompiledCatalog = %v", res.Data)
+	}
+}
+
+func TestCompileRejectsMalformedAST(t *testing.T) {
+	var nilAssign *AssignStatement
+	for name, p := range map[string]*Program{
+		"nil program":     nil,
+		"nil statement":   {Statements: []Statement{nil}},
+		"typed nil":       {Statements: []Statement{nilAssign}},
+		"foreign type":    {Statements: []Statement{struct{ *SectionStatement }{&SectionStatement{Name: "x"}}}},
+		"nil term":        {Terms: map[string]*TermDefinition{"brand": nil}},
+		"nil value":       {Statements: []Statement{&AssignStatement{Name: "title"}}},
+		"typed nil value": {Statements: []Statement{&AssignStatement{Name: "title", Value: (*StringLiteral)(nil)}}},
+	} {
+		_, err := NewCompiler().Compile(p)
+		if !errors.Is(err, ErrCompile) {
+			t.Errorf("%s: error %v, want ErrCompile", name, err)
+		}
+	}
+
+	var ce *CompileError
+	_, err := NewCompiler().Compile(&Program{Statements: []Statement{&AssignStatement{Name: "title"}}})
+	if !errors.As(err, &ce) || ce.Key != "title" {
+		t.Errorf("nil value error = %v, want a *CompileError for title", err)
+	}
+}
@@ -0,0 +1,51 @@
+package mbel
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// contextURLTypes are the annotations linking a key to a picture of it
+// in place, for translators and reviewers:
+//
+//	# AI_Screenshot: https://cdn.example.com/i18n/checkout.png
+//	# AI_Figma: https://www.figma.com/file/Xyz/Shop?node-id=12-34
+//	checkout_title = "Checkout"
+var contextURLTypes = map[string]bool{"Screenshot": true, "Figma": true}
+
+// ContextURLs returns the AI_Screenshot and AI_Figma links of anns, in
+// file order
+func ContextURLs(anns []*AIAnnotation) []string {
+	var out []string
+	for _, ann := range anns {
+		if contextURLTypes[ann.Type] {
+			out = append(out, ann.Value)
+		}
+	}
+	return out
+}
+
+// ContextURLRule returns a lint rule reporting AI_Screenshot and AI_Figma
+// annotations that are not absolute http(s) URLs
+func ContextURLRule() LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		for _, ann := range p.AIAnnotations {
+			if !contextURLTypes[ann.Type] || isContextURL(ann.Value) {
+				continue
+			}
+			out = append(out, Diagnostic{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("AI_%s %q is not an http(s) URL", ann.Type, ann.Value),
+				Line:     ann.Line,
+				Column:   1,
+			})
+		}
+		return out
+	}
+}
+
+func isContextURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,28 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContextURLs(t *testing.T) {
+	src := `# AI_Screenshot: https://cdn.example.com/cart.png
+# AI_Context: Cart title
+# AI_Figma: figma.com/file/Xyz
+title = "Cart"
+# AI_Screenshot: ftp://example.com/a.png
+empty = "Empty"
+`
+	p := NewParser(NewLexer(src)).ParseProgram()
+	if got := ContextURLs(AnnotationsFor(p, "title")); !reflect.DeepEqual(got, []string{"https://cdn.example.com/cart.png", "figma.com/file/Xyz"}) {
+		t.Errorf("ContextURLs = %q", got)
+	}
+
+	var got []int
+	for _, d := range ContextURLRule()(p, LintContext{}) {
+		got = append(got, d.Line)
+	}
+	if !reflect.DeepEqual(got, []int{3, 5}) {
+		t.Errorf("diagnostic lines = %v", got)
+	}
+}
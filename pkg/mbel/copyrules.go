@@ -0,0 +1,221 @@
+package mbel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CopyRules are the machine-checkable rules a key's annotations set on
+// its copy, for design systems:
+//
+//	# AI_MinLength: 3
+//	# AI_MaxLength: 24
+//	# AI_Pattern: ^[A-Z]
+//	# AI_NoTrailingPunctuation: true
+//	# AI_MustContain: count, name
+//	cart_button = "Pay {count} items, {name}"
+//
+// Lengths count characters, placeholders as written. Pattern is a Go
+// regular expression found anywhere in the text unless anchored.
+type CopyRules struct {
+	MinLength             int // 0 = none
+	MaxLength             int // 0 = none
+	Pattern               *regexp.Regexp
+	NoTrailingPunctuation bool
+	MustContain           []string // placeholder names
+}
+
+// trailingPunctuation ends sentences and clauses; a label ending in one
+// breaks NoTrailingPunctuation
+const trailingPunctuation = ".,;:!?…。、！？；："
+
+// ParseCopyRules reads the copy rules among anns; other annotations are
+// ignored. A malformed rule is an error naming it.
+func ParseCopyRules(anns []*AIAnnotation) (CopyRules, error) {
+	var r CopyRules
+	for _, ann := range anns {
+		var err error
+		switch ann.Type {
+		case "MinLength":
+			r.MinLength, err = strconv.Atoi(ann.Value)
+		case "MaxLength":
+			r.MaxLength, err = strconv.Atoi(ann.Value)
+		case "Pattern":
+			r.Pattern, err = regexp.Compile(ann.Value)
+		case "NoTrailingPunctuation":
+			r.NoTrailingPunctuation, err = strconv.ParseBool(ann.Value)
+		case "MustContain":
+			for _, name := range strings.Split(ann.Value, ",") {
+				name = strings.Trim(strings.TrimSpace(name), "{}")
+				if name != "" {
+					r.MustContain = append(r.MustContain, name)
+				}
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return CopyRules{}, fmt.Errorf("AI_%s: invalid value %q", ann.Type, ann.Value)
+		}
+	}
+	return r, nil
+}
+
+// IsZero reports whether r sets no rule
+func (r CopyRules) IsZero() bool {
+	return r.MinLength == 0 && r.MaxLength == 0 && r.Pattern == nil && !r.NoTrailingPunctuation && len(r.MustContain) == 0
+}
+
+// Check returns the rules a compiled value (a string or *RuntimeBlock,
+// placeholders in the canonical {name} style) breaks; each case of a
+// block must pass on its own, and its findings start with "[case] "
+func (r CopyRules) Check(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return r.checkText(v)
+	case *RuntimeBlock:
+		var out []string
+		for _, e := range blockEntries(v) {
+			for _, msg := range r.checkText(e.value) {
+				out = append(out, fmt.Sprintf("[%s] %s", e.cond, msg))
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func (r CopyRules) checkText(s string) []string {
+	var out []string
+	n := utf8.RuneCountInString(s)
+	if r.MinLength > 0 && n < r.MinLength {
+		out = append(out, fmt.Sprintf("shorter than AI_MinLength %d (got %d)", r.MinLength, n))
+	}
+	if r.MaxLength > 0 && n > r.MaxLength {
+		out = append(out, fmt.Sprintf("exceeds AI_MaxLength %d (got %d)", r.MaxLength, n))
+	}
+	if r.Pattern != nil && !r.Pattern.MatchString(s) {
+		out = append(out, fmt.Sprintf("does not match AI_Pattern %s", r.Pattern))
+	}
+	if r.NoTrailingPunctuation {
+		if last, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(s)); strings.ContainsRune(trailingPunctuation, last) {
+			out = append(out, fmt.Sprintf("ends in %q despite AI_NoTrailingPunctuation", last))
+		}
+	}
+	if len(r.MustContain) > 0 {
+		have := make(map[string]bool)
+		for _, loc := range placeholderIndexes(argRe, s) {
+			have[s[loc[2]:loc[3]]] = true
+		}
+		for _, name := range r.MustContain {
+			if !have[name] {
+				out = append(out, fmt.Sprintf("lacks {%s} required by AI_MustContain", name))
+			}
+		}
+	}
+	return out
+}
+
+// CopyRulesRule returns a lint rule enforcing the copy rules of every
+// annotated key (see CopyRules) and reporting malformed ones
+func CopyRulesRule() LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		byKey := make(map[string][]*AIAnnotation)
+		for ann, key := range annotationKeys(p) {
+			byKey[key] = append(byKey[key], ann)
+		}
+		style, err := interpolationOf(Metadata(p), ctx.Interpolation)
+		if err != nil {
+			style = ctx.Interpolation
+		}
+
+		var out []Diagnostic
+		for key, assign := range Assignments(p) {
+			anns := byKey[key]
+			if len(anns) == 0 {
+				continue
+			}
+			sort.Slice(anns, func(i, j int) bool { return anns[i].Line < anns[j].Line })
+			rules, err := ParseCopyRules(anns)
+			if err != nil {
+				out = append(out, Diagnostic{Severity: SeverityError, Message: fmt.Sprintf("%s %v", key, err), Line: anns[0].Line, Column: 1})
+				continue
+			}
+			if rules.IsZero() {
+				continue
+			}
+
+			switch v := assign.Value.(type) {
+			case *StringLiteral:
+				for _, msg := range rules.checkText(style.Canonical(v.Value)) {
+					out = append(out, DiagnosticAt(v.Token, SeverityError, fmt.Sprintf("%s %s", key, msg)))
+				}
+			case *BlockExpression:
+				for _, bc := range v.Cases {
+					for _, msg := range rules.checkText(style.Canonical(bc.Value)) {
+						out = append(out, DiagnosticAt(bc.ValueToken, SeverityError, fmt.Sprintf("%s[%s] %s", key, bc.Condition, msg)))
+					}
+				}
+			}
+		}
+		return out
+	}
+}
+
+// CopyViolation is a compiled value breaking a copy rule of its key
+type CopyViolation struct {
+	Key     string
+	Case    string // condition of the logic block case, "" for strings
+	Message string
+}
+
+func (v CopyViolation) Error() string {
+	if v.Case != "" {
+		return fmt.Sprintf("%s[%s] %s", v.Key, v.Case, v.Message)
+	}
+	return v.Key + " " + v.Message
+}
+
+// ValidateCopy checks the compiled data of one file against the copy
+// rules of its keys, read from the annotations it carries under "__ai",
+// sorted by key. `mbel compile -validate` fails on them.
+func ValidateCopy(data map[string]interface{}) []CopyViolation {
+	ai, _ := data["__ai"].(map[string][]map[string]string)
+	keys := make([]string, 0, len(ai))
+	for key := range ai {
+		if key != "__global" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var out []CopyViolation
+	for _, key := range keys {
+		anns := make([]*AIAnnotation, len(ai[key]))
+		for i, e := range ai[key] {
+			anns[i] = &AIAnnotation{Type: e["type"], Value: e["value"], ForKey: key}
+		}
+		rules, err := ParseCopyRules(anns)
+		if err != nil {
+			out = append(out, CopyViolation{Key: key, Message: err.Error()})
+			continue
+		}
+		switch v := data[key].(type) {
+		case string:
+			for _, msg := range rules.checkText(v) {
+				out = append(out, CopyViolation{Key: key, Message: msg})
+			}
+		case *RuntimeBlock:
+			for _, e := range blockEntries(v) {
+				for _, msg := range rules.checkText(e.value) {
+					out = append(out, CopyViolation{Key: key, Case: e.cond, Message: msg})
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package mbel
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const copyRulesSrc = `@lang: en
+# AI_MinLength: 3
+# AI_NoTrailingPunctuation: true
+# AI_Pattern: ^[A-Z]
+ok = "Ok."
+# AI_MustContain: n, {name}
+items(n) {
+    [one] => "One item for {name}"
+    [other] => "{n} items for {name}"
+}
+# AI_MaxLength: 5
+long = "Too long"
+# AI_MinLength: x
+bad = "x"
+# AI_Pattern: ^[a-z]
+fine = "whatever"
+`
+
+func TestParseCopyRules(t *testing.T) {
+	rules, err := ParseCopyRules([]*AIAnnotation{
+		{Type: "Context", Value: "Button"},
+		{Type: "MinLength", Value: "2"},
+		{Type: "MustContain", Value: "count, {name}"},
+		{Type: "NoTrailingPunctuation", Value: "true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.MinLength != 2 || !rules.NoTrailingPunctuation || !reflect.DeepEqual(rules.MustContain, []string{"count", "name"}) {
+		t.Errorf("rules = %+v", rules)
+	}
+	if _, err := ParseCopyRules([]*AIAnnotation{{Type: "Pattern", Value: "("}}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	if rules, _ := ParseCopyRules([]*AIAnnotation{{Type: "Tone", Value: "Casual"}}); !rules.IsZero() {
+		t.Errorf("expected no rules, got %+v", rules)
+	}
+}
+
+func TestCopyRulesCheck(t *testing.T) {
+	rules := CopyRules{MinLength: 4, NoTrailingPunctuation: true, MustContain: []string{"n"}}
+	tests := []struct {
+		value interface{}
+		want  int
+	}{
+		{"{n} items", 0},
+		{"{n, date}", 0},
+		{"Hi!", 3},
+		{"{n} items。", 1},
+		{&RuntimeBlock{Cases: map[string]string{"one": "One", "other": "{n} items"}}, 2},
+	}
+	for _, tt := range tests {
+		if got := rules.Check(tt.value); len(got) != tt.want {
+			t.Errorf("Check(%v) = %q, want %d findings", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCopyRulesRule(t *testing.T) {
+	p := NewParser(NewLexer(copyRulesSrc)).ParseProgram()
+	var got []string
+	diags := CopyRulesRule()(p, LintContext{})
+	sort.Slice(diags, func(i, j int) bool { return diags[i].Line < diags[j].Line })
+	for _, d := range diags {
+		got = append(got, d.Message)
+	}
+	want := []string{
+		"ok ends in '.' despite AI_NoTrailingPunctuation",
+		"items[one] lacks {n} required by AI_MustContain",
+		"long exceeds AI_MaxLength 5 (got 8)",
+		`bad AI_MinLength: invalid value "x"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestValidateCopy(t *testing.T) {
+	data, _, err := CompileSource([]byte(copyRulesSrc), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range ValidateCopy(data) {
+		got = append(got, v.Error())
+	}
+	want := []string{
+		`bad AI_MinLength: invalid value "x"`,
+		"items[one] lacks {n} required by AI_MustContain",
+		"long exceeds AI_MaxLength 5 (got 8)",
+		"ok ends in '.' despite AI_NoTrailingPunctuation",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package mbel
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A placeholder with a style, {d, date}, {d, time} or {d, datetime},
+// renders a time.Time in the locale's short numeric format. The zone is,
+// in order: TimezoneVar in the arguments, WithTimezone on the context of
+// T, Config.Timezone, else the value's own.
+//
+//	delivery = "Arrives {eta, date} at {eta, time}"
+
+// TimezoneVar is the Vars key setting the zone of date placeholders: a
+// *time.Location or an IANA name such as "Europe/Warsaw". It can never
+// clash with a placeholder name.
+const TimezoneVar = "@tz"
+
+type timezoneContextKey struct{}
+
+// WithTimezone sets the zone date placeholders render in for T calls
+// made with the returned context
+func WithTimezone(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, timezoneContextKey{}, loc)
+}
+
+// TimezoneFromContext returns the zone set by WithTimezone, or nil
+func TimezoneFromContext(ctx context.Context) *time.Location {
+	loc, _ := ctx.Value(timezoneContextKey{}).(*time.Location)
+	return loc
+}
+
+// dateLayouts are a locale's short date and time layouts and what joins
+// them in a datetime
+type dateLayouts struct {
+	date, time, join string
+}
+
+var dateFormats = map[string]dateLayouts{
+	"en":    {"1/2/2006", "3:04 PM", ", "},
+	"en-gb": {"02/01/2006", "15:04", ", "},
+	"de":    {"02.01.2006", "15:04", ", "},
+	"pl":    {"02.01.2006", "15:04", ", "},
+	"ru":    {"02.01.2006", "15:04", ", "},
+	"uk":    {"02.01.2006", "15:04", ", "},
+	"cs":    {"2. 1. 2006", "15:04", " "},
+	"fr":    {"02/01/2006", "15:04", " "},
+	"es":    {"2/1/2006", "15:04", ", "},
+	"it":    {"02/01/2006", "15:04", ", "},
+	"pt":    {"02/01/2006", "15:04", ", "},
+	"nl":    {"02-01-2006", "15:04", ", "},
+	"ja":    {"2006/01/02", "15:04", " "},
+	"zh":    {"2006/1/2", "15:04", " "},
+}
+
+// isoDates is used by locales without an entry of their own
+var isoDates = dateLayouts{"2006-01-02", "15:04", " "}
+
+// dateLayoutsFor returns the layouts of lang, else of its base language
+func dateLayoutsFor(lang string) dateLayouts {
+	key := localeKey(lang)
+	if l, ok := dateFormats[key]; ok {
+		return l
+	}
+	if base, _, ok := strings.Cut(key, "-"); ok {
+		if l, ok := dateFormats[base]; ok {
+			return l
+		}
+	}
+	return isoDates
+}
+
+// formatTime renders t in style ("date", "time" or "datetime") for the
+// runtime's locale, in the zone chosen by arg or the runtime
+func (r *Runtime) formatTime(t time.Time, style string, arg interface{}) string {
+	if loc := timezoneOf(arg); loc != nil {
+		t = t.In(loc)
+	} else if r.location != nil {
+		t = t.In(r.location)
+	}
+
+	l := dateLayoutsFor(r.Language)
+	if r.dates != nil {
+		l = *r.dates
+	}
+	switch style {
+	case "date":
+		return t.Format(l.date)
+	case "time":
+		return t.Format(l.time)
+	default:
+		return t.Format(l.date + l.join + l.time)
+	}
+}
+
+// locations caches time.LoadLocation, which reads the zone database
+var locations sync.Map // name -> *time.Location
+
+// timezoneOf returns the zone TimezoneVar sets in arg, or nil
+func timezoneOf(arg interface{}) *time.Location {
+	var v interface{}
+	switch m := arg.(type) {
+	case Vars:
+		v = m[TimezoneVar]
+	case map[string]interface{}:
+		v = m[TimezoneVar]
+	}
+
+	switch tz := v.(type) {
+	case *time.Location:
+		return tz
+	case string:
+		if loc, ok := locations.Load(tz); ok {
+			return loc.(*time.Location)
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil
+		}
+		locations.Store(tz, loc)
+		return loc
+	}
+	return nil
+}
+
+// withTimezone adds loc to the variables in args unless they set a zone
+// already. Scalar arguments cannot carry one and are left alone.
+func withTimezone(args []interface{}, loc *time.Location) []interface{} {
+	if loc == nil || len(args) == 0 {
+		return args
+	}
+
+	var vars map[string]interface{}
+	switch m := args[0].(type) {
+	case Vars:
+		vars = m
+	case map[string]interface{}:
+		vars = m
+	default:
+		return args
+	}
+	if _, ok := vars[TimezoneVar]; ok {
+		return args
+	}
+
+	merged := make(Vars, len(vars)+1)
+	for k, v := range vars {
+		merged[k] = v
+	}
+	merged[TimezoneVar] = loc
+	return append([]interface{}{merged}, args[1:]...)
+}
@@ -0,0 +1,43 @@
+package mbel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDatePlaceholders(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skip("no zone database:", err)
+	}
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"eta": "Arrives {d, date} at {d, time}", "at": "{d, datetime}", "plain": "{d}"},
+		"pl": {"eta": "Dostawa {d, date} o {d, time}"},
+	}), Config{DefaultLocale: "en", Timezone: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := time.Date(2026, 3, 5, 13, 0, 0, 0, time.UTC)
+
+	if got := m.Get("en", "eta", Vars{"d": d}); got != "Arrives 3/5/2026 at 1:00 PM" {
+		t.Errorf("Config default zone: %q", got)
+	}
+	if got := m.Get("pl", "eta", Vars{"d": d, TimezoneVar: "Europe/Warsaw"}); got != "Dostawa 05.03.2026 o 14:00" {
+		t.Errorf("zone from Vars: %q", got)
+	}
+
+	ctx := WithLocale(WithManager(WithTimezone(context.Background(), warsaw), m), "en")
+	if got := T(ctx, "at", Vars{"d": d}); got != "3/5/2026, 2:00 PM" {
+		t.Errorf("zone from context: %q", got)
+	}
+	if got := T(ctx, "at", Vars{"d": d, TimezoneVar: time.UTC}); got != "3/5/2026, 1:00 PM" {
+		t.Errorf("Vars override the context: %q", got)
+	}
+	if got := m.Get("en", "at", Vars{"d": d, TimezoneVar: "Nowhere/Invalid"}); got != "3/5/2026, 1:00 PM" {
+		t.Errorf("invalid zone name: %q", got)
+	}
+	if got := m.Get("en", "plain", Vars{"d": "soon"}); got != "soon" {
+		t.Errorf("unstyled placeholder: %q", got)
+	}
+}
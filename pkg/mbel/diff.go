@@ -0,0 +1,83 @@
+package mbel
+
+// DiffReport describes how catalog b differs from catalog a
+type DiffReport struct {
+	Missing      []string              `json:"missing,omitempty"`      // keys of a that b lacks
+	Extra        []string              `json:"extra,omitempty"`        // keys of b that a lacks
+	Changed      []string              `json:"changed,omitempty"`      // keys whose text or cases differ
+	Placeholders []PlaceholderMismatch `json:"placeholders,omitempty"` // keys whose placeholders differ
+}
+
+// PlaceholderMismatch lists the {placeholders} and {-terms} of a key
+// found on one side only
+type PlaceholderMismatch struct {
+	Key     string   `json:"key"`
+	Missing []string `json:"missing,omitempty"` // used in a, not in b
+	Extra   []string `json:"extra,omitempty"`   // used in b, not in a
+}
+
+// Empty reports whether the catalogs match
+func (d DiffReport) Empty() bool {
+	return len(d.Missing)+len(d.Extra)+len(d.Changed)+len(d.Placeholders) == 0
+}
+
+// DiffCatalogs compares two compiled catalogs of one locale (key ->
+// string or *RuntimeBlock), e.g. a remote bundle against the embedded
+// fallback, to alert on drift. Keys starting with "__" are ignored. For
+// a source and a translation only Missing, Extra and Placeholders are
+// meaningful. All lists are sorted by key.
+func DiffCatalogs(a, b map[string]interface{}) DiffReport {
+	var d DiffReport
+	for _, key := range SortedKeys(a) {
+		bv, ok := b[key]
+		if !ok {
+			d.Missing = append(d.Missing, key)
+			continue
+		}
+		av := a[key]
+		if ContentHash(av) != ContentHash(bv) {
+			d.Changed = append(d.Changed, key)
+		}
+
+		as, bs := valuePlaceholders(av), valuePlaceholders(bv)
+		m := PlaceholderMismatch{Key: key}
+		for _, p := range sortedSet(as) {
+			if !bs[p] {
+				m.Missing = append(m.Missing, p)
+			}
+		}
+		for _, p := range sortedSet(bs) {
+			if !as[p] {
+				m.Extra = append(m.Extra, p)
+			}
+		}
+		if m.Missing != nil || m.Extra != nil {
+			d.Placeholders = append(d.Placeholders, m)
+		}
+	}
+	for _, key := range SortedKeys(b) {
+		if _, ok := a[key]; !ok {
+			d.Extra = append(d.Extra, key)
+		}
+	}
+	return d
+}
+
+// valuePlaceholders collects the placeholders of a string or of every
+// case of a logic block
+func valuePlaceholders(v interface{}) map[string]bool {
+	set := make(map[string]bool)
+	switch val := v.(type) {
+	case string:
+		for _, p := range placeholders(val) {
+			set[p] = true
+		}
+	case *RuntimeBlock:
+		for _, e := range blockEntries(val) {
+			for _, p := range placeholders(e.value) {
+				set[p] = true
+			}
+		}
+	}
+	return set
+}
@@ -0,0 +1,39 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	embedded := map[string]interface{}{
+		"__meta":  map[string]string{"lang": "en"},
+		"title":   "Hello {name}",
+		"footer":  "Bye",
+		"legacy":  "Old",
+		"items":   &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}},
+		"welcome": "Welcome to {-brand}",
+	}
+	remote := map[string]interface{}{
+		"title":   "Hello {user}",
+		"footer":  "Bye",
+		"items":   &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "one item", "other": "{n} items"}},
+		"welcome": "Welcome to {-brand}",
+		"banner":  "Sale!",
+	}
+
+	want := DiffReport{
+		Missing: []string{"legacy"},
+		Extra:   []string{"banner"},
+		Changed: []string{"items", "title"},
+		Placeholders: []PlaceholderMismatch{
+			{Key: "title", Missing: []string{"{name}"}, Extra: []string{"{user}"}},
+		},
+	}
+	if got := DiffCatalogs(embedded, remote); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffCatalogs = %+v\nwant %+v", got, want)
+	}
+	if d := DiffCatalogs(remote, remote); !d.Empty() {
+		t.Errorf("identical catalogs differ: %+v", d)
+	}
+}
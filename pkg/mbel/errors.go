@@ -0,0 +1,37 @@
+package mbel
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sentinel errors. Errors returned by the package wrap them, so callers
+// can branch with errors.Is rather than match messages:
+//
+//	if _, err := m.Lookup("pl", "cart.title"); errors.Is(err, mbel.ErrKeyNotFound) { ... }
+//	if err := m.Load(ctx); errors.Is(err, mbel.ErrParse) { ... }
+var (
+	// ErrLocaleNotFound: the requested locale is not loaded
+	ErrLocaleNotFound = errors.New("mbel: locale not found")
+	// ErrKeyNotFound: no locale consulted has the key
+	ErrKeyNotFound = errors.New("mbel: key not found")
+	// ErrParse: a source file has syntax errors (see *ParseError)
+	ErrParse = errors.New("mbel: syntax error")
+	// ErrCompile: a parsed file does not compile (see *CompileError)
+	ErrCompile = errors.New("mbel: compile error")
+	// ErrInvalidMessage: a message given to Manager.Update is rejected
+	ErrInvalidMessage = errors.New("mbel: invalid message")
+)
+
+// ParseError holds the syntax errors of one source file, as reported by
+// Parser.Errors
+type ParseError struct {
+	Errors []string
+}
+
+func (e *ParseError) Error() string {
+	return strings.Join(e.Errors, "; ")
+}
+
+// Is makes errors.Is(err, ErrParse) true
+func (e *ParseError) Is(target error) bool { return target == ErrParse }
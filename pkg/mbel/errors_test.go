@@ -0,0 +1,41 @@
+package mbel
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Shop"},
+		"pl": {"cart": "Koszyk"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := m.Lookup("pl", "title"); err != nil || got != "Shop" {
+		t.Errorf("Lookup(pl, title) = %q, %v; want the en fallback", got, err)
+	}
+	if _, err := m.Lookup("pl", "nope"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("missing key: %v", err)
+	}
+	if _, err := m.Lookup("de", "title"); !errors.Is(err, ErrLocaleNotFound) {
+		t.Errorf("missing locale: %v", err)
+	}
+
+	if _, err := FormatSource("broken = \n"); !errors.Is(err, ErrParse) {
+		t.Errorf("FormatSource: %v", err)
+	}
+	if _, err := NewReviewSheet(map[string]map[string]interface{}{}, nil, "en", "pl"); !errors.Is(err, ErrLocaleNotFound) {
+		t.Errorf("NewReviewSheet: %v", err)
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("MustT panicked with %v", err)
+		}
+	}()
+	MustT(WithManager(context.Background(), m), "nope")
+}
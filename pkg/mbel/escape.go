@@ -0,0 +1,146 @@
+package mbel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// An escape profile escapes interpolated values for where the message
+// ends up, so the same key can be rendered into an HTML attribute, a JSON
+// payload or a shell command. Message text is never escaped, only the
+// values. The profile is, in order: EscapeVar in the arguments,
+// WithEscape on the context of T, Config.Escape, else none.
+//
+//	mbel.T(ctx, "deploy.confirm", mbel.Vars{"file": path, mbel.EscapeVar: mbel.EscapeShell})
+
+// EscapeProfile names how interpolated values are escaped
+type EscapeProfile string
+
+const (
+	EscapeNone  EscapeProfile = ""      // values are inserted as they are
+	EscapeHTML  EscapeProfile = "html"  // HTML text and quoted attributes
+	EscapeJSON  EscapeProfile = "json"  // the inside of a JSON string
+	EscapeShell EscapeProfile = "shell" // each value one POSIX shell word
+)
+
+// EscapeVar is the Vars key selecting the escape profile of one call: an
+// EscapeProfile or its name. It can never clash with a placeholder name.
+const EscapeVar = "@escape"
+
+// ParseEscapeProfile returns the profile named s ("", "none", "html",
+// "json" or "shell")
+func ParseEscapeProfile(s string) (EscapeProfile, error) {
+	switch p := EscapeProfile(strings.ToLower(strings.TrimSpace(s))); p {
+	case "none":
+		return EscapeNone, nil
+	case EscapeNone, EscapeHTML, EscapeJSON, EscapeShell:
+		return p, nil
+	}
+	return EscapeNone, fmt.Errorf("mbel: unknown escape profile %q (want html, json or shell)", s)
+}
+
+// Escape returns s escaped for the profile
+func (p EscapeProfile) Escape(s string) string {
+	switch p {
+	case EscapeHTML:
+		return html.EscapeString(s)
+	case EscapeJSON:
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.Encode(s)
+		out := b.String() // "..." and a newline
+		return out[1 : len(out)-2]
+	case EscapeShell:
+		return shellQuote(s)
+	}
+	return s
+}
+
+// shellQuote returns s as one POSIX shell word: as it is when it only
+// has characters no shell treats specially, else single-quoted
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+type escapeContextKey struct{}
+
+// WithEscape sets the escape profile of T calls made with the returned
+// context
+func WithEscape(ctx context.Context, p EscapeProfile) context.Context {
+	return context.WithValue(ctx, escapeContextKey{}, p)
+}
+
+// EscapeFromContext returns the profile set by WithEscape, or EscapeNone
+func EscapeFromContext(ctx context.Context) EscapeProfile {
+	p, _ := ctx.Value(escapeContextKey{}).(EscapeProfile)
+	return p
+}
+
+// escapeOf returns the profile EscapeVar sets in arg, and whether it
+// sets a known one
+func escapeOf(arg interface{}) (EscapeProfile, bool) {
+	var v interface{}
+	switch m := arg.(type) {
+	case Vars:
+		v = m[EscapeVar]
+	case map[string]interface{}:
+		v = m[EscapeVar]
+	}
+
+	var name string
+	switch p := v.(type) {
+	case EscapeProfile:
+		name = string(p)
+	case string:
+		name = p
+	default:
+		return EscapeNone, false
+	}
+	p, err := ParseEscapeProfile(name)
+	return p, err == nil
+}
+
+// withEscape adds p to the variables in args unless they set a profile
+// already. Scalar arguments cannot carry one and are left alone.
+func withEscape(args []interface{}, p EscapeProfile) []interface{} {
+	if p == EscapeNone || len(args) == 0 {
+		return args
+	}
+
+	var vars map[string]interface{}
+	switch m := args[0].(type) {
+	case Vars:
+		vars = m
+	case map[string]interface{}:
+		vars = m
+	default:
+		return args
+	}
+	if _, ok := vars[EscapeVar]; ok {
+		return args
+	}
+
+	merged := make(Vars, len(vars)+1)
+	for k, v := range vars {
+		merged[k] = v
+	}
+	merged[EscapeVar] = p
+	return append([]interface{}{merged}, args[1:]...)
+}
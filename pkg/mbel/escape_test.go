@@ -0,0 +1,70 @@
+package mbel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEscapeProfiles(t *testing.T) {
+	tests := []struct {
+		p    EscapeProfile
+		in   string
+		want string
+	}{
+		{EscapeNone, `<a href="x">'`, `<a href="x">'`},
+		{EscapeHTML, `<a href="x">'`, `&lt;a href=&#34;x&#34;&gt;&#39;`},
+		{EscapeJSON, "say \"hi\"\n\\", `say \"hi\"\n\\`},
+		{EscapeShell, "report.pdf", "report.pdf"},
+		{EscapeShell, "it's $(rm -rf)", `'it'\''s $(rm -rf)'`},
+		{EscapeShell, "", "''"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Escape(tt.in); got != tt.want {
+			t.Errorf("%q.Escape(%q) = %q, want %q", tt.p, tt.in, got, tt.want)
+		}
+	}
+
+	if p, err := ParseEscapeProfile(" JSON "); err != nil || p != EscapeJSON {
+		t.Errorf("ParseEscapeProfile(JSON) = %q, %v", p, err)
+	}
+	if _, err := ParseEscapeProfile("xml"); err == nil {
+		t.Error("ParseEscapeProfile(xml) succeeded")
+	}
+}
+
+func TestEscapeManager(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"attr": `title="{name}"`},
+	})
+	m, err := NewManagerWithRepo(repo, Config{Escape: EscapeHTML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := Vars{"name": `"Bob" & co`}
+
+	if got := m.Get("en", "attr", name); got != `title="&#34;Bob&#34; &amp; co"` {
+		t.Errorf("manager profile: %q", got)
+	}
+	if got := m.Get("en", "attr", Vars{"name": `"Bob"`, EscapeVar: "json"}); got != `title="\"Bob\""` {
+		t.Errorf("per-call profile: %q", got)
+	}
+	if got := m.Get("en", "attr", Vars{"name": "a b", EscapeVar: EscapeShell}); got != `title="'a b'"` {
+		t.Errorf("per-call shell profile: %q", got)
+	}
+
+	ctx := WithManager(WithEscape(context.Background(), EscapeNone), m)
+	if got := T(ctx, "attr", name); got != `title="&#34;Bob&#34; &amp; co"` {
+		t.Errorf("EscapeNone on the context must keep the manager's profile: %q", got)
+	}
+	ctx = WithManager(WithEscape(context.Background(), EscapeJSON), m)
+	if got := T(ctx, "attr", name); got != `title="\"Bob\" & co"` {
+		t.Errorf("context profile: %q", got)
+	}
+	if got := T(ctx, "attr", Vars{"name": "<b>", EscapeVar: EscapeHTML}); got != `title="&lt;b&gt;"` {
+		t.Errorf("Vars must override the context: %q", got)
+	}
+
+	if _, err := NewManagerWithRepo(repo, Config{Escape: "xml"}); err == nil {
+		t.Error("unknown Config.Escape accepted")
+	}
+}
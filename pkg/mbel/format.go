@@ -0,0 +1,269 @@
+package mbel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Format renders a parsed program as canonical MBEL source.
+// Statement order, comments (trailing ones on their line), AI annotations
+// and single blank lines between groups are preserved; indentation and
+// spacing are normalized.
+// Parsing the output yields the same compiled data as the input.
+func Format(program *Program) string {
+	return FormatWithStyle(program, InterpolationSingle)
+}
+
+// FormatWithStyle is Format for a project whose files without an
+// @interpolation line are written in style
+func FormatWithStyle(program *Program, style Interpolation) string {
+	items := formatItems(program, style)
+
+	var b strings.Builder
+	prevEnd := 0
+	for i, it := range items {
+		if i > 0 && it.line > prevEnd+1 {
+			b.WriteString("\n")
+		}
+		b.WriteString(it.text)
+		if it.end > prevEnd {
+			prevEnd = it.end
+		}
+	}
+
+	return b.String()
+}
+
+// FormatSource parses src and returns its canonical form.
+// Sources with syntax errors are returned as an error wrapping ErrParse,
+// never rewritten.
+func FormatSource(src string) (string, error) {
+	return FormatSourceWithStyle(src, InterpolationSingle)
+}
+
+// FormatSourceWithStyle is FormatSource for a project whose files
+// without an @interpolation line are written in style
+func FormatSourceWithStyle(src string, style Interpolation) (string, error) {
+	p := NewParser(NewLexer(src))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) > 0 {
+		return "", fmt.Errorf("%w:\n  %s", ErrParse, strings.Join(errs, "\n  "))
+	}
+	return FormatWithStyle(program, style), nil
+}
+
+// FormatData renders compiled data (one language) as MBEL source:
+// metadata, then keys in sorted order. Terms, imports and AI annotations
+// are not part of compiled data and are not written.
+func FormatData(data map[string]interface{}) string {
+	var b strings.Builder
+
+	meta, _ := data["__meta"].(map[string]string)
+	names := make([]string, 0, len(meta))
+	for k := range meta {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintf(&b, "@%s: %s\n", k, formatMetaValue(meta[k]))
+	}
+
+	keys := SortedKeys(data)
+	if len(names) > 0 && len(keys) > 0 {
+		b.WriteString("\n")
+	}
+	for _, key := range keys {
+		stmt := &AssignStatement{Name: key}
+		switch v := data[key].(type) {
+		case string:
+			stmt.Value = &StringLiteral{Value: v}
+		case *RuntimeBlock:
+			block := &BlockExpression{Argument: v.Argument}
+			for _, e := range blockEntries(v) {
+				block.Cases = append(block.Cases, &BlockCase{Condition: e.cond, Value: e.value})
+			}
+			stmt.Value = block
+		default:
+			continue
+		}
+		b.WriteString(formatKeyMeta(data, key))
+		b.WriteString(formatAssign(stmt).text)
+	}
+	return b.String()
+}
+
+// DetectLineEnding returns the line ending src uses, "\r\n" or "\n",
+// judged by its first line break
+func DetectLineEnding(src string) string {
+	if i := strings.IndexByte(src, '\n'); i > 0 && src[i-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// NormalizeLineEndings rewrites every line ending of s, CRLF or LF, as eol
+func NormalizeLineEndings(s, eol string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if eol != "\n" {
+		s = strings.ReplaceAll(s, "\n", eol)
+	}
+	return s
+}
+
+// formatItem is one rendered source element with the lines it spans
+type formatItem struct {
+	line int
+	end  int
+	text string
+}
+
+func formatItems(program *Program, def Interpolation) []formatItem {
+	var items []formatItem
+	// Only the single style escapes lone braces; ICU braces are syntax
+	meta := Metadata(program)
+	style, _ := interpolationOf(meta, def)
+	keep := meta[syntaxMetaKey] == "icu" || style != InterpolationSingle
+
+	// Single-line comments by line; those sharing a line with a
+	// statement stay at its end, the rest get a line of their own
+	comments := make(map[int]string)
+	for _, c := range program.Comments {
+		comments[c.Line] = "#" + c.Text
+	}
+	for _, ann := range program.AIAnnotations {
+		if text := formatAnnotation(ann); strings.Count(text, "\n") == 1 {
+			comments[ann.Line] = strings.TrimSuffix(text, "\n")
+		} else {
+			items = append(items, formatItem{line: ann.Line, end: ann.Line + strings.Count(text, "\n") - 1, text: text})
+		}
+	}
+	trailing := func(line int) string {
+		c, ok := comments[line]
+		if !ok {
+			return ""
+		}
+		delete(comments, line)
+		return " " + c
+	}
+
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *MetadataStatement:
+			items = append(items, formatItem{line: s.Token.Line, end: s.Token.Line, text: fmt.Sprintf("@%s: %s%s\n", s.Key, formatMetaValue(s.Value), trailing(s.ValueToken.EndLine))})
+		case *ImportStatement:
+			items = append(items, formatItem{line: s.Token.Line, end: s.Token.Line, text: fmt.Sprintf("@import %s%s\n", s.Namespace, trailing(s.Token.Line))})
+		case *SectionStatement:
+			items = append(items, formatItem{line: s.Token.Line, end: s.Token.Line, text: fmt.Sprintf("[%s]%s\n", s.Name, trailing(s.Token.Line))})
+		case *AssignStatement:
+			items = append(items, formatAssignTrailing(canonicalAssign(s, keep), trailing))
+		}
+	}
+
+	for line, c := range comments {
+		items = append(items, formatItem{line: line, end: line, text: c + "\n"})
+	}
+
+	// Statements and comments are collected separately; restore source order
+	sort.SliceStable(items, func(i, j int) bool { return items[i].line < items[j].line })
+	return items
+}
+
+// blockHeader renders the line opening the block of key name:
+// "name(arg) {" or, for variants, "name = variant {"
+func blockHeader(name, argument string) string {
+	if argument == variantArgument {
+		return name + " = " + variantKeyword + " {\n"
+	}
+	return name + "(" + argument + ") {\n"
+}
+
+func formatAssign(s *AssignStatement) formatItem {
+	return formatAssignTrailing(s, func(int) string { return "" })
+}
+
+// formatAssignTrailing renders s, appending trailing(line) to each
+// rendered line that ends on that source line
+func formatAssignTrailing(s *AssignStatement, trailing func(line int) string) formatItem {
+	line := s.Token.Line
+
+	switch v := s.Value.(type) {
+	case *StringLiteral:
+		return formatItem{line: line, end: v.Token.EndLine, text: fmt.Sprintf("%s = %s%s\n", s.Name, quoteValue(v.Value), trailing(v.Token.EndLine))}
+	case *BlockExpression:
+		var b strings.Builder
+		b.WriteString(strings.TrimSuffix(blockHeader(s.Name, v.Argument), "\n") + trailing(v.Token.Line) + "\n")
+		for _, bc := range v.Cases {
+			fmt.Fprintf(&b, "    [%s] => %s%s\n", bc.Condition, quoteValue(bc.Value), trailing(bc.ValueToken.EndLine))
+		}
+		b.WriteString("}" + trailing(v.EndLine) + "\n")
+		return formatItem{line: line, end: v.EndLine, text: b.String()}
+	default:
+		return formatItem{line: line, end: line, text: s.String()}
+	}
+}
+
+// canonicalAssign returns s with the lone braces of its text escaped
+// (see canonicalBraces); ICU messages, whose braces are syntax, and text
+// of other interpolation styles (keep) are left as written
+func canonicalAssign(s *AssignStatement, keep bool) *AssignStatement {
+	text := func(v string) string {
+		if keep || looksLikeICU(v) {
+			return v
+		}
+		return canonicalBraces(v)
+	}
+	out := *s
+	switch v := s.Value.(type) {
+	case *StringLiteral:
+		sl := *v
+		sl.Value = text(v.Value)
+		out.Value = &sl
+	case *BlockExpression:
+		block := *v
+		block.Cases = make([]*BlockCase, len(v.Cases))
+		for i, bc := range v.Cases {
+			c := *bc
+			c.Value = text(bc.Value)
+			block.Cases[i] = &c
+		}
+		out.Value = &block
+	}
+	return &out
+}
+
+// formatAnnotation renders an AI annotation as comment lines; multi-line
+// values use the { ... } form understood by the parser
+func formatAnnotation(ann *AIAnnotation) string {
+	if !strings.Contains(ann.Value, "\n") {
+		return fmt.Sprintf("# AI_%s: %s\n", ann.Type, ann.Value)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# AI_%s: {\n", ann.Type)
+	for _, l := range strings.Split(ann.Value, "\n") {
+		fmt.Fprintf(&b, "# %s\n", strings.TrimSpace(l))
+	}
+	b.WriteString("# }\n")
+	return b.String()
+}
+
+// quoteValue picks the string form that reproduces v exactly: the lexer
+// has no escapes, so values with quotes or newlines need """
+func quoteValue(v string) string {
+	if strings.ContainsAny(v, "\"\n") {
+		return `"""` + v + `"""`
+	}
+	return `"` + v + `"`
+}
+
+// formatMetaValue keeps identifiers and numbers bare and quotes anything
+// else, so the value lexes back as a single token
+func formatMetaValue(v string) string {
+	l := NewLexer(v)
+	tok := l.NextToken()
+	if (tok.Type == TOKEN_IDENT || tok.Type == TOKEN_NUMBER) && tok.Literal == v && l.NextToken().Type == TOKEN_EOF {
+		return v
+	}
+	return quoteValue(v)
+}
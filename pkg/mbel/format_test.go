@@ -0,0 +1,109 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../../examples/*.mbel")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no example files found: %v", err)
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		formatted, err := FormatSource(string(src))
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		if again, _ := FormatSource(formatted); again != formatted {
+			t.Errorf("%s: formatting is not idempotent:\n%s\n---\n%s", file, formatted, again)
+		}
+
+		if want, got := compileSource(t, string(src)), compileSource(t, formatted); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: formatted source compiles differently\nwant: %v\ngot:  %v", file, want, got)
+		}
+	}
+}
+
+func TestFormatPreservesCommentsAndBlocks(t *testing.T) {
+	src := `# Header
+@lang:   pl
+
+# AI_Context: Button label
+cta =   "Kup teraz"
+quote = """Say "hi"
+twice"""
+items(n) {
+  [one]=>"1"
+	[2..4]   => "{n}"
+}
+`
+	want := `# Header
+@lang: pl
+
+# AI_Context: Button label
+cta = "Kup teraz"
+quote = """Say "hi"
+twice"""
+items(n) {
+    [one] => "1"
+    [2..4] => "{n}"
+}
+`
+	got, err := FormatSource(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("unexpected format output:\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestFormatKeepsTrailingComments(t *testing.T) {
+	src := `[shop] # section
+title =  "Hello" # note
+cart = "Cart"   # AI_Context: button
+# own line
+items(n) { # block
+  [one] => "1" # one
+    [other] => "{n}"
+} # end
+`
+	want := `[shop] # section
+title = "Hello" # note
+cart = "Cart" # AI_Context: button
+# own line
+items(n) { # block
+    [one] => "1" # one
+    [other] => "{n}"
+} # end
+`
+	got, err := FormatSource(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("unexpected format output:\n%s\n--- want ---\n%s", got, want)
+	}
+	if want, got := compileSource(t, src), compileSource(t, got); !reflect.DeepEqual(want, got) {
+		t.Errorf("formatted source compiles differently\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func compileSource(t *testing.T, src string) map[string]interface{} {
+	t.Helper()
+	out, err := NewCompiler().Compile(NewParser(NewLexer(src)).ParseProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.Data
+}
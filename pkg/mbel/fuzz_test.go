@@ -0,0 +1,65 @@
+package mbel
+
+import (
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	"@lang: pl\ntitle = \"Hello\"\n",
+	"count(n) {\n\t[one] => \"1\"\n\t[2..4] => \"few\"\n\t[other] => \"{n}\"\n}\n",
+	"description = \"\"\"\nLine 1\n",
+	"broken(n) {\n\t[one] => \"x\"\n",
+	"key = ..\n",
+	"[section\nkey = \"v\"\n",
+	"# AI_Context: {\n# unterminated\n",
+	"@import\n",
+	"x(n) { [1..] => \"a\" }",
+	"\"unterminated",
+}
+
+func FuzzLexer(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		l := NewLexer(input)
+		// Every call must consume input, so the stream ends within len+2 tokens
+		for i := 0; i <= len(input)+1; i++ {
+			if tok := l.NextToken(); tok.Type == TOKEN_EOF {
+				return
+			}
+		}
+		t.Fatalf("lexer did not reach EOF for %q", input)
+	})
+}
+
+func FuzzParser(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		p := NewParser(NewLexer(input))
+		program := p.ParseProgram()
+		if _, err := NewCompiler().Compile(program); err != nil {
+			t.Fatalf("compile failed for %q: %v", input, err)
+		}
+	})
+}
+
+func TestMalformedInputReportsErrors(t *testing.T) {
+	inputs := []string{
+		"description = \"\"\"\nLine 1\n",
+		"broken(n) {\n\t[one] => \"x\"\n",
+		"key = ..\n",
+		"key = \"v\" .\n",
+		"count(n) {\n\tjunk\n}\n",
+	}
+
+	for _, input := range inputs {
+		p := NewParser(NewLexer(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected errors for %q", input)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package mbel
+
+// GenderStrategy selects how logic blocks with a [neutral] case are
+// resolved, so a locale can offer inclusive copy without a second catalog
+type GenderStrategy int
+
+const (
+	// GenderExplicit picks the case named by the argument. Values without
+	// a case of their own ("neutral", "nonbinary", ...) get [neutral]
+	// before falling back to [other].
+	GenderExplicit GenderStrategy = iota
+
+	// GenderNeutral always uses the [neutral] case of blocks that have
+	// one, e.g. German gendered forms ("Liebe Kund*innen") for every reader
+	GenderNeutral
+)
+
+// neutralCase is the selector of gender-neutral block cases
+const neutralCase = "neutral"
+
+// SetGenderStrategy sets how blocks with a [neutral] case are resolved
+func (r *Runtime) SetGenderStrategy(s GenderStrategy) {
+	r.genderStrategy = s
+}
+
+// genderStrategy returns the configured strategy for lang, or for its
+// base language (de-AT -> de)
+func (m *Manager) genderStrategy(lang string) GenderStrategy {
+	if s, ok := m.genderStrategies[lang]; ok {
+		return s
+	}
+	if len(lang) > 2 {
+		return m.genderStrategies[lang[:2]]
+	}
+	return GenderExplicit
+}
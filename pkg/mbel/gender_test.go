@@ -0,0 +1,47 @@
+package mbel
+
+import "testing"
+
+func TestGenderNeutralCase(t *testing.T) {
+	data, _, err := CompileSource([]byte(`greeting(gender) {
+    [male] => "Lieber Kunde"
+    [female] => "Liebe Kundin"
+    [neutral] => "Liebe Kund*innen"
+    [other] => "Hallo"
+}
+plain(gender) {
+    [male] => "er"
+    [other] => "sie"
+}
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewMemoryRepository(map[string]map[string]interface{}{"de": data, "de-CH": data})
+
+	explicit, err := NewManagerWithRepo(repo, Config{DefaultLocale: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gender, want := range map[string]string{"male": "Lieber Kunde", "female": "Liebe Kundin", "neutral": "Liebe Kund*innen", "nonbinary": "Liebe Kund*innen"} {
+		if got := explicit.Get("de", "greeting", Vars{"gender": gender}); got != want {
+			t.Errorf("explicit %s: got %q, want %q", gender, got, want)
+		}
+	}
+	if got := explicit.Get("de", "plain", Vars{"gender": "nonbinary"}); got != "sie" {
+		t.Errorf("block without [neutral] should use [other], got %q", got)
+	}
+
+	inclusive, err := NewManagerWithRepo(repo, Config{DefaultLocale: "de", GenderStrategies: map[string]GenderStrategy{"de": GenderNeutral}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lang := range []string{"de", "de-CH"} {
+		if got := inclusive.Get(lang, "greeting", Vars{"gender": "male"}); got != "Liebe Kund*innen" {
+			t.Errorf("neutral strategy (%s): got %q", lang, got)
+		}
+	}
+	if got := inclusive.Get("de", "plain", Vars{"gender": "male"}); got != "er" {
+		t.Errorf("block without [neutral] unaffected, got %q", got)
+	}
+}
@@ -0,0 +1,94 @@
+package mbel
+
+import "context"
+
+// Global vars fill placeholders that would otherwise be passed at every
+// call site, such as the product name or the support URL. Values are
+// looked up in order: the arguments of the call, WithVars on the context
+// of T, then Manager.SetGlobalVars. A scalar argument fills every
+// placeholder by itself, so they only complete Vars arguments.
+//
+//	m.SetGlobalVars(mbel.Vars{"app_name": "Acme"})
+//	ctx = mbel.WithVars(ctx, mbel.Vars{"tenant": tenant.Name})
+//	mbel.T(ctx, "welcome", mbel.Vars{"name": user.Name}) // "Welcome to Acme, Ola"
+
+type varsContextKey struct{}
+
+// WithVars adds vars to those filling placeholders for T calls made with
+// the returned context, on top of any set by an outer WithVars
+func WithVars(ctx context.Context, vars Vars) context.Context {
+	if outer := VarsFromContext(ctx); len(outer) > 0 {
+		vars = mergeVars(outer, vars)
+	}
+	return context.WithValue(ctx, varsContextKey{}, vars)
+}
+
+// VarsFromContext returns the vars set by WithVars, or nil
+func VarsFromContext(ctx context.Context) Vars {
+	vars, _ := ctx.Value(varsContextKey{}).(Vars)
+	return vars
+}
+
+// SetGlobalVars replaces the vars filling placeholders in every lookup
+// of m (nil clears them). It is safe to call while m serves lookups.
+func (m *Manager) SetGlobalVars(vars Vars) {
+	if len(vars) == 0 {
+		m.globalVars.Store(nil)
+		return
+	}
+	vars = mergeVars(nil, vars) // the caller may keep changing its map
+	m.globalVars.Store(&vars)
+}
+
+// GlobalVars returns the vars set by SetGlobalVars, or nil
+func (m *Manager) GlobalVars() Vars {
+	if vars := m.globalVars.Load(); vars != nil {
+		return mergeVars(nil, *vars)
+	}
+	return nil
+}
+
+// withVars completes the arguments of a lookup with the global vars of m
+// and those of ctx
+func (m *Manager) withVars(ctx context.Context, args []interface{}) []interface{} {
+	var base Vars
+	if vars := m.globalVars.Load(); vars != nil {
+		base = *vars
+	}
+	if scoped := VarsFromContext(ctx); len(scoped) > 0 {
+		if base == nil {
+			base = scoped
+		} else {
+			base = mergeVars(base, scoped)
+		}
+	}
+	if len(base) == 0 {
+		return args
+	}
+	if len(args) == 0 {
+		return []interface{}{base}
+	}
+
+	var vars map[string]interface{}
+	switch v := args[0].(type) {
+	case Vars:
+		vars = v
+	case map[string]interface{}:
+		vars = v
+	default:
+		return args
+	}
+	return append([]interface{}{mergeVars(base, vars)}, args[1:]...)
+}
+
+// mergeVars returns a new map of base overridden by over
+func mergeVars(base, over map[string]interface{}) Vars {
+	merged := make(Vars, len(base)+len(over))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range over {
+		merged[k] = v
+	}
+	return merged
+}
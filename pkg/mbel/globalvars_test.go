@@ -0,0 +1,51 @@
+package mbel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGlobalVars(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"welcome": "Welcome to {app_name}, {name}", "support": "Write to {support_url}", "tenant": "{tenant} on {app_name}"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	globals := Vars{"app_name": "Acme", "support_url": "acme.io/help"}
+	m.SetGlobalVars(globals)
+	globals["app_name"] = "Changed"
+
+	if got := m.Get("en", "welcome", Vars{"name": "Ola"}); got != "Welcome to Acme, Ola" {
+		t.Errorf("with call vars: %q", got)
+	}
+	if got := m.Get("en", "support"); got != "Write to acme.io/help" {
+		t.Errorf("without arguments: %q", got)
+	}
+	if got := m.Get("en", "welcome", Vars{"name": "Ola", "app_name": "Beta"}); got != "Welcome to Beta, Ola" {
+		t.Errorf("call vars override globals: %q", got)
+	}
+	if got := m.RenderAll("en", []string{"support"}, nil)["support"]; got != "Write to acme.io/help" {
+		t.Errorf("RenderAll: %q", got)
+	}
+	if got, _ := m.Lookup("en", "support"); got != "Write to acme.io/help" {
+		t.Errorf("Lookup: %q", got)
+	}
+
+	ctx := WithManager(context.Background(), m)
+	ctx = WithVars(WithVars(ctx, Vars{"tenant": "Shop", "app_name": "Tenant App"}), Vars{"tenant": "Store"})
+	if got := T(ctx, "tenant"); got != "Store on Tenant App" {
+		t.Errorf("context vars: %q", got)
+	}
+	if got := T(ctx, "tenant", Vars{"tenant": "Mine"}); got != "Mine on Tenant App" {
+		t.Errorf("call vars override the context: %q", got)
+	}
+
+	m.SetGlobalVars(nil)
+	if got := m.Get("en", "support"); got != "Write to {support_url}" {
+		t.Errorf("cleared globals: %q", got)
+	}
+	if m.GlobalVars() != nil {
+		t.Errorf("GlobalVars = %v", m.GlobalVars())
+	}
+}
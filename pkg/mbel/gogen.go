@@ -0,0 +1,157 @@
+package mbel
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateGo writes Go source for package pkg with a typed accessor per
+// key of data (the compiled source locale): a Key constant and a
+// function taking the key's variables, rendering through mbel.T:
+//
+//	const KeyCartItems = "cart.items"
+//
+//	// CartItems renders cart.items: "{n} items"
+//	func CartItems(ctx context.Context, n interface{}) string
+//
+// Keys whose accessors or constants share a Go name are an error.
+func GenerateGo(w io.Writer, data map[string]interface{}, pkg string) error {
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("mbel: invalid Go package name %q", pkg)
+	}
+	keys := SortedKeys(data)
+	names := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name := goName(key)
+		if name == "" {
+			return fmt.Errorf("mbel: key %s has no Go name", key)
+		}
+		// Accessors and Key constants share the package scope
+		for _, ident := range []string{name, "Key" + name} {
+			if prev, ok := names[ident]; ok {
+				return fmt.Errorf("mbel: keys %s and %s both generate %s", prev, key, ident)
+			}
+			names[ident] = key
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by mbel; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(keys) == 0 {
+		return writeGo(w, b.Bytes())
+	}
+	b.WriteString("import (\n\t\"context\"\n\n\t\"github.com/makkiattooo/MBEL/pkg/mbel\"\n)\n\n")
+
+	b.WriteString("// Keys of the catalog, for mbel.T and Manager lookups\nconst (\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\tKey%s = %q\n", goName(key), key)
+	}
+	b.WriteString(")\n")
+
+	for _, key := range keys {
+		name := goName(key)
+		vars := goVars(data[key])
+		params := make([]string, len(vars))
+		fields := make([]string, len(vars))
+		for i, v := range vars {
+			params[i] = goParam(v) + " interface{}"
+			fields[i] = fmt.Sprintf("%q: %s", v, goParam(v))
+		}
+
+		fmt.Fprintf(&b, "\n// %s renders %s: %s\n", name, key, strconv.Quote(goSample(data[key])))
+		fmt.Fprintf(&b, "func %s(%s) string {\n", name, strings.Join(append([]string{"ctx context.Context"}, params...), ", "))
+		if len(vars) == 0 {
+			fmt.Fprintf(&b, "\treturn mbel.T(ctx, Key%s)\n}\n", name)
+			continue
+		}
+		fmt.Fprintf(&b, "\treturn mbel.T(ctx, Key%s, mbel.Vars{%s})\n}\n", name, strings.Join(fields, ", "))
+	}
+	return writeGo(w, b.Bytes())
+}
+
+// writeGo gofmts src and writes it to w
+func writeGo(w io.Writer, src []byte) error {
+	out, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("mbel: formatting generated code: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// goName turns a key into an exported Go name: "cart.item_count"
+// becomes "CartItemCount"
+func goName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		switch {
+		case r == '.' || r == '_' || r == '-':
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if !token.IsIdentifier(name) {
+		return ""
+	}
+	return name
+}
+
+// goParam returns the parameter name of variable v, renamed when it is a
+// Go keyword or would shadow ctx or the mbel package
+func goParam(v string) string {
+	if token.IsKeyword(v) || v == "ctx" || v == "mbel" {
+		return v + "_"
+	}
+	return v
+}
+
+// goVars lists the variables of a value, sorted: the argument of a
+// logic block and the {placeholders} of every case
+func goVars(v interface{}) []string {
+	set := make(map[string]bool)
+	for p := range valuePlaceholders(v) {
+		if !strings.HasPrefix(p, "{-") {
+			set[strings.Trim(p, "{}")] = true
+		}
+	}
+	if rb, ok := v.(*RuntimeBlock); ok && !rb.IsVariant() {
+		set[rb.Argument] = true
+	}
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// goSample returns the text shown in an accessor's doc comment: the
+// message, or the "other" case of a block
+func goSample(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case *RuntimeBlock:
+		if s, ok := val.Cases["other"]; ok {
+			return s
+		}
+		if e := blockEntries(val); len(e) > 0 {
+			return e[0].value
+		}
+	}
+	return ""
+}
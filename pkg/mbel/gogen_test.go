@@ -0,0 +1,57 @@
+package mbel
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	data := map[string]interface{}{
+		"__meta":         map[string]string{"lang": "en"},
+		"checkout.title": "Checkout",
+		"cart.item_count": &RuntimeBlock{Argument: "n", Cases: map[string]string{
+			"one":   "{n} item for {name}",
+			"other": "{n} items for {name}",
+		}},
+		"greeting": "Hi {type}, welcome to {-brand}",
+	}
+
+	var buf bytes.Buffer
+	if err := GenerateGo(&buf, data, "i18n"); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "i18n_gen.go", src, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"// Code generated by mbel; DO NOT EDIT.",
+		"package i18n",
+		`KeyCartItemCount = "cart.item_count"`,
+		`// CartItemCount renders cart.item_count: "{n} items for {name}"`,
+		`func CartItemCount(ctx context.Context, n interface{}, name interface{}) string {`,
+		`return mbel.T(ctx, KeyCartItemCount, mbel.Vars{"n": n, "name": name})`,
+		`func CheckoutTitle(ctx context.Context) string {`,
+		`return mbel.T(ctx, KeyCheckoutTitle)`,
+		`func Greeting(ctx context.Context, type_ interface{}) string {`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code lacks %q:\n%s", want, src)
+		}
+	}
+
+	err := GenerateGo(&buf, map[string]interface{}{"a.b_c": "x", "a.bC": "y"}, "i18n")
+	if err == nil || !strings.Contains(err.Error(), "both generate ABC") {
+		t.Errorf("colliding names: err = %v", err)
+	}
+	err = GenerateGo(&buf, map[string]interface{}{"foo": "x", "key.foo": "y"}, "i18n")
+	if err == nil || !strings.Contains(err.Error(), "both generate KeyFoo") {
+		t.Errorf("accessor colliding with a constant: err = %v", err)
+	}
+	if err := GenerateGo(&buf, data, "my-pkg"); err == nil {
+		t.Error("invalid package name accepted")
+	}
+}
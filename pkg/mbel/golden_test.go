@@ -0,0 +1,14 @@
+package mbel_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/makkiattooo/MBEL/pkg/mbel/mbeltest"
+)
+
+var _ = flag.Bool("update", false, "rewrite golden files")
+
+func TestCompiledOutputGolden(t *testing.T) {
+	mbeltest.AssertGolden(t, "../../examples/locales", "testdata/golden/examples_locales.json")
+}
@@ -2,40 +2,271 @@ package mbel
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 )
 
+// LocaleResolver extracts a locale code from an incoming request.
+// Resolve returns "" when the request carries no usable locale,
+// letting the next resolver in the chain try.
+type LocaleResolver interface {
+	Resolve(r *http.Request) string
+}
+
+// LocaleResolverFunc adapts an ordinary function to the LocaleResolver interface
+type LocaleResolverFunc func(r *http.Request) string
+
+// Resolve calls f(r)
+func (f LocaleResolverFunc) Resolve(r *http.Request) string {
+	return f(r)
+}
+
+// varyResolver is implemented by resolvers whose result depends on a
+// request header, so caches must key responses on it
+type varyResolver interface {
+	varyHeader() string
+}
+
+type cookieResolver struct {
+	name string
+}
+
+func (c cookieResolver) Resolve(r *http.Request) string {
+	ck, err := r.Cookie(c.name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(ck.Value)
+}
+
+func (c cookieResolver) varyHeader() string { return "Cookie" }
+
+// Cookie resolves the locale from the named cookie
+func Cookie(name string) LocaleResolver {
+	return cookieResolver{name: name}
+}
+
+// Query resolves the locale from the named URL query parameter (e.g. ?lang=pl)
+func Query(param string) LocaleResolver {
+	return LocaleResolverFunc(func(r *http.Request) string {
+		return strings.TrimSpace(r.URL.Query().Get(param))
+	})
+}
+
+// Fixed always resolves to lang. Placed first in a chain it pins the
+// locale regardless of what the request asks for.
+func Fixed(lang string) LocaleResolver {
+	return LocaleResolverFunc(func(r *http.Request) string {
+		return lang
+	})
+}
+
+type headerResolver struct{}
+
+func (headerResolver) Resolve(r *http.Request) string {
+	return parseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+func (headerResolver) varyHeader() string { return "Accept-Language" }
+
+// Header resolves the locale from the Accept-Language header
+func Header() LocaleResolver {
+	return headerResolver{}
+}
+
+// parseAcceptLanguage is a simple Accept-Language parser (grabs first preference)
+func parseAcceptLanguage(accept string) string {
+	if accept == "" {
+		return ""
+	}
+	// e.g. "pl-PL,pl;q=0.9,en-US;q=0.8" -> "pl-PL"
+	parts := strings.Split(accept, ",")
+	first := strings.TrimSpace(parts[0])
+	// remove quality score if present (though conventionally first component doesn't have it)
+	if semicolon := strings.Index(first, ";"); semicolon != -1 {
+		first = strings.TrimSpace(first[:semicolon])
+	}
+	if first == "*" {
+		return ""
+	}
+	return first
+}
+
+// Options configures MiddlewareWithOptions
+type Options struct {
+	// Resolvers are tried in order; the first non-empty result wins.
+	// Defaults to []LocaleResolver{Header()}.
+	Resolvers []LocaleResolver
+	// Default is used when no resolver yields a locale.
+	// Defaults to the Manager's default locale, or "en".
+	Default string
+	// Manager is injected into the request context so T uses it
+	// instead of the global instance. Optional.
+	Manager *Manager
+	// NegotiationCacheSize is the number of Accept-Language headers
+	// whose locale the middleware remembers (see WithNegotiationCache).
+	// 0 means DefaultNegotiationCacheSize; negative disables the cache.
+	NegotiationCacheSize int
+}
+
 // Middleware automatically extracts the locale from the request
 // checks Accept-Language header and injects it into the Context
 func Middleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Simple Accept-Language parser (grabs first preference)
-		lang := "en" // default fallback if unconfigured
-
-		accept := r.Header.Get("Accept-Language")
-		if accept != "" {
-			// e.g. "pl-PL,pl;q=0.9,en-US;q=0.8" -> "pl-PL"
-			parts := strings.Split(accept, ",")
-			if len(parts) > 0 {
-				first := strings.TrimSpace(parts[0])
-				// remove quality score if present (though conventionally first component doesn't have it)
-				semicolon := strings.Index(first, ";")
-				if semicolon != -1 {
-					first = first[:semicolon]
-				}
-				lang = first
+	return MiddlewareWithOptions(Options{Default: "en"})(next)
+}
+
+// MiddlewareWithOptions builds a locale middleware from a resolver chain.
+// The negotiated locale is sent back as Content-Language, and Vary lists
+// Accept-Language (plus Cookie when a cookie resolver is used) so shared
+// caches keep localized responses apart.
+//
+//	mux = mbel.MiddlewareWithOptions(mbel.Options{
+//		Resolvers: []mbel.LocaleResolver{mbel.Cookie("lang"), mbel.Query("lang"), mbel.Header()},
+//		Manager:   m,
+//	})(mux)
+func MiddlewareWithOptions(opts Options) func(http.Handler) http.Handler {
+	opts = opts.WithNegotiationCache()
+	vary := opts.VaryHeaders()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang := opts.Negotiate(r)
+
+			w.Header().Set("Content-Language", lang)
+			for _, v := range vary {
+				w.Header().Add("Vary", v)
+			}
+
+			// Inject into context
+			ctx := WithLocale(r.Context(), lang)
+			if opts.Manager != nil {
+				ctx = WithManager(ctx, opts.Manager)
 			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Negotiate runs the resolver chain against r and returns the winning
+// locale, or the default. Adapters for non-net/http frameworks use it
+// to perform the same negotiation as MiddlewareWithOptions.
+// A nil request yields the default locale.
+func (o Options) Negotiate(r *http.Request) string {
+	if r == nil {
+		return o.defaultLocale()
+	}
+	for _, res := range o.resolvers() {
+		if lang := res.Resolve(r); lang != "" {
+			return lang
+		}
+	}
+	return o.defaultLocale()
+}
+
+// VaryHeaders lists the request headers the negotiated locale depends on
+func (o Options) VaryHeaders() []string {
+	vary := []string{"Accept-Language"}
+	for _, res := range o.resolvers() {
+		if vr, ok := res.(varyResolver); ok && !containsString(vary, vr.varyHeader()) {
+			vary = append(vary, vr.varyHeader())
+		}
+	}
+	return vary
+}
+
+func (o Options) resolvers() []LocaleResolver {
+	if len(o.Resolvers) == 0 {
+		return []LocaleResolver{Header()}
+	}
+	return o.Resolvers
+}
+
+// ForceLocale overrides the locale negotiated upstream for every request
+// passing through it. It has the func(http.Handler) http.Handler shape
+// used by chi and similar routers, so a route subtree can be pinned:
+//
+//	r.Use(mbel.MiddlewareWithOptions(opts))
+//	r.Route("/admin", func(r chi.Router) {
+//		r.Use(mbel.ForceLocale("en"))
+//		...
+//	})
+func ForceLocale(lang string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Language", lang)
+			next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), lang)))
+		})
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
 		}
+	}
+	return false
+}
+
+func (o Options) defaultLocale() string {
+	if o.Default != "" {
+		return o.Default
+	}
+	if o.Manager != nil {
+		return o.Manager.defaultLang
+	}
+	return "en"
+}
 
-		// Inject into context
-		ctx := WithLocale(r.Context(), lang)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// SwitchLocaleHandler stores the locale requested via the "lang" form or
+// query value in cookieName and redirects back to the local path given in
+// redirectParam (or "/"). Locales that are not loaded are rejected with
+// 400. Pair it with the Cookie resolver:
+//
+//	mux.Handle("/locale", mbel.SwitchLocaleHandler("lang", "next"))
+//	// <a href="/locale?lang=pl&next=/pricing">Polski</a>
+func SwitchLocaleHandler(cookieName, redirectParam string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := strings.TrimSpace(r.FormValue("lang"))
+		m := ManagerFromContext(r.Context())
+		if lang == "" || m == nil || !m.HasLanguage(lang) {
+			http.Error(w, "unsupported locale", http.StatusBadRequest)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookieName,
+			Value:    lang,
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		http.Redirect(w, r, safeRedirect(r.FormValue(redirectParam)), http.StatusSeeOther)
 	})
 }
 
+// safeRedirect only allows local paths, so the switcher cannot be used
+// as an open redirect. Control characters are rejected outright: browsers
+// strip tabs and newlines, turning "/\t/evil.com" into "//evil.com".
+func safeRedirect(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return "/"
+	}
+	for i := 0; i < len(target); i++ {
+		if target[i] < 0x20 || target[i] == 0x7f {
+			return "/"
+		}
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil {
+		return "/"
+	}
+	return target
+}
+
 // HandlerFunc wrapper for convenience
 func Handler(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		Middleware(next).ServeHTTP(w, r)
-	}
+	return Middleware(next).ServeHTTP
 }
@@ -0,0 +1,229 @@
+package mbel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMiddlewareWithOptionsResolverOrder(t *testing.T) {
+	mw := MiddlewareWithOptions(Options{
+		Resolvers: []LocaleResolver{Cookie("lang"), Query("lang"), Header()},
+		Default:   "de",
+	})
+
+	tests := []struct {
+		name     string
+		cookie   string
+		query    string
+		header   string
+		expected string
+	}{
+		{"cookie wins", "pl", "fr", "es", "pl"},
+		{"query before header", "", "fr", "es-ES,es;q=0.9", "fr"},
+		{"header", "", "", "es-ES,es;q=0.9", "es-ES"},
+		{"default", "", "", "", "de"},
+	}
+
+	for _, tt := range tests {
+		var got string
+		h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = LocaleFromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/?lang="+tt.query, nil)
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+		}
+		if tt.header != "" {
+			req.Header.Set("Accept-Language", tt.header)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got != tt.expected {
+			t.Errorf("%s: expected locale %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestMiddlewareWithOptionsBindsManager(t *testing.T) {
+	repo := &staticRepo{data: map[string]map[string]interface{}{
+		"en": {"title": "Hello"},
+		"pl": {"title": "Cześć"},
+	}}
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	h := MiddlewareWithOptions(Options{Manager: m})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = T(r.Context(), "title")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "pl")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "Cześć" {
+		t.Errorf("expected %q, got %q", "Cześć", got)
+	}
+}
+
+type staticRepo struct {
+	data map[string]map[string]interface{}
+}
+
+func (r *staticRepo) LoadAll() (map[string]map[string]interface{}, error) {
+	return r.data, nil
+}
+
+func TestMiddlewareSetsCacheHeaders(t *testing.T) {
+	h := MiddlewareWithOptions(Options{
+		Resolvers: []LocaleResolver{Cookie("lang"), Header()},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "pl-PL,pl;q=0.9")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Language"); got != "pl-PL" {
+		t.Errorf("expected Content-Language %q, got %q", "pl-PL", got)
+	}
+	vary := rec.Header().Values("Vary")
+	if len(vary) != 2 || vary[0] != "Accept-Language" || vary[1] != "Cookie" {
+		t.Errorf("unexpected Vary headers: %v", vary)
+	}
+}
+
+func TestForceLocaleOverridesSubtree(t *testing.T) {
+	var got string
+	record := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = LocaleFromContext(r.Context())
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", ForceLocale("en")(record))
+	mux.Handle("/", record)
+	h := MiddlewareWithOptions(Options{})(mux)
+
+	for path, expected := range map[string]string{"/": "pl", "/admin/users": "en"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Language", "pl")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got != expected {
+			t.Errorf("%s: expected locale %q, got %q", path, expected, got)
+		}
+		if cl := rec.Header().Get("Content-Language"); cl != expected {
+			t.Errorf("%s: expected Content-Language %q, got %q", path, expected, cl)
+		}
+	}
+}
+
+func TestBundleHandler(t *testing.T) {
+	repo := &staticRepo{data: map[string]map[string]interface{}{
+		"pl": {"auth.login": "Zaloguj", "home.title": "Start", "__meta": map[string]string{"lang": "pl"}},
+	}}
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "pl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := BundleHandler(m)
+
+	req := httptest.NewRequest(http.MethodGet, "/i18n/pl.json?prefix=auth.", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != `{"auth.login":"Zaloguj"}` {
+		t.Errorf("unexpected bundle: %s", body)
+	}
+
+	etag := rec.Header().Get("ETag")
+	req = httptest.NewRequest(http.MethodGet, "/i18n/pl.json?prefix=auth.", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for matching ETag, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/i18n/xx.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown locale, got %d", rec.Code)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"checkout.title": "Checkout", "checkout.help": "Ask {-brand}", "home.title": "Home",
+			"__terms": map[string]string{"brand": "Acme"}},
+		"pl": {"checkout.title": "Kasa", "cart.items": &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} produkt", "other": "{n} produktów"}},
+			"__terms": map[string]string{"brand": "Akme"}},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := m.Subset("pl-PL", []string{"checkout.", "cart."})
+	want := map[string]string{
+		"checkout.title":    "Kasa",
+		"checkout.help":     "Ask Acme", // from en, with en's terms
+		"cart.items[one]":   "{n} produkt",
+		"cart.items[other]": "{n} produktów",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Subset = %v", got)
+	}
+	if got := m.Subset("en", nil); len(got) != 3 {
+		t.Errorf("Subset without prefixes = %v", got)
+	}
+}
+
+func TestSwitchLocaleHandler(t *testing.T) {
+	repo := &staticRepo{data: map[string]map[string]interface{}{"en": {}, "pl": {}}}
+	m, err := NewManagerWithRepo(repo, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := MiddlewareWithOptions(Options{Manager: m})(SwitchLocaleHandler("lang", "next"))
+
+	tests := []struct {
+		url      string
+		code     int
+		location string
+	}{
+		{"/locale?lang=pl&next=/pricing", http.StatusSeeOther, "/pricing"},
+		{"/locale?lang=pl&next=//evil.example", http.StatusSeeOther, "/"},
+		{"/locale?lang=pl&next=/%09/evil.example", http.StatusSeeOther, "/"},
+		{"/locale?lang=pl&next=/%0A/evil.example", http.StatusSeeOther, "/"},
+		{"/locale?lang=pl&next=/%5Cevil.example", http.StatusSeeOther, "/"},
+		{"/locale?lang=pl&next=/docs%3Fq%3Da%2Fb", http.StatusSeeOther, "/docs?q=a/b"},
+		{"/locale?lang=xx", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.code, rec.Code)
+			continue
+		}
+		if tt.location != "" {
+			if loc := rec.Header().Get("Location"); loc != tt.location {
+				t.Errorf("%s: expected redirect to %q, got %q", tt.url, tt.location, loc)
+			}
+			if c := rec.Result().Cookies(); len(c) != 1 || c[0].Value != "pl" {
+				t.Errorf("%s: expected lang cookie, got %v", tt.url, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package mbel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// i18next JSON mapping. Dotted keys nest as objects, {placeholders}
+// become {{placeholders}} and plural blocks use i18next's suffixes, their
+// argument renamed to count:
+//
+//	{"cart": {"items_one": "{{count}} item", "items_other": "{{count}} items"}}
+//
+// An exact [0] case maps to _zero and a block with only [other] is a
+// plain message. Blocks with other cases (select, ranges, other exact
+// numbers) have no i18next form and are dropped, as are metadata, terms
+// and AI annotations.
+
+// i18nextCount is the variable i18next selects plural forms with
+const i18nextCount = "count"
+
+// i18nextSuffixes are i18next's plural suffixes, in CLDR order
+var i18nextSuffixes = []string{"zero", "one", "two", "few", "many", "other"}
+
+var i18nextVarRe = regexp.MustCompile(`\{\{-?\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:,\s*([^}]*?))?\s*\}\}`)
+
+// WriteI18next encodes compiled data (one language) as i18next JSON
+func WriteI18next(w io.Writer, data map[string]interface{}) error {
+	root := make(map[string]interface{})
+	for _, key := range SortedKeys(data) {
+		switch v := data[key].(type) {
+		case string:
+			if err := i18nextSet(root, key, toI18next(v, "")); err != nil {
+				return err
+			}
+		case *RuntimeBlock:
+			if other, ok := v.Cases["other"]; ok && len(v.Cases) == 1 && len(v.RangeCases) == 0 {
+				// Always resolves to [other]: a plain message
+				if err := i18nextSet(root, key, toI18next(other, "")); err != nil {
+					return err
+				}
+				continue
+			}
+			forms, ok := i18nextForms(v)
+			if !ok {
+				continue
+			}
+			for suffix, text := range forms {
+				if err := i18nextSet(root, key+"_"+suffix, toI18next(text, v.Argument)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// i18nextForms maps a plural block's cases to i18next suffixes; ok is
+// false for blocks with cases i18next cannot express
+func i18nextForms(rb *RuntimeBlock) (forms map[string]string, ok bool) {
+	if len(rb.RangeCases) > 0 {
+		return nil, false
+	}
+	forms = make(map[string]string, len(rb.Cases))
+	for cond, text := range rb.Cases {
+		switch {
+		case cond == "0", cond == "zero":
+			// An exact [0] wins over the CLDR zero category
+			if _, set := forms["zero"]; !set || cond == "0" {
+				forms["zero"] = text
+			}
+		case containsString(i18nextSuffixes, cond):
+			forms[cond] = text
+		default:
+			return nil, false
+		}
+	}
+	_, ok = forms["other"]
+	return forms, ok
+}
+
+// i18nextSet stores value under the dotted key, nesting objects
+func i18nextSet(root map[string]interface{}, key string, value string) error {
+	parts := strings.Split(key, ".")
+	node := root
+	for i, p := range parts[:len(parts)-1] {
+		switch child := node[p].(type) {
+		case map[string]interface{}:
+			node = child
+		case nil:
+			next := make(map[string]interface{})
+			node[p] = next
+			node = next
+		default:
+			return fmt.Errorf("i18next: key %s is both a message and a namespace", strings.Join(parts[:i+1], "."))
+		}
+	}
+	last := parts[len(parts)-1]
+	if _, ok := node[last].(map[string]interface{}); ok {
+		return fmt.Errorf("i18next: key %s is both a message and a namespace", key)
+	}
+	node[last] = value
+	return nil
+}
+
+// toI18next rewrites {name} placeholders as {{name}}; the block argument
+// arg, if any, becomes {{count}}. Escaped braces become literal ones.
+func toI18next(s, arg string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range placeholderIndexes(argRe, s) {
+		b.WriteString(UnescapeBraces(s[last:loc[0]]))
+		name := s[loc[2]:loc[3]]
+		if name == arg {
+			name = i18nextCount
+		}
+		if loc[4] >= 0 {
+			b.WriteString("{{" + name + ", " + s[loc[4]:loc[5]] + "}}")
+		} else {
+			b.WriteString("{{" + name + "}}")
+		}
+		last = loc[1]
+	}
+	b.WriteString(UnescapeBraces(s[last:]))
+	return b.String()
+}
+
+// fromI18next rewrites {{name}} variables as {name}; formats other than
+// MBEL's date styles are dropped. Other braces are escaped.
+func fromI18next(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range i18nextVarRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(EscapeBraces(s[last:loc[0]]))
+		name, format := s[loc[2]:loc[3]], ""
+		if loc[4] >= 0 {
+			format = s[loc[4]:loc[5]]
+		}
+		switch format {
+		case "date", "time", "datetime":
+			b.WriteString("{" + name + ", " + format + "}")
+		default:
+			b.WriteString("{" + name + "}")
+		}
+		last = loc[1]
+	}
+	b.WriteString(EscapeBraces(s[last:]))
+	return b.String()
+}
+
+// ReadI18next decodes i18next JSON into compiled data. Nested objects
+// become dotted keys and keys with plural suffixes are folded into a
+// block on count when an _other form exists. Non-string values are
+// skipped.
+func ReadI18next(r io.Reader) (map[string]interface{}, error) {
+	var root map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("i18next: %w", err)
+	}
+
+	flat := make(map[string]string)
+	i18nextFlatten(flat, "", root)
+
+	data := make(map[string]interface{}, len(flat))
+	for key, text := range flat {
+		data[key] = fromI18next(text)
+	}
+	for key := range flat {
+		base, ok := strings.CutSuffix(key, "_other")
+		if !ok || strings.HasSuffix(base, "_ordinal") {
+			continue
+		}
+		rb := &RuntimeBlock{Argument: i18nextCount, Cases: make(map[string]string), RangeCases: []RangeCase{}}
+		for _, suffix := range i18nextSuffixes {
+			text, ok := flat[base+"_"+suffix]
+			if !ok {
+				continue
+			}
+			delete(data, base+"_"+suffix)
+			if suffix == "zero" {
+				suffix = "0"
+			}
+			rb.Cases[suffix] = fromI18next(text)
+		}
+		data[base] = rb
+	}
+
+	data["__schema"] = SchemaVersion
+	return data, nil
+}
+
+// i18nextFlatten collects the strings of a nested i18next object under
+// dotted keys
+func i18nextFlatten(dst map[string]string, prefix string, node map[string]interface{}) {
+	for k, v := range node {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case string:
+			dst[key] = v
+		case map[string]interface{}:
+			i18nextFlatten(dst, key, v)
+		}
+	}
+}
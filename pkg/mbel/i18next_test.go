@@ -0,0 +1,90 @@
+package mbel
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestI18nextRoundTrip(t *testing.T) {
+	src := `title = "Hello {name}"
+cart.empty = "Cart is <empty>"
+cart.items(n) {
+    [0] => "No items"
+    [one] => "{n} item"
+    [other] => "{n} items, due {d, date}"
+}
+greeting(gender) {
+    [male] => "Hi sir"
+    [other] => "Hi"
+}
+required(field) {
+    [other] => "{field} is required"
+}
+`
+	data, _, err := CompileSource([]byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteI18next(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "cart": {
+    "empty": "Cart is <empty>",
+    "items_one": "{{count}} item",
+    "items_other": "{{count}} items, due {{d, date}}",
+    "items_zero": "No items"
+  },
+  "required": "{{field}} is required",
+  "title": "Hello {{name}}"
+}
+`
+	if buf.String() != want {
+		t.Errorf("WriteI18next =\n%s", buf.String())
+	}
+
+	back, err := ReadI18next(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := &RuntimeBlock{Argument: "count", RangeCases: []RangeCase{}, Cases: map[string]string{
+		"0": "No items", "one": "{count} item", "other": "{count} items, due {d, date}",
+	}}
+	if !reflect.DeepEqual(back["cart.items"], items) || back["title"] != "Hello {name}" || back["greeting"] != nil {
+		t.Errorf("ReadI18next = %v", back)
+	}
+
+	// Suffixes only fold into a block next to an _other form
+	lone, err := ReadI18next(strings.NewReader(`{"user_one": "x", "n": 1, "a": {"b_other": "{{ count, number }}"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lone["user_one"] != "x" || lone["n"] != nil || !reflect.DeepEqual(lone["a.b"], &RuntimeBlock{Argument: "count", RangeCases: []RangeCase{}, Cases: map[string]string{"other": "{count}"}}) {
+		t.Errorf("ReadI18next = %v", lone)
+	}
+
+	if err := WriteI18next(&buf, map[string]interface{}{"a": "x", "a.b": "y"}); err == nil {
+		t.Error("message/namespace conflict not reported")
+	}
+}
+
+func TestFormatData(t *testing.T) {
+	data := map[string]interface{}{
+		"__meta": map[string]string{"lang": "pl"},
+		"title":  `Say "hi" now`,
+		"items":  &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} plik", "other": "{n} plików"}, RangeCases: []RangeCase{{2, 4, "{n} pliki"}}},
+	}
+	src := FormatData(data)
+	back, errs, err := CompileSource([]byte(src), nil)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("FormatData output does not compile: %v %v\n%s", err, errs, src)
+	}
+	delete(back, "__schema")
+	if !reflect.DeepEqual(back, data) {
+		t.Errorf("round trip = %v\n%s", back, src)
+	}
+}
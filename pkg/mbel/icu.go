@@ -0,0 +1,320 @@
+package mbel
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ICU MessageFormat values let teams paste existing messages into .mbel
+// files while migrating:
+//
+//	@syntax: icu
+//	cart = "You have {count, plural, =0 {no items} one {# item} other {# items}}"
+//
+// Without @syntax: icu, values are still compiled as ICU when they hold a
+// plural or select argument. One plural or select per message is
+// supported; the text around it is copied into every case.
+
+// syntaxMetaKey is the metadata choosing how values are read
+const syntaxMetaKey = "syntax"
+
+// errICUUnsupported marks valid ICU that MBEL blocks cannot express;
+// such values stay plain text even under @syntax: icu
+var errICUUnsupported = errors.New("not supported")
+
+var icuArgRe = regexp.MustCompile(`\{\s*[a-zA-Z_][a-zA-Z0-9_]*\s*,\s*(plural|select|selectordinal)\s*,`)
+
+// looksLikeICU reports whether s has an ICU plural or select argument
+func looksLikeICU(s string) bool {
+	return strings.IndexByte(s, ',') >= 0 && icuArgRe.MatchString(s)
+}
+
+// ParseICU compiles an ICU MessageFormat message into runtime form: a
+// string, or a *RuntimeBlock when it has a plural or select argument.
+// Simple arguments become placeholders ({n, number} -> {n}; date and
+// time keep their style) and # in plural cases becomes the plural
+// argument. Nested plurals/selects, selectordinal and offset are not
+// supported; they fail with an error the compiler treats as plain text.
+func ParseICU(msg string) (interface{}, error) {
+	p := &icuParser{src: msg}
+	before, sel, after, err := p.message("", true)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected '}'")
+	}
+	if sel == nil {
+		return before, nil
+	}
+
+	rb := &RuntimeBlock{Argument: sel.arg, Cases: make(map[string]string), RangeCases: []RangeCase{}}
+	for _, c := range sel.cases {
+		rb.Cases[c.selector] = before + c.text + after
+	}
+	return rb, nil
+}
+
+// icuSelect is a plural or select argument
+type icuSelect struct {
+	arg   string
+	cases []icuCase
+}
+
+type icuCase struct {
+	selector string // "=2" is stored as "2", the form of MBEL exact cases
+	text     string
+}
+
+type icuParser struct {
+	src string
+	pos int
+}
+
+func (p *icuParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("ICU message at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// unsupported reports a valid ICU feature MBEL has no equivalent for
+func (p *icuParser) unsupported(feature string) error {
+	return fmt.Errorf("ICU message at offset %d: %s is %w", p.pos, feature, errICUUnsupported)
+}
+
+// message reads text and arguments up to the end of the input or an
+// unmatched '}'. The plural or select argument, if any, splits it in
+// before and after; only a top-level message may have one. # stands for
+// pluralArg when it is set.
+func (p *icuParser) message(pluralArg string, top bool) (before string, sel *icuSelect, after string, err error) {
+	var b strings.Builder
+loop:
+	for p.pos < len(p.src) {
+		switch ch := p.src[p.pos]; {
+		case ch == '}':
+			break loop
+		case ch == '{':
+			simple, s, err := p.argument()
+			if err != nil {
+				return "", nil, "", err
+			}
+			if s == nil {
+				b.WriteString(simple)
+				continue
+			}
+			if !top {
+				return "", nil, "", p.unsupported("nested plural/select")
+			}
+			if sel != nil {
+				return "", nil, "", p.unsupported("more than one plural/select per message")
+			}
+			before, sel = b.String(), s
+			b.Reset()
+		case ch == '#' && pluralArg != "":
+			b.WriteString("{" + pluralArg + "}")
+			p.pos++
+		case ch == '\'':
+			p.quoted(&b, pluralArg != "")
+		default:
+			b.WriteByte(ch)
+			p.pos++
+		}
+	}
+	if sel == nil {
+		return b.String(), nil, "", nil
+	}
+	return before, sel, b.String(), nil
+}
+
+// quoted handles an apostrophe: a doubled one is a literal apostrophe, one
+// before a special char quotes text up to the next lone apostrophe
+func (p *icuParser) quoted(b *strings.Builder, inPlural bool) {
+	p.pos++
+	if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+		b.WriteByte('\'')
+		p.pos++
+		return
+	}
+	if p.pos >= len(p.src) || !strings.ContainsRune("{}|", rune(p.src[p.pos])) && !(inPlural && p.src[p.pos] == '#') {
+		b.WriteByte('\'')
+		return
+	}
+	for p.pos < len(p.src) {
+		if p.src[p.pos] == '\'' {
+			if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+				b.WriteByte('\'')
+				p.pos += 2
+				continue
+			}
+			p.pos++
+			return
+		}
+		if c := p.src[p.pos]; c == '{' || c == '}' {
+			b.WriteByte(c) // quoted braces are literal: {{ or }}
+		}
+		b.WriteByte(p.src[p.pos])
+		p.pos++
+	}
+}
+
+// argument reads a {...} argument: a simple one is returned as an MBEL
+// placeholder, a plural or select one as sel
+func (p *icuParser) argument() (simple string, sel *icuSelect, err error) {
+	p.pos++ // {
+	p.skipSpace()
+	name := p.ident()
+	if name == "" || !isLetter(name[0]) {
+		return "", nil, p.errorf("argument name must be an identifier")
+	}
+	p.skipSpace()
+	if p.consume('}') {
+		return "{" + name + "}", nil, nil
+	}
+	if !p.consume(',') {
+		return "", nil, p.errorf("expected ',' or '}' after %s", name)
+	}
+	p.skipSpace()
+	typ := p.ident()
+	p.skipSpace()
+
+	switch typ {
+	case "plural", "select":
+		if !p.consume(',') {
+			return "", nil, p.errorf("expected ',' after %s", typ)
+		}
+		pluralArg := ""
+		if typ == "plural" {
+			pluralArg = name
+		}
+		sel, err := p.cases(name, pluralArg)
+		return "", sel, err
+	case "selectordinal":
+		return "", nil, p.unsupported("selectordinal")
+	case "number", "date", "time", "spellout", "ordinal", "duration":
+		if p.consume(',') {
+			// The style (short, currency, ::skeleton) has no MBEL equivalent
+			for p.pos < len(p.src) && p.src[p.pos] != '}' {
+				p.pos++
+			}
+		}
+		if !p.consume('}') {
+			return "", nil, p.errorf("unterminated argument %s", name)
+		}
+		if typ == "date" || typ == "time" {
+			return "{" + name + ", " + typ + "}", nil, nil
+		}
+		return "{" + name + "}", nil, nil
+	default:
+		return "", nil, p.errorf("unknown argument type %q", typ)
+	}
+}
+
+// cases reads the selector {message} pairs of a plural or select
+// argument and its closing brace
+func (p *icuParser) cases(arg, pluralArg string) (*icuSelect, error) {
+	sel := &icuSelect{arg: arg}
+	hasOther := false
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated %s argument", arg)
+		}
+		if p.consume('}') {
+			break
+		}
+		if strings.HasPrefix(p.src[p.pos:], "offset:") {
+			p.pos += len("offset:")
+			p.skipSpace()
+			if n := p.ident(); n != "0" {
+				return nil, p.unsupported("plural offset")
+			}
+			continue
+		}
+
+		start := p.pos
+		for p.pos < len(p.src) && !isICUSpace(p.src[p.pos]) && p.src[p.pos] != '{' && p.src[p.pos] != '}' {
+			p.pos++
+		}
+		selector := p.src[start:p.pos]
+		if strings.HasPrefix(selector, "=") {
+			if _, err := strconv.Atoi(selector[1:]); err != nil {
+				return nil, p.errorf("invalid selector %q", selector)
+			}
+			selector = selector[1:]
+		}
+		if selector == "" {
+			return nil, p.errorf("missing selector in %s argument", arg)
+		}
+		hasOther = hasOther || selector == "other"
+
+		p.skipSpace()
+		if !p.consume('{') {
+			return nil, p.errorf("expected '{' after selector %q", selector)
+		}
+		text, _, _, err := p.message(pluralArg, false)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume('}') {
+			return nil, p.errorf("unterminated case %q", selector)
+		}
+		sel.cases = append(sel.cases, icuCase{selector: selector, text: text})
+	}
+	if !hasOther {
+		return nil, p.errorf("%s argument has no 'other' case", arg)
+	}
+	return sel, nil
+}
+
+func (p *icuParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *icuParser) consume(ch byte) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == ch {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *icuParser) skipSpace() {
+	for p.pos < len(p.src) && isICUSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isICUSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// ICURule warns about values that render as plain text, braces and all,
+// although they read as ICU messages: those using ICU features MBEL
+// blocks cannot express (offset, selectordinal, nested arguments), and in
+// files without @syntax: icu, those with a plural or select argument that
+// do not parse. Under @syntax: icu the latter fail to compile instead.
+func ICURule() LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		icuFile := Metadata(p)[syntaxMetaKey] == "icu"
+		var out []Diagnostic
+		walkKeyMeta(p, func(key string, a *AssignStatement, _ map[string]*MetadataStatement) {
+			sl, ok := a.Value.(*StringLiteral)
+			if !ok || !icuFile && !looksLikeICU(sl.Value) {
+				return
+			}
+			_, err := ParseICU(sl.Value)
+			switch {
+			case errors.Is(err, errICUUnsupported):
+				out = append(out, DiagnosticAt(sl.Token, SeverityWarning, fmt.Sprintf("%s: %v; it renders as plain text, rewrite it as MBEL blocks", key, err)))
+			case err != nil && !icuFile:
+				out = append(out, DiagnosticAt(sl.Token, SeverityWarning, fmt.Sprintf("%s looks like an ICU message but does not parse (%v); it renders as plain text", key, err)))
+			}
+		})
+		return out
+	}
+}
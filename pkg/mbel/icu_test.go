@@ -0,0 +1,125 @@
+package mbel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseICU(t *testing.T) {
+	for _, tc := range []struct {
+		msg  string
+		want interface{}
+	}{
+		{"Hello {name}, you have {n, number} points", "Hello {name}, you have {n} points"},
+		{"Sent {d, date, short} at {d, time}", "Sent {d, date} at {d, time}"},
+		{"It''s '{literal}' and don't", "It's {{literal}} and don't"},
+		{
+			"You have {count, plural, =0 {no items} one {# item} other {# items}} in {cart}",
+			&RuntimeBlock{Argument: "count", RangeCases: []RangeCase{}, Cases: map[string]string{
+				"0":     "You have no items in {cart}",
+				"one":   "You have {count} item in {cart}",
+				"other": "You have {count} items in {cart}",
+			}},
+		},
+		{
+			"{gender, select, male {He} female {She} other {They}} replied, #1",
+			&RuntimeBlock{Argument: "gender", RangeCases: []RangeCase{}, Cases: map[string]string{
+				"male": "He replied, #1", "female": "She replied, #1", "other": "They replied, #1",
+			}},
+		},
+	} {
+		got, err := ParseICU(tc.msg)
+		if err != nil {
+			t.Errorf("%q: %v", tc.msg, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q = %#v", tc.msg, got)
+		}
+	}
+
+	for msg, want := range map[string]string{
+		"{n, plural, one {#} other {{g, select, f {x} other {y}}}}": "nested",
+		"{a, select, x {1} other {2}} {b, select, x {1} other {2}}": "more than one",
+		"{n, plural, one {#}}":                      "no 'other'",
+		"{n, selectordinal, one {#st} other {#th}}": "selectordinal",
+		"{n, plural, offset:1 one {#} other {#}}":   "offset",
+		"{0} items":                     "identifier",
+		"{n, plural, one {#} other {#}": "unterminated",
+		"a } b":                         "unexpected",
+	} {
+		if _, err := ParseICU(msg); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error %v, want %q", msg, err, want)
+		}
+	}
+}
+
+func TestCompileICU(t *testing.T) {
+	src := `@lang: pl
+files = "{n, plural, one {# plik} few {# pliki} other {# plików}}"
+plain = "Witaj '{name}'"
+`
+	data, _, err := CompileSource([]byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRuntime(data)
+	if got := r.Get("files", 3); got != "3 pliki" {
+		t.Errorf("detected ICU plural = %q", got)
+	}
+	if data["plain"] != "Witaj '{name}'" {
+		t.Errorf("plain value without @syntax changed: %q", data["plain"])
+	}
+
+	data, _, err = CompileSource([]byte("@syntax: icu\n"+src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["plain"] != "Witaj {{name}}" {
+		t.Errorf("@syntax: icu value = %q", data["plain"])
+	}
+	if got := NewRuntime(data).Get("plain", Vars{"name": "Ala"}); got != "Witaj {name}" {
+		t.Errorf("quoted braces rendered as %q", got)
+	}
+
+	streamed := make(map[string]interface{})
+	if _, err := CompileStream(strings.NewReader("@syntax: icu\n"+src), func(key string, v interface{}) error {
+		streamed[key] = v
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if streamed["plain"] != data["plain"] || !reflect.DeepEqual(streamed["files"], data["files"]) {
+		t.Errorf("CompileStream = %v", streamed)
+	}
+
+	// Malformed ICU stays plain text with a lint warning, unless the file
+	// declares @syntax: icu
+	bad := `bad = "{n, plural, one {#}}"` + "\n"
+	data, _, err = CompileSource([]byte(bad), nil)
+	if err != nil || data["bad"] != "{n, plural, one {#}}" {
+		t.Errorf("malformed ICU without @syntax = %q, %v", data["bad"], err)
+	}
+	diags := ICURule()(NewParser(NewLexer(bad)).ParseProgram(), LintContext{})
+	if len(diags) != 1 || diags[0].Severity != SeverityWarning || !strings.Contains(diags[0].Message, "bad looks like an ICU message") {
+		t.Errorf("ICURule = %v", diags)
+	}
+	if _, _, err := CompileSource([]byte("@syntax: icu\n"+bad), nil); err == nil || !strings.Contains(err.Error(), "key bad") {
+		t.Errorf("malformed ICU under @syntax: icu: %v", err)
+	}
+	if diags := ICURule()(NewParser(NewLexer(src)).ParseProgram(), LintContext{}); len(diags) != 0 {
+		t.Errorf("ICURule on valid messages = %v", diags)
+	}
+
+	// Valid ICU that blocks cannot express never fails a load
+	offset := "@syntax: icu\nguests = \"{n, plural, offset:1 one {# guest} other {# guests}}\"\n"
+	data, _, err = CompileSource([]byte(offset), nil)
+	if err != nil || data["guests"] != "{n, plural, offset:1 one {# guest} other {# guests}}" {
+		t.Errorf("offset under @syntax: icu = %q, %v", data["guests"], err)
+	}
+	diags = ICURule()(NewParser(NewLexer(offset)).ParseProgram(), LintContext{})
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "plural offset is not supported") {
+		t.Errorf("ICURule on offset = %v", diags)
+	}
+}
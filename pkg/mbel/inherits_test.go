@@ -0,0 +1,54 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocaleInheritance(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"es/app.mbel":    "@audience: beta\npromo = \"Oferta\"\ntitle = \"Tienda\"\ncart = \"Carrito\"\n",
+		"es-MX/app.mbel": "@inherits: es\ncart = \"Carrito de compras\"\n",
+		"es-AR/app.mbel": "@inherits: \"es-MX\"\ntitle = \"Negocio\"\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := NewManager(dir, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ lang, key, want string }{
+		{"es-MX", "app.title", "Tienda"},
+		{"es-MX", "app.cart", "Carrito de compras"},
+		{"es-AR", "app.title", "Negocio"},
+		{"es-AR", "app.cart", "Carrito de compras"},
+		{"es-AR", "app.promo", "Oferta"},
+	} {
+		if got := m.Get(tc.lang, tc.key); got != tc.want {
+			t.Errorf("%s %s = %q, want %q", tc.lang, tc.key, got, tc.want)
+		}
+	}
+	// Materialized, not a runtime fallback: the keys are the locale's own
+	if keys := m.Keys("es-AR"); len(keys) != 3 {
+		t.Errorf("es-AR keys = %v", keys)
+	}
+	if got := m.Subset("es-MX", []string{"app"}); got["app.promo"] != "" {
+		t.Errorf("beta key inherited without its audience: %v", got)
+	}
+
+	_, err = ResolveInheritance(map[string]map[string]interface{}{
+		"a": {"__meta": map[string]string{"inherits": "b"}},
+		"b": {"__meta": map[string]string{"inherits": "a"}},
+		"c": {"__meta": map[string]string{"inherits": "zz"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "cycle") || !strings.Contains(err.Error(), "c inherits unknown locale zz") {
+		t.Errorf("err = %v", err)
+	}
+}
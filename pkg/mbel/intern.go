@@ -0,0 +1,56 @@
+package mbel
+
+// interner hands out one shared copy of equal strings. Large catalogs
+// repeat the same keys in every locale, and often the same values (brand
+// names, "OK", "{n} items" in locales that keep the source text).
+type interner map[string]string
+
+func (in interner) intern(s string) string {
+	if v, ok := in[s]; ok {
+		return v
+	}
+	in[s] = s
+	return s
+}
+
+// internCatalog returns langData with equal keys sharing memory across
+// locales and, with values, equal strings and logic block cases too.
+// Blocks are copied rather than changed, since repositories may cache
+// and reuse them.
+func internCatalog(langData map[string]map[string]interface{}, values bool) map[string]map[string]interface{} {
+	in := make(interner)
+	out := make(map[string]map[string]interface{}, len(langData))
+	for lang, data := range langData {
+		d := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			if values {
+				v = in.value(v)
+			}
+			d[in.intern(k)] = v
+		}
+		out[lang] = d
+	}
+	return out
+}
+
+// value returns v with its strings interned
+func (in interner) value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return in.intern(val)
+	case *RuntimeBlock:
+		rb := &RuntimeBlock{Argument: in.intern(val.Argument)}
+		if val.Cases != nil {
+			rb.Cases = make(map[string]string, len(val.Cases))
+			for cond, text := range val.Cases {
+				rb.Cases[in.intern(cond)] = in.intern(text)
+			}
+		}
+		for _, rc := range val.RangeCases {
+			rc.Value = in.intern(rc.Value)
+			rb.RangeCases = append(rb.RangeCases, rc)
+		}
+		return rb
+	}
+	return v
+}
@@ -0,0 +1,43 @@
+package mbel
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestInternCatalog(t *testing.T) {
+	// Build equal strings in separate allocations, as compiling each file does
+	fresh := func(s string) string { return strings.Clone(s) }
+	block := &RuntimeBlock{Argument: "n", Cases: map[string]string{"other": fresh("{n} items")}}
+	langData := map[string]map[string]interface{}{
+		"en":    {fresh("shop.title"): fresh("Acme"), fresh("shop.items"): block},
+		"en-GB": {fresh("shop.title"): fresh("Acme"), fresh("shop.total"): fresh("{n} items")},
+	}
+	same := func(a, b string) bool { return unsafe.StringData(a) == unsafe.StringData(b) }
+	keyOf := func(data map[string]interface{}, key string) string {
+		for k := range data {
+			if k == key {
+				return k
+			}
+		}
+		t.Fatalf("no key %s", key)
+		return ""
+	}
+
+	keysOnly := internCatalog(langData, false)
+	if !same(keyOf(keysOnly["en"], "shop.title"), keyOf(keysOnly["en-GB"], "shop.title")) {
+		t.Error("keys are not shared across locales")
+	}
+	if same(keysOnly["en"]["shop.title"].(string), keysOnly["en-GB"]["shop.title"].(string)) {
+		t.Error("values interned without InternValues")
+	}
+
+	all := internCatalog(langData, true)
+	if !same(all["en"]["shop.title"].(string), all["en-GB"]["shop.title"].(string)) {
+		t.Error("equal values are not shared")
+	}
+	if rb := all["en"]["shop.items"].(*RuntimeBlock); rb == block || !same(rb.Cases["other"], all["en-GB"]["shop.total"].(string)) {
+		t.Error("block cases are not shared, or the block was not copied")
+	}
+}
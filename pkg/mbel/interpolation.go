@@ -0,0 +1,187 @@
+package mbel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Interpolation styles let teams keep the placeholder delimiters their
+// copy already uses:
+//
+//	@interpolation: double
+//	greeting = "Hello {{name}}, welcome to {{-brand}}"
+//
+// The compiler rewrites every style to the canonical {name}, so compiled
+// data, the runtime and every tool reading it are unaffected. In the
+// double and percent styles every other brace is literal text; in the
+// percent style "%%{" is a literal "%{".
+
+// Interpolation is a placeholder delimiter style
+type Interpolation string
+
+const (
+	InterpolationSingle  Interpolation = "single"  // {name} (the default)
+	InterpolationDouble  Interpolation = "double"  // {{name}}
+	InterpolationPercent Interpolation = "percent" // %{name}
+)
+
+// interpolationMetaKey is the metadata choosing a file's style
+const interpolationMetaKey = "interpolation"
+
+var (
+	// styledPlaceholderRe matches the inside of a placeholder or term
+	// reference, without its delimiters
+	styledPlaceholderRe = regexp.MustCompile(`^(?:-[a-zA-Z_][a-zA-Z0-9_-]*|[a-zA-Z_][a-zA-Z0-9_]*(?:,\s*(?:date|time|datetime))?)`)
+
+	// literalPlaceholderRe matches, in canonical text, escaped braces
+	// around what would otherwise be a placeholder
+	literalPlaceholderRe = regexp.MustCompile(`\{\{(-[a-zA-Z_][a-zA-Z0-9_-]*|[a-zA-Z_][a-zA-Z0-9_]*(?:,\s*(?:date|time|datetime))?)\}\}`)
+)
+
+// ParseInterpolation parses a style name ("" is the default, single)
+func ParseInterpolation(s string) (Interpolation, error) {
+	switch style := Interpolation(s); style {
+	case "":
+		return InterpolationSingle, nil
+	case InterpolationSingle, InterpolationDouble, InterpolationPercent:
+		return style, nil
+	}
+	return "", fmt.Errorf("unknown interpolation style %q (want single, double or percent)", s)
+}
+
+// interpolationOf returns the style of a file with the given metadata;
+// def ("" = single) when it has no @interpolation line
+func interpolationOf(meta map[string]string, def Interpolation) (Interpolation, error) {
+	v, ok := meta[interpolationMetaKey]
+	if !ok {
+		return ParseInterpolation(string(def))
+	}
+	return ParseInterpolation(v)
+}
+
+// delimiters returns the opening and closing delimiters of style
+func (style Interpolation) delimiters() (open, close string) {
+	switch style {
+	case InterpolationDouble:
+		return "{{", "}}"
+	case InterpolationPercent:
+		return "%{", "}"
+	}
+	return "{", "}"
+}
+
+// Canonical rewrites text written in style to the canonical single style:
+// "Hi {{name}} {x}" (double) becomes "Hi {name} {{x}}"
+func (style Interpolation) Canonical(s string) string {
+	if style == InterpolationSingle || style == "" || !strings.ContainsAny(s, "{}") {
+		return s
+	}
+	open, close := style.delimiters()
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if style == InterpolationPercent && strings.HasPrefix(s[i:], "%%{") {
+			b.WriteString("%{{")
+			i += 3
+			continue
+		}
+		if strings.HasPrefix(s[i:], open) {
+			rest := s[i+len(open):]
+			if loc := styledPlaceholderRe.FindStringIndex(rest); loc != nil && strings.HasPrefix(rest[loc[1]:], close) {
+				b.WriteString("{" + rest[:loc[1]] + "}")
+				i += len(open) + loc[1] + len(close)
+				continue
+			}
+		}
+		switch s[i] {
+		case '{':
+			b.WriteString("{{")
+		case '}':
+			b.WriteString("}}")
+		default:
+			b.WriteByte(s[i])
+		}
+		i++
+	}
+	return b.String()
+}
+
+// canonicalValue applies Canonical to a string or every case of a block
+func (style Interpolation) canonicalValue(v interface{}) interface{} {
+	if style == InterpolationSingle || style == "" {
+		return v
+	}
+	return mapMessages(v, style.Canonical)
+}
+
+// mapMessages applies f to a string, or in place to every case of a block
+func mapMessages(v interface{}, f func(string) string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return f(val)
+	case *RuntimeBlock:
+		for c, text := range val.Cases {
+			val.Cases[c] = f(text)
+		}
+		for i := range val.RangeCases {
+			val.RangeCases[i].Value = f(val.RangeCases[i].Value)
+		}
+	}
+	return v
+}
+
+// InterpolationRule reports unknown @interpolation styles, and text that
+// reads like a placeholder but renders literally in the file's style:
+// "{name}" under double, or "{{name}}" under single (the usual collision
+// with copy written for another framework)
+func InterpolationRule() LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		style, err := ParseInterpolation(string(ctx.Interpolation))
+		if err != nil {
+			style = InterpolationSingle
+		}
+		for _, stmt := range p.Statements {
+			if ms, ok := stmt.(*MetadataStatement); ok && ms.Key == interpolationMetaKey {
+				s, err := ParseInterpolation(ms.Value)
+				if err != nil {
+					return append(out, DiagnosticAt(ms.ValueToken, SeverityError, err.Error()))
+				}
+				style = s
+			}
+		}
+		if Metadata(p)[syntaxMetaKey] == "icu" {
+			return out
+		}
+
+		check := func(tok Token, text string) {
+			if looksLikeICU(text) {
+				return
+			}
+			canon := style.Canonical(text)
+			for _, loc := range literalPlaceholderRe.FindAllStringSubmatchIndex(canon, -1) {
+				if style == InterpolationPercent && loc[0] > 0 && canon[loc[0]-1] == '%' {
+					continue // %%{name}, an escaped placeholder
+				}
+				open, close := style.delimiters()
+				name := canon[loc[2]:loc[3]]
+				msg := fmt.Sprintf("{%s} renders as text (@interpolation: %s); write %s%s%s for a placeholder", name, style, open, name, close)
+				if style == InterpolationSingle {
+					msg = fmt.Sprintf("{{%s}} renders as text {%s}; write {%s} for a placeholder, or set @interpolation: double", name, name, name)
+				}
+				out = append(out, DiagnosticAt(tok, SeverityWarning, msg))
+			}
+		}
+		walkKeyMeta(p, func(key string, a *AssignStatement, _ map[string]*MetadataStatement) {
+			switch v := a.Value.(type) {
+			case *StringLiteral:
+				check(v.Token, v.Value)
+			case *BlockExpression:
+				for _, bc := range v.Cases {
+					check(bc.ValueToken, bc.Value)
+				}
+			}
+		})
+		return out
+	}
+}
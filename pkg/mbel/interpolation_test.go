@@ -0,0 +1,134 @@
+package mbel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolationCanonical(t *testing.T) {
+	tests := []struct {
+		style Interpolation
+		in    string
+		want  string
+	}{
+		{InterpolationSingle, "Hi {name} {{x}}", "Hi {name} {{x}}"},
+		{InterpolationDouble, "Hi {{name}}, {x} { y }", "Hi {name}, {{x}} {{ y }}"},
+		{InterpolationDouble, "Sent {{d, date}} via {{-brand}}", "Sent {d, date} via {-brand}"},
+		{InterpolationPercent, "Hi %{name}, 50%{pct} {x}", "Hi {name}, 50{pct} {{x}}"},
+		{InterpolationPercent, "Use %%{name} literally", "Use %{{name}} literally"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.Canonical(tt.in); got != tt.want {
+			t.Errorf("%s.Canonical(%q) = %q, want %q", tt.style, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolationCompile(t *testing.T) {
+	src := `@interpolation: double
+greeting = "Hello {{name}}, {{-brand}} says {hi}"
+items(n) {
+    [one] => "{{n}} item"
+    [other] => "{{n}} items"
+}
+`
+	data, errs, err := CompileSource([]byte(src), nil)
+	if err != nil || len(errs) > 0 {
+		t.Fatal(errs, err)
+	}
+	data["__terms"] = map[string]string{"brand": "Acme"}
+	rt := NewRuntime(data)
+	if got := rt.Get("greeting", Vars{"name": "Ann"}); got != "Hello Ann, Acme says {hi}" {
+		t.Errorf("greeting = %q", got)
+	}
+	if got := rt.Get("items", 2); got != "2 items" {
+		t.Errorf("items = %q", got)
+	}
+
+	data, _, err = CompileSourceWithStyle([]byte(`total = "Total: %{amount} {EUR}"`), nil, InterpolationPercent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewRuntime(data).Get("total", Vars{"amount": 5}); got != "Total: 5 {EUR}" {
+		t.Errorf("total with percent default = %q", got)
+	}
+
+	_, _, err = CompileSource([]byte("@interpolation: angle\nk = \"v\"\n"), nil)
+	if !errors.Is(err, ErrCompile) {
+		t.Errorf("unknown style: err = %v, want ErrCompile", err)
+	}
+}
+
+func TestInterpolationFormatKeepsText(t *testing.T) {
+	src := "@interpolation: double\nk = \"Hi {{name}} { x }\"\n"
+	out, err := FormatSource(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != src {
+		t.Errorf("FormatSource rewrote double-style text:\n%s", out)
+	}
+}
+
+func TestInterpolationRule(t *testing.T) {
+	lint := func(src string) []Diagnostic {
+		p := NewParser(NewLexer(src))
+		program := p.ParseProgram()
+		if errs := p.Errors(); len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		return InterpolationRule()(program, LintContext{})
+	}
+
+	d := lint("@interpolation: double\nk = \"Hi {{name}}, {user}\"\n")
+	if len(d) != 1 || !strings.Contains(d[0].Message, "write {{user}}") || d[0].Severity != SeverityWarning {
+		t.Errorf("double: %+v", d)
+	}
+	d = lint("k = \"Hi {{name}}\"\n")
+	if len(d) != 1 || !strings.Contains(d[0].Message, "@interpolation: double") {
+		t.Errorf("single: %+v", d)
+	}
+	if d = lint("@interpolation: percent\nk = \"%{a} and %%{b}\"\n"); len(d) != 0 {
+		t.Errorf("percent: %+v", d)
+	}
+	if d = lint("@interpolation: angle\nk = \"v\"\n"); len(d) != 1 || d[0].Severity != SeverityError {
+		t.Errorf("unknown style: %+v", d)
+	}
+}
+
+func TestInterpolationPerManager(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "en"), 0755)
+	os.WriteFile(filepath.Join(dir, "en", "app.mbel"), []byte(`hi = "Hi {{name}} {name}"`+"\n"), 0644)
+
+	single, err := NewManager(dir, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	double, err := NewManager(dir, Config{Interpolation: InterpolationDouble})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := Vars{"name": "Ann"}
+	if got := single.Get("en", "app.hi", vars); got != "Hi {name} Ann" {
+		t.Errorf("single = %q", got)
+	}
+	if got := double.Get("en", "app.hi", vars); got != "Hi Ann {name}" {
+		t.Errorf("double = %q", got)
+	}
+	if _, err := NewManager(dir, Config{Interpolation: "angle"}); err == nil {
+		t.Error("unknown style accepted")
+	}
+
+	// Unchanged files are recompiled when the style changes
+	repo := &FileRepository{RootPath: dir}
+	data, _ := repo.LoadAll()
+	repo.Interpolation = InterpolationDouble
+	again, _ := repo.LoadAll()
+	if data["en"]["app.hi"] == again["en"]["app.hi"] {
+		t.Errorf("style change served the cached compile: %q", again["en"]["app.hi"])
+	}
+}
@@ -0,0 +1,138 @@
+package mbel
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// KeyFilter selects the keys of a slice of a shared catalog by full key
+// (folder namespace included), with path.Match patterns where * also
+// spans dots: "auth.*", "checkout.*".
+type KeyFilter struct {
+	Include []string // keys must match one of these (empty = every key)
+	Exclude []string // keys matching one of these are left out
+}
+
+// ParseKeyFilter builds a KeyFilter from comma-separated pattern lists,
+// as given to the -include and -exclude flags
+func ParseKeyFilter(include, exclude string) (KeyFilter, error) {
+	var f KeyFilter
+	var err error
+	if f.Include, err = splitPatterns(include); err != nil {
+		return KeyFilter{}, err
+	}
+	if f.Exclude, err = splitPatterns(exclude); err != nil {
+		return KeyFilter{}, err
+	}
+	return f, nil
+}
+
+// splitPatterns splits a comma-separated list and validates each pattern
+func splitPatterns(s string) ([]string, error) {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q", p)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Empty reports whether the filter keeps every key
+func (f KeyFilter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match reports whether key is selected
+func (f KeyFilter) Match(key string) bool {
+	return (len(f.Include) == 0 || allowed(key, f.Include)) && !allowed(key, f.Exclude)
+}
+
+// Apply returns the selected keys of compiled data. Internal "__"
+// entries are kept; per-key schedules and audiences only for selected
+// keys.
+func (f KeyFilter) Apply(data map[string]interface{}) map[string]interface{} {
+	if f.Empty() {
+		return data
+	}
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		switch {
+		case k == scheduleDataKey || k == audienceDataKey:
+			if m, ok := v.(map[string]string); ok {
+				v = f.perKey(m)
+			}
+			out[k] = v
+		case strings.HasPrefix(k, "__") || f.Match(k):
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// perKey returns the entries of a per-key metadata map ("key" or
+// "key@field") whose key is selected
+func (f KeyFilter) perKey(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		key := k
+		if i := strings.LastIndexByte(k, '@'); i >= 0 {
+			key = k[:i]
+		}
+		if f.Match(key) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Program returns a copy of p without the assignments of unselected keys,
+// their per-key metadata lines and their AI annotations, so lint rules
+// only see the selected slice. Keys are matched under namespace.
+func (f KeyFilter) Program(p *Program, namespace string) *Program {
+	if f.Empty() {
+		return p
+	}
+	out := *p
+	out.Statements = nil
+	kept := make(map[string]bool)
+	var pending []Statement
+	section := ""
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *MetadataStatement:
+			if keyMetaKeys[s.Key] {
+				pending = append(pending, s)
+				continue
+			}
+		case *SectionStatement:
+			section = s.Name
+		case *AssignStatement:
+			key := joinKey(section, s.Name)
+			if f.Match(joinKey(namespace, key)) {
+				out.Statements = append(out.Statements, pending...)
+				out.Statements = append(out.Statements, s)
+				kept[s.Name] = true
+			}
+			pending = nil
+			continue
+		}
+		out.Statements = append(out.Statements, pending...)
+		out.Statements = append(out.Statements, stmt)
+		pending = nil
+	}
+	out.Statements = append(out.Statements, pending...)
+
+	out.AIAnnotations = nil
+	for _, ann := range p.AIAnnotations {
+		if ann.ForKey == "" || kept[ann.ForKey] {
+			out.AIAnnotations = append(out.AIAnnotations, ann)
+		}
+	}
+	return &out
+}
@@ -0,0 +1,60 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyFilter(t *testing.T) {
+	f, err := ParseKeyFilter("auth.*, checkout.*", "checkout.debug.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{
+		"auth.login":           true,
+		"checkout.pay":         true,
+		"checkout.debug.order": false,
+		"home.title":           false,
+	} {
+		if got := f.Match(key); got != want {
+			t.Errorf("Match(%q) = %v", key, got)
+		}
+	}
+
+	data := map[string]interface{}{
+		"auth.login":           "Log in",
+		"checkout.debug.order": "Order",
+		"home.title":           "Home",
+		"__meta":               map[string]string{"lang": "en"},
+		audienceDataKey:        map[string]string{"auth.login": "beta", "home.title": "internal"},
+		scheduleDataKey:        map[string]string{"home.title@valid_until": "2025-01-01"},
+	}
+	want := map[string]interface{}{
+		"auth.login":    "Log in",
+		"__meta":        map[string]string{"lang": "en"},
+		audienceDataKey: map[string]string{"auth.login": "beta"},
+		scheduleDataKey: map[string]string{},
+	}
+	if got := f.Apply(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply = %v", got)
+	}
+
+	if _, err := ParseKeyFilter("[", ""); err == nil {
+		t.Error("ParseKeyFilter accepted a malformed pattern")
+	}
+}
+
+func TestKeyFilterProgram(t *testing.T) {
+	src := "@lang: en\n[auth]\n@status: bogus\n# AI_Context: Login button\nlogin = \"Log in\"\n[internal]\n@status: bogus\n# AI_Screenshot: not a url\ndebug = \"x\"\n"
+	p := NewParser(NewLexer(src)).ParseProgram()
+
+	f := KeyFilter{Exclude: []string{"app.internal.*"}}
+	sliced := f.Program(p, "app")
+	diags := append(KeyStatusRule("")(sliced, LintContext{}), ContextURLRule()(sliced, LintContext{})...)
+	if len(diags) != 1 || diags[0].Line != 3 {
+		t.Errorf("diagnostics = %v", diags)
+	}
+	if len(p.Statements) != 7 {
+		t.Errorf("Program modified its input: %d statements", len(p.Statements))
+	}
+}
@@ -0,0 +1,42 @@
+package mbel
+
+// KeyHash returns a short, stable hash of the template Get would render
+// for key in lang: the message (every case of a logic block) of the
+// locale serving it, with term references filled in. It changes exactly
+// when the copy readers see changes, so frontends and e-mail systems can
+// use it as a cache key without diffing bundles. ok is false when no
+// candidate locale has the key.
+func (m *Manager) KeyHash(lang, key string) (hash string, ok bool) {
+	cat := m.state.Load()
+	var buf [3]string
+	for _, l := range m.candidates(buf[:0], lang) {
+		r, ok := m.runtimeIn(cat, l)
+		if !ok {
+			continue
+		}
+		if val, ok := r.value(key); ok {
+			return ContentHash(r.resolvedTemplate(val)), true
+		}
+	}
+	return "", false
+}
+
+// resolvedTemplate returns val with the term references of its messages
+// substituted
+func (r *Runtime) resolvedTemplate(val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		return r.template(v).source
+	case *RuntimeBlock:
+		out := &RuntimeBlock{Argument: v.Argument, Cases: make(map[string]string, len(v.Cases))}
+		for cond, s := range v.Cases {
+			out.Cases[cond] = r.template(s).source
+		}
+		for _, rc := range v.RangeCases {
+			rc.Value = r.template(rc.Value).source
+			out.RangeCases = append(out.RangeCases, rc)
+		}
+		return out
+	}
+	return val
+}
@@ -0,0 +1,62 @@
+package mbel
+
+import "testing"
+
+func TestKeyHash(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {
+			"welcome": "Welcome to {-brand}, {name}",
+			"items":   &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} item", "other": "{n} items"}},
+			"__terms": map[string]string{"brand": "Acme"},
+		},
+		"pl": {"welcome": "Witaj w {-brand}, {name}", "__terms": map[string]string{"brand": "Acme"}},
+	})
+	m, err := NewManagerWithRepo(repo, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	en, ok := m.KeyHash("en", "welcome")
+	if !ok || en == "" {
+		t.Fatal("no hash for en welcome")
+	}
+	if again, _ := m.KeyHash("en", "welcome"); again != en {
+		t.Error("hash is not stable")
+	}
+	if pl, _ := m.KeyHash("pl", "welcome"); pl == en {
+		t.Error("pl and en copy share a hash")
+	}
+	if fallback, _ := m.KeyHash("pl", "items"); fallback != mustKeyHash(t, m, "en", "items") {
+		t.Error("fallback key should hash the serving locale's copy")
+	}
+	if _, ok := m.KeyHash("en", "missing"); ok {
+		t.Error("missing key has a hash")
+	}
+
+	// A term change changes what readers see, so it changes the hash
+	repo.Set("en", "__terms", map[string]string{"brand": "Globex"})
+	if err := m.Load(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustKeyHash(t, m, "en", "welcome"); got == en {
+		t.Error("hash did not change with the brand term")
+	}
+
+	before := mustKeyHash(t, m, "en", "items")
+	repo.Set("en", "items", &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} item", "other": "{n} things"}})
+	if err := m.Load(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if mustKeyHash(t, m, "en", "items") == before {
+		t.Error("hash did not change with a block case")
+	}
+}
+
+func mustKeyHash(t *testing.T, m *Manager, lang, key string) string {
+	t.Helper()
+	h, ok := m.KeyHash(lang, key)
+	if !ok {
+		t.Fatalf("no hash for %s %s", lang, key)
+	}
+	return h
+}
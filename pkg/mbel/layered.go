@@ -0,0 +1,201 @@
+package mbel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Expiring is a value of an override layer that stops applying at
+// Expires. A remote layer (database, Redis) returns it from LoadAll for
+// emergency copy hotfixes, so a forgotten override falls back to the
+// shipped bundle by itself. Only LayeredRepository understands it.
+type Expiring struct {
+	Value   interface{} // string or *RuntimeBlock
+	Expires time.Time
+}
+
+// LayeredRepository merges repositories key by key, later layers
+// overriding earlier ones, e.g. the shipped files under a database of
+// hotfixes:
+//
+//	repo := mbel.NewLayeredRepository(files, overrides)
+//	m, _ := mbel.NewManagerWithRepo(repo, mbel.Config{Watch: true})
+//
+// Expiring values are dropped once expired, uncovering the layers below.
+// It implements ChangeNotifier: a watching manager reloads when a layer
+// announces a change and when the next override expires.
+type LayeredRepository struct {
+	Layers []Repository
+
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
+
+	mu     sync.Mutex
+	next   time.Time // earliest expiry of the last load, zero if none
+	rearms []chan struct{}
+}
+
+// A reload failing after an expiry is retried after expiryRetryMin,
+// doubling up to expiryRetryMax
+const (
+	expiryRetryMin = 500 * time.Millisecond
+	expiryRetryMax = time.Minute
+)
+
+// NewLayeredRepository layers the repositories, base first
+func NewLayeredRepository(layers ...Repository) *LayeredRepository {
+	return &LayeredRepository{Layers: layers}
+}
+
+// LoadAll merges all layers
+func (r *LayeredRepository) LoadAll() (map[string]map[string]interface{}, error) {
+	return r.LoadAllContext(context.Background())
+}
+
+// LoadAllContext merges all layers, loading those implementing
+// ContextRepository with ctx
+func (r *LayeredRepository) LoadAllContext(ctx context.Context) (map[string]map[string]interface{}, error) {
+	now := r.now()
+	var next time.Time
+	out := make(map[string]map[string]interface{})
+
+	for _, layer := range r.Layers {
+		var (
+			data map[string]map[string]interface{}
+			err  error
+		)
+		if cr, ok := layer.(ContextRepository); ok {
+			data, err = cr.LoadAllContext(ctx)
+		} else {
+			data, err = layer.LoadAll()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for lang, entries := range data {
+			merged, ok := out[lang]
+			if !ok {
+				merged = make(map[string]interface{}, len(entries))
+				out[lang] = merged
+			}
+			for key, val := range entries {
+				if e, ok := val.(Expiring); ok {
+					if !now.Before(e.Expires) {
+						continue
+					}
+					if next.IsZero() || e.Expires.Before(next) {
+						next = e.Expires
+					}
+					val = e.Value
+				}
+				merged[key] = val
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.next = next
+	for _, ch := range r.rearms {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	r.mu.Unlock()
+	return out, nil
+}
+
+// Changes notifies on every change of a layer implementing
+// ChangeNotifier and when the earliest override of the last load
+// expires, until ctx is done. If the reload after an expiry fails, it
+// notifies again with a growing delay until a load succeeds.
+func (r *LayeredRepository) Changes(ctx context.Context) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case out <- struct{}{}:
+		default:
+		}
+	}
+
+	for _, layer := range r.Layers {
+		n, ok := layer.(ChangeNotifier)
+		if !ok {
+			continue
+		}
+		changes := n.Changes(ctx)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changes:
+					notify()
+				}
+			}
+		}()
+	}
+
+	rearm := make(chan struct{}, 1)
+	r.mu.Lock()
+	r.rearms = append(r.rearms, rearm)
+	r.mu.Unlock()
+
+	go func() {
+		defer r.removeRearm(rearm)
+		timer := time.NewTimer(0)
+		timer.Stop()
+		defer timer.Stop()
+		retry := expiryRetryMin
+		for {
+			r.mu.Lock()
+			next := r.next
+			r.mu.Unlock()
+			if !next.IsZero() {
+				timer.Reset(max(next.Sub(r.now()), 0))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				notify()
+				// Wait for the reload to set the next expiry. A failed
+				// reload sets nothing: notify again, backing off, until
+				// one succeeds.
+				select {
+				case <-ctx.Done():
+					return
+				case <-rearm:
+					retry = expiryRetryMin
+				case <-time.After(retry):
+					retry = min(2*retry, expiryRetryMax)
+				}
+			case <-rearm:
+				timer.Stop()
+				retry = expiryRetryMin
+			}
+		}
+	}()
+	return out
+}
+
+func (r *LayeredRepository) removeRearm(ch chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, c := range r.rearms {
+		if c == ch {
+			r.rearms = append(r.rearms[:i], r.rearms[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *LayeredRepository) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
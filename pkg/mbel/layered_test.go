@@ -0,0 +1,115 @@
+package mbel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLayeredRepositoryExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	base := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello", "bye": "Bye"},
+	})
+	overrides := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {
+			"title": Expiring{Value: "Hotfix", Expires: now.Add(time.Hour)},
+			"bye":   "Goodbye",
+		},
+		"pl": {"title": Expiring{Value: "Poprawka", Expires: now.Add(-time.Second)}},
+	})
+	repo := NewLayeredRepository(base, overrides)
+	repo.Now = func() time.Time { return now }
+
+	data, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["en"]["title"] != "Hotfix" || data["en"]["bye"] != "Goodbye" {
+		t.Errorf("overrides not applied: %v", data["en"])
+	}
+	if _, ok := data["pl"]["title"]; ok {
+		t.Errorf("expired override applied: %v", data["pl"])
+	}
+
+	now = now.Add(time.Hour)
+	data, _ = repo.LoadAll()
+	if data["en"]["title"] != "Hello" || data["en"]["bye"] != "Goodbye" {
+		t.Errorf("expired override should uncover the base: %v", data["en"])
+	}
+}
+
+func TestLayeredRepositoryWatchExpires(t *testing.T) {
+	base := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello"},
+	})
+	overrides := NewMemoryRepository(nil)
+	repo := NewLayeredRepository(base, overrides)
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en", Watch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for m.Get("en", "title") != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("title = %q, want %q", m.Get("en", "title"), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	overrides.Set("en", "title", Expiring{Value: "Hotfix", Expires: time.Now().Add(300 * time.Millisecond)})
+	waitFor("Hotfix")
+	waitFor("Hello")
+
+	// A later hotfix arms the timer again
+	overrides.Set("en", "title", Expiring{Value: "Hotfix 2", Expires: time.Now().Add(300 * time.Millisecond)})
+	waitFor("Hotfix 2")
+	waitFor("Hello")
+}
+
+// flakyRepository fails the next fail loads
+type flakyRepository struct {
+	Repository
+	fail atomic.Int32
+}
+
+func (r *flakyRepository) LoadAll() (map[string]map[string]interface{}, error) {
+	if r.fail.Add(-1) >= 0 {
+		return nil, errors.New("layer unavailable")
+	}
+	return r.Repository.LoadAll()
+}
+
+func TestLayeredRepositoryRetriesFailedExpiry(t *testing.T) {
+	base := &flakyRepository{Repository: NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello"},
+	})}
+	overrides := NewMemoryRepository(nil)
+	m, err := NewManagerWithRepo(NewLayeredRepository(base, overrides), Config{DefaultLocale: "en", Watch: true, OnReloadError: func(error) {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	waitFor := func(want string, within time.Duration) {
+		t.Helper()
+		deadline := time.Now().Add(within)
+		for m.Get("en", "title") != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("title = %q, want %q", m.Get("en", "title"), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	overrides.Set("en", "title", Expiring{Value: "Hotfix", Expires: time.Now().Add(200 * time.Millisecond)})
+	waitFor("Hotfix", 2*time.Second)
+	base.fail.Store(1) // the reload at expiry fails
+	waitFor("Hello", 2*time.Second)
+}
@@ -0,0 +1,113 @@
+package mbel
+
+import (
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// LengthOverrun is a translation longer than its AI_MaxLength budget
+type LengthOverrun struct {
+	Lang   string
+	Key    string
+	Length int // characters; the longest case for logic blocks
+	Budget int
+	Source SourceLocation
+}
+
+// PercentOver returns how far the translation exceeds its budget, in percent
+func (o LengthOverrun) PercentOver() int {
+	return (o.Length - o.Budget) * 100 / o.Budget
+}
+
+// CheckLengths reports every value in langData longer than its
+// AI_MaxLength budget, grouped by locale and worst first. Budgets are
+// read from the annotated source files found through loc (usually the
+// FileRepository langData was loaded from); a budget annotated in one
+// locale applies to every locale that does not set its own, so the
+// source-language annotations cover German expansion too. Placeholders
+// count as written.
+func CheckLengths(langData map[string]map[string]interface{}, loc SourceLocator) ([]LengthOverrun, error) {
+	own := make(map[string]map[string]int) // lang -> key -> budget
+	shared := make(map[string]int)         // key -> tightest budget in any locale
+	ix := newAnnotationIndex(loc)
+
+	for lang, data := range langData {
+		own[lang] = make(map[string]int)
+		for _, key := range SortedKeys(data) {
+			anns, err := ix.of(lang, key)
+			if err != nil {
+				return nil, err
+			}
+			if b, ok := maxLength(anns); ok {
+				own[lang][key] = b
+				if s, ok := shared[key]; !ok || b < s {
+					shared[key] = b
+				}
+			}
+		}
+	}
+
+	var out []LengthOverrun
+	for lang, data := range langData {
+		if lang == CommonLocale {
+			continue
+		}
+		for _, key := range SortedKeys(data) {
+			budget, ok := own[lang][key]
+			if !ok {
+				budget, ok = shared[key]
+			}
+			if !ok || budget <= 0 {
+				continue
+			}
+			if n := displayLength(data[key]); n > budget {
+				src, _ := loc.Locate(lang, key)
+				out = append(out, LengthOverrun{Lang: lang, Key: key, Length: n, Budget: budget, Source: src})
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Lang != b.Lang {
+			return a.Lang < b.Lang
+		}
+		if a.PercentOver() != b.PercentOver() {
+			return a.PercentOver() > b.PercentOver()
+		}
+		return a.Key < b.Key
+	})
+	return out, nil
+}
+
+// maxLength returns the AI_MaxLength budget among annotations
+func maxLength(anns []*AIAnnotation) (int, bool) {
+	for _, ann := range anns {
+		if ann.Type != "MaxLength" {
+			continue
+		}
+		if n, err := strconv.Atoi(ann.Value); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// displayLength returns the length of a compiled value in characters
+func displayLength(v interface{}) int {
+	switch val := v.(type) {
+	case string:
+		return utf8.RuneCountInString(val)
+	case *RuntimeBlock:
+		longest := 0
+		for _, c := range val.Cases {
+			longest = max(longest, utf8.RuneCountInString(c))
+		}
+		for _, rc := range val.RangeCases {
+			longest = max(longest, utf8.RuneCountInString(rc.Value))
+		}
+		return longest
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLengths(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"en.mbel": "# AI_MaxLength: 10\nbutton = \"Buy now\"\n\n# AI_MaxLength: 5\nok = \"OK\"\n",
+		"de.mbel": "button = \"Jetzt kaufen!\"\nok = \"Gut\"\n",
+		"pl.mbel": "# AI_MaxLength: 20\nbutton = \"Kup teraz teraz\"\nok = \"Dobrze!\"\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := &FileRepository{RootPath: dir}
+	langData, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CheckLengths(langData, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// German inherits the English budget; Polish has its own for button
+	if len(got) != 2 {
+		t.Fatalf("expected 2 overruns, got %+v", got)
+	}
+	if o := got[0]; o.Lang != "de" || o.Key != "button" || o.Length != 13 || o.Budget != 10 || o.PercentOver() != 30 || o.Source.Line != 1 {
+		t.Errorf("unexpected overrun %+v", o)
+	}
+	if o := got[1]; o.Lang != "pl" || o.Key != "ok" || o.Budget != 5 {
+		t.Errorf("unexpected overrun %+v", o)
+	}
+}
@@ -1,5 +1,16 @@
 package mbel
 
+import (
+	"io"
+	"strings"
+)
+
+// bom is the UTF-8 byte order mark Windows editors put at the start of files
+const bom = "\ufeff"
+
+// readChunk is how much a reader-backed lexer pulls from its source at once
+const readChunk = 32 * 1024
+
 type Lexer struct {
 	input        string
 	position     int  // current position in input (points to current char)
@@ -7,15 +18,81 @@ type Lexer struct {
 	ch           byte // current char under examination
 	line         int
 	column       int
+	prevLine     int // position of the last consumed char, for token ends
+	prevColumn   int
+
+	// Reader-backed lexers hold only a window of the source in input:
+	// consumed tokens are dropped and more is read on demand
+	src io.Reader
+	buf []byte
+	err error
 }
 
 func NewLexer(input string) *Lexer {
 	l := &Lexer{input: input, line: 1, column: 0}
 	l.readChar()
+	l.skipBOM()
+	return l
+}
+
+// NewReaderLexer creates a lexer that reads its input incrementally from
+// src, so memory use is bounded by the largest token rather than the file
+func NewReaderLexer(src io.Reader) *Lexer {
+	l := &Lexer{src: src, line: 1, column: 0}
+	l.readChar()
+	l.skipBOM()
 	return l
 }
 
+// skipBOM steps over a byte order mark at the start of the input.
+// Like editors, columns on the first line do not count it.
+func (l *Lexer) skipBOM() {
+	l.fill(len(bom) - 1)
+	if !strings.HasPrefix(l.input[l.position:], bom) {
+		return
+	}
+	for i := 0; i < len(bom); i++ {
+		l.readChar()
+	}
+	l.column = 1
+}
+
+// Err returns the first non-EOF error from the underlying reader
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+// fill makes sure n bytes after the current char are buffered, if the
+// source has them
+func (l *Lexer) fill(n int) {
+	for l.src != nil && l.readPosition+n > len(l.input) {
+		if l.buf == nil {
+			l.buf = make([]byte, readChunk)
+		}
+		k, err := l.src.Read(l.buf)
+		l.input += string(l.buf[:k])
+		if err != nil {
+			if err != io.EOF {
+				l.err = err
+			}
+			l.src = nil
+		}
+	}
+}
+
+// discard drops the already lexed part of a reader-backed input
+func (l *Lexer) discard() {
+	if l.src == nil || l.position == 0 {
+		return
+	}
+	l.input = l.input[l.position:]
+	l.readPosition -= l.position
+	l.position = 0
+}
+
 func (l *Lexer) readChar() {
+	l.prevLine, l.prevColumn = l.line, l.column
+	l.fill(1)
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -27,30 +104,43 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) peekChar() byte {
+	l.fill(1)
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
 	return l.input[l.readPosition]
 }
 
+// NextToken returns the next token, with its start and end positions
 func (l *Lexer) NextToken() Token {
-	var tok Token
-
+	l.discard()
 	l.skipWhitespace()
 
+	tok := l.scan()
+	switch tok.Type {
+	case TOKEN_NEWLINE, TOKEN_EOF:
+		tok.EndLine, tok.EndColumn = tok.Line, tok.Column
+	default:
+		// The scanners stop one char past the token
+		tok.EndLine, tok.EndColumn = l.prevLine, l.prevColumn
+	}
+	return tok
+}
+
+// scan reads one token starting at the current char; tokens carry the
+// position of their first char
+func (l *Lexer) scan() Token {
+	var tok Token
+
 	switch l.ch {
 	case '\n':
-		tok = newToken(TOKEN_NEWLINE, "", l.line, 0)
-		// We leave line increment to next skipWhitespace/readChar or do it here?
-		// If we do it here, the token line is the OLD line.
-		tok.Line = l.line
+		tok = newToken(TOKEN_NEWLINE, "", l.line, l.column)
 		l.line++
 		l.column = 0
 	case '=':
 		if l.peekChar() == '>' {
-			ch := l.ch
+			tok = newToken(TOKEN_ARROW, "=>", l.line, l.column)
 			l.readChar()
-			tok = newToken(TOKEN_ARROW, string(ch)+string(l.ch), l.line, l.column)
 		} else {
 			tok = newToken(TOKEN_ASSIGN, string(l.ch), l.line, l.column)
 		}
@@ -74,47 +164,43 @@ func (l *Lexer) NextToken() Token {
 		tok = newToken(TOKEN_COMMA, string(l.ch), l.line, l.column)
 	case '.':
 		if l.peekChar() == '.' {
-			ch := l.ch
+			tok = newToken(TOKEN_DOT_RANGE, "..", l.line, l.column)
 			l.readChar()
-			tok = newToken(TOKEN_DOT_RANGE, string(ch)+string(l.ch), l.line, l.column)
 		} else {
 			tok = newToken(TOKEN_ILLEGAL, string(l.ch), l.line, l.column)
 		}
 	case '"':
 		if l.isTripleQuote() {
-			tok.Type = TOKEN_STRING
-			tok.Literal = l.readTripleQuotedString()
-			tok.Line = l.line
-			tok.Column = l.column
+			line, col := l.line, l.column
+			lit, terminated := l.readTripleQuotedString()
+			if !terminated {
+				// Unterminated """ swallowed the rest of the file
+				return newToken(TOKEN_ILLEGAL, `"""`, line, col)
+			}
+			return newToken(TOKEN_STRING, lit, line, col)
+		}
+		tok = newToken(TOKEN_STRING, "", l.line, l.column)
+		var terminated bool
+		if tok.Literal, terminated = l.readString(); !terminated {
+			// Leave the line break for the next call
+			tok.Type = TOKEN_UNTERMINATED_STRING
 			return tok
-		} else {
-			tok.Type = TOKEN_STRING
-			tok.Literal = l.readString()
-			tok.Line = l.line
-			tok.Column = l.column
 		}
 	case '#':
-		tok.Type = TOKEN_COMMENT
+		tok = newToken(TOKEN_COMMENT, "", l.line, l.column)
 		tok.Literal = l.readComment()
-		tok.Line = l.line
-		tok.Column = l.column
+		// Leave the '\n' for the next call so it is counted as a line break
+		return tok
 	case 0:
-		tok.Literal = ""
-		tok.Type = TOKEN_EOF
-		tok.Line = l.line
-		tok.Column = l.column
+		tok = newToken(TOKEN_EOF, "", l.line, l.column)
 	default:
 		if isLetter(l.ch) {
+			tok = newToken(TOKEN_IDENT, "", l.line, l.column)
 			tok.Literal = l.readIdentifier()
-			tok.Type = TOKEN_IDENT
-			tok.Line = l.line
-			tok.Column = l.column
 			return tok
 		} else if isDigit(l.ch) {
+			tok = newToken(TOKEN_NUMBER, "", l.line, l.column)
 			tok.Literal = l.readNumber()
-			tok.Type = TOKEN_NUMBER
-			tok.Line = l.line
-			tok.Column = l.column
 			return tok
 		} else {
 			tok = newToken(TOKEN_ILLEGAL, string(l.ch), l.line, l.column)
@@ -167,36 +253,45 @@ func (l *Lexer) readNumber() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
+// readString reads a "..." string; terminated is false when the line or
+// input ended before the closing quote, which is then the current char
+func (l *Lexer) readString() (str string, terminated bool) {
 	position := l.position + 1
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch {
+		case l.ch == '"':
+			return l.input[position:l.position], true
+		case l.ch == '\n' || l.ch == 0 || l.ch == '\r' && l.peekChar() == '\n':
+			return l.input[position:l.position], false
 		}
 	}
-	return l.input[position:l.position]
 }
 
 func (l *Lexer) isTripleQuote() bool {
+	l.fill(2)
 	if l.ch == '"' && l.readPosition < len(l.input) && l.input[l.readPosition] == '"' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '"' {
 		return true
 	}
 	return false
 }
 
-func (l *Lexer) readTripleQuotedString() string {
+// readTripleQuotedString reads a """...""" string; terminated is false
+// when EOF was reached before the closing quotes
+func (l *Lexer) readTripleQuotedString() (str string, terminated bool) {
 	l.readChar()
 	l.readChar()
 	l.readChar()
 
 	position := l.position
 	for {
+		l.fill(2)
 		if l.ch == '"' && l.readPosition < len(l.input) && l.input[l.readPosition] == '"' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '"' {
+			terminated = true
 			break
 		}
 		if l.ch == 0 {
-			break
+			return strings.ReplaceAll(l.input[position:], "\r\n", "\n"), false
 		}
 		if l.ch == '\n' {
 			l.line++
@@ -204,20 +299,20 @@ func (l *Lexer) readTripleQuotedString() string {
 		}
 		l.readChar()
 	}
-	str := l.input[position:l.position]
+	str = strings.ReplaceAll(l.input[position:l.position], "\r\n", "\n")
 
 	l.readChar()
 	l.readChar()
 	l.readChar()
 
-	return str
+	return str, terminated
 }
 
 func (l *Lexer) readComment() string {
 	position := l.position + 1
 	for {
 		l.readChar()
-		if l.ch == '\n' || l.ch == 0 {
+		if l.ch == '\n' || l.ch == 0 || l.ch == '\r' && l.peekChar() == '\n' {
 			break
 		}
 	}
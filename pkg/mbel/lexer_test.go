@@ -1,6 +1,8 @@
 package mbel
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -40,6 +42,7 @@ Line 2
 
 		{TOKEN_NEWLINE, ""}, // Empty line
 		{TOKEN_COMMENT, " Context: Main Title"},
+		{TOKEN_NEWLINE, ""},
 
 		{TOKEN_IDENT, "title"},
 		{TOKEN_ASSIGN, "="},
@@ -98,3 +101,111 @@ Line 2
 		}
 	}
 }
+
+func TestTokenSpans(t *testing.T) {
+	input := "key = \"hi\"\ndesc = \"\"\"\nLine 1\n  Line 2\"\"\" # note\nnext = 12\n"
+
+	type span struct{ line, col, endLine, endCol int }
+	want := map[string]span{
+		"key":                {1, 1, 1, 3},
+		"hi":                 {1, 7, 1, 10},
+		"desc":               {2, 1, 2, 4},
+		"\nLine 1\n  Line 2": {2, 8, 4, 11},
+		" note":              {4, 13, 4, 18},
+		"next":               {5, 1, 5, 4},
+		"12":                 {5, 8, 5, 9},
+	}
+
+	l := NewLexer(input)
+	for tok := l.NextToken(); tok.Type != TOKEN_EOF; tok = l.NextToken() {
+		w, ok := want[tok.Literal]
+		if !ok {
+			continue
+		}
+		delete(want, tok.Literal)
+		if got := (span{tok.Line, tok.Column, tok.EndLine, tok.EndColumn}); got != w {
+			t.Errorf("%q: span %v, want %v", tok.Literal, got, w)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("tokens not seen: %v", want)
+	}
+}
+
+func TestWindowsSource(t *testing.T) {
+	src := "\ufeff@lang: pl\r\n# AI_Context: Greeting\r\ntitle = \"Cześć\"\r\ndesc = \"\"\"\r\nLine 1\r\nLine 2\"\"\"\r\n"
+
+	for name, l := range map[string]*Lexer{
+		"string": NewLexer(src),
+		"reader": NewReaderLexer(strings.NewReader(src)),
+	} {
+		var lits []string
+		for tok := l.NextToken(); tok.Type != TOKEN_EOF; tok = l.NextToken() {
+			if tok.Type != TOKEN_NEWLINE {
+				lits = append(lits, tok.Literal)
+			}
+			if tok.Literal == "@" && (tok.Line != 1 || tok.Column != 1) {
+				t.Errorf("%s: first token at %d:%d", name, tok.Line, tok.Column)
+			}
+		}
+		want := []string{"@", "lang", ":", "pl", " AI_Context: Greeting", "title", "=", "Cześć", "desc", "=", "\nLine 1\nLine 2"}
+		if !reflect.DeepEqual(lits, want) {
+			t.Errorf("%s: tokens %q", name, lits)
+		}
+	}
+
+	data, _, err := CompileSource([]byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta := data["__meta"].(map[string]string); meta["lang"] != "pl" || data["desc"] != "\nLine 1\nLine 2" {
+		t.Errorf("compiled %v", data)
+	}
+
+	// Edits keep the file's BOM and line endings
+	out, _ := SetKeyStatus([]byte(src), StatusReviewed, func(key string) bool { return key == "title" })
+	if want := strings.Replace(src, "title", "@status: reviewed\r\ntitle", 1); string(out) != want {
+		t.Errorf("SetKeyStatus = %q", out)
+	}
+}
+
+func TestLineEndings(t *testing.T) {
+	if DetectLineEnding("a\r\nb\n") != "\r\n" || DetectLineEnding("a\nb\r\n") != "\n" || DetectLineEnding("") != "\n" {
+		t.Error("DetectLineEnding")
+	}
+	if got := NormalizeLineEndings("a\r\nb\nc", "\r\n"); got != "a\r\nb\r\nc" {
+		t.Errorf("to CRLF: %q", got)
+	}
+	if got := NormalizeLineEndings("a\r\nb\n", "\n"); got != "a\nb\n" {
+		t.Errorf("to LF: %q", got)
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	src := "a = \"one\r\nb = \"two\"\nc(n) {\n    [one] => \"x\n    [other] => \"y\"\n}\n"
+
+	l := NewLexer(src)
+	tok := l.NextToken()
+	for tok.Type != TOKEN_UNTERMINATED_STRING {
+		tok = l.NextToken()
+	}
+	if tok.Literal != "one" || tok.Line != 1 || tok.Column != 5 {
+		t.Errorf("token = %+v", tok)
+	}
+	if next := l.NextToken(); next.Type != TOKEN_NEWLINE {
+		t.Errorf("lexing should resume at the line break, got %s", next.Type)
+	}
+
+	p := NewParser(NewLexer(src))
+	program := p.ParseProgram()
+	want := []string{
+		`unterminated string: '"' at line 1, column 5 is never closed`,
+		`unterminated string: '"' at line 4, column 14 is never closed`,
+	}
+	if !reflect.DeepEqual(p.Errors(), want) {
+		t.Errorf("errors = %q", p.Errors())
+	}
+	if len(program.Statements) != 3 {
+		t.Errorf("parsing did not recover: %d statements", len(program.Statements))
+	}
+}
@@ -0,0 +1,280 @@
+package mbel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CommonLocale is the pseudo-locale holding language-independent keys
+// (brand names, URLs, e-mail addresses). Repositories return them once
+// under this name and the Manager merges them into every locale that
+// does not define them itself; it is never served as a language.
+const CommonLocale = "_common"
+
+// sharedLocaleDirs are top-level folders loaded as CommonLocale
+var sharedLocaleDirs = map[string]bool{
+	CommonLocale: true,
+	"common":     true,
+	"shared":     true,
+}
+
+// skipLocaleDir reports whether a directory is hidden from FileRepository
+func skipLocaleDir(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// isLocaleTag reports whether s is a syntactically valid BCP-47 tag
+// ("en", "pt-BR", "zh-Hant-TW", "es-419"). Underscores are accepted as
+// separators, as in folder names like "pt_BR".
+func isLocaleTag(s string) bool {
+	subtags := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 || strings.Join(subtags, "-") != strings.ReplaceAll(s, "_", "-") {
+		return false // empty, or leading, trailing or doubled separators
+	}
+
+	// Primary language: 2-3 letters (ISO 639)
+	if n := len(subtags[0]); n < 2 || n > 3 || !isAlpha(subtags[0]) {
+		return false
+	}
+	for _, sub := range subtags[1:] {
+		if len(sub) > 8 || !isAlnum(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// localeKey folds the spellings of one locale ("pt_BR", "pt-br") together
+func localeKey(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) || s[i] == '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) && (!isLetter(s[i]) || s[i] == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// localeOf decides which locale a compiled file belongs to: its @lang
+// metadata, else its top-level folder (or file) name. ok is false, and a
+// warning logged, when neither is a valid locale.
+func (r *FileRepository) localeOf(path, segment string, data map[string]interface{}) (lang string, ok bool) {
+	folder := strings.TrimSuffix(segment, ".mbel")
+	if !isLocaleTag(folder) {
+		folder = ""
+	}
+
+	meta, _ := data["__meta"].(map[string]string)
+	declared := meta["lang"]
+	switch {
+	case declared == "":
+	case !isLocaleTag(declared):
+		r.logger().Warn("mbel: invalid @lang, ignored", "file", path, "lang", declared)
+	default:
+		if folder != "" && localeKey(folder) != localeKey(declared) {
+			r.logger().Warn("mbel: ambiguous locale, @lang overrides folder", "file", path, "folder", folder, "lang", declared)
+		}
+		return declared, true
+	}
+
+	if folder == "" {
+		r.logger().Warn("mbel: skipping file outside a locale folder (no valid @lang)", "file", path)
+		return "", false
+	}
+	return folder, true
+}
+
+// mergeCommon merges the CommonLocale entry of langData into every
+// locale and drops it. Locale keys win; values are shared, not copied.
+func mergeCommon(langData map[string]map[string]interface{}) map[string]map[string]interface{} {
+	common, ok := langData[CommonLocale]
+	if !ok {
+		return langData
+	}
+
+	merged := make(map[string]map[string]interface{}, len(langData)-1)
+	for lang, data := range langData {
+		if lang == CommonLocale {
+			continue
+		}
+		d := make(map[string]interface{}, len(data)+len(common))
+		for k, v := range common {
+			if !strings.HasPrefix(k, "__") {
+				d[k] = v
+			}
+		}
+		for k, v := range data {
+			d[k] = v
+		}
+		for _, mk := range []string{scheduleDataKey, audienceDataKey} {
+			cm, ok := common[mk].(map[string]string)
+			if !ok {
+				continue
+			}
+			// Shared keys keep their per-key metadata; the locale's own wins
+			both := make(map[string]string, len(cm))
+			for k, v := range cm {
+				both[k] = v
+			}
+			own, _ := data[mk].(map[string]string)
+			for k, v := range own {
+				both[k] = v
+			}
+			d[mk] = both
+		}
+		if cm, ok := common["__ai"].(map[string][]map[string]string); ok {
+			both := make(map[string][]map[string]string, len(cm))
+			for k, v := range cm {
+				if k != "__global" {
+					both[k] = v
+				}
+			}
+			own, _ := data["__ai"].(map[string][]map[string]string)
+			for k, v := range own {
+				both[k] = v
+			}
+			d["__ai"] = both
+		}
+		merged[lang] = d
+	}
+	return merged
+}
+
+// inheritsMetaKey is the metadata naming the locale a locale extends
+const inheritsMetaKey = "inherits"
+
+// ResolveInheritance materializes the locales whose files declare
+// "@inherits: es": every key the locale lacks, with its terms, schedule,
+// audience and AI annotations, is taken from its parent (after the
+// parent's own inheritance), so "es-MX" files need only hold overrides.
+// Values are shared, not copied. Unknown parents and cycles are reported
+// in the error; those locales are returned as loaded.
+func ResolveInheritance(langData map[string]map[string]interface{}) (map[string]map[string]interface{}, error) {
+	parents := make(map[string]string)
+	byKey := make(map[string]string, len(langData))
+	for lang, data := range langData {
+		byKey[localeKey(lang)] = lang
+		if meta, _ := data["__meta"].(map[string]string); meta[inheritsMetaKey] != "" {
+			parents[lang] = meta[inheritsMetaKey]
+		}
+	}
+	if len(parents) == 0 {
+		return langData, nil
+	}
+
+	out := make(map[string]map[string]interface{}, len(langData))
+	for lang, data := range langData {
+		out[lang] = data
+	}
+	var problems []string
+	done := make(map[string]bool)
+	var resolve func(lang string, chain []string)
+	resolve = func(lang string, chain []string) {
+		if done[lang] {
+			return
+		}
+		done[lang] = true
+		declared, ok := parents[lang]
+		if !ok {
+			return
+		}
+		parent, ok := byKey[localeKey(declared)]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s inherits unknown locale %s", lang, declared))
+			return
+		case parent == lang || containsString(chain, parent):
+			problems = append(problems, fmt.Sprintf("%s: inheritance cycle through %s", lang, parent))
+			return
+		}
+		resolve(parent, append(chain, lang))
+		out[lang] = inheritLocale(out[lang], out[parent])
+	}
+	for _, lang := range sortedLocales(langData) {
+		resolve(lang, nil)
+	}
+
+	if len(problems) > 0 {
+		return out, fmt.Errorf("mbel: %s", strings.Join(problems, "; "))
+	}
+	return out, nil
+}
+
+// inheritLocale returns data with the keys and per-key metadata of
+// parent it lacks; its own always win
+func inheritLocale(data, parent map[string]interface{}) map[string]interface{} {
+	d := make(map[string]interface{}, len(parent)+len(data))
+	for k, v := range parent {
+		if !strings.HasPrefix(k, "__") {
+			d[k] = v
+		}
+	}
+	for k, v := range data {
+		d[k] = v
+	}
+	for _, mk := range []string{"__terms", scheduleDataKey, audienceDataKey} {
+		pm, ok := parent[mk].(map[string]string)
+		if !ok {
+			continue
+		}
+		both := make(map[string]string, len(pm))
+		for k, v := range pm {
+			both[k] = v
+		}
+		own, _ := data[mk].(map[string]string)
+		for k, v := range own {
+			both[k] = v
+		}
+		d[mk] = both
+	}
+	if pm, ok := parent["__ai"].(map[string][]map[string]string); ok {
+		both := make(map[string][]map[string]string, len(pm))
+		for k, v := range pm {
+			both[k] = v
+		}
+		own, _ := data["__ai"].(map[string][]map[string]string)
+		for k, v := range own {
+			both[k] = v
+		}
+		d["__ai"] = both
+	}
+	return d
+}
+
+func sortedLocales(langData map[string]map[string]interface{}) []string {
+	langs := make([]string, 0, len(langData))
+	for lang := range langData {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// warnAmbiguousLocales logs locales loaded under several spellings
+// ("pt_BR" and "pt-BR"), which would otherwise be silently split
+func (r *FileRepository) warnAmbiguousLocales(langData map[string]map[string]interface{}) {
+	spellings := make(map[string][]string)
+	for lang := range langData {
+		k := localeKey(lang)
+		spellings[k] = append(spellings[k], lang)
+	}
+	for _, langs := range spellings {
+		if len(langs) > 1 {
+			sort.Strings(langs)
+			r.logger().Warn("mbel: ambiguous layout, one locale under several names", "locales", langs)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package mbel
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsLocaleTag(t *testing.T) {
+	for tag, want := range map[string]bool{
+		"en": true, "pt-BR": true, "pt_BR": true, "zh-Hant-TW": true, "es-419": true,
+		"common": false, ".git": false, "e": false, "en-": false, "en--US": false, "": false, "_common": false,
+	} {
+		if got := isLocaleTag(tag); got != want {
+			t.Errorf("isLocaleTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestFileRepositoryLocaleDetection(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"en.mbel":            "title = \"Hello\"\n",
+		"pl/brand.mbel":      "name = \"Akme\"\n", // overrides the shared key
+		"pl_legacy/old.mbel": "@lang: de\nold = \"Alt\"\n",
+		"misc/notes.mbel":    "@lang: fr\nnote = \"Bonjour\"\n",
+		"_common/brand.mbel": "name = \"Acme\"\n",
+		"docs/readme.mbel":   "x = \"not a locale\"\n",
+		".git/stale.mbel":    "x = \"y\"\n",
+		"pt_BR.mbel":         "a = \"b\"\n",
+		"pt-BR/more.mbel":    "c = \"d\"\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var logs bytes.Buffer
+	repo := &FileRepository{RootPath: dir, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	data, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 7 || data[CommonLocale] == nil || data["en"] == nil || data["pl"] == nil || data["de"] == nil || data["fr"] == nil {
+		t.Fatalf("unexpected locales %v", data)
+	}
+	if data["de"]["old.old"] != "Alt" || data["fr"]["notes.note"] != "Bonjour" {
+		t.Errorf("@lang override not applied: de=%v fr=%v", data["de"], data["fr"])
+	}
+	if data[CommonLocale]["brand.name"] != "Acme" || data["en"]["brand.name"] != nil {
+		t.Errorf("shared keys should load once, under %s: %v", CommonLocale, data[CommonLocale])
+	}
+	if loc, ok := repo.Locate("en", "brand.name"); !ok || !strings.HasSuffix(loc.File, filepath.Join("_common", "brand.mbel")) {
+		t.Errorf("shared key located at %+v", loc)
+	}
+
+	for _, want := range []string{
+		"skipping file outside a locale folder",
+		"ambiguous locale, @lang overrides folder",
+		"ambiguous layout, one locale under several names",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("missing warning %q in:\n%s", want, logs.String())
+		}
+	}
+	if strings.Contains(logs.String(), "stale.mbel") {
+		t.Error("hidden directory was scanned")
+	}
+}
+
+func TestCommonLocaleMerged(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		CommonLocale: {"brand": "Acme", "support": "help@acme.test"},
+		"en":         {"title": "Hello"},
+		"pl":         {"title": "Cześć", "brand": "Akme"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Languages(); len(got) != 2 || got[0] != "en" || got[1] != "pl" {
+		t.Errorf("%s served as a language: %v", CommonLocale, got)
+	}
+	if got := m.Get("en", "support"); got != "help@acme.test" {
+		t.Errorf("en support = %q", got)
+	}
+	if got := m.Get("pl", "brand"); got != "Akme" {
+		t.Errorf("locale key should win over common, got %q", got)
+	}
+	if got := m.Get("en", "brand"); got != "Acme" {
+		t.Errorf("en brand = %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+package mbel
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Localizer is a Manager bound to one locale, for code that knows the
+// user's language but has no request context: cron jobs, e-mail senders,
+// CLIs.
+//
+//	l := mbel.For(user.Lang)
+//	subject := l.T("email.welcome", mbel.Vars{"name": user.Name})
+//	digest := l.TN("email.digest", len(items))
+type Localizer struct {
+	m    *Manager
+	lang string
+}
+
+// For returns a Localizer for lang on the global manager. Without Init
+// its methods return keys unchanged.
+func For(lang string) *Localizer {
+	return Default().For(lang)
+}
+
+// For returns a Localizer for lang on m
+func (m *Manager) For(lang string) *Localizer {
+	return &Localizer{m: m, lang: lang}
+}
+
+// Lang returns the locale the Localizer is bound to
+func (l *Localizer) Lang() string {
+	return l.lang
+}
+
+// T translates key, falling back like Manager.Get
+func (l *Localizer) T(key string, args ...interface{}) string {
+	if l.m == nil {
+		return key
+	}
+	return l.m.Get(l.lang, key, args...)
+}
+
+// TN translates a key counting n: n selects the case of the key's logic
+// block and fills its argument placeholder, vars fill the others
+//
+//	l.TN("cart.items", 3, mbel.Vars{"name": "Ola"}) // items(count) { ... }
+func (l *Localizer) TN(key string, n interface{}, vars ...Vars) string {
+	if l.m == nil {
+		return key
+	}
+	if len(vars) == 0 {
+		return l.m.Get(l.lang, key, n)
+	}
+
+	arg := l.m.blockArgument(l.lang, key)
+	if arg == "" {
+		arg = "n"
+	}
+	merged := make(Vars, len(vars[0])+1)
+	for _, v := range vars {
+		for k, val := range v {
+			merged[k] = val
+		}
+	}
+	merged[arg] = n
+	return l.m.Get(l.lang, key, merged)
+}
+
+// FormatNumber formats n with the locale's decimal and grouping
+// separators ("12,345.5" in en, "12 345,5" in pl). decimals fixes the
+// number of decimal places; a negative value uses as many as needed.
+// @decimal_separator and @thousands_separator in the locale's files
+// override the built-in separators.
+func (l *Localizer) FormatNumber(n float64, decimals int) string {
+	sym, _ := l.numberFormat()
+	return formatNumber(sym, n, decimals)
+}
+
+// FormatCurrency formats amount with two decimals and a currency code or
+// symbol ("CHF 1’234.50", "1 234,50 zł"); "" uses the locale's @currency,
+// and without one the plain number is returned
+func (l *Localizer) FormatCurrency(amount float64, currency string) string {
+	sym, def := l.numberFormat()
+	if currency == "" {
+		currency = def
+	}
+	s := formatNumber(sym, amount, 2)
+	if currency == "" {
+		return s
+	}
+	return placeCurrency(l.lang, s, currency)
+}
+
+// FormatMoney formats m like FormatCurrency, with its currency's symbol
+// and number of decimals
+func (l *Localizer) FormatMoney(m Money) string {
+	sym, _ := l.numberFormat()
+	return m.format(l.lang, sym)
+}
+
+// placeCurrency adds currency to the formatted amount s where lang puts
+// it: single-character symbols before the amount attach to it
+func placeCurrency(lang, s, currency string) string {
+	switch {
+	case !currencyFirstFor(lang):
+		return s + nbsp + currency
+	case utf8.RuneCountInString(currency) == 1:
+		if strings.HasPrefix(s, "-") {
+			return "-" + currency + s[1:]
+		}
+		return currency + s
+	default:
+		return currency + nbsp + s
+	}
+}
+
+// Metadata keys overriding a locale's number format
+const (
+	decimalSeparatorMeta   = "decimal_separator"
+	thousandsSeparatorMeta = "thousands_separator"
+	currencyMeta           = "currency"
+)
+
+// numberFormat returns the locale's number symbols and default currency,
+// with the metadata overrides of its locale files applied
+func (l *Localizer) numberFormat() (numberSymbols, string) {
+	sym := numberSymbolsFor(l.lang)
+	if l.m == nil {
+		return sym, ""
+	}
+	meta := l.m.localeMeta(l.lang)
+	return sym.withMeta(meta), meta[currencyMeta]
+}
+
+// withMeta applies the separator overrides of a locale's metadata
+func (sym numberSymbols) withMeta(meta map[string]string) numberSymbols {
+	if v, ok := meta[decimalSeparatorMeta]; ok {
+		sym.decimal = v
+	}
+	if v, ok := meta[thousandsSeparatorMeta]; ok {
+		sym.group = v
+	}
+	return sym
+}
+
+// blockArgument returns the argument name of key's logic block in the
+// locale serving lang, or "" when key is not a block
+func (m *Manager) blockArgument(lang, key string) string {
+	var buf [3]string
+	for _, c := range m.candidates(buf[:0], lang) {
+		if r, ok := m.runtime(c); ok {
+			if v, ok := r.value(key); ok {
+				if rb, ok := v.(*RuntimeBlock); ok {
+					return rb.Argument
+				}
+				return ""
+			}
+		}
+	}
+	return ""
+}
+
+// localeMeta returns the file metadata of lang, else of its base
+// language. Unlike messages it never comes from the default locale: an
+// en override must not leak into de.
+func (m *Manager) localeMeta(lang string) map[string]string {
+	tags := []string{lang}
+	if len(lang) > 2 {
+		tags = append(tags, lang[:2])
+	}
+	for _, tag := range tags {
+		if r, ok := m.runtime(tag); ok {
+			meta, _ := r.Data["__meta"].(map[string]string)
+			return meta
+		}
+	}
+	return nil
+}
+
+// numberSymbols are a locale's decimal and group separators; groups of
+// three digits start at minGrouping+3 digits (CLDR minimumGroupingDigits)
+type numberSymbols struct {
+	decimal, group string
+	minGrouping    int
+}
+
+const (
+	nbsp       = "\u00a0"
+	narrowNbsp = "\u202f"
+)
+
+var numberFormats = map[string]numberSymbols{
+	"en": {".", ",", 1}, "ja": {".", ",", 1}, "zh": {".", ",", 1}, "ko": {".", ",", 1},
+	"he": {".", ",", 1}, "th": {".", ",", 1}, "hi": {".", ",", 1}, "ar": {".", ",", 1},
+	"de": {",", ".", 1}, "it": {",", ".", 1}, "nl": {",", ".", 1}, "pt": {",", ".", 1},
+	"id": {",", ".", 1}, "tr": {",", ".", 1}, "da": {",", ".", 1}, "el": {",", ".", 1},
+	"ro": {",", ".", 1}, "hr": {",", ".", 1}, "sl": {",", ".", 1}, "vi": {",", ".", 1},
+	"es": {",", ".", 2},
+	"fr": {",", narrowNbsp, 1},
+	"pl": {",", nbsp, 2}, "ru": {",", nbsp, 1}, "uk": {",", nbsp, 1}, "cs": {",", nbsp, 1},
+	"sk": {",", nbsp, 1}, "fi": {",", nbsp, 1}, "sv": {",", nbsp, 1}, "nb": {",", nbsp, 1},
+	"hu": {",", nbsp, 1}, "bg": {",", nbsp, 1}, "lt": {",", nbsp, 1}, "lv": {",", nbsp, 1}, "et": {",", nbsp, 1},
+	"pt-pt": {",", nbsp, 2}, "de-ch": {".", "\u2019", 1}, "fr-ch": {",", narrowNbsp, 1},
+}
+
+// currencyFirst lists locales writing the currency before the amount;
+// the others put it after, separated by a no-break space
+var currencyFirst = map[string]bool{
+	"en": true, "ja": true, "zh": true, "ko": true, "th": true, "hi": true,
+	"nl": true, "pt-br": true, "de-ch": true, "fr-ch": true,
+}
+
+// currencyFirstFor reports whether lang, else its base language, writes
+// the currency first
+func currencyFirstFor(lang string) bool {
+	key := localeKey(lang)
+	if first, ok := currencyFirst[key]; ok {
+		return first
+	}
+	base, _, _ := strings.Cut(key, "-")
+	return currencyFirst[base]
+}
+
+// numberSymbolsFor returns the separators of lang, else of its base
+// language, else English ones
+func numberSymbolsFor(lang string) numberSymbols {
+	key := localeKey(lang)
+	if s, ok := numberFormats[key]; ok {
+		return s
+	}
+	if base, _, ok := strings.Cut(key, "-"); ok {
+		if s, ok := numberFormats[base]; ok {
+			return s
+		}
+	}
+	return numberFormats["en"]
+}
+
+func formatNumber(sym numberSymbols, n float64, decimals int) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	s := strconv.FormatFloat(math.Abs(n), 'f', decimals, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	if len(intPart) >= sym.minGrouping+3 {
+		for i, d := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				b.WriteString(sym.group)
+			}
+			b.WriteRune(d)
+		}
+	} else {
+		b.WriteString(intPart)
+	}
+	if frac != "" {
+		b.WriteString(sym.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
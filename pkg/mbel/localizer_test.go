@@ -0,0 +1,124 @@
+package mbel
+
+import "testing"
+
+func TestLocalizer(t *testing.T) {
+	items := &RuntimeBlock{
+		Argument: "count",
+		Cases:    map[string]string{"one": "{name}: {count} plik", "few": "{name}: {count} pliki", "other": "{name}: {count} plików"},
+	}
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"hello": "Hello {name}"},
+		"pl": {"hello": "Cześć {name}", "files": items, "plain": "{n} razy"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := m.For("pl-PL")
+	if got := l.T("hello", Vars{"name": "Ola"}); got != "Cześć Ola" {
+		t.Errorf("T = %q", got)
+	}
+	if got := l.TN("files", 3, Vars{"name": "Ola"}); got != "Ola: 3 pliki" {
+		t.Errorf("TN with vars = %q", got)
+	}
+	if got := l.TN("files", 5, Vars{"name": "Ola"}); got != "Ola: 5 plików" {
+		t.Errorf("TN = %q", got)
+	}
+	if got := l.TN("plain", 2, Vars{}); got != "2 razy" {
+		t.Errorf("TN on a string = %q", got)
+	}
+
+	if got := (&Localizer{lang: "pl"}).T("hello"); got != "hello" {
+		t.Errorf("Localizer without a manager = %q", got)
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	for _, tc := range []struct {
+		lang     string
+		n        float64
+		decimals int
+		want     string
+	}{
+		{"en", 1234567.891, 2, "1,234,567.89"},
+		{"en-US", -1234.5, -1, "-1,234.5"},
+		{"de", 1234567.5, 1, "1.234.567,5"},
+		{"pl", 1234, 0, "1234"},
+		{"pl", 12345.5, 1, "12 345,5"},
+		{"fr", 1234, 0, "1 234"},
+		{"pt-PT", 1234, 0, "1234"},
+		{"pt-BR", 1234, 0, "1.234"},
+		{"xx", 999, 0, "999"},
+		{"en", -0.001, 2, "0.00"},
+	} {
+		if got := formatNumber(numberSymbolsFor(tc.lang), tc.n, tc.decimals); got != tc.want {
+			t.Errorf("formatNumber(%s, %v, %d) = %q, want %q", tc.lang, tc.n, tc.decimals, got, tc.want)
+		}
+	}
+}
+
+func TestNumberFormatMetadata(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en":    {"__meta": map[string]string{"thousands_separator": " "}},
+		"de-CH": {"__meta": map[string]string{"thousands_separator": "'", "decimal_separator": ".", "currency": "CHF"}},
+		"pl":    {"__meta": map[string]string{"currency": "zł"}},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		lang, currency string
+		n              float64
+		want           string
+	}{
+		{"de-CH", "", 1234567.5, "CHF" + nbsp + "1'234'567.50"},
+		{"pl-PL", "", -12345, "-12" + nbsp + "345,00" + nbsp + "zł"},
+		{"en", "$", -1234.5, "-$1 234.50"},
+		{"de", "EUR", 1234, "1.234,00" + nbsp + "EUR"}, // the en override does not leak
+		{"fr", "", 1, "1,00"},
+	} {
+		if got := m.For(tc.lang).FormatCurrency(tc.n, tc.currency); got != tc.want {
+			t.Errorf("FormatCurrency(%s, %v) = %q, want %q", tc.lang, tc.n, got, tc.want)
+		}
+	}
+	if got := m.For("de-CH").FormatNumber(1234.5, -1); got != "1'234.5" {
+		t.Errorf("FormatNumber = %q", got)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	for _, tc := range []struct {
+		lang  string
+		money Money
+		want  string
+	}{
+		{"en", Money{1234.5, "EUR"}, "€1,234.50"},
+		{"de", Money{1234.5, "EUR"}, "1.234,50" + nbsp + "€"},
+		{"pl", Money{1234.5, "eur"}, "1234,50" + nbsp + "€"},
+		{"en-US", Money{-1234.5, "USD"}, "-$1,234.50"},
+		{"ja", Money{1234.5, "JPY"}, "¥1,234"},
+		{"en", Money{10, "CHF"}, "CHF" + nbsp + "10.00"},
+		{"pl", Money{12345, "PLN"}, "12" + nbsp + "345,00" + nbsp + "zł"},
+		{"ar", Money{1.2346, "KWD"}, "1.235" + nbsp + "KWD"},
+	} {
+		if got := tc.money.Format(tc.lang); got != tc.want {
+			t.Errorf("%v.Format(%s) = %q, want %q", tc.money, tc.lang, got, tc.want)
+		}
+	}
+
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"de-CH": {"total": "Total: {total}", "__meta": map[string]string{"thousands_separator": "'", "decimal_separator": "."}},
+	}), Config{DefaultLocale: "de-CH"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := Money{Amount: 1234.5, Currency: "CHF"}
+	if got := m.Get("de-CH", "total", Vars{"total": total}); got != "Total: CHF"+nbsp+"1'234.50" {
+		t.Errorf("interpolated = %q", got)
+	}
+	if got := m.For("de-CH").FormatMoney(total); got != "CHF"+nbsp+"1'234.50" {
+		t.Errorf("FormatMoney = %q", got)
+	}
+}
@@ -0,0 +1,161 @@
+package mbel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// LockFileName is the freshness lockfile kept next to the locale folders
+const LockFileName = "mbel.lock"
+
+const lockFileVersion = 1
+
+// LockFile tracks translation freshness: for every translated key it
+// records a hash of the source-locale text the translation was made
+// from, and of the translation itself. When the source text changes but
+// the translation does not, the translation is stale.
+type LockFile struct {
+	Version int                             `json:"version"`
+	Source  string                          `json:"source"`
+	Locales map[string]map[string]LockEntry `json:"locales"` // target locale -> key -> entry
+}
+
+// LockEntry is the recorded state of one translation
+type LockEntry struct {
+	Source string `json:"source"` // ContentHash of the source text
+	Target string `json:"target"` // ContentHash of the translation
+}
+
+// NewLockFile returns an empty lockfile for the given source locale
+func NewLockFile(source string) *LockFile {
+	return &LockFile{Version: lockFileVersion, Source: source, Locales: make(map[string]map[string]LockEntry)}
+}
+
+// ReadLockFile reads a lockfile written by WriteFile
+func ReadLockFile(path string) (*LockFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l LockFile
+	if err := json.Unmarshal(content, &l); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if l.Version != lockFileVersion {
+		return nil, fmt.Errorf("%s: unsupported lockfile version %d", path, l.Version)
+	}
+	if l.Locales == nil {
+		l.Locales = make(map[string]map[string]LockEntry)
+	}
+	return &l, nil
+}
+
+// WriteFile writes the lockfile as indented JSON with sorted keys, so
+// it diffs well under version control
+func (l *LockFile) WriteFile(path string) error {
+	out, err := l.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// Bytes returns the content WriteFile writes
+func (l *LockFile) Bytes() ([]byte, error) {
+	out, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// ContentHash returns a short hash of a compiled value; logic blocks
+// hash their argument and every case
+func ContentHash(v interface{}) string {
+	h := sha256.New()
+	switch val := v.(type) {
+	case string:
+		h.Write([]byte(val))
+	case *RuntimeBlock:
+		fmt.Fprintf(h, "(%s)", val.Argument)
+		for _, e := range blockEntries(val) {
+			fmt.Fprintf(h, "\x00%s\x00%s", e.cond, e.value)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// Update records the current source text for every translation that is
+// new or has changed since the last update, and forgets keys missing
+// from the source or target locale. Translations left untouched while
+// their source changed keep their old record, and so stay stale.
+func (l *LockFile) Update(langData map[string]map[string]interface{}) {
+	src := langData[l.Source]
+	for lang, data := range langData {
+		if lang == l.Source || lang == CommonLocale {
+			continue
+		}
+		old := l.Locales[lang]
+		entries := make(map[string]LockEntry)
+		for _, key := range SortedKeys(data) {
+			sv, ok := src[key]
+			if !ok {
+				continue
+			}
+			target := ContentHash(data[key])
+			if e, ok := old[key]; ok && e.Target == target {
+				entries[key] = e
+			} else {
+				entries[key] = LockEntry{Source: ContentHash(sv), Target: target}
+			}
+		}
+		l.Locales[lang] = entries
+	}
+	for lang := range l.Locales {
+		if _, ok := langData[lang]; !ok {
+			delete(l.Locales, lang)
+		}
+	}
+}
+
+// Accept marks the translations of lang matching one of the key
+// patterns (path.Match syntax, as in Untranslated) as up to date with
+// the current source text
+func (l *LockFile) Accept(langData map[string]map[string]interface{}, lang string, patterns []string) []string {
+	var accepted []string
+	for _, key := range l.Stale(langData, lang) {
+		if allowed(key, patterns) {
+			l.Locales[lang][key] = LockEntry{
+				Source: ContentHash(langData[l.Source][key]),
+				Target: ContentHash(langData[lang][key]),
+			}
+			accepted = append(accepted, key)
+		}
+	}
+	return accepted
+}
+
+// Stale returns the keys of lang, sorted, whose translation is unchanged
+// since it was recorded while the source text has changed. Keys without
+// a record are not stale.
+func (l *LockFile) Stale(langData map[string]map[string]interface{}, lang string) []string {
+	src, data := langData[l.Source], langData[lang]
+	var stale []string
+	for key, e := range l.Locales[lang] {
+		sv, ok := src[key]
+		tv, ok2 := data[key]
+		if !ok || !ok2 || strings.HasPrefix(key, "__") {
+			continue
+		}
+		if e.Source != ContentHash(sv) && e.Target == ContentHash(tv) {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
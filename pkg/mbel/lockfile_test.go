@@ -0,0 +1,64 @@
+package mbel
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockFileStale(t *testing.T) {
+	langData := map[string]map[string]interface{}{
+		"en": {"title": "Hello", "body": "Text", "cta": "Buy"},
+		"pl": {"title": "Cześć", "body": "Tekst"},
+	}
+	lock := NewLockFile("en")
+	lock.Update(langData)
+	if stale := lock.Stale(langData, "pl"); len(stale) != 0 {
+		t.Fatalf("fresh lockfile has stale keys %v", stale)
+	}
+
+	// Source edited: title and body go stale until retranslated
+	langData["en"]["title"] = "Hello there"
+	langData["en"]["body"] = "New text"
+	lock.Update(langData)
+	if stale := lock.Stale(langData, "pl"); !reflect.DeepEqual(stale, []string{"body", "title"}) {
+		t.Fatalf("stale = %v", stale)
+	}
+
+	// Retranslating clears the mark, and so does accepting
+	langData["pl"]["title"] = "Cześć wszystkim"
+	lock.Update(langData)
+	if accepted := lock.Accept(langData, "pl", []string{"b*"}); !reflect.DeepEqual(accepted, []string{"body"}) {
+		t.Errorf("accepted = %v", accepted)
+	}
+	if stale := lock.Stale(langData, "pl"); len(stale) != 0 {
+		t.Errorf("stale after retranslation = %v", stale)
+	}
+
+	path := filepath.Join(t.TempDir(), LockFileName)
+	if err := lock.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ReadLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, lock) {
+		t.Errorf("round trip = %+v, want %+v", back, lock)
+	}
+}
+
+func TestContentHashBlocks(t *testing.T) {
+	a := &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}}
+	b := &RuntimeBlock{Argument: "n", Cases: map[string]string{"other": "{n} items", "one": "1 item"}}
+	if ContentHash(a) != ContentHash(b) {
+		t.Error("hash depends on map order")
+	}
+	b.Cases["few"] = "{n} items"
+	if ContentHash(a) == ContentHash(b) {
+		t.Error("hash ignores an added case")
+	}
+	if ContentHash("x") == ContentHash("y") {
+		t.Error("hash ignores text")
+	}
+}
@@ -1,20 +1,67 @@
 package mbel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Config configures the MBEL manager
 type Config struct {
 	DefaultLocale string
-	Watch         bool // Enable hot-reloading (works only with FileRepository)
-	LazyLoad      bool // Enable lazy-loading of runtimes (load on demand)
+	Watch         bool          // Hot-reload in the background for the manager's lifetime (see Manager.Watch)
+	LazyLoad      bool          // Enable lazy-loading of runtimes (load on demand)
+	Observer      Observer      // Receives missing-key, fallback and reload events (nil = disabled)
+	Logger        *slog.Logger  // Destination for syntax errors and reload failures (nil = slog.Default())
+	CompileCache  *CompileCache // On-disk cache of compiled files (nil = disabled)
+	WatchInterval time.Duration // How often Watch polls a FileRepository (0 = 1s)
+	OnReloadError func(error)   // Called when a hot reload fails (nil = log to Logger)
+
+	// OnMissingVariable is called when a {placeholder} has no value in the
+	// arguments passed to T (nil = disabled)
+	OnMissingVariable func(MissingVariable)
+
+	// GenderStrategies chooses, per locale or base language, how blocks
+	// with a [neutral] case resolve (unset = GenderExplicit)
+	GenderStrategies map[string]GenderStrategy
+
+	// Timezone is the default zone of {d, date}, {d, time} and
+	// {d, datetime} placeholders (nil = the time value's own)
+	Timezone *time.Location
+
+	// InternValues makes equal values and block cases share memory across
+	// keys and locales, on top of the keys that always do. It costs time
+	// on every load, and pays off for catalogs of many thousands of keys.
+	InternValues bool
+
+	// Audiences lists the @audience tags BundleHandler and Subset may
+	// serve to clients (nil = public only). T and Get see every key.
+	Audiences []Audience
+
+	// Audit receives a CatalogChange for every message changed by Update
+	// or by a reload after the first load (nil = disabled)
+	Audit AuditSink
+
+	// Regional overrides plural rules, date layouts and the first day of
+	// the week per locale or base language, on top of the built-in ones
+	Regional map[string]Regional
+
+	// Escape is the default escape profile of interpolated values
+	// (EscapeNone = as they are); see EscapeVar and WithEscape
+	Escape EscapeProfile
+
+	// Interpolation is the placeholder style of files without an
+	// @interpolation line, for NewManager ("" = InterpolationSingle)
+	Interpolation Interpolation
 }
 
 // Repository defines the interface for loading localization data
@@ -24,276 +71,950 @@ type Repository interface {
 	LoadAll() (map[string]map[string]interface{}, error)
 }
 
+// ContextRepository is a Repository whose loads honour cancellation and
+// deadlines, for remote sources (HTTP, database). Manager.Load uses
+// LoadAllContext when the repository implements it.
+type ContextRepository interface {
+	Repository
+	LoadAllContext(ctx context.Context) (map[string]map[string]interface{}, error)
+}
+
+// ChangeNotifier is implemented by repositories that announce their own
+// changes. A watching manager reloads on every notification. The channel
+// stops receiving once ctx is done.
+type ChangeNotifier interface {
+	Changes(ctx context.Context) <-chan struct{}
+}
+
 // Manager manages localization data for multiple languages
 type Manager struct {
-	mu          sync.RWMutex
-	runtimes    map[string]*Runtime // lang -> Runtime (cached)
+	mu          sync.Mutex              // Serializes writers; readers never lock
+	state       atomic.Pointer[catalog] // Swapped wholesale on reload
 	defaultLang string
 	repo        Repository
-	lazyLoad    bool                              // Load runtimes on demand instead of all upfront
-	allData     map[string]map[string]interface{} // Cached raw data for lazy loading
+	lazyLoad    bool // Load runtimes on demand instead of all upfront
+	observer    Observer
+	logger      *slog.Logger
+
+	watchInterval     time.Duration
+	onReloadError     func(error)
+	onMissingVariable func(MissingVariable)
+	genderStrategies  map[string]GenderStrategy
+	timezone          *time.Location
+	internValues      bool
+	audiences         []Audience
+	auditSink         AuditSink
+	globalVars        atomic.Pointer[Vars] // set by SetGlobalVars
+	escape            EscapeProfile
+	regionals         map[string]Regional
+	watching          atomic.Bool
+
+	stopWatch context.CancelFunc // stops the Config.Watch goroutine; nil without one
+	watchDone chan struct{}      // closed when that goroutine returns
+}
+
+// catalog is an immutable snapshot of loaded data. Writers build a new
+// catalog off to the side and publish it with a single atomic store.
+type catalog struct {
+	runtimes   map[string]*Runtime               // lang -> Runtime (cached)
+	allData    map[string]map[string]interface{} // Raw data, kept for lazy loading
+	gen        uint64                            // Bumped by every install; kept by lazily extended copies
+	namespaces map[string]bool                   // Top-level namespaces of the loaded keys, for metrics labels
 }
 
 // NewManager creates a standard file-based localization manager
 func NewManager(rootPath string, cfg Config) (*Manager, error) {
-	repo := &FileRepository{RootPath: rootPath, cache: make(map[string]cachedFile)}
+	if _, err := ParseInterpolation(string(cfg.Interpolation)); err != nil {
+		return nil, err
+	}
+	repo := &FileRepository{RootPath: rootPath, Logger: cfg.Logger, Cache: cfg.CompileCache, Interpolation: cfg.Interpolation, cache: make(map[string]cachedFile)}
 	return NewManagerWithRepo(repo, cfg)
 }
 
 // NewManagerWithRepo creates a manager with a custom repository (e.g. Database)
 func NewManagerWithRepo(repo Repository, cfg Config) (*Manager, error) {
+	if _, err := ParseEscapeProfile(string(cfg.Escape)); err != nil {
+		return nil, err
+	}
+	m := newManager(repo, cfg)
+
+	if err := m.watch(cfg, func() error { return m.Load(context.Background()) }, false); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func newManager(repo Repository, cfg Config) *Manager {
 	m := &Manager{
-		runtimes:    make(map[string]*Runtime),
 		defaultLang: cfg.DefaultLocale,
 		repo:        repo,
 		lazyLoad:    cfg.LazyLoad,
-		allData:     make(map[string]map[string]interface{}),
+		observer:    cfg.Observer,
+		logger:      cfg.Logger,
+
+		watchInterval:     cfg.WatchInterval,
+		onReloadError:     cfg.OnReloadError,
+		onMissingVariable: cfg.OnMissingVariable,
+		genderStrategies:  cfg.GenderStrategies,
+		timezone:          cfg.Timezone,
+		internValues:      cfg.InternValues,
+		audiences:         cfg.Audiences,
+		auditSink:         cfg.Audit,
+		escape:            cfg.Escape,
+		regionals:         cfg.Regional,
+	}
+	m.state.Store(&catalog{
+		runtimes: make(map[string]*Runtime),
+		allData:  make(map[string]map[string]interface{}),
+	})
+
+	if m.logger == nil {
+		m.logger = slog.Default()
 	}
 
 	if m.defaultLang == "" {
 		m.defaultLang = "en"
 	}
 
-	if err := m.Load(); err != nil {
-		return nil, err
+	if m.watchInterval <= 0 {
+		m.watchInterval = time.Second
 	}
 
-	if cfg.Watch {
-		go m.watchLoop()
+	return m
+}
+
+// watch fills the catalog with load and, when cfg asks for it, starts
+// background hot-reloading. The watcher subscribes before load runs, so
+// it starts from the state load saw instead of loading everything again;
+// reload makes it load once more first, for catalogs load did not take
+// from the repository.
+func (m *Manager) watch(cfg Config, load func() error, reload bool) error {
+	if !cfg.Watch || m.repo == nil {
+		return load()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	seed, err := m.seedWatch(ctx)
+	if err != nil {
+		m.logger.Warn("mbel: hot reload disabled", "err", err)
+		cancel()
+		return load()
+	}
+	if err := load(); err != nil {
+		cancel()
+		return err
 	}
 
-	return m, nil
+	m.watching.Store(true)
+	m.stopWatch, m.watchDone = cancel, make(chan struct{})
+	go func() {
+		defer close(m.watchDone)
+		defer m.watching.Store(false)
+		if reload {
+			m.reload(ctx)
+		}
+		m.runWatch(ctx, seed)
+	}()
+	return nil
+}
+
+// Close stops the hot reloading started by Config.Watch and waits for it
+// to finish. The manager keeps serving its current catalog. Close may be
+// called more than once.
+func (m *Manager) Close() error {
+	if m.stopWatch != nil {
+		m.stopWatch()
+		<-m.watchDone
+	}
+	return nil
 }
 
-// Load (re)loads all data from the repository
-func (m *Manager) Load() error {
+// Load (re)loads all data from the repository. A ContextRepository stops
+// when ctx is done. The current catalog is kept on any error except a
+// *QuarantineError, which comes with data to serve and is returned after
+// installing it.
+func (m *Manager) Load(ctx context.Context) (err error) {
+	if m.observer != nil {
+		done := m.observer.Reload(ctx)
+		defer func() { done(err) }()
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	langData, err := m.repo.LoadAll()
-	if err != nil {
+	if m.repo == nil {
+		return errors.New("mbel: manager has no repository to load from")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var langData map[string]map[string]interface{}
+	if repo, ok := m.repo.(ContextRepository); ok {
+		langData, err = repo.LoadAllContext(ctx)
+	} else {
+		langData, err = m.repo.LoadAll()
+	}
+	var quarantine *QuarantineError
+	if err != nil && !errors.As(err, &quarantine) {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	prev := m.state.Load()
+	m.install(langData)
+	if m.auditSink != nil && prev.gen > 0 {
+		m.audit(ctx, ChangeReload, prev.allData, m.state.Load().allData)
+	}
+	return err
+}
+
+// install publishes langData as the current catalog; callers hold m.mu
+func (m *Manager) install(langData map[string]map[string]interface{}) {
+	langData, err := ResolveInheritance(langData)
+	if err != nil {
+		m.logger.Warn("mbel: locale inheritance", "err", err)
+	}
+	langData = internCatalog(ShareAudiences(mergeCommon(langData)), m.internValues)
+
 	// Store raw data for lazy loading
-	m.allData = langData
+	next := &catalog{
+		runtimes:   make(map[string]*Runtime),
+		allData:    langData,
+		gen:        m.state.Load().gen + 1,
+		namespaces: topNamespaces(langData),
+	}
 
 	// If not lazy-loading, create all runtimes upfront
 	if !m.lazyLoad {
-		newRuntimes := make(map[string]*Runtime)
 		for lang, data := range langData {
-			newRuntimes[lang] = NewRuntime(data)
+			next.runtimes[lang] = m.newRuntime(lang, data)
 		}
-		m.runtimes = newRuntimes
-	} else {
-		// Clear runtimes to force lazy reload
-		m.runtimes = make(map[string]*Runtime)
 	}
 
-	return nil
+	m.state.Store(next)
+}
+
+// runtime returns the Runtime for lang, creating it on first use when
+// lazy loading is enabled
+func (m *Manager) runtime(lang string) (*Runtime, bool) {
+	return m.runtimeIn(m.state.Load(), lang)
+}
+
+// runtimeIn returns the Runtime for lang in the catalog snapshot cat.
+// Lazily created runtimes are published with a compare-and-swap, so
+// lookups never wait for m.mu (held by Load for a whole reload).
+func (m *Manager) runtimeIn(cat *catalog, lang string) (*Runtime, bool) {
+	if r, ok := cat.runtimes[lang]; ok || !m.lazyLoad {
+		return r, ok
+	}
+	data, ok := cat.allData[lang]
+	if !ok {
+		return nil, false // e.g. a pl-PL fallback candidate
+	}
+
+	r := m.newRuntime(lang, data)
+	for {
+		latest := m.state.Load()
+		if latest.gen != cat.gen {
+			// Reloaded since cat was taken: serve cat's data, uncached
+			return r, true
+		}
+		if existing, ok := latest.runtimes[lang]; ok {
+			return existing, true // another lookup won
+		}
+		runtimes := make(map[string]*Runtime, len(latest.runtimes)+1)
+		for l, rt := range latest.runtimes {
+			runtimes[l] = rt
+		}
+		runtimes[lang] = r
+		if m.state.CompareAndSwap(latest, &catalog{runtimes: runtimes, allData: latest.allData, gen: latest.gen, namespaces: latest.namespaces}) {
+			return r, true
+		}
+	}
+}
+
+// newRuntime creates the Runtime serving lang, wired to the manager's hooks
+func (m *Manager) newRuntime(lang string, data map[string]interface{}) *Runtime {
+	r := NewRuntime(data)
+	if meta, _ := data["__meta"].(map[string]string); meta["lang"] == "" {
+		r.Language = lang // plural rules follow the locale served, not "en"
+	}
+	r.genderStrategy = m.genderStrategy(lang)
+	r.location = m.timezone
+	r.escape = m.escape
+	m.applyRegional(r, lang)
+	if m.onMissingVariable != nil {
+		r.onMissingVar = func(key, name string) {
+			loc, _ := m.Locate(lang, key)
+			m.onMissingVariable(MissingVariable{Lang: lang, Key: key, Name: name, Source: loc})
+		}
+	}
+	return r
+}
+
+// Locate returns where key was defined for lang, when the repository
+// implements SourceLocator
+func (m *Manager) Locate(lang, key string) (SourceLocation, bool) {
+	if l, ok := m.repo.(SourceLocator); ok {
+		return l.Locate(lang, key)
+	}
+	return SourceLocation{}, false
 }
 
 // Get retrieves a localized string
 func (m *Manager) Get(lang, key string, args ...interface{}) string {
-	m.mu.RLock()
-
-	// Lazy load runtime if needed
-	if m.lazyLoad {
-		if _, exists := m.runtimes[lang]; !exists {
-			m.mu.RUnlock()
-			// Load runtime lazily
-			m.mu.Lock()
-			if data, exists := m.allData[lang]; exists && m.runtimes[lang] == nil {
-				m.runtimes[lang] = NewRuntime(data)
-			}
-			m.mu.Unlock()
-			m.mu.RLock()
+	return m.get(context.Background(), lang, key, args...)
+}
+
+// get resolves key and reports misses and fallbacks to the observer
+func (m *Manager) get(ctx context.Context, lang, key string, args ...interface{}) string {
+	args = withTimezone(withExperiment(m.withVars(ctx, args), ExperimentFromContext(ctx)), TimezoneFromContext(ctx))
+	args = withEscape(args, EscapeFromContext(ctx))
+	val, resolved := m.lookup(lang, key, args...)
+	m.report(ctx, lang, key, resolved)
+	return val
+}
+
+// report records the outcome of a lookup served by resolved ("" for a
+// miss) in the metrics and tells the observer
+func (m *Manager) report(ctx context.Context, lang, key, resolved string) {
+	recordLookup(m.metricsLocale(lang), m.metricsNamespace(key), resolved == "", resolved != "" && resolved != lang)
+	if m.observer != nil {
+		switch {
+		case resolved == "":
+			m.observer.MissingKey(ctx, lang, key)
+		case resolved != lang:
+			m.observer.Fallback(ctx, lang, resolved, key)
 		}
 	}
+}
+
+// RenderAll resolves keys for lang against a single catalog snapshot,
+// so the strings of one e-mail or push notification never mix versions
+// when a reload lands half-way. vars (may be nil) fill every message,
+// completed by the global vars; fallbacks, metrics and the observer work
+// as in Get.
+func (m *Manager) RenderAll(lang string, keys []string, vars Vars) map[string]string {
+	cat := m.state.Load()
+	var args []interface{}
+	if vars != nil {
+		args = []interface{}{vars}
+	}
+	args = m.withVars(context.Background(), args)
 
-	defer m.mu.RUnlock()
+	out := make(map[string]string, len(keys))
+	for _, key := range keys {
+		val, resolved := m.lookupIn(cat, lang, key, args...)
+		m.report(context.Background(), lang, key, resolved)
+		out[key] = val
+	}
+	return out
+}
 
-	// Try requested language
-	if r, ok := m.runtimes[lang]; ok {
-		val := r.Get(key, args...)
-		if val != key {
-			return val
+// metricsLocale maps lang to a loaded locale so per-locale metrics stay
+// bounded no matter what clients send
+func (m *Manager) metricsLocale(lang string) string {
+	data := m.state.Load().allData
+	if _, ok := data[lang]; ok {
+		return lang
+	}
+	if len(lang) > 2 {
+		if _, ok := data[lang[:2]]; ok {
+			return lang[:2]
 		}
 	}
+	return "other"
+}
 
-	// Try partial language match (e.g. en-US -> en)
-	if len(lang) > 2 {
-		shortLang := lang[:2]
-		if r, ok := m.runtimes[shortLang]; ok {
-			val := r.Get(key, args...)
-			if val != key {
-				return val
+// metricsNamespace maps key to its top-level namespace when a loaded key
+// shares it, so per-namespace metrics stay bounded no matter what keys
+// callers pass
+func (m *Manager) metricsNamespace(key string) string {
+	i := strings.IndexByte(key, '.')
+	if i <= 0 {
+		return rootNamespace
+	}
+	if m.state.Load().namespaces[key[:i]] {
+		return key[:i]
+	}
+	return "other"
+}
+
+// topNamespaces returns the top-level namespaces of the keys of langData
+func topNamespaces(langData map[string]map[string]interface{}) map[string]bool {
+	out := make(map[string]bool)
+	for _, data := range langData {
+		for k := range data {
+			if i := strings.IndexByte(k, '.'); i > 0 && !strings.HasPrefix(k, "__") {
+				out[k[:i]] = true
 			}
 		}
 	}
+	return out
+}
+
+// lookup returns the value of key and the language it was found in
+// ("" when the key is missing in every candidate language)
+func (m *Manager) lookup(lang, key string, args ...interface{}) (string, string) {
+	return m.lookupIn(m.state.Load(), lang, key, args...)
+}
 
-	// Try default language
-	if lang != m.defaultLang {
-		if r, ok := m.runtimes[m.defaultLang]; ok {
-			return r.Get(key, args...)
+// lookupIn is lookup against the catalog snapshot cat
+func (m *Manager) lookupIn(cat *catalog, lang, key string, args ...interface{}) (string, string) {
+	var buf [3]string
+	for _, l := range m.candidates(buf[:0], lang) {
+		if r, ok := m.runtimeIn(cat, l); ok {
+			if val := r.Get(key, args...); val != key {
+				return val, l
+			}
 		}
 	}
+	return key, "" // Fallback to key
+}
+
+// candidates appends the locales tried for lang to dst, in order: lang
+// itself, its base language (en-US -> en), then the default locale
+func (m *Manager) candidates(dst []string, lang string) []string {
+	dst = append(dst, lang)
+	if len(lang) > 2 {
+		dst = append(dst, lang[:2])
+	}
+	if !containsString(dst, m.defaultLang) {
+		dst = append(dst, m.defaultLang)
+	}
+	return dst
+}
 
-	return key // Fallback to key
+// Resolution explains how a key was resolved: which locale served it
+// and whether a fallback or the missing-key path was taken
+type Resolution struct {
+	Key       string         `json:"key"`
+	Value     string         `json:"value"`
+	Requested string         `json:"requested"` // locale asked for
+	Locale    string         `json:"locale"`    // locale that served the value ("" when missing)
+	Tried     []string       `json:"tried"`     // locales consulted, in order
+	Fallback  bool           `json:"fallback"`  // served by a locale other than Requested
+	Missing   bool           `json:"missing"`   // no locale had the key; Value is the key
+	Source    SourceLocation `json:"source"`    // where the served value is defined, when known
 }
 
-// watchLoop polls for changes
-func (m *Manager) watchLoop() {
-	// Only support watching if repository is file-based
-	fileRepo, ok := m.repo.(*FileRepository)
-	if !ok {
-		// Watching not supported for non-file repos (yet)
-		return
+// GetAny resolves key like Get and also returns the resolution trace,
+// for debug endpoints and support tooling ("why did this user see
+// English on a Polish page?"). It is not counted in metrics nor reported
+// to the Observer.
+func (m *Manager) GetAny(lang, key string, args ...interface{}) Resolution {
+	res := Resolution{Key: key, Value: key, Requested: lang, Missing: true}
+	args = m.withVars(context.Background(), args)
+	for _, l := range m.candidates(nil, lang) {
+		res.Tried = append(res.Tried, l)
+		r, ok := m.runtime(l)
+		if !ok {
+			continue
+		}
+		if val := r.Get(key, args...); val != key {
+			res.Value, res.Locale, res.Missing = val, l, false
+			res.Fallback = l != lang
+			res.Source, _ = m.Locate(l, key)
+			break
+		}
 	}
+	return res
+}
 
-	lastMod := make(map[string]time.Time)
-	ticker := time.NewTicker(1 * time.Second)
+// Lookup resolves key like Get, but returns an error instead of the key
+// when it cannot: one wrapping ErrLocaleNotFound when lang is not loaded
+// (Get would fall back), and ErrKeyNotFound when neither lang nor its
+// fallbacks have key. Like GetAny, it is not counted in metrics.
+func (m *Manager) Lookup(lang, key string, args ...interface{}) (string, error) {
+	if !m.HasLanguage(lang) {
+		return "", fmt.Errorf("%w: %s", ErrLocaleNotFound, lang)
+	}
+	res := m.GetAny(lang, key, args...)
+	if res.Missing {
+		return "", fmt.Errorf("%w: %s (tried %s)", ErrKeyNotFound, key, strings.Join(res.Tried, ", "))
+	}
+	return res.Value, nil
+}
 
-	for range ticker.C {
-		changed := false
-		filepath.Walk(fileRepo.RootPath, func(path string, info os.FileInfo, err error) error {
-			if err != nil || info.IsDir() || !strings.HasSuffix(path, ".mbel") {
-				return nil
+// Preload eagerly creates the runtime for lang and parses the listed
+// messages (every case of logic blocks included), so latency-sensitive
+// handlers never pay first-hit costs. Keys missing in lang are warmed in
+// the fallback locale Get would use.
+func (m *Manager) Preload(lang string, keys []string) {
+	candidates := m.candidates(nil, lang)
+
+	for _, key := range keys {
+		for _, l := range candidates {
+			r, ok := m.runtime(l)
+			if !ok {
+				continue
 			}
-			if last, exists := lastMod[path]; !exists || info.ModTime().After(last) {
-				lastMod[path] = info.ModTime()
-				changed = true
+			if _, exists := r.value(key); exists {
+				r.Preload(key)
+				break
 			}
-			return nil
-		})
+		}
+	}
+}
+
+// Languages returns the codes of all loaded locales, sorted
+func (m *Manager) Languages() []string {
+	allData := m.state.Load().allData
+
+	langs := make([]string, 0, len(allData))
+	for lang := range allData {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Keys returns the translation keys loaded for lang, sorted
+// (internal "__" entries such as __meta are omitted)
+func (m *Manager) Keys(lang string) []string {
+	return SortedKeys(m.state.Load().allData[lang])
+}
+
+// HasLanguage reports whether lang is loaded
+func (m *Manager) HasLanguage(lang string) bool {
+	_, ok := m.state.Load().allData[lang]
+	return ok
+}
+
+// ErrAlreadyWatching is returned by Watch while another Watch call on the
+// same manager is running
+var ErrAlreadyWatching = errors.New("mbel: manager is already being watched")
+
+// Watch hot-reloads the catalog until ctx is done, then returns ctx.Err().
+// It first reloads once, picking up changes made while nobody was
+// watching, so it can be stopped and restarted freely. FileRepository is
+// polled every Config.WatchInterval; repositories implementing
+// ChangeNotifier reload on notification. Failed reloads go to
+// Config.OnReloadError and do not stop watching; other repositories
+// cannot be watched and return an error immediately.
+func (m *Manager) Watch(ctx context.Context) error {
+	if !m.watching.CompareAndSwap(false, true) {
+		return ErrAlreadyWatching
+	}
+	defer m.watching.Store(false)
 
-		if changed {
-			// Reload in background
-			m.Load()
+	seed, err := m.seedWatch(ctx)
+	if err != nil {
+		return err
+	}
+	m.reload(ctx)
+	return m.runWatch(ctx, seed)
+}
+
+// watchSeed is what a watcher compares the repository against: the
+// subscription of a ChangeNotifier, or the file times of a
+// FileRepository, taken before the load it follows
+type watchSeed struct {
+	changes <-chan struct{}
+	lastMod map[string]time.Time
+}
+
+// seedWatch subscribes to the repository's changes, or records its file
+// times; repositories that cannot be watched are an error
+func (m *Manager) seedWatch(ctx context.Context) (watchSeed, error) {
+	switch repo := m.repo.(type) {
+	case ChangeNotifier:
+		return watchSeed{changes: repo.Changes(ctx)}, nil
+	case *FileRepository:
+		lastMod := make(map[string]time.Time)
+		repo.changed(lastMod)
+		return watchSeed{lastMod: lastMod}, nil
+	}
+	return watchSeed{}, fmt.Errorf("mbel: repository %T cannot be watched", m.repo)
+}
+
+// runWatch reloads on every change after seed until ctx is done
+func (m *Manager) runWatch(ctx context.Context, seed watchSeed) error {
+	if seed.changes != nil {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-seed.changes:
+				m.reload(ctx)
+			}
 		}
 	}
+
+	repo := m.repo.(*FileRepository)
+	ticker := time.NewTicker(m.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if repo.changed(seed.lastMod) {
+				m.reload(ctx)
+			}
+		}
+	}
+}
+
+// reload runs Load and reports a failure, unless watching was stopped
+// meanwhile
+func (m *Manager) reload(ctx context.Context) {
+	err := m.Load(ctx)
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	if m.onReloadError != nil {
+		m.onReloadError(err)
+		return
+	}
+	m.logger.Error("mbel: reload failed", "err", err)
 }
 
 // ============================================================================
 // File Repository Implementation
 // ============================================================================
 
-// FileRepository loads MBEL files from the filesystem
+// FileRepository loads MBEL files from the filesystem:
+//
+//	locales/en.mbel               locale "en"
+//	locales/pt-BR/checkout.mbel   locale "pt-BR", namespace "checkout"
+//	locales/_common/brand.mbel    shared: namespace "brand" in every locale
+//	locales/.git/...              hidden: skipped
+//
+// The first path segment names the locale and must be a valid BCP-47
+// tag; @lang metadata in a file overrides it. Files that resolve to no
+// locale are skipped with a warning instead of becoming a catalog.
+// Files in "_common" (also "common" or "shared", or a root _common.mbel)
+// are returned once under CommonLocale.
 type FileRepository struct {
 	RootPath string
-	mu       sync.Mutex
-	cache    map[string]cachedFile
+	Logger   *slog.Logger  // nil = slog.Default()
+	Cache    *CompileCache // On-disk compile cache shared with the CLI (nil = disabled)
+
+	// Interpolation is the placeholder style of files without an
+	// @interpolation line ("" = InterpolationSingle)
+	Interpolation Interpolation
+
+	mu    sync.Mutex
+	cache map[string]cachedFile
+
+	// Where each loaded key came from; line numbers are only worked out
+	// (and cached per file) when Locate is called
+	origins    map[string]map[string]keyOrigin // lang -> key -> origin
+	sourceMaps map[string]SourceMap            // path -> source map
+}
+
+type keyOrigin struct {
+	path string
+	key  string // key as written in the file (without folder namespace)
 }
 
 type cachedFile struct {
 	modTime time.Time
+	style   Interpolation // the Interpolation data was compiled with
 	data    map[string]interface{}
 }
 
+func (r *FileRepository) logger() *slog.Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return slog.Default()
+}
+
+// changed reports whether any .mbel file under RootPath was added,
+// modified or removed since the times recorded in lastMod, updating them
+func (r *FileRepository) changed(lastMod map[string]time.Time) bool {
+	changed := false
+	seen := make(map[string]bool, len(lastMod))
+	filepath.Walk(r.RootPath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() && path != r.RootPath && skipLocaleDir(info.Name()) {
+			return filepath.SkipDir
+		}
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".mbel") {
+			return nil
+		}
+		seen[path] = true
+		if last, exists := lastMod[path]; !exists || info.ModTime().After(last) {
+			lastMod[path] = info.ModTime()
+			changed = true
+		}
+		return nil
+	})
+	for path := range lastMod {
+		if !seen[path] {
+			delete(lastMod, path)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Locate returns the file and line defining key in lang, as of the last
+// LoadAll. The file is re-parsed on first use for diagnostics.
+func (r *FileRepository) Locate(lang, key string) (SourceLocation, bool) {
+	r.mu.Lock()
+	o, ok := r.origins[lang][key]
+	if !ok {
+		o, ok = r.origins[CommonLocale][key]
+	}
+	sm, parsed := r.sourceMaps[o.path]
+	r.mu.Unlock()
+	if !ok {
+		return SourceLocation{}, false
+	}
+
+	if !parsed {
+		content, err := os.ReadFile(o.path)
+		if err != nil {
+			return SourceLocation{}, false
+		}
+		sm = BuildSourceMap(NewParser(NewLexer(string(content))).ParseProgram(), o.path)
+
+		r.mu.Lock()
+		if r.sourceMaps != nil {
+			r.sourceMaps[o.path] = sm
+		}
+		r.mu.Unlock()
+	}
+
+	loc, ok := sm[o.key]
+	return loc, ok
+}
+
 // LoadAll scans the directory and compiles all .mbel files
 func (r *FileRepository) LoadAll() (map[string]map[string]interface{}, error) {
 	langData := make(map[string]map[string]interface{})
+	origins := make(map[string]map[string]keyOrigin)
+	quarantined := make(map[string]error)
+
+	// Allow a zero-value &FileRepository{RootPath: ...} to be used directly
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = make(map[string]cachedFile)
+	}
+	r.mu.Unlock()
 
 	err := filepath.Walk(r.RootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if info.IsDir() {
+			if path != r.RootPath && skipLocaleDir(info.Name()) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		if !strings.HasSuffix(path, ".mbel") {
 			return nil
 		}
 
-		// Determine language and namespace
+		// The first segment is the locale (or a shared folder), the rest the namespace
 		rel, _ := filepath.Rel(r.RootPath, path)
 		parts := strings.Split(rel, string(os.PathSeparator))
 
-		if len(parts) == 0 {
-			return nil
-		}
+		namespace := fileNamespace(parts)
 
-		lang := parts[0]
-		if strings.HasSuffix(lang, ".mbel") {
-			lang = strings.TrimSuffix(lang, ".mbel")
+		resMap, err := r.compile(path, info)
+		if err != nil {
+			prev, ok := r.lastGood(path)
+			if !ok {
+				return err
+			}
+			r.logger().Warn("mbel: file quarantined, serving its previous keys", "file", path, "err", err)
+			quarantined[path] = err
+			resMap = prev
 		}
 
-		namespace := ""
-		if len(parts) > 1 {
-			dir := filepath.Dir(strings.Join(parts[1:], "/"))
-			fname := strings.TrimSuffix(filepath.Base(path), ".mbel")
-
-			if dir == "." {
-				namespace = fname
-			} else {
-				namespace = strings.ReplaceAll(dir, "/", ".") + "." + fname
-			}
+		if parts[0] == CommonLocale+".mbel" || len(parts) > 1 && sharedLocaleDirs[parts[0]] {
+			r.merge(langData, origins, CommonLocale, namespace, path, resMap)
+			return nil
 		}
 
-		content, err := ioutil.ReadFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", path, err)
+		lang, ok := r.localeOf(path, parts[0], resMap)
+		if !ok {
+			return nil
 		}
+		r.merge(langData, origins, lang, namespace, path, resMap)
+		return nil
+	})
+
+	if err == nil {
+		r.warnAmbiguousLocales(langData)
+	}
 
-		// Check cache
+	if err == nil {
 		r.mu.Lock()
-		cached, ok := r.cache[path]
+		r.origins = origins
+		r.sourceMaps = make(map[string]SourceMap)
 		r.mu.Unlock()
-		if ok && !info.ModTime().After(cached.modTime) {
-			resMap := cached.data
-			if _, exists := langData[lang]; !exists {
-				langData[lang] = make(map[string]interface{})
-				langData[lang]["__meta"] = map[string]string{"lang": lang}
-			}
-			for k, v := range resMap {
-				key := k
-				if namespace != "" && !strings.HasPrefix(k, "__") {
-					key = namespace + "." + k
-				}
-				langData[lang][key] = v
-			}
-			return nil
-		}
+	}
 
-		l := NewLexer(string(content))
-		p := NewParser(l)
-		program := p.ParseProgram()
+	if err == nil && len(quarantined) > 0 {
+		err = &QuarantineError{Files: quarantined}
+	}
+	return langData, err
+}
 
-		if len(p.Errors()) > 0 {
-			fmt.Fprintf(os.Stderr, "MBEL Syntax Error in %s: %v\n", path, p.Errors())
-		}
+// QuarantineError is returned by FileRepository.LoadAll, together with
+// the data, when files that loaded before no longer compile: their
+// previous keys are served until they are fixed, instead of losing keys
+// or failing the whole reload. Manager.Load installs the data and
+// returns the error, so hot reload reports it to Config.OnReloadError.
+type QuarantineError struct {
+	Files map[string]error // path -> why it was quarantined
+}
 
-		c := NewCompiler()
-		res, err := c.Compile(program)
-		if err != nil {
-			return fmt.Errorf("compilation failed for %s: %w", path, err)
-		}
+func (e *QuarantineError) Error() string {
+	paths := make([]string, 0, len(e.Files))
+	for path := range e.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	msgs := make([]string, len(paths))
+	for i, path := range paths {
+		msgs[i] = e.Files[path].Error()
+	}
+	return fmt.Sprintf("mbel: %d file(s) quarantined: %s", len(paths), strings.Join(msgs, "; "))
+}
 
-		resMap, ok := res.(map[string]interface{})
-		if !ok {
-			return nil
-		}
+// Unwrap returns the errors of the quarantined files, so errors.Is finds
+// ErrParse or ErrCompile in them
+func (e *QuarantineError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Files))
+	for _, err := range e.Files {
+		errs = append(errs, err)
+	}
+	return errs
+}
 
-		// Store in cache
-		r.mu.Lock()
-		r.cache[path] = cachedFile{modTime: info.ModTime(), data: resMap}
-		r.mu.Unlock()
+// lastGood returns the data of the last successful compile of path
+func (r *FileRepository) lastGood(path string) (map[string]interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cached, ok := r.cache[path]
+	return cached.data, ok
+}
 
-		if _, exists := langData[lang]; !exists {
-			langData[lang] = make(map[string]interface{})
-			langData[lang]["__meta"] = map[string]string{"lang": lang}
-		}
+// compile compiles one file, reusing the result while it is unmodified
+func (r *FileRepository) compile(path string, info os.FileInfo) (map[string]interface{}, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[path]
+	r.mu.Unlock()
+	if ok && !info.ModTime().After(cached.modTime) && cached.style == r.Interpolation {
+		return cached.data, nil
+	}
 
-		for k, v := range resMap {
-			key := k
-			if namespace != "" && !strings.HasPrefix(k, "__") {
-				key = namespace + "." + k
-			}
-			langData[lang][key] = v
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	resMap, errs, err := CompileSourceWithStyle(content, r.Cache, r.Interpolation)
+	if len(errs) > 0 {
+		if ok {
+			// Loaded before: keep its previous keys rather than lose those past the error
+			return nil, fmt.Errorf("syntax errors in %s: %w", path, &ParseError{Errors: errs})
 		}
+		r.logger().Warn("mbel: syntax error", "file", path, "errors", errs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("compilation failed for %s: %w", path, err)
+	}
 
-		return nil
-	})
+	r.mu.Lock()
+	r.cache[path] = cachedFile{modTime: info.ModTime(), style: r.Interpolation, data: resMap}
+	r.mu.Unlock()
+	return resMap, nil
+}
 
-	return langData, err
+// FileNamespace returns the key prefix FileRepository gives the keys of
+// the file at path when loading root ("" for files outside root)
+func FileNamespace(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return fileNamespace(strings.Split(filepath.ToSlash(rel), "/"))
+}
+
+// fileNamespace returns the key prefix of a file from its path segments
+// below RootPath: everything after the locale folder, file name included
+// ("pl/shop/cart.mbel" -> "shop.cart"; "pl.mbel" -> "")
+func fileNamespace(parts []string) string {
+	if len(parts) < 2 {
+		return ""
+	}
+	names := append([]string(nil), parts[1:]...)
+	names[len(names)-1] = strings.TrimSuffix(names[len(names)-1], ".mbel")
+	return strings.Join(names, ".")
+}
+
+// merge adds the compiled keys of one file to langData, prefixed with
+// its folder namespace, and records where each came from
+func (r *FileRepository) merge(langData map[string]map[string]interface{}, origins map[string]map[string]keyOrigin, lang, namespace, path string, resMap map[string]interface{}) {
+	if _, exists := langData[lang]; !exists {
+		langData[lang] = make(map[string]interface{})
+		langData[lang]["__meta"] = map[string]string{"lang": lang}
+		origins[lang] = make(map[string]keyOrigin)
+	}
+
+	for k, v := range resMap {
+		if k == scheduleDataKey || k == audienceDataKey {
+			// Per-key metadata names keys; give them the namespace too
+			merged, _ := langData[lang][k].(map[string]string)
+			if merged == nil {
+				merged = make(map[string]string)
+				langData[lang][k] = merged
+			}
+			tags, ok := v.(map[string]string)
+			if !ok {
+				continue
+			}
+			for sk, sv := range tags {
+				if k == scheduleDataKey && strings.HasSuffix(sk, "@"+alternateMeta) {
+					sv = joinKey(namespace, sv)
+				}
+				merged[joinKey(namespace, sk)] = sv
+			}
+			continue
+		}
+		if k == "__ai" {
+			merged, _ := langData[lang][k].(map[string][]map[string]string)
+			if merged == nil {
+				merged = make(map[string][]map[string]string)
+				langData[lang][k] = merged
+			}
+			fileAI, _ := v.(map[string][]map[string]string)
+			for ak, anns := range fileAI {
+				if ak != "__global" {
+					ak = joinKey(namespace, ak)
+				}
+				merged[ak] = append(merged[ak], anns...)
+			}
+			continue
+		}
+		if k == "__meta" {
+			// Merge metadata across a locale's files; later files win
+			merged := make(map[string]string)
+			old, _ := langData[lang][k].(map[string]string)
+			for mk, mv := range old {
+				merged[mk] = mv
+			}
+			meta, _ := v.(map[string]string)
+			for mk, mv := range meta {
+				merged[mk] = mv
+			}
+			langData[lang][k] = merged
+			continue
+		}
+		key := k
+		if namespace != "" && !strings.HasPrefix(k, "__") {
+			key = namespace + "." + k
+		}
+		langData[lang][key] = v
+		origins[lang][key] = keyOrigin{path: path, key: k}
+	}
 }
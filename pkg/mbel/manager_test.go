@@ -0,0 +1,142 @@
+package mbel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetAnyTrace(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello", "only_en": "English only"},
+		"pl": {"title": "Cześć"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := m.GetAny("pl-PL", "title")
+	if res.Value != "Cześć" || res.Locale != "pl" || !res.Fallback || res.Missing {
+		t.Errorf("base-language fallback: %+v", res)
+	}
+	if !reflect.DeepEqual(res.Tried, []string{"pl-PL", "pl"}) {
+		t.Errorf("tried %v", res.Tried)
+	}
+
+	res = m.GetAny("pl", "only_en")
+	if res.Value != "English only" || res.Locale != "en" || !res.Fallback {
+		t.Errorf("default-locale fallback: %+v", res)
+	}
+
+	res = m.GetAny("pl", "nope")
+	if !res.Missing || res.Value != "nope" || res.Locale != "" || !reflect.DeepEqual(res.Tried, []string{"pl", "en"}) {
+		t.Errorf("missing key: %+v", res)
+	}
+
+	if res := m.GetAny("en", "title"); res.Fallback || res.Locale != "en" || len(res.Tried) != 1 {
+		t.Errorf("direct hit: %+v", res)
+	}
+}
+
+func TestRenderAll(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"subject": "Hi {name}", "footer": "Bye"},
+		"pl": {"subject": "Cześć {name}"},
+	})
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en", LazyLoad: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := m.RenderAll("pl", []string{"subject", "footer", "nope"}, Vars{"name": "Ola"})
+	want := map[string]string{"subject": "Cześć Ola", "footer": "Bye", "nope": "nope"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderAll = %v", got)
+	}
+
+	// A snapshot taken before a reload keeps serving its own strings,
+	// even for locales it had not loaded yet
+	cat := m.state.Load()
+	repo.Set("en", "footer", "Goodbye")
+	if err := m.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.lookupIn(cat, "en", "footer"); v != "Bye" {
+		t.Errorf("old snapshot served %q", v)
+	}
+	if v := m.RenderAll("en", []string{"footer"}, nil)["footer"]; v != "Goodbye" {
+		t.Errorf("after reload = %q", v)
+	}
+}
+
+func TestLazyLookupsDuringReload(t *testing.T) {
+	repo := slowRepo{NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello"},
+		"pl": {"title": "Cześć"},
+	}), 0}
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en", LazyLoad: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo.delay = 500 * time.Millisecond
+	m.repo = repo
+	done := make(chan error, 1)
+	go func() { done <- m.Load(context.Background()) }()
+	time.Sleep(20 * time.Millisecond) // Load now holds m.mu
+
+	start := time.Now()
+	if got := m.Get("pl-PL", "title"); got != "Cześć" {
+		t.Errorf("Get = %q", got)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("lazy lookup waited %v for the reload", elapsed)
+	}
+	if _, ok := m.state.Load().runtimes["pl"]; !ok {
+		t.Error("lazily created runtime was not cached")
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// slowRepo is a remote-like repository answering after delay
+type slowRepo struct {
+	*MemoryRepository
+	delay time.Duration
+}
+
+func (r slowRepo) LoadAllContext(ctx context.Context) (map[string]map[string]interface{}, error) {
+	select {
+	case <-time.After(r.delay):
+		return r.LoadAll()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	repo := slowRepo{NewMemoryRepository(map[string]map[string]interface{}{"en": {"title": "Hello"}}), 0}
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo.Set("en", "title", "Hi")
+	repo.delay = time.Minute
+	m.repo = repo
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Load(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Load = %v, want a deadline error", err)
+	}
+	if got := m.Get("en", "title"); got != "Hello" {
+		t.Errorf("failed load replaced the catalog: %q", got)
+	}
+
+	if err := m.Load(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Load with a done context = %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package mbeltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+// AssertGolden compiles the locale directory at root and compares the
+// result, as indented JSON keyed by language, with the committed file
+// golden. Changes to compiled output thus show up as reviewable diffs.
+//
+// Run the tests with -update (if the test binary defines that flag) or
+// with MBEL_UPDATE_GOLDEN=1 to rewrite golden instead of comparing.
+func AssertGolden(t testing.TB, root, golden string) {
+	t.Helper()
+
+	data, err := (&mbel.FileRepository{RootPath: root}).LoadAll()
+	if err != nil {
+		t.Fatalf("mbeltest: compiling %s: %v", root, err)
+	}
+
+	got, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		t.Fatalf("mbeltest: marshaling %s: %v", root, err)
+	}
+	got = append(got, '\n')
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatalf("mbeltest: %v", err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatalf("mbeltest: writing %s: %v", golden, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("mbeltest: reading %s: %v (run with -update to create it)", golden, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("mbeltest: compiled output of %s differs from %s (run with -update to accept)\n--- got ---\n%s", root, golden, got)
+	}
+}
+
+func updateGolden() bool {
+	if os.Getenv("MBEL_UPDATE_GOLDEN") == "1" {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		return f.Value.String() == "true"
+	}
+	return false
+}
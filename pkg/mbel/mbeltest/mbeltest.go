@@ -0,0 +1,90 @@
+// Package mbeltest provides helpers for checking MBEL catalogs from go test.
+//
+//	func TestLocales(t *testing.T) {
+//		mbeltest.AssertAllLocalesComplete(t, "../locales")
+//	}
+package mbeltest
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+// NewManager returns a Manager serving the given canned strings
+// (map[lang]map[key]value), for tests of code that translates
+func NewManager(t testing.TB, defaultLocale string, catalog map[string]map[string]string) *mbel.Manager {
+	t.Helper()
+
+	data := make(map[string]map[string]interface{})
+	for lang, entries := range catalog {
+		data[lang] = make(map[string]interface{})
+		for k, v := range entries {
+			data[lang][k] = v
+		}
+	}
+
+	m, err := mbel.NewManagerWithRepo(mbel.NewMemoryRepository(data), mbel.Config{DefaultLocale: defaultLocale})
+	if err != nil {
+		t.Fatalf("mbeltest: creating manager: %v", err)
+	}
+	return m
+}
+
+// Load loads the locale directory at root, failing the test on error
+func Load(t testing.TB, root string) *mbel.Manager {
+	t.Helper()
+
+	m, err := mbel.NewManager(root, mbel.Config{})
+	if err != nil {
+		t.Fatalf("mbeltest: loading %s: %v", root, err)
+	}
+	return m
+}
+
+// AssertKeyExists fails the test if key is not defined for lang
+// (fallback locales are not consulted)
+func AssertKeyExists(t testing.TB, m *mbel.Manager, lang, key string) {
+	t.Helper()
+
+	for _, k := range m.Keys(lang) {
+		if k == key {
+			return
+		}
+	}
+	t.Errorf("mbeltest: key %q missing in locale %q", key, lang)
+}
+
+// AssertAllLocalesComplete fails the test for every key that is defined
+// in some locale under root but missing in another
+func AssertAllLocalesComplete(t testing.TB, root string) {
+	t.Helper()
+
+	m := Load(t, root)
+	langs := m.Languages()
+
+	all := make(map[string]bool)
+	perLang := make(map[string]map[string]bool)
+	for _, lang := range langs {
+		perLang[lang] = make(map[string]bool)
+		for _, k := range m.Keys(lang) {
+			perLang[lang][k] = true
+			all[k] = true
+		}
+	}
+
+	for _, lang := range langs {
+		var missing []string
+		for k := range all {
+			if !perLang[lang][k] {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			t.Errorf("mbeltest: locale %q is missing %d keys: %s", lang, len(missing), strings.Join(missing, ", "))
+		}
+	}
+}
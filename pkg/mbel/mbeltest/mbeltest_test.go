@@ -0,0 +1,61 @@
+package mbeltest
+
+import (
+	"testing"
+	"time"
+
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+func TestAssertAllLocalesComplete(t *testing.T) {
+	AssertAllLocalesComplete(t, "testdata/complete")
+
+	rec := &recorder{TB: t}
+	AssertAllLocalesComplete(rec, "testdata/incomplete")
+	if len(rec.errors) != 1 {
+		t.Fatalf("expected one failure for incomplete catalog, got %v", rec.errors)
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	m := NewManager(t, "en", map[string]map[string]string{
+		"en": {"title": "Hello"},
+	})
+	AssertKeyExists(t, m, "en", "title")
+
+	if got := m.Get("en", "title"); got != "Hello" {
+		t.Errorf("expected %q, got %q", "Hello", got)
+	}
+}
+
+func TestTempLocalesWatch(t *testing.T) {
+	l := TempLocales(t, map[string]string{
+		"en/app.mbel":  "title = \"One\"\n",
+		"en/shop.mbel": "cart = \"Cart\"\n",
+	})
+	m := l.Watch(mbel.Config{DefaultLocale: "en"})
+	if got := m.Get("en", "app.title"); got != "One" {
+		t.Fatalf("initial load: got %q", got)
+	}
+
+	l.Write("en/app.mbel", "title = \"Two\"\n")
+	AssertEventually(t, m, "en", "app.title", "Two", 2*time.Second)
+
+	l.Write("pl/app.mbel", "title = \"Dwa\"\n")
+	AssertEventually(t, m, "pl", "app.title", "Dwa", 2*time.Second)
+
+	l.Remove("en/shop.mbel")
+	Eventually(t, 2*time.Second, func() bool { return len(m.Keys("en")) == 1 })
+}
+
+// recorder captures failures instead of failing the enclosing test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
@@ -0,0 +1,131 @@
+package mbeltest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	mbel "github.com/makkiattooo/MBEL/pkg/mbel"
+)
+
+// Locales is a temporary locale tree for testing hot reload:
+//
+//	func TestReload(t *testing.T) {
+//		l := mbeltest.TempLocales(t, map[string]string{"en/app.mbel": `title = "One"`})
+//		m := l.Watch(mbel.Config{DefaultLocale: "en"})
+//		l.Write("en/app.mbel", `title = "Two"`)
+//		mbeltest.AssertEventually(t, m, "en", "app.title", "Two", time.Second)
+//	}
+type Locales struct {
+	Root string
+
+	t   testing.TB
+	mu  sync.Mutex
+	mod time.Time // modification time of the last write
+}
+
+// TempLocales creates a locale tree in a temporary directory removed
+// after the test, from files keyed by path below the root
+// ("en/app.mbel")
+func TempLocales(t testing.TB, files map[string]string) *Locales {
+	t.Helper()
+
+	l := &Locales{Root: t.TempDir(), t: t, mod: time.Now().Add(-time.Hour)}
+	for rel, content := range files {
+		l.Write(rel, content)
+	}
+	return l
+}
+
+// Write creates or replaces the file at rel. Each write gets a later
+// modification time than the one before, so polling watchers notice it
+// even on file systems with coarse timestamps.
+func (l *Locales) Write(rel, content string) {
+	l.t.Helper()
+
+	path := filepath.Join(l.Root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		l.t.Fatalf("mbeltest: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		l.t.Fatalf("mbeltest: writing %s: %v", rel, err)
+	}
+
+	l.mu.Lock()
+	l.mod = l.mod.Add(time.Second)
+	mod := l.mod
+	l.mu.Unlock()
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		l.t.Fatalf("mbeltest: %v", err)
+	}
+}
+
+// Remove deletes the file at rel
+func (l *Locales) Remove(rel string) {
+	l.t.Helper()
+
+	if err := os.Remove(filepath.Join(l.Root, filepath.FromSlash(rel))); err != nil {
+		l.t.Fatalf("mbeltest: removing %s: %v", rel, err)
+	}
+}
+
+// Watch returns a Manager for the tree that hot-reloads until the test
+// ends. cfg.WatchInterval defaults to 5ms.
+func (l *Locales) Watch(cfg mbel.Config) *mbel.Manager {
+	l.t.Helper()
+
+	if cfg.WatchInterval == 0 {
+		cfg.WatchInterval = 5 * time.Millisecond
+	}
+	cfg.Watch = false // watched below, so it stops with the test
+	m, err := mbel.NewManager(l.Root, cfg)
+	if err != nil {
+		l.t.Fatalf("mbeltest: loading %s: %v", l.Root, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Watch(ctx)
+	}()
+	l.t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return m
+}
+
+// Eventually fails the test unless cond becomes true within d
+func Eventually(t testing.TB, d time.Duration, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(d)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("mbeltest: condition not met within %v", d)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// AssertEventually fails the test unless m.Get(lang, key) returns want
+// within d, e.g. after a file was changed under a watching Manager
+func AssertEventually(t testing.TB, m *mbel.Manager, lang, key, want string, d time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(d)
+	for {
+		got := m.Get(lang, key)
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("mbeltest: %s %s = %q after %v, want %q", lang, key, got, d, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
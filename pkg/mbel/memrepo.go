@@ -0,0 +1,132 @@
+package mbel
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryRepository is a mutable in-memory Repository, for tests, admin
+// tooling and catalogs managed by the application itself. It implements
+// ChangeNotifier, so a watching manager reloads after every mutation.
+// The zero value is an empty repository ready for use.
+type MemoryRepository struct {
+	mu   sync.RWMutex
+	data map[string]map[string]interface{}
+	subs []chan struct{}
+}
+
+// NewMemoryRepository creates a repository holding a copy of data
+// (map[lang]map[key]value; values are strings or *RuntimeBlock)
+func NewMemoryRepository(data map[string]map[string]interface{}) *MemoryRepository {
+	r := &MemoryRepository{}
+	r.Replace(data)
+	return r
+}
+
+// LoadAll returns a copy of the stored data
+func (r *MemoryRepository) LoadAll() (map[string]map[string]interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return copyLangData(r.data), nil
+}
+
+// Set stores value under key for lang
+func (r *MemoryRepository) Set(lang, key string, value interface{}) {
+	r.mu.Lock()
+	r.lang(lang)[key] = value
+	r.mu.Unlock()
+	r.notify()
+}
+
+// SetMany stores all values for lang in one change
+func (r *MemoryRepository) SetMany(lang string, values map[string]interface{}) {
+	r.mu.Lock()
+	data := r.lang(lang)
+	for k, v := range values {
+		data[k] = v
+	}
+	r.mu.Unlock()
+	r.notify()
+}
+
+// Delete removes key from lang
+func (r *MemoryRepository) Delete(lang, key string) {
+	r.mu.Lock()
+	delete(r.data[lang], key)
+	r.mu.Unlock()
+	r.notify()
+}
+
+// DeleteLanguage removes lang with all its keys
+func (r *MemoryRepository) DeleteLanguage(lang string) {
+	r.mu.Lock()
+	delete(r.data, lang)
+	r.mu.Unlock()
+	r.notify()
+}
+
+// Replace swaps the whole catalog for a copy of data
+func (r *MemoryRepository) Replace(data map[string]map[string]interface{}) {
+	r.mu.Lock()
+	r.data = copyLangData(data)
+	r.mu.Unlock()
+	r.notify()
+}
+
+// Changes returns a channel receiving a value after each mutation until
+// ctx is done. Notifications are coalesced: a slow reader sees at least
+// one per burst.
+func (r *MemoryRepository) Changes(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	r.mu.Lock()
+	r.subs = append(r.subs, ch)
+	r.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i, sub := range r.subs {
+			if sub == ch {
+				r.subs = append(r.subs[:i], r.subs[i+1:]...)
+				break
+			}
+		}
+	})
+	return ch
+}
+
+// lang returns the map for lang, creating it; callers hold r.mu
+func (r *MemoryRepository) lang(lang string) map[string]interface{} {
+	if r.data == nil {
+		r.data = make(map[string]map[string]interface{})
+	}
+	data, ok := r.data[lang]
+	if !ok {
+		data = make(map[string]interface{})
+		r.data[lang] = data
+	}
+	return data
+}
+
+func (r *MemoryRepository) notify() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, ch := range r.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func copyLangData(data map[string]map[string]interface{}) map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{}, len(data))
+	for lang, entries := range data {
+		m := make(map[string]interface{}, len(entries))
+		for k, v := range entries {
+			m[k] = v
+		}
+		out[lang] = m
+	}
+	return out
+}
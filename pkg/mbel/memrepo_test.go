@@ -0,0 +1,51 @@
+package mbel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello"},
+	})
+	repo.Set("pl", "title", "Cześć")
+	repo.SetMany("en", map[string]interface{}{"bye": "Bye", "ok": "OK"})
+	repo.Delete("en", "ok")
+
+	data, _ := repo.LoadAll()
+	if len(data["en"]) != 2 || data["pl"]["title"] != "Cześć" {
+		t.Fatalf("unexpected data: %v", data)
+	}
+
+	// LoadAll hands out copies
+	data["en"]["title"] = "changed"
+	if again, _ := repo.LoadAll(); again["en"]["title"] != "Hello" {
+		t.Error("LoadAll result aliases repository state")
+	}
+
+	var zero MemoryRepository
+	zero.Set("en", "k", "v")
+	if d, _ := zero.LoadAll(); d["en"]["k"] != "v" {
+		t.Error("zero value repository should be usable")
+	}
+}
+
+func TestMemoryRepositoryWatch(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Hello"},
+	})
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en", Watch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo.Set("en", "title", "Hi")
+	deadline := time.Now().Add(2 * time.Second)
+	for m.Get("en", "title") != "Hi" {
+		if time.Now().After(deadline) {
+			t.Fatal("manager did not reload after Set")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
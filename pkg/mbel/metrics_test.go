@@ -0,0 +1,89 @@
+package mbel
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsBreakdown(t *testing.T) {
+	m, err := NewManagerWithRepo(&staticRepo{data: map[string]map[string]interface{}{
+		"en": {"auth.login": "Log in", "title": "Hello"},
+		"pl": {"title": "Cześć"},
+	}}, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResetMetrics()
+	defer ResetMetrics()
+
+	m.Get("pl", "title")
+	m.Get("pl", "auth.login")    // fallback to en
+	m.Get("pl-PL", "auth.nope")  // missing, counted for pl
+	m.Get("xx", "title")         // unknown locale
+	m.Get("en", "user-42.title") // unknown namespace
+
+	locales := LocaleMetrics()
+	if got := locales["pl"]; got != (LabelMetrics{Gets: 3, Misses: 1, Fallbacks: 1}) {
+		t.Errorf("pl: got %+v", got)
+	}
+	if got := locales["other"]; got.Gets != 1 {
+		t.Errorf("other: got %+v", got)
+	}
+
+	namespaces := NamespaceMetrics()
+	if got := namespaces["auth"]; got != (LabelMetrics{Gets: 2, Misses: 1, Fallbacks: 1}) {
+		t.Errorf("auth: got %+v", got)
+	}
+	if got := namespaces[rootNamespace]; got.Gets != 2 {
+		t.Errorf("root: got %+v", got)
+	}
+	if got := namespaces["other"]; got.Gets != 1 {
+		t.Errorf("other: got %+v", got)
+	}
+	if _, ok := namespaces["user-42"]; ok {
+		t.Error("unknown namespace got its own label")
+	}
+
+	if GetMetrics()["misses.namespace.auth"] != 1 {
+		t.Error("GetMetrics should include the breakdown")
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`mbel_locale_lookups_total{locale="pl"} 3`,
+		`mbel_namespace_missing_keys_total{namespace="auth"} 1`,
+		"# TYPE mbel_get_calls_total counter",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	PublishExpvar()
+	PublishExpvar() // expvar.Publish panics on a second registration
+
+	v := expvar.Get("mbel")
+	if v == nil {
+		t.Fatal("mbel expvar not published")
+	}
+	var published map[string]int64
+	if err := json.Unmarshal([]byte(v.String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	want := GetMetrics()
+	if len(published) != len(want) {
+		t.Errorf("published %v, want the keys of %v", published, want)
+	}
+	for key := range want {
+		if _, ok := published[key]; !ok {
+			t.Errorf("published metrics lack %q", key)
+		}
+	}
+}
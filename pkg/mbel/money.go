@@ -0,0 +1,45 @@
+package mbel
+
+import "strings"
+
+// Money is an amount in a currency. As a placeholder value it renders
+// like Localizer.FormatCurrency, with the currency's symbol and number of
+// decimals, so callers need not pre-format amounts:
+//
+//	m.T(ctx, "cart.total", mbel.Vars{"total": mbel.Money{Amount: 1234.5, Currency: "EUR"}})
+//	// en: "€1,234.50", de: "1.234,50 €", pl: "1234,50 €"
+type Money struct {
+	Amount   float64 // in major units (euros, not cents)
+	Currency string  // ISO 4217 code, e.g. "EUR"
+}
+
+// currencySymbols maps ISO codes to symbols; other codes print as is
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "¥",
+	"PLN": "zł", "CZK": "Kč", "RUB": "₽", "UAH": "₴", "BRL": "R$",
+	"INR": "₹", "KRW": "₩", "ILS": "₪", "TRY": "₺", "VND": "₫",
+}
+
+// currencyDigits are the decimals of currencies not using two
+var currencyDigits = map[string]int{
+	"JPY": 0, "KRW": 0, "CLP": 0, "ISK": 0, "VND": 0, "HUF": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// Format renders m for lang with the built-in separators
+func (m Money) Format(lang string) string {
+	return m.format(lang, numberSymbolsFor(lang))
+}
+
+func (m Money) format(lang string, sym numberSymbols) string {
+	code := strings.ToUpper(m.Currency)
+	digits, ok := currencyDigits[code]
+	if !ok {
+		digits = 2
+	}
+	symbol, ok := currencySymbols[code]
+	if !ok {
+		symbol = code
+	}
+	return placeCurrency(lang, formatNumber(sym, m.Amount, digits), symbol)
+}
@@ -0,0 +1,381 @@
+package mbel
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var keyPathRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_0-9][a-zA-Z0-9_]*)*$`)
+
+// KeyMove renames a key, or with Prefix every key under a namespace
+type KeyMove struct {
+	From, To string // without the ".*" of a prefix move
+	Prefix   bool
+}
+
+// ParseKeyMove parses the arguments of `mbel mv`: "auth.login" to
+// "account.login" moves one key, "auth.*" to "account.auth.*" the
+// namespace
+func ParseKeyMove(from, to string) (KeyMove, error) {
+	fromNS, fromPrefix := strings.CutSuffix(from, ".*")
+	toNS, toPrefix := strings.CutSuffix(to, ".*")
+	if fromPrefix != toPrefix {
+		return KeyMove{}, fmt.Errorf("move %s to %s: both or neither must end in .*", from, to)
+	}
+	for _, k := range []string{fromNS, toNS} {
+		if !keyPathRe.MatchString(k) {
+			return KeyMove{}, fmt.Errorf("invalid key %q", k)
+		}
+	}
+	if fromNS == toNS {
+		return KeyMove{}, fmt.Errorf("%s is moved onto itself", from)
+	}
+	if fromPrefix && strings.HasPrefix(toNS+".", fromNS+".") {
+		// account.* to account.old.* would move the keys it creates
+		return KeyMove{}, fmt.Errorf("cannot move %s into itself", from)
+	}
+	return KeyMove{From: fromNS, To: toNS, Prefix: fromPrefix}, nil
+}
+
+// Apply returns the new name of key, and false when the move leaves it
+func (m KeyMove) Apply(key string) (string, bool) {
+	if key == m.From {
+		return m.To, true
+	}
+	if m.Prefix && strings.HasPrefix(key, m.From+".") {
+		return m.To + key[len(m.From):], true
+	}
+	return "", false
+}
+
+// MoveResult is what PlanMove would change
+type MoveResult struct {
+	Moved   map[string]string // old key -> new key, namespace included
+	Changes []FileChange
+}
+
+// moveFile is a locale file under the moved tree
+type moveFile struct {
+	path   string
+	locale string // first path segment below the root: "pl" or "pl.mbel"
+	ns     string
+	src    *sourceFile
+}
+
+// PlanMove moves keys in every locale under root, the directory layout
+// of FileRepository. A key stays in its file, renamed in place or put
+// under a new [section], while the file's namespace still prefixes it;
+// otherwise it goes to the file of the locale whose namespace is the
+// longest prefix of the new key, created as needed: auth/*.mbel files
+// moved by "auth.*" to "account.auth.*" become account/auth/*.mbel. A
+// key's comments, annotations and @status lines move with it. Keys
+// that would overwrite one the move leaves are an error.
+func PlanMove(root string, mv KeyMove) (*MoveResult, error) {
+	ed := &sourceEdits{files: make(map[string]*sourceFile)}
+	var files []*moveFile
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && skipLocaleDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".mbel") {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		src, err := ed.file(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &moveFile{path: path, locale: parts[0], ns: fileNamespace(parts), src: src})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys the move leaves in place may not be overwritten
+	kept := make(map[string]map[string]bool)
+	for _, f := range files {
+		if kept[f.locale] == nil {
+			kept[f.locale] = make(map[string]bool)
+		}
+		for key := range Assignments(f.src.program) {
+			if full := joinKey(f.ns, key); !matchesMove(mv, full) {
+				kept[f.locale][full] = true
+			}
+		}
+	}
+	res := &MoveResult{Moved: make(map[string]string)}
+	for _, f := range files {
+		for key := range Assignments(f.src.program) {
+			if to, ok := mv.Apply(joinKey(f.ns, key)); ok && kept[f.locale][to] {
+				return nil, fmt.Errorf("%s: %s already exists", f.locale, to)
+			}
+		}
+	}
+
+	p := &movePlan{root: root, mv: mv, ed: ed, files: files, res: res}
+	for _, f := range files {
+		if err := p.moveFile(f); err != nil {
+			return nil, err
+		}
+	}
+	res.Changes = ed.changes()
+	return res, nil
+}
+
+func matchesMove(mv KeyMove, key string) bool {
+	_, ok := mv.Apply(key)
+	return ok
+}
+
+type movePlan struct {
+	root  string
+	mv    KeyMove
+	ed    *sourceEdits
+	files []*moveFile
+	res   *MoveResult
+}
+
+// moveFile moves the matching keys of f
+func (p *movePlan) moveFile(f *moveFile) error {
+	if f.src.removed {
+		return nil
+	}
+	// A file whose namespace the move covers is renamed whole, unless a
+	// file already holds its new namespace
+	if newNS, ok := p.mv.Apply(f.ns); ok && p.mv.Prefix && !strings.HasSuffix(f.locale, ".mbel") {
+		path := p.nsPath(f.locale, newNS)
+		if _, err := os.Stat(path); os.IsNotExist(err) && p.fileAt(path) == nil {
+			for key := range Assignments(f.src.program) {
+				p.res.Moved[joinKey(f.ns, key)] = joinKey(newNS, key)
+			}
+			moved, _ := p.ed.file(path)
+			moved.content, moved.orig, moved.program, moved.section, moved.grown = f.src.content, "", f.src.program, f.src.section, true
+			f.src.removed = true
+			p.files = append(p.files, &moveFile{path: path, locale: f.locale, ns: newNS, src: moved})
+			return nil
+		}
+	}
+
+	sections := make(map[*SectionStatement][2]int) // keys, keys moved out
+	var section *SectionStatement
+	for _, stmt := range f.src.program.Statements {
+		switch s := stmt.(type) {
+		case *SectionStatement:
+			section = s
+		case *AssignStatement:
+			counts := sections[section]
+			counts[0]++
+			moved, err := p.moveKey(f, section, s)
+			if err != nil {
+				return err
+			}
+			if moved {
+				counts[1]++
+			}
+			sections[section] = counts
+		}
+	}
+
+	// Drop the headers of sections left empty, with the blank line
+	// separating them
+	lines := strings.Split(f.src.content, "\n")
+	for s, counts := range sections {
+		if s == nil || counts[0] != counts[1] {
+			continue
+		}
+		first := s.Token.Line
+		if first > 1 && strings.TrimSpace(lines[first-2]) == "" {
+			first--
+		}
+		f.src.removeLines(first, s.Token.Line)
+	}
+	return nil
+}
+
+// moveKey moves the assignment a of f, in section, if the move covers
+// it, and reports whether it left its place
+func (p *movePlan) moveKey(f *moveFile, section *SectionStatement, a *AssignStatement) (bool, error) {
+	sectionName := ""
+	if section != nil {
+		sectionName = section.Name
+	}
+	old := joinKey(f.ns, joinKey(sectionName, a.Name))
+	key, ok := p.mv.Apply(old)
+	if !ok {
+		return false, nil
+	}
+	p.res.Moved[old] = key
+
+	target, err := p.target(f, key)
+	if err != nil {
+		return false, err
+	}
+	rest := key
+	if target.ns != "" {
+		rest = strings.TrimPrefix(key, target.ns+".")
+	}
+	// Keep the key in its section if it can, else keep its own name
+	newSection, name := "", rest
+	if s, ok := strings.CutPrefix(rest, sectionName+"."); ok && target == f && sectionName != "" {
+		newSection, name = sectionName, s
+	} else if s, ok := strings.CutSuffix(rest, "."+a.Name); ok {
+		newSection, name = s, a.Name
+	}
+
+	if target == f && newSection == sectionName {
+		if name != a.Name {
+			off := f.src.offset(a.Token.Line, a.Token.Column)
+			f.src.splices = append(f.src.splices, splice{off, off + len(a.Name), name})
+		}
+		return false, nil
+	}
+
+	first, last := f.src.keyLines(a)
+	start, end := f.src.offset(first, 1), f.src.offset(last+1, 1)
+	text := f.src.content[start:end]
+	nameAt := f.src.offset(a.Token.Line, a.Token.Column) - start
+	text = text[:nameAt] + name + text[nameAt+len(a.Name):]
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	f.src.removeLines(first, last)
+	target.src.addKey(newSection, text)
+	return true, nil
+}
+
+// target returns the file of f's locale that key goes to: the one whose
+// namespace is the longest prefix of key, f itself on a tie, or a new
+// file mirroring f under the moved namespace
+func (p *movePlan) target(f *moveFile, key string) (*moveFile, error) {
+	var best *moveFile
+	for _, c := range p.files {
+		if c.locale != f.locale || c.src.removed || c.ns != "" && !strings.HasPrefix(key, c.ns+".") {
+			continue
+		}
+		if best == nil || len(c.ns) > len(best.ns) || len(c.ns) == len(best.ns) && c == f {
+			best = c
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	ns, ok := p.mv.Apply(f.ns)
+	if !ok || f.ns == "" {
+		ns = p.mv.To
+		if !p.mv.Prefix {
+			// A single key goes to the file of its namespace
+			ns = key[:max(strings.LastIndexByte(key, '.'), 0)]
+		}
+	}
+	if ns == "" {
+		return nil, fmt.Errorf("%s: no file can hold %s", f.locale, key)
+	}
+	path := p.nsPath(f.locale, ns)
+	src, _ := p.ed.file(path)
+	created := &moveFile{path: path, locale: f.locale, ns: ns, src: src}
+	p.files = append(p.files, created)
+	return created, nil
+}
+
+// fileAt returns the planned file at path, nil if there is none
+func (p *movePlan) fileAt(path string) *moveFile {
+	for _, f := range p.files {
+		if f.path == path && !f.src.removed {
+			return f
+		}
+	}
+	return nil
+}
+
+// nsPath returns the path of the file holding namespace ns in locale
+func (p *movePlan) nsPath(locale, ns string) string {
+	return filepath.Join(p.root, locale, filepath.FromSlash(strings.ReplaceAll(ns, ".", "/"))+".mbel")
+}
+
+// keyLines returns the lines of the assignment a, from the comments,
+// annotations and per-key metadata directly above it to its last line
+func (f *sourceFile) keyLines(a *AssignStatement) (first, last int) {
+	last = a.Token.Line
+	switch v := a.Value.(type) {
+	case *StringLiteral:
+		last = max(v.Token.EndLine, last)
+	case *BlockExpression:
+		last = max(v.EndLine, last)
+	}
+
+	lines := strings.Split(f.content, "\n")
+	first = a.Token.Line
+	for first > 1 {
+		line := strings.TrimSpace(lines[first-2])
+		if meta, _, ok := strings.Cut(strings.TrimPrefix(line, "@"), ":"); !strings.HasPrefix(line, "#") && !(strings.HasPrefix(line, "@") && ok && keyMetaKeys[strings.TrimSpace(meta)]) {
+			break
+		}
+		first--
+	}
+	return first, last
+}
+
+// removeLines deletes lines first through last
+func (f *sourceFile) removeLines(first, last int) {
+	f.splices = append(f.splices, splice{f.offset(first, 1), f.offset(last+1, 1), ""})
+}
+
+// addKey adds the text of an assignment to section: appended under a
+// new [section] header unless the file already ends in it, and for no
+// section before the file's first section and the blank line above it
+func (f *sourceFile) addKey(section, text string) {
+	if section == "" {
+		for _, stmt := range f.program.Statements {
+			if s, ok := stmt.(*SectionStatement); ok {
+				line := s.Token.Line
+				if lines := strings.Split(f.content, "\n"); line > 1 && strings.TrimSpace(lines[line-2]) == "" {
+					line--
+				}
+				f.insertLine(line, text)
+				return
+			}
+		}
+		if f.section == "" {
+			f.appendText(text)
+			return
+		}
+		// Only appended sections: insert before them
+		if n := len(f.orig); n > 0 && f.orig[n-1] != '\n' {
+			text = "\n" + text
+		}
+		f.splices = append(f.splices, splice{len(f.orig), len(f.orig), NormalizeLineEndings(text, DetectLineEnding(f.content))})
+		return
+	}
+	if f.section != section {
+		header := "[" + section + "]\n"
+		if f.content != "" {
+			header = "\n" + header
+		}
+		f.appendText(header)
+		f.section = section
+	}
+	f.appendText(text)
+}
+
+// SortedMoves returns the moved keys in order
+func (r *MoveResult) SortedMoves() []string {
+	keys := make([]string, 0, len(r.Moved))
+	for k := range r.Moved {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
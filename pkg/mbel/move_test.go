@@ -0,0 +1,171 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKeyMove(t *testing.T) {
+	mv, err := ParseKeyMove("auth.*", "account.auth.*")
+	if err != nil || mv != (KeyMove{From: "auth", To: "account.auth", Prefix: true}) {
+		t.Fatalf("ParseKeyMove = %+v, %v", mv, err)
+	}
+	for key, want := range map[string]string{"auth": "account.auth", "auth.login": "account.auth.login", "authority": ""} {
+		if got, _ := mv.Apply(key); got != want {
+			t.Errorf("Apply(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	for _, args := range [][2]string{
+		{"auth.*", "account"},
+		{"auth", "auth"},
+		{"auth.*", "auth.old.*"},
+		{"auth.*", "bad key.*"},
+	} {
+		if _, err := ParseKeyMove(args[0], args[1]); err == nil {
+			t.Errorf("ParseKeyMove(%q, %q) should fail", args[0], args[1])
+		}
+	}
+}
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// changedFiles maps the changes below root to their new content, or
+// "<removed>"
+func changedFiles(root string, changes []FileChange) map[string]string {
+	out := make(map[string]string)
+	for _, c := range changes {
+		rel, _ := filepath.Rel(root, c.Path)
+		if c.Removed {
+			out[filepath.ToSlash(rel)] = "<removed>"
+		} else {
+			out[filepath.ToSlash(rel)] = c.After
+		}
+	}
+	return out
+}
+
+func TestPlanMoveRenamesFiles(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"en/auth.mbel":        "# AI_Context: Button\nlogin = \"Log in\"\n",
+		"en/auth/social.mbel": "google = \"Google\"\n",
+		"en/home.mbel":        "title = \"Home\"\n",
+		"pl/auth.mbel":        "login = \"Zaloguj\"\n",
+	})
+	mv, _ := ParseKeyMove("auth.*", "account.auth.*")
+	res, err := PlanMove(root, mv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"en/auth.mbel":                "<removed>",
+		"en/auth/social.mbel":         "<removed>",
+		"pl/auth.mbel":                "<removed>",
+		"en/account/auth.mbel":        "# AI_Context: Button\nlogin = \"Log in\"\n",
+		"en/account/auth/social.mbel": "google = \"Google\"\n",
+		"pl/account/auth.mbel":        "login = \"Zaloguj\"\n",
+	}
+	if got := changedFiles(root, res.Changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %q\nwant %q", got, want)
+	}
+	wantMoved := map[string]string{"auth.login": "account.auth.login", "auth.social.google": "account.auth.social.google"}
+	if !reflect.DeepEqual(res.Moved, wantMoved) {
+		t.Errorf("Moved = %v", res.Moved)
+	}
+
+	if err := WriteChanges(res.Changes); err != nil {
+		t.Fatal(err)
+	}
+	data, err := (&FileRepository{RootPath: root}).LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["pl"]["account.auth.login"] != "Zaloguj" || data["en"]["account.auth.social.google"] != "Google" || data["en"]["auth.login"] != nil {
+		t.Errorf("reloaded catalog: %v", data)
+	}
+}
+
+func TestPlanMoveSections(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"en.mbel": `@lang: en
+title = "Home"
+
+[auth]
+# AI_Context: Button
+@status: final
+login = "Log in"
+items(n) {
+    [other] => "{n}"
+}
+
+[shop]
+cart = "Cart"
+`,
+	})
+	mv, _ := ParseKeyMove("auth.*", "account.auth.*")
+	res, err := PlanMove(root, mv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `@lang: en
+title = "Home"
+
+[shop]
+cart = "Cart"
+
+[account.auth]
+# AI_Context: Button
+@status: final
+login = "Log in"
+items(n) {
+    [other] => "{n}"
+}
+`
+	if got := changedFiles(root, res.Changes)["en.mbel"]; got != want {
+		t.Errorf("en.mbel =\n%s\nwant\n%s", got, want)
+	}
+	WriteChanges(res.Changes)
+
+	// A key renamed within its section stays in place; one leaving its
+	// section goes before the first section
+	for _, m := range [][2]string{{"account.auth.login", "account.auth.sign_in"}, {"shop.cart", "basket"}} {
+		mv, _ := ParseKeyMove(m[0], m[1])
+		res, err := PlanMove(root, mv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		WriteChanges(res.Changes)
+	}
+	got, _ := os.ReadFile(filepath.Join(root, "en.mbel"))
+	if !strings.Contains(string(got), "title = \"Home\"\nbasket = \"Cart\"\n\n[account.auth]\n") || !strings.Contains(string(got), "@status: final\nsign_in = ") || strings.Contains(string(got), "[shop]") {
+		t.Errorf("en.mbel =\n%s", got)
+	}
+}
+
+func TestPlanMoveConflict(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"en/auth.mbel":    "login = \"Log in\"\n",
+		"en/account.mbel": "auth.login = \"Sign in\"\n",
+	})
+	mv, _ := ParseKeyMove("auth.*", "account.auth.*")
+	if _, err := PlanMove(root, mv); err == nil || !strings.Contains(err.Error(), "account.auth.login already exists") {
+		t.Errorf("err = %v", err)
+	}
+}
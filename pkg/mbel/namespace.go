@@ -0,0 +1,58 @@
+package mbel
+
+import (
+	"context"
+	"strings"
+)
+
+// Namespace is a translator scoped to a key prefix, so feature packages
+// don't repeat long prefixes and a typo in one is confined to one place.
+// The zero value translates unprefixed keys.
+//
+//	var tr = mbel.NS("checkout")
+//
+//	tr.T(ctx, "title")               // resolves "checkout.title"
+//	tr.NS("payment").T(ctx, "card")  // resolves "checkout.payment.card"
+type Namespace struct {
+	prefix string // "checkout." (with the trailing dot), or ""
+}
+
+// NS returns a translator for keys under name ("checkout", "checkout.payment")
+func NS(name string) Namespace {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return Namespace{}
+	}
+	return Namespace{prefix: name + "."}
+}
+
+// NS returns a nested namespace
+func (n Namespace) NS(name string) Namespace {
+	return NS(n.prefix + name)
+}
+
+// Name returns the namespace without the trailing dot ("" for the root)
+func (n Namespace) Name() string {
+	return strings.TrimSuffix(n.prefix, ".")
+}
+
+// Key returns the full key for a key relative to the namespace
+func (n Namespace) Key(key string) string {
+	return n.prefix + key
+}
+
+// T translates a namespaced key using the locale found in context. Like
+// T, a missing key returns the full key.
+func (n Namespace) T(ctx context.Context, key string, args ...interface{}) string {
+	return T(ctx, n.prefix+key, args...)
+}
+
+// MustT translates a namespaced key and panics if it is not found
+func (n Namespace) MustT(ctx context.Context, key string, args ...interface{}) string {
+	return MustT(ctx, n.prefix+key, args...)
+}
+
+// Get translates a namespaced key with an explicit manager and locale
+func (n Namespace) Get(m *Manager, lang, key string, args ...interface{}) string {
+	return m.Get(lang, n.prefix+key, args...)
+}
@@ -0,0 +1,36 @@
+package mbel
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"checkout.title": "Checkout", "checkout.payment.card": "Card", "title": "Home"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithManager(context.Background(), m)
+
+	tr := NS("checkout")
+	if got := tr.T(ctx, "title"); got != "Checkout" {
+		t.Errorf("T = %q", got)
+	}
+	if got := tr.NS("payment").T(ctx, "card"); got != "Card" {
+		t.Errorf("nested T = %q", got)
+	}
+	if got := tr.T(ctx, "missing"); got != "checkout.missing" {
+		t.Errorf("missing key should return the full key, got %q", got)
+	}
+	if got := NS(".checkout.").Key("title"); got != "checkout.title" {
+		t.Errorf("Key = %q", got)
+	}
+	if got := NS("").Get(m, "en", "title"); got != "Home" {
+		t.Errorf("root namespace Get = %q", got)
+	}
+	if got := tr.NS("payment").Name(); got != "checkout.payment" {
+		t.Errorf("Name = %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+package mbel
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// KeyNaming is a key naming convention, enforced by the lint rule
+// returned from KeyNamingRule
+type KeyNaming struct {
+	SnakeCase bool // every segment of a key and section is snake_case
+	MaxDepth  int  // max segments of the full key, folder namespace included (0 = unlimited)
+
+	// Prefixes maps a directory inside the locale folder ("checkout",
+	// "admin/users"; "" for files directly in it) to the prefixes the keys
+	// of its files must start with, as written (section included). The
+	// deepest matching directory applies.
+	Prefixes map[string][]string
+}
+
+var snakeCaseRe = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// KeyNamingRule returns a lint rule enforcing c. Diagnostics carry a
+// Suggestion when the key can be renamed mechanically.
+//
+//	mbel.RegisterLintRule("key-naming", mbel.KeyNamingRule(mbel.KeyNaming{SnakeCase: true, MaxDepth: 4}))
+func KeyNamingRule(c KeyNaming) LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		prefixes := c.prefixesFor(ctx.Namespace)
+		section := ""
+
+		for _, stmt := range p.Statements {
+			switch s := stmt.(type) {
+			case *SectionStatement:
+				section = s.Name
+				if c.SnakeCase && !isSnakeKey(s.Name) {
+					d := DiagnosticAt(s.NameToken, SeverityError, fmt.Sprintf("section [%s] is not snake_case", s.Name))
+					d.Suggestion = snakeKey(s.Name)
+					out = append(out, d)
+				}
+			case *AssignStatement:
+				key := s.Name
+				if section != "" {
+					key = section + "." + s.Name
+				}
+
+				// Both fixes are folded into one suggestion so that
+				// applying either diagnostic yields a valid name
+				fixed := s.Name
+				if c.SnakeCase {
+					fixed = snakeKey(fixed)
+				}
+				wrongPrefix := prefixes != nil && !hasAnyPrefix(key, prefixes)
+				if wrongPrefix && section == "" {
+					fixed = prefixes[0] + fixed
+				}
+
+				if c.SnakeCase && !isSnakeKey(s.Name) {
+					d := DiagnosticAt(s.Token, SeverityError, fmt.Sprintf("key %s is not snake_case", key))
+					d.Suggestion = fixed
+					out = append(out, d)
+				}
+				if full := joinKey(ctx.Namespace, key); c.MaxDepth > 0 && strings.Count(full, ".")+1 > c.MaxDepth {
+					out = append(out, DiagnosticAt(s.Token, SeverityError,
+						fmt.Sprintf("key %s has %d segments (max %d)", full, strings.Count(full, ".")+1, c.MaxDepth)))
+				}
+				if wrongPrefix {
+					d := DiagnosticAt(s.Token, SeverityError,
+						fmt.Sprintf("key %s must start with %s here", key, strings.Join(prefixes, " or ")))
+					if section == "" {
+						d.Suggestion = fixed
+					}
+					out = append(out, d)
+				}
+			}
+		}
+		return out
+	}
+}
+
+// prefixesFor returns the prefixes of the deepest configured directory
+// containing the file with the given namespace, nil when unrestricted
+func (c KeyNaming) prefixesFor(namespace string) []string {
+	dir := ""
+	if i := strings.LastIndexByte(namespace, '.'); i >= 0 {
+		dir = strings.ReplaceAll(namespace[:i], ".", "/")
+	}
+
+	var best []string
+	bestLen := -1
+	for d, prefixes := range c.Prefixes {
+		d = strings.Trim(filepath.ToSlash(d), "/")
+		if (d == "" || dir == d || strings.HasPrefix(dir, d+"/")) && len(d) > bestLen {
+			best, bestLen = prefixes, len(d)
+		}
+	}
+	return best
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinKey(namespace, key string) string {
+	if namespace == "" {
+		return key
+	}
+	return namespace + "." + key
+}
+
+func isSnakeKey(key string) bool {
+	for _, seg := range strings.Split(key, ".") {
+		if !snakeCaseRe.MatchString(seg) {
+			return false
+		}
+	}
+	return true
+}
+
+// snakeKey converts each segment of key to snake_case
+// ("checkoutPage.HTTPError" -> "checkout_page.http_error")
+func snakeKey(key string) string {
+	segs := strings.Split(key, ".")
+	for i, seg := range segs {
+		segs[i] = snakeCase(seg)
+	}
+	return strings.Join(segs, ".")
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	sep := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			sep()
+		case unicode.IsUpper(r):
+			// A word starts at an upper-case letter after a lower-case one
+			// or digit, or at the last capital of an acronym ("HTTPError")
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				sep()
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
@@ -0,0 +1,83 @@
+package mbel
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"loginButton":   "login_button",
+		"HTTPError":     "http_error",
+		"page2Title":    "page2_title",
+		"already_snake": "already_snake",
+		"Kebab-Case":    "kebab_case",
+		"double__under": "double_under",
+		"userID":        "user_id",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestKeyNamingRule(t *testing.T) {
+	rule := KeyNamingRule(KeyNaming{
+		SnakeCase: true,
+		MaxDepth:  3,
+		Prefixes: map[string][]string{
+			"":         {"common_", "app_"},
+			"checkout": {"checkout_"},
+		},
+	})
+	src := "common_ok = \"a\"\npayNow = \"b\"\n[Header]\napp_title = \"c\"\n"
+
+	diags := rule(NewParser(NewLexer(src)).ParseProgram(), LintContext{Namespace: "checkout.pay"})
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Message+" -> "+d.Suggestion)
+	}
+	want := []string{
+		"key common_ok must start with checkout_ here -> checkout_common_ok",
+		"key payNow is not snake_case -> checkout_pay_now",
+		"key payNow must start with checkout_ here -> checkout_pay_now",
+		"section [Header] is not snake_case -> header",
+		"key checkout.pay.Header.app_title has 4 segments (max 3) -> ",
+		"key Header.app_title must start with checkout_ here -> ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Files directly in the locale folder use the "" prefixes
+	diags = rule(NewParser(NewLexer(src)).ParseProgram(), LintContext{Namespace: "app"})
+	if len(diags) != 4 {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestApplySuggestions(t *testing.T) {
+	src := "loginButton = \"x\"\n[userProfile]\ntitle = \"t\"\n"
+	program := NewParser(NewLexer(src)).ParseProgram()
+	diags := KeyNamingRule(KeyNaming{SnakeCase: true})(program, LintContext{})
+
+	out, n := ApplySuggestions([]byte(src), diags)
+	if n != 2 || string(out) != "login_button = \"x\"\n[user_profile]\ntitle = \"t\"\n" {
+		t.Errorf("ApplySuggestions = %q, %d", out, n)
+	}
+}
+
+func TestFileNamespace(t *testing.T) {
+	root := "locales"
+	for path, want := range map[string]string{
+		filepath.Join(root, "en.mbel"):                 "",
+		filepath.Join(root, "en", "app.mbel"):          "app",
+		filepath.Join(root, "en", "shop", "cart.mbel"): "shop.cart",
+		filepath.Join("elsewhere", "en", "x.mbel"):     "",
+	} {
+		if got := FileNamespace(root, path); got != want {
+			t.Errorf("FileNamespace(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package mbel
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// DefaultNegotiationCacheSize is the number of distinct Accept-Language
+// headers a middleware remembers when Options.NegotiationCacheSize is 0
+const DefaultNegotiationCacheSize = 256
+
+// maxCachedHeader bounds the headers worth caching; longer ones are
+// negotiated every time so odd clients cannot pin memory
+const maxCachedHeader = 512
+
+// WithNegotiationCache returns a copy of o whose Header resolvers
+// remember the locale negotiated for each raw Accept-Language value, in
+// an LRU of NegotiationCacheSize entries shared by every request the
+// copy serves. MiddlewareWithOptions calls it once per middleware;
+// adapters for other frameworks call it when they are built.
+func (o Options) WithNegotiationCache() Options {
+	size := o.NegotiationCacheSize
+	if size == 0 {
+		size = DefaultNegotiationCacheSize
+	}
+	if size < 0 {
+		return o
+	}
+
+	var cache *negotiationCache
+	resolvers := make([]LocaleResolver, len(o.resolvers()))
+	for i, res := range o.resolvers() {
+		if _, ok := res.(headerResolver); ok {
+			if cache == nil {
+				cache = newNegotiationCache(size)
+			}
+			res = cachedHeaderResolver{cache}
+		}
+		resolvers[i] = res
+	}
+	o.Resolvers = resolvers
+	return o
+}
+
+type cachedHeaderResolver struct {
+	cache *negotiationCache
+}
+
+func (c cachedHeaderResolver) Resolve(r *http.Request) string {
+	accept := r.Header.Get("Accept-Language")
+	if len(accept) > maxCachedHeader {
+		return parseAcceptLanguage(accept)
+	}
+	if lang, ok := c.cache.get(accept); ok {
+		return lang
+	}
+	lang := parseAcceptLanguage(accept)
+	c.cache.put(accept, lang)
+	return lang
+}
+
+func (cachedHeaderResolver) varyHeader() string { return "Accept-Language" }
+
+// negotiationCache is a fixed-size LRU from raw headers to locales
+type negotiationCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *negotiationEntry, most recent first
+	items map[string]*list.Element
+}
+
+type negotiationEntry struct {
+	header, lang string
+}
+
+func newNegotiationCache(size int) *negotiationCache {
+	return &negotiationCache{size: size, order: list.New(), items: make(map[string]*list.Element, size)}
+}
+
+func (c *negotiationCache) get(header string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[header]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*negotiationEntry).lang, true
+}
+
+func (c *negotiationCache) put(header, lang string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[header]; ok {
+		el.Value.(*negotiationEntry).lang = lang
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[header] = c.order.PushFront(&negotiationEntry{header, lang})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*negotiationEntry).header)
+	}
+}
@@ -0,0 +1,67 @@
+package mbel
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiationCacheEvictsLeastRecent(t *testing.T) {
+	c := newNegotiationCache(2)
+	c.put("pl", "pl")
+	c.put("de", "de")
+	c.get("pl") // de is now the oldest
+	c.put("fr", "fr")
+
+	if _, ok := c.get("de"); ok {
+		t.Error("expected de to be evicted")
+	}
+	for _, h := range []string{"pl", "fr"} {
+		if lang, ok := c.get(h); !ok || lang != h {
+			t.Errorf("get(%q) = %q, %v", h, lang, ok)
+		}
+	}
+}
+
+func TestWithNegotiationCache(t *testing.T) {
+	opts := Options{Resolvers: []LocaleResolver{Cookie("lang"), Header()}}.WithNegotiationCache()
+	cached, ok := opts.Resolvers[1].(cachedHeaderResolver)
+	if !ok {
+		t.Fatalf("Header resolver not cached: %T", opts.Resolvers[1])
+	}
+	if _, ok := opts.Resolvers[0].(cookieResolver); !ok {
+		t.Errorf("Cookie resolver replaced: %T", opts.Resolvers[0])
+	}
+	if got := opts.VaryHeaders(); len(got) != 2 {
+		t.Errorf("VaryHeaders = %v", got)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "pl-PL,pl;q=0.9,en;q=0.8")
+	for i := 0; i < 2; i++ {
+		if got := opts.Negotiate(r); got != "pl-PL" {
+			t.Errorf("Negotiate = %q, want pl-PL", got)
+		}
+	}
+	if lang, ok := cached.cache.get("pl-PL,pl;q=0.9,en;q=0.8"); !ok || lang != "pl-PL" {
+		t.Errorf("header not cached: %q, %v", lang, ok)
+	}
+
+	// Headers too long to cache are still negotiated
+	long := "de," + strings.Repeat("x", maxCachedHeader)
+	r.Header.Set("Accept-Language", long)
+	if got := opts.Negotiate(r); got != "de" {
+		t.Errorf("Negotiate(long) = %q, want de", got)
+	}
+	if _, ok := cached.cache.get(long); ok {
+		t.Error("long header was cached")
+	}
+
+	// The default chain is cached too; a negative size disables it
+	if _, ok := (Options{}).WithNegotiationCache().Resolvers[0].(cachedHeaderResolver); !ok {
+		t.Error("default Header resolver not cached")
+	}
+	if _, ok := (Options{NegotiationCacheSize: -1}).WithNegotiationCache().resolvers()[0].(headerResolver); !ok {
+		t.Error("negative size should disable the cache")
+	}
+}
@@ -0,0 +1,19 @@
+package mbel
+
+import "context"
+
+// Observer receives lookup and reload events from a Manager, e.g. to
+// record them as tracing spans or metrics. Set it via Config.Observer;
+// a nil Observer costs nothing on the lookup path.
+//
+// The ctx passed to MissingKey and Fallback is the one given to T, so
+// implementations can attach events to the caller's active span.
+type Observer interface {
+	// MissingKey is called when key is not found in lang nor any fallback
+	MissingKey(ctx context.Context, lang, key string)
+	// Fallback is called when key was served from resolved instead of requested
+	Fallback(ctx context.Context, requested, resolved, key string)
+	// Reload is called when a (re)load starts; the returned func is called
+	// with the result once it finishes
+	Reload(ctx context.Context) func(err error)
+}
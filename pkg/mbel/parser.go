@@ -12,6 +12,7 @@ type Parser struct {
 	peekToken            Token
 	errors               []string
 	pendingAIAnnotations []*AIAnnotation // AI annotations waiting to be attached to next key
+	comments             []*Comment      // Plain comments seen so far
 }
 
 func NewParser(l *Lexer) *Parser {
@@ -28,14 +29,28 @@ func (p *Parser) Errors() []string {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+	p.checkUnterminated()
 
 	// Extract AI annotations from comments, skip other comments
 	for p.curToken.Type == TOKEN_COMMENT {
 		if ann := p.parseAIAnnotation(p.curToken); ann != nil {
 			p.pendingAIAnnotations = append(p.pendingAIAnnotations, ann)
+		} else {
+			p.comments = append(p.comments, &Comment{Text: p.curToken.Literal, Line: p.curToken.Line})
 		}
 		p.curToken = p.peekToken
 		p.peekToken = p.l.NextToken()
+		p.checkUnterminated()
+	}
+}
+
+// checkUnterminated reports a string missing its closing quote at the
+// opening quote, then lets it through as a string ending at the line
+// break, so the rest of the line and file parse as usual
+func (p *Parser) checkUnterminated() {
+	if p.peekToken.Type == TOKEN_UNTERMINATED_STRING {
+		p.errors = append(p.errors, fmt.Sprintf("unterminated string: '\"' at line %d, column %d is never closed", p.peekToken.Line, p.peekToken.Column))
+		p.peekToken.Type = TOKEN_STRING
 	}
 }
 
@@ -62,15 +77,22 @@ func (p *Parser) parseAIAnnotation(tok Token) *AIAnnotation {
 	aiType := strings.TrimPrefix(text[:colonIdx], "AI_")
 	value := strings.TrimSpace(text[colonIdx+1:])
 
-	// Handle multi-line values in curly braces
-	if strings.HasPrefix(value, "{") {
+	// Handle multi-line values in curly braces; a value closing its
+	// braces on the same line (e.g. JSON) is kept as written
+	if strings.HasPrefix(value, "{") && strings.Count(value, "{") > strings.Count(value, "}") {
 		// Value spans multiple lines until closing }
 		// Collect lines until we find the closing }
 		lines := []string{value}
-		bracketCount := 1
-
-		for bracketCount > 0 && p.peekToken.Type != TOKEN_EOF {
-			p.nextToken() // Move to next token
+		bracketCount := strings.Count(value, "{") - strings.Count(value, "}")
+
+		// The value ends at the first line that is not a comment
+		for bracketCount > 0 && (p.peekToken.Type == TOKEN_COMMENT || p.peekToken.Type == TOKEN_NEWLINE) {
+			p.curToken = p.peekToken
+			p.peekToken = p.l.NextToken()
+			p.checkUnterminated()
+			if p.curToken.Type == TOKEN_NEWLINE {
+				continue
+			}
 			line := p.curToken.Literal
 
 			// Count brackets
@@ -120,25 +142,40 @@ func (p *Parser) ParseProgram() *Program {
 	}
 	program.Statements = []Statement{}
 
-	for p.curToken.Type != TOKEN_EOF {
-		stmt := p.parseStatement(program)
-		if stmt != nil {
-			program.Statements = append(program.Statements, stmt)
-		} else if p.curToken.Type != TOKEN_NEWLINE && p.curToken.Type != TOKEN_EOF {
-			// If statement parsing failed and it wasn't just an empty line,
-			// we need to skip to the next safe point
-			p.synchronize()
+	for {
+		stmt, ok := p.nextStatement(program)
+		if !ok {
+			break
 		}
-		p.nextToken()
+		program.Statements = append(program.Statements, stmt)
 	}
 
 	// Add any remaining pending annotations
 	program.AIAnnotations = append(program.AIAnnotations, p.pendingAIAnnotations...)
 	p.pendingAIAnnotations = nil
+	program.Comments = p.comments
 
 	return program
 }
 
+// nextStatement parses up to and including the next statement;
+// ok is false once the input is exhausted
+func (p *Parser) nextStatement(program *Program) (stmt Statement, ok bool) {
+	for p.curToken.Type != TOKEN_EOF {
+		stmt = p.parseStatement(program)
+		if stmt == nil && p.curToken.Type != TOKEN_NEWLINE && p.curToken.Type != TOKEN_EOF {
+			// If statement parsing failed and it wasn't just an empty line,
+			// we need to skip to the next safe point
+			p.synchronize()
+		}
+		p.nextToken()
+		if stmt != nil {
+			return stmt, true
+		}
+	}
+	return nil, false
+}
+
 // synchronize skips tokens until a safe state (statement boundary) is found
 // Used for error recovery
 func (p *Parser) synchronize() {
@@ -175,7 +212,11 @@ func (p *Parser) parseStatement(program *Program) Statement {
 		return stmt
 	case TOKEN_NEWLINE:
 		return nil // Skip empty lines / separators
+	case TOKEN_ILLEGAL:
+		p.illegalError(p.curToken)
+		return nil
 	default:
+		p.errors = append(p.errors, fmt.Sprintf("unexpected %s at line %d", p.curToken.Type, p.curToken.Line))
 		return nil
 	}
 }
@@ -187,6 +228,7 @@ func (p *Parser) parseSectionStatement() *SectionStatement {
 		return nil
 	}
 	stmt.Name = p.curToken.Literal
+	stmt.NameToken = p.curToken
 
 	if !p.expectPeek(TOKEN_RBRACKET) {
 		return nil
@@ -227,6 +269,7 @@ func (p *Parser) parseMetadataOrImport(program *Program) Statement {
 	// Metadata value can be IDENT (e.g. pl) or NUMBER (e.g. 1.0) or STRING
 	if p.curToken.Type == TOKEN_IDENT || p.curToken.Type == TOKEN_NUMBER || p.curToken.Type == TOKEN_STRING {
 		stmt.Value = p.curToken.Literal
+		stmt.ValueToken = p.curToken
 	} else {
 		p.peekError(TOKEN_STRING)
 		return nil
@@ -251,6 +294,15 @@ func (p *Parser) parseAssignStatement(program *Program) *AssignStatement {
 	if p.peekTokenIs(TOKEN_ASSIGN) {
 		p.nextToken() // move to =
 		p.nextToken() // move to value
+		if p.curToken.Type == TOKEN_IDENT && p.curToken.Literal == variantKeyword && p.peekTokenIs(TOKEN_LBRACE) {
+			// handle variants: key = variant { [a:50] => ... }
+			p.nextToken() // move to LBRACE
+			block := &BlockExpression{Token: p.curToken, Argument: variantArgument}
+			block.Cases = p.parseBlockCases(true)
+			block.EndLine = p.curToken.Line
+			stmt.Value = block
+			return stmt
+		}
 		stmt.Value = p.parseExpression()
 		if stmt.Value == nil {
 			p.errors = append(p.errors, fmt.Sprintf("Expected expression after = at line %d", p.curToken.Line))
@@ -285,14 +337,18 @@ func (p *Parser) parseBlockAssignStatement(stmt *AssignStatement) *AssignStateme
 	}
 
 	block := &BlockExpression{Token: p.curToken, Argument: argName}
-	block.Cases = p.parseBlockCases()
+	block.Cases = p.parseBlockCases(false)
+	block.EndLine = p.curToken.Line
 
 	stmt.Value = block
 	return stmt
 }
 
-func (p *Parser) parseBlockCases() []*BlockCase {
+// parseBlockCases parses the cases of a block up to its '}'; variant
+// blocks take [name:weight] conditions instead
+func (p *Parser) parseBlockCases(variant bool) []*BlockCase {
 	cases := []*BlockCase{}
+	open := p.curToken
 
 	for !p.peekTokenIs(TOKEN_RBRACE) && !p.peekTokenIs(TOKEN_EOF) {
 		p.nextToken()
@@ -301,6 +357,15 @@ func (p *Parser) parseBlockCases() []*BlockCase {
 			continue
 		}
 
+		if p.curToken.Type != TOKEN_LBRACKET {
+			if p.curToken.Type == TOKEN_ILLEGAL {
+				p.illegalError(p.curToken)
+			} else {
+				p.errors = append(p.errors, fmt.Sprintf("expected [condition] in block at line %d, got %s", p.curToken.Line, p.curToken.Type))
+			}
+			return nil
+		}
+
 		if p.curToken.Type == TOKEN_LBRACKET {
 			// [condition] => "value" or [2..4] => "value"
 			bc := &BlockCase{}
@@ -334,6 +399,13 @@ func (p *Parser) parseBlockCases() []*BlockCase {
 					// Simple number condition
 					bc.Condition = startNum
 				}
+			} else if p.curToken.Type == TOKEN_IDENT && variant {
+				// Variant name and weight: [a:50]
+				name := p.curToken.Literal
+				if !p.expectPeek(TOKEN_COLON) || !p.expectPeek(TOKEN_NUMBER) {
+					return nil
+				}
+				bc.Condition = name + ":" + p.curToken.Literal
 			} else if p.curToken.Type == TOKEN_IDENT {
 				// Keyword conditions: one, few, many, other, male, female, etc.
 				bc.Condition = p.curToken.Literal
@@ -354,13 +426,16 @@ func (p *Parser) parseBlockCases() []*BlockCase {
 				return nil
 			}
 			bc.Value = p.curToken.Literal
+			bc.ValueToken = p.curToken
 			cases = append(cases, bc)
 		}
 	}
 
-	if !p.expectPeek(TOKEN_RBRACE) {
+	if p.peekTokenIs(TOKEN_EOF) {
+		p.errors = append(p.errors, fmt.Sprintf("unterminated block: '{' at line %d is never closed", open.Line))
 		return nil
 	}
+	p.nextToken()
 
 	return cases
 }
@@ -388,6 +463,14 @@ func (p *Parser) peekTokenIs(t TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+func (p *Parser) illegalError(tok Token) {
+	if tok.Literal == `"""` {
+		p.errors = append(p.errors, fmt.Sprintf("unterminated \"\"\" string starting at line %d", tok.Line))
+		return
+	}
+	p.errors = append(p.errors, fmt.Sprintf("illegal character %q at line %d", tok.Literal, tok.Line))
+}
+
 func (p *Parser) peekError(t TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead at line %d", t, p.peekToken.Type, p.peekToken.Line)
 	p.errors = append(p.errors, msg)
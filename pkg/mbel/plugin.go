@@ -0,0 +1,193 @@
+package mbel
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Severity of a lint Diagnostic
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Diagnostic is a finding reported by a lint rule. Line/Column is where
+// the finding starts and EndLine/EndColumn where it ends (inclusive);
+// RunLintRules defaults a missing end to the start.
+type Diagnostic struct {
+	Rule       string // set by RunLintRules
+	Severity   Severity
+	Message    string
+	Line       int
+	Column     int
+	EndLine    int
+	EndColumn  int
+	Suggestion string // replacement text for the span, "" when there is no fix
+}
+
+// DiagnosticAt returns a diagnostic spanning tok, e.g. a whole
+// multi-line """ string
+func DiagnosticAt(tok Token, severity Severity, message string) Diagnostic {
+	return Diagnostic{
+		Severity:  severity,
+		Message:   message,
+		Line:      tok.Line,
+		Column:    tok.Column,
+		EndLine:   tok.EndLine,
+		EndColumn: tok.EndColumn,
+	}
+}
+
+// LintContext describes the file a lint rule is checking
+type LintContext struct {
+	File      string // path as given to the linter
+	Lang      string // @lang metadata, or "" when absent
+	Namespace string // key prefix from the file's folder ("shop.cart"), when known
+
+	// Interpolation is the style of files without an @interpolation
+	// line ("" = InterpolationSingle)
+	Interpolation Interpolation
+}
+
+// LintRule checks a parsed file and returns its findings
+type LintRule func(p *Program, ctx LintContext) []Diagnostic
+
+// CompileTransform rewrites the compiled data of one file
+type CompileTransform func(data map[string]interface{}) map[string]interface{}
+
+var plugins struct {
+	mu         sync.RWMutex
+	rules      map[string]LintRule
+	transforms []CompileTransform
+}
+
+// RegisterLintRule makes a custom lint rule available to RunLintRules and
+// `mbel lint`. It is meant to be called from init functions, usually in a
+// plugin loaded with `mbel lint -plugin`. Registering a name twice panics.
+func RegisterLintRule(name string, rule LintRule) {
+	plugins.mu.Lock()
+	defer plugins.mu.Unlock()
+
+	if rule == nil {
+		panic("mbel: RegisterLintRule rule is nil")
+	}
+	if _, dup := plugins.rules[name]; dup {
+		panic("mbel: RegisterLintRule called twice for rule " + name)
+	}
+	if plugins.rules == nil {
+		plugins.rules = make(map[string]LintRule)
+	}
+	plugins.rules[name] = rule
+}
+
+// LintRules returns the names of the registered lint rules, sorted
+func LintRules() []string {
+	plugins.mu.RLock()
+	defer plugins.mu.RUnlock()
+
+	names := make([]string, 0, len(plugins.rules))
+	for name := range plugins.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunLintRules runs every registered rule over p. Diagnostics are tagged
+// with the rule name and sorted by position, then rule name.
+func RunLintRules(p *Program, ctx LintContext) []Diagnostic {
+	if ctx.Lang == "" {
+		ctx.Lang = Metadata(p)["lang"]
+	}
+
+	var out []Diagnostic
+	for _, name := range LintRules() {
+		plugins.mu.RLock()
+		rule := plugins.rules[name]
+		plugins.mu.RUnlock()
+
+		for _, d := range rule(p, ctx) {
+			d.Rule = name
+			if d.EndLine == 0 {
+				d.EndLine, d.EndColumn = d.Line, d.Column
+			}
+			out = append(out, d)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Line != out[j].Line {
+			return out[i].Line < out[j].Line
+		}
+		return out[i].Column < out[j].Column
+	})
+	return out
+}
+
+// RegisterCompileTransform adds a transform applied, in registration
+// order, to the output of every compiled file (FileRepository and
+// `mbel compile`; not CompileStream, which never holds a whole file)
+func RegisterCompileTransform(t CompileTransform) {
+	if t == nil {
+		panic("mbel: RegisterCompileTransform transform is nil")
+	}
+	plugins.mu.Lock()
+	plugins.transforms = append(plugins.transforms, t)
+	plugins.mu.Unlock()
+}
+
+// ApplyCompileTransforms runs the registered transforms over data
+func ApplyCompileTransforms(data map[string]interface{}) map[string]interface{} {
+	plugins.mu.RLock()
+	transforms := plugins.transforms
+	plugins.mu.RUnlock()
+
+	for _, t := range transforms {
+		data = t(data)
+	}
+	return data
+}
+
+// String formats the diagnostic as "line:col: severity: message [rule]",
+// followed by the suggested fix if there is one
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%d:%d: %s: %s [%s]", d.Line, d.Column, d.Severity, d.Message, d.Rule)
+	if d.Suggestion != "" {
+		s += fmt.Sprintf(" (fix: %s)", d.Suggestion)
+	}
+	return s
+}
+
+// ApplySuggestions replaces the span of every diagnostic carrying a
+// Suggestion with it, returning the new source and the number of fixes
+// applied. Of overlapping spans only the first is fixed.
+func ApplySuggestions(src []byte, diags []Diagnostic) ([]byte, int) {
+	f := &sourceFile{content: string(src)}
+	end := -1
+	fixes := append([]Diagnostic(nil), diags...)
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].Line < fixes[j].Line || fixes[i].Line == fixes[j].Line && fixes[i].Column < fixes[j].Column
+	})
+	for _, d := range fixes {
+		if d.Suggestion == "" {
+			continue
+		}
+		start := f.offset(d.Line, d.Column)
+		if start <= end {
+			continue
+		}
+		end = f.offset(d.EndLine, d.EndColumn)
+		f.splices = append(f.splices, splice{start, end + 1, d.Suggestion})
+	}
+	return []byte(f.apply()), len(f.splices)
+}
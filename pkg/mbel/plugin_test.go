@@ -0,0 +1,64 @@
+package mbel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintRuleRegistry(t *testing.T) {
+	RegisterLintRule("test-snake-case", func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		for key, a := range Assignments(p) {
+			if strings.ToLower(key) != key {
+				out = append(out, DiagnosticAt(a.Token, SeverityError, key+" is not snake_case"))
+			}
+		}
+		return out
+	})
+	defer func() {
+		plugins.mu.Lock()
+		delete(plugins.rules, "test-snake-case")
+		plugins.mu.Unlock()
+	}()
+
+	program := NewParser(NewLexer("@lang: en\nokKey = \"a\"\nfine = \"b\"\nBad = \"c\"\n")).ParseProgram()
+	diags := RunLintRules(program, LintContext{File: "en.mbel"})
+	if len(diags) != 2 || diags[0].Line != 2 || diags[1].Line != 4 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if d := diags[1]; d.Column != 1 || d.EndLine != 4 || d.EndColumn != 3 {
+		t.Errorf("unexpected span %d:%d-%d:%d", d.Line, d.Column, d.EndLine, d.EndColumn)
+	}
+	if got := diags[0].String(); got != "2:1: error: okKey is not snake_case [test-snake-case]" {
+		t.Errorf("unexpected format %q", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a rule twice should panic")
+		}
+	}()
+	RegisterLintRule("test-snake-case", func(*Program, LintContext) []Diagnostic { return nil })
+}
+
+func TestCompileTransforms(t *testing.T) {
+	RegisterCompileTransform(func(data map[string]interface{}) map[string]interface{} {
+		if _, ok := data["brand"]; ok {
+			data["brand"] = strings.ToUpper(data["brand"].(string))
+		}
+		return data
+	})
+	defer func() {
+		plugins.mu.Lock()
+		plugins.transforms = plugins.transforms[:len(plugins.transforms)-1]
+		plugins.mu.Unlock()
+	}()
+
+	data, _, err := CompileSource([]byte("brand = \"acme\"\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["brand"] != "ACME" {
+		t.Errorf("transform not applied: %v", data["brand"])
+	}
+}
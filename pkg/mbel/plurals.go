@@ -175,3 +175,39 @@ func ResolvePluralCategoryExtended(lang string, n int) string {
 	// Default to English rules
 	return pluralEnglish(n)
 }
+
+// countCacheSize bounds the counts whose resolved block value a Runtime
+// memoizes; badges and counters almost always fall below it
+const countCacheSize = 128
+
+// countTable is one block's resolved value for the counts
+// 0..countCacheSize-1 in one language. It is immutable once built.
+type countTable struct {
+	lang string
+	vals [countCacheSize]string
+}
+
+func newCountTable(rb *RuntimeBlock, lang string, rule PluralRule) *countTable {
+	t := &countTable{lang: lang}
+	for n := range t.vals {
+		t.vals[n] = rb.resolve(n, lang, rule)
+	}
+	return t
+}
+
+// resolveBlock is ResolveWithLang in the runtime's language. Small
+// integer counts, the hot path of re-rendered counters, are answered
+// from a per-message table instead of re-running the plural rules.
+func (r *Runtime) resolveBlock(rb *RuntimeBlock, arg interface{}) string {
+	n, ok := rb.argument(arg).(int)
+	if !ok || n < 0 || n >= countCacheSize {
+		return rb.resolve(arg, r.Language, r.plural)
+	}
+
+	if t, ok := r.counts.Load(rb); ok && t.(*countTable).lang == r.Language {
+		return t.(*countTable).vals[n]
+	}
+	t := newCountTable(rb, r.Language, r.plural)
+	r.counts.Store(rb, t)
+	return t.vals[n]
+}
@@ -0,0 +1,74 @@
+package mbel
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// pluralTestNumbers are the counts a plural test exercises: the edges of
+// every rule MBEL knows (teens, 2-4 endings, hundreds) and a fraction
+var pluralTestNumbers = []float64{0, 1, 2, 3, 4, 5, 11, 12, 14, 21, 22, 25, 100, 101, 102, 111, 112, 1000, 1.5}
+
+// pluralCategoryOrder is the CLDR order of plural categories
+var pluralCategoryOrder = []string{"zero", "one", "two", "few", "many", "other"}
+
+// PluralSample is one plural category of a locale and the representative
+// counts that select it
+type PluralSample struct {
+	Category string
+	Numbers  []float64
+}
+
+// PluralSamples returns the categories lang's rule produces, in CLDR
+// order, with the counts selecting each as Get resolves them (fractions
+// are truncated, so 1.5 selects what 1 does)
+func PluralSamples(lang string) []PluralSample {
+	byCat := make(map[string][]float64)
+	for _, n := range pluralTestNumbers {
+		cat := ResolvePluralCategory(lang, int(n))
+		byCat[cat] = append(byCat[cat], n)
+	}
+	var out []PluralSample
+	for _, cat := range pluralCategoryOrder {
+		if nums, ok := byCat[cat]; ok {
+			out = append(out, PluralSample{Category: cat, Numbers: nums})
+		}
+	}
+	return out
+}
+
+// WritePluralTest writes an .mbel file for lang with a plural_test block
+// holding a case per category of its rule, each listing the counts that
+// select it. Translators replace the placeholder wording and check it
+// with `mbel preview`.
+func WritePluralTest(w io.Writer, lang string) error {
+	samples := PluralSamples(lang)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Plural rule test for %s, generated by mbel gen-plural-tests.\n", lang)
+	b.WriteString("# Write each case as you would for a real count, then check every\n")
+	b.WriteString("# number below reads right from a scratch directory holding only\n")
+	b.WriteString("# this file (not your locales directory):\n")
+	b.WriteString("#   mbel preview <dir> -key plural_test -vars n=22\n")
+	fmt.Fprintf(&b, "@lang: %s\n\n", lang)
+
+	for _, s := range samples {
+		fmt.Fprintf(&b, "# [%s] %s\n", s.Category, formatPluralNumbers(s.Numbers))
+	}
+	b.WriteString("plural_test(n) {\n")
+	for _, s := range samples {
+		fmt.Fprintf(&b, "    [%s] => \"{n} (%s)\"\n", s.Category, s.Category)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatPluralNumbers(nums []float64) string {
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		parts[i] = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,43 @@
+package mbel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPluralSamples(t *testing.T) {
+	want := []PluralSample{
+		{"one", []float64{1, 1.5}},
+		{"few", []float64{2, 3, 4, 22, 102}},
+		{"many", []float64{0, 5, 11, 12, 14, 21, 25, 100, 101, 111, 112, 1000}},
+	}
+	if got := PluralSamples("pl"); !reflect.DeepEqual(got, want) {
+		t.Errorf("PluralSamples(pl) = %v", got)
+	}
+	if got := PluralSamples("en"); len(got) != 2 || got[0].Category != "one" || got[1].Category != "other" {
+		t.Errorf("PluralSamples(en) = %v", got)
+	}
+}
+
+func TestWritePluralTest(t *testing.T) {
+	var b strings.Builder
+	if err := WritePluralTest(&b, "pl"); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "# [few] 2, 3, 4, 22, 102\n") {
+		t.Errorf("missing sample comment:\n%s", out)
+	}
+
+	data, errs, err := CompileSource([]byte(out), nil)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("generated file does not compile: %v %v\n%s", errs, err, out)
+	}
+	rt := NewRuntime(data)
+	for n, want := range map[int]string{1: "1 (one)", 22: "22 (few)", 12: "12 (many)"} {
+		if got := rt.Get("plural_test", n); got != want {
+			t.Errorf("plural_test(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
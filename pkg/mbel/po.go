@@ -0,0 +1,238 @@
+package mbel
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Gettext PO mapping. Keys are msgids and values msgstrs; each case of
+// a logic block is an entry "key[condition]" flagged with the block
+// argument, so blocks survive a round trip through PO editors:
+//
+//	#, mbel-arg:n
+//	msgid "items[one]"
+//	msgstr "{n} item"
+//
+// @lang becomes the Language header and other metadata X-MBEL-* headers.
+// Terms, imports and AI annotations have no PO equivalent and are dropped.
+
+const poArgFlag = "mbel-arg:"
+
+// WritePO encodes compiled data (one language) as a gettext PO file,
+// entries sorted by key
+func WritePO(w io.Writer, data map[string]interface{}) error {
+	bw := bufio.NewWriter(w)
+
+	meta, _ := data["__meta"].(map[string]string)
+	header := "Content-Type: text/plain; charset=UTF-8\n"
+	if lang := meta["lang"]; lang != "" {
+		header = "Language: " + lang + "\n" + header
+	}
+	names := make([]string, 0, len(meta))
+	for k := range meta {
+		if k != "lang" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		header += "X-MBEL-" + k + ": " + meta[k] + "\n"
+	}
+	writePOEntry(bw, "", "", header)
+
+	for _, key := range SortedKeys(data) {
+		switch v := data[key].(type) {
+		case string:
+			writePOEntry(bw, "", key, v)
+		case *RuntimeBlock:
+			conds := make([]string, 0, len(v.Cases))
+			for c := range v.Cases {
+				conds = append(conds, c)
+			}
+			sort.Strings(conds)
+			for _, c := range conds {
+				writePOEntry(bw, poArgFlag+v.Argument, key+"["+c+"]", v.Cases[c])
+			}
+			for _, rc := range v.RangeCases {
+				writePOEntry(bw, poArgFlag+v.Argument, fmt.Sprintf("%s[%d..%d]", key, rc.Start, rc.End), rc.Value)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func writePOEntry(w *bufio.Writer, flag, msgid, msgstr string) {
+	if flag != "" {
+		fmt.Fprintf(w, "#, %s\n", flag)
+	}
+	fmt.Fprintf(w, "msgid %s\n", poQuote(msgid))
+	fmt.Fprintf(w, "msgstr %s\n\n", poQuote(msgstr))
+}
+
+// poQuote renders a PO string, splitting multi-line values after each \n
+func poQuote(s string) string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return strconv.Quote(s)
+	}
+	var b strings.Builder
+	b.WriteString(`""`)
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line != "" {
+			b.WriteString("\n" + strconv.Quote(line))
+		}
+	}
+	return b.String()
+}
+
+// ReadPO decodes a PO file written by WritePO (or edited by a translation
+// tool) back into compiled data. Fuzzy and obsolete entries are kept as
+// they are; plural entries (msgid_plural) are not supported.
+func ReadPO(r io.Reader) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+
+	var (
+		flags, msgid, msgstr string
+		target               *string
+		inEntry              bool
+		lineNo               int
+	)
+	flush := func() error {
+		if !inEntry {
+			return nil
+		}
+		defer func() { flags, msgid, msgstr, target, inEntry = "", "", "", nil, false }()
+
+		if msgid == "" {
+			if meta := poHeaderMeta(msgstr); len(meta) > 0 {
+				data["__meta"] = meta
+			}
+			return nil
+		}
+		arg, isBlock := poFlag(flags, poArgFlag)
+		open := strings.IndexByte(msgid, '[')
+		if !isBlock || open < 0 || !strings.HasSuffix(msgid, "]") {
+			data[msgid] = msgstr
+			return nil
+		}
+
+		key, cond := msgid[:open], msgid[open+1:len(msgid)-1]
+		rb, _ := data[key].(*RuntimeBlock)
+		if rb == nil {
+			rb = &RuntimeBlock{Argument: arg, Cases: make(map[string]string), RangeCases: []RangeCase{}}
+			data[key] = rb
+		}
+		if lo, hi, ok := strings.Cut(cond, ".."); ok {
+			start, err1 := strconv.Atoi(lo)
+			end, err2 := strconv.Atoi(hi)
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("line %d: invalid range %q", lineNo, cond)
+			}
+			rb.RangeCases = append(rb.RangeCases, RangeCase{Start: start, End: end, Value: msgstr})
+		} else {
+			rb.Cases[cond] = msgstr
+		}
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		line = strings.TrimPrefix(line, "#~ ") // obsolete entries
+
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#,"):
+			if target != nil {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			flags += "," + line[2:]
+			inEntry = true
+		case strings.HasPrefix(line, "#"):
+			// Translator, extracted and reference comments
+		case strings.HasPrefix(line, "msgid_plural"), strings.HasPrefix(line, "msgstr["):
+			return nil, fmt.Errorf("line %d: plural PO entries are not supported", lineNo)
+		case strings.HasPrefix(line, "msgctxt "):
+			// Context is not part of the key mapping
+		case strings.HasPrefix(line, "msgid "):
+			if target != nil {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			inEntry = true
+			target = &msgid
+			if err := poAppend(target, line[len("msgid "):]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		case strings.HasPrefix(line, "msgstr "):
+			target = &msgstr
+			if err := poAppend(target, line[len("msgstr "):]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		case strings.HasPrefix(line, `"`) && target != nil:
+			if err := poAppend(target, line); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", lineNo, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	data["__schema"] = SchemaVersion
+	return data, nil
+}
+
+func poAppend(dst *string, quoted string) error {
+	s, err := strconv.Unquote(quoted)
+	if err != nil {
+		return fmt.Errorf("invalid PO string %s", quoted)
+	}
+	*dst += s
+	return nil
+}
+
+// poFlag returns the value of the first "name..." flag in a "#," list
+func poFlag(flags, name string) (string, bool) {
+	for _, f := range strings.Split(flags, ",") {
+		if f = strings.TrimSpace(f); strings.HasPrefix(f, name) {
+			return f[len(name):], true
+		}
+	}
+	return "", false
+}
+
+// poHeaderMeta maps the PO header back to __meta
+func poHeaderMeta(header string) map[string]string {
+	meta := make(map[string]string)
+	for _, line := range strings.Split(header, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case name == "Language":
+			meta["lang"] = value
+		case strings.HasPrefix(name, "X-MBEL-"):
+			meta[strings.TrimPrefix(name, "X-MBEL-")] = value
+		}
+	}
+	return meta
+}
@@ -0,0 +1,53 @@
+package mbel
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPORoundTrip(t *testing.T) {
+	data, errs, err := CompileSource([]byte(`@lang: pl
+@version: 2
+title = "Sklep Acme"
+body = """
+Linia 1
+Linia 2
+"""
+items(n) {
+    [one] => "1 produkt"
+    [2..4] => "{n} produkty"
+    [other] => "{n} produktów"
+}
+`), nil)
+	if err != nil || len(errs) > 0 {
+		t.Fatal(err, errs)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePO(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	po := buf.String()
+	for _, want := range []string{`"Language: pl\n"`, "#, mbel-arg:n\nmsgid \"items[2..4]\"", `msgid "title"`} {
+		if !strings.Contains(po, want) {
+			t.Errorf("PO output lacks %q:\n%s", want, po)
+		}
+	}
+
+	back, err := ReadPO(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, data) {
+		t.Errorf("round trip changed data:\n got %#v\nwant %#v", back, data)
+	}
+}
+
+func TestReadPORejectsPlurals(t *testing.T) {
+	_, err := ReadPO(strings.NewReader("msgid \"a\"\nmsgid_plural \"as\"\nmsgstr[0] \"x\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a line-numbered error, got %v", err)
+	}
+}
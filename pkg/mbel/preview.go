@@ -0,0 +1,153 @@
+package mbel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sample variables give a key example values for previews, so reviewers
+// read rendered copy rather than templates:
+//
+//	# AI_SampleVars: {"name": "Alice", "n": 3}
+//	cart.summary = "{name} has {n} items"
+//
+// Longer samples may use the multi-line annotation form.
+
+// sampleVarsType is the annotation type holding sample variables
+const sampleVarsType = "SampleVars"
+
+// ParseSampleVars parses the JSON object of an AI_SampleVars annotation;
+// the outer braces may be left out, as in the multi-line form
+func ParseSampleVars(s string) (Vars, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		s = "{" + s + "}"
+	}
+	var vars Vars
+	if err := json.Unmarshal([]byte(s), &vars); err != nil {
+		return nil, fmt.Errorf("AI_SampleVars: %v", err)
+	}
+	return vars, nil
+}
+
+// SampleVars returns the AI_SampleVars of key, and nil when it has none
+func (r *Runtime) SampleVars(key string) (Vars, error) {
+	for _, e := range r.aiEntries(key) {
+		if e["type"] == sampleVarsType {
+			return ParseSampleVars(e["value"])
+		}
+	}
+	return nil, nil
+}
+
+// SamplePreview is a key rendered in one locale with sample variables
+type SamplePreview struct {
+	Lang    string
+	Text    string       // "" when Missing
+	Cases   []SampleCase // every case of a logic block, in review order
+	Missing bool         // the locale lacks the key
+}
+
+// SampleCase is one case of a logic block rendered with the sample
+// variables, e.g. Cond "few" of a Polish plural
+type SampleCase struct {
+	Cond string
+	Text string
+}
+
+// PreviewKey renders key in every locale of langData (source first, then
+// sorted) with its AI_SampleVars overlaid with vars (which may be nil);
+// locales without samples of their own use the source locale's.
+// Placeholders without a value stay as written.
+func PreviewKey(langData map[string]map[string]interface{}, source, key string, vars Vars) ([]SamplePreview, error) {
+	langs := sortedLocales(langData)
+	for i, lang := range langs {
+		if lang == source {
+			langs = append([]string{source}, append(langs[:i:i], langs[i+1:]...)...)
+			break
+		}
+	}
+
+	var fallback Vars
+	if data, ok := langData[source]; ok {
+		vars, err := NewRuntime(data).SampleVars(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", source, key, err)
+		}
+		fallback = vars
+	}
+
+	out := make([]SamplePreview, 0, len(langs))
+	for _, lang := range langs {
+		rt := NewRuntime(langData[lang])
+		if _, ok := rt.value(key); !ok {
+			out = append(out, SamplePreview{Lang: lang, Missing: true})
+			continue
+		}
+		samples, err := rt.SampleVars(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", lang, key, err)
+		}
+		if samples == nil {
+			samples = fallback
+		}
+		args := make(Vars, len(samples)+len(vars))
+		for k, v := range samples {
+			args[k] = v
+		}
+		for k, v := range vars {
+			args[k] = v
+		}
+
+		p := SamplePreview{Lang: lang, Text: rt.Get(key, args)}
+		if rb, ok := langData[lang][key].(*RuntimeBlock); ok {
+			for _, e := range blockEntries(rb) {
+				p.Cases = append(p.Cases, SampleCase{Cond: e.cond, Text: rt.interpolate(key, e.value, args)})
+			}
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// ParseSampleFlag parses "n=3,name=Alice" into variables; integer values
+// become ints, so they select plural cases
+func ParseSampleFlag(s string) (Vars, error) {
+	vars := make(Vars)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !placeholderNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable %q (want name=value)", pair)
+		}
+		if n, err := strconv.Atoi(value); err == nil {
+			vars[name] = n
+		} else {
+			vars[name] = value
+		}
+	}
+	return vars, nil
+}
+
+// SampleVarsRule returns a lint rule reporting AI_SampleVars annotations
+// that are not a JSON object
+func SampleVarsRule() LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		for _, ann := range p.AIAnnotations {
+			if ann.Type != sampleVarsType {
+				continue
+			}
+			if _, err := ParseSampleVars(ann.Value); err != nil {
+				out = append(out, Diagnostic{
+					Severity: SeverityError,
+					Message:  err.Error(),
+					Line:     ann.Line,
+					Column:   1,
+				})
+			}
+		}
+		return out
+	}
+}
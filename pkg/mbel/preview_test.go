@@ -0,0 +1,93 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreviewKey(t *testing.T) {
+	compile := func(src string) map[string]interface{} {
+		data, errs, err := CompileSource([]byte(src), nil)
+		if err != nil || len(errs) > 0 {
+			t.Fatal(errs, err)
+		}
+		return data
+	}
+	langData := map[string]map[string]interface{}{
+		"en": compile(`@lang: en
+# AI_SampleVars: {"name": "Alice", "n": 3}
+summary(n) {
+    [one] => "{name} has one item"
+    [other] => "{name} has {n} items"
+}
+next = "Next"
+`),
+		"pl": compile(`@lang: pl
+summary(n) {
+    [one] => "{name} ma jeden przedmiot"
+    [few] => "{name} ma {n} przedmioty"
+    [other] => "{name} ma {n} przedmiotów"
+}
+`),
+		"de": compile(`@lang: de
+# AI_SampleVars: {
+#   "name": "Jonas",
+#   "n": 1
+# }
+summary(n) {
+    [one] => "{name} hat einen Artikel"
+    [other] => "{name} hat {n} Artikel"
+}
+`),
+		"fr": compile("@lang: fr\nnext = \"Suivant\"\n"),
+	}
+
+	got, err := PreviewKey(langData, "en", "summary", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, p := range got {
+		texts = append(texts, p.Lang+": "+p.Text)
+	}
+	want := []string{"en: Alice has 3 items", "de: Jonas hat einen Artikel", "fr: ", "pl: Alice ma 3 przedmioty"}
+	if !reflect.DeepEqual(texts, want) || !got[2].Missing {
+		t.Errorf("PreviewKey = %q\nwant %q", texts, want)
+	}
+
+	// Variables given explicitly win over the samples; cases are expanded
+	vars, err := ParseSampleFlag("n=5,name=Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = PreviewKey(langData, "en", "summary", vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := got[3]
+	wantCases := []SampleCase{
+		{Cond: "few", Text: "Bob ma 5 przedmioty"},
+		{Cond: "one", Text: "Bob ma jeden przedmiot"},
+		{Cond: "other", Text: "Bob ma 5 przedmiotów"},
+	}
+	if pl.Text != "Bob ma 5 przedmiotów" || !reflect.DeepEqual(pl.Cases, wantCases) {
+		t.Errorf("pl with vars = %+v", pl)
+	}
+	if _, err := ParseSampleFlag("n"); err == nil {
+		t.Error("ParseSampleFlag accepted a pair without =")
+	}
+
+	// The single-line JSON annotation must not swallow the key after it
+	if NewRuntime(langData["en"]).Get("next") != "Next" {
+		t.Error("annotation consumed the following lines")
+	}
+}
+
+func TestSampleVarsRule(t *testing.T) {
+	p := NewParser(NewLexer("# AI_SampleVars: {\"name\": }\nk = \"{name}\"\n"))
+	program := p.ParseProgram()
+	d := SampleVarsRule()(program, LintContext{})
+	if len(d) != 1 || d[0].Severity != SeverityError || d[0].Line != 1 {
+		t.Errorf("invalid JSON: %+v", d)
+	}
+}
@@ -0,0 +1,138 @@
+package mbel
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProjectFileName is the project file read by `mbel generate`
+const ProjectFileName = "mbel.toml"
+
+// Project is the content of an mbel.toml file: the code to generate from
+// the project's catalogs.
+//
+//	# mbel.toml
+//	[[generate]]
+//	locales = "locales"      # relative to mbel.toml
+//	output = "i18n/i18n_gen.go"
+//	package = "i18n"         # default: the output directory's name
+//	source = "en"            # default: en
+//
+// Only the TOML needed here is understood: [generate] or [[generate]]
+// tables of quoted string values, and # comments.
+type Project struct {
+	Dir      string // directory of the project file
+	Generate []GenerateTarget
+}
+
+// GenerateTarget is one [[generate]] table of a project file
+type GenerateTarget struct {
+	Locales string // locales root, relative to the project directory
+	Output  string // Go file to write, relative to the project directory
+	Package string // Go package name ("" = the output directory's name)
+	Source  string // locale whose keys and placeholders are used ("" = en)
+}
+
+// ParseProject parses the content of a project file found in dir
+func ParseProject(dir, src string) (*Project, error) {
+	p := &Project{Dir: dir}
+	var cur *GenerateTarget
+	sc := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(src, bom)))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(stripTOMLComment(sc.Text()))
+		if text == "" {
+			continue
+		}
+		switch text {
+		case "[generate]", "[[generate]]":
+			p.Generate = append(p.Generate, GenerateTarget{})
+			cur = &p.Generate[len(p.Generate)-1]
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			return nil, fmt.Errorf("line %d: unknown table %s", line, text)
+		}
+
+		name, raw, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: want key = \"value\"", line)
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("line %d: %s outside a [generate] table", line, strings.TrimSpace(name))
+		}
+		value, err := tomlString(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		switch name = strings.TrimSpace(name); name {
+		case "locales":
+			cur.Locales = value
+		case "output":
+			cur.Output = value
+		case "package":
+			cur.Package = value
+		case "source":
+			cur.Source = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %s", line, name)
+		}
+	}
+	for i, t := range p.Generate {
+		if t.Locales == "" || t.Output == "" {
+			return nil, fmt.Errorf("generate target %d: locales and output are required", i+1)
+		}
+	}
+	return p, nil
+}
+
+// stripTOMLComment removes a # comment outside quotes
+func stripTOMLComment(s string) string {
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// tomlString parses a basic ("...") or literal ('...') TOML string
+func tomlString(s string) (string, error) {
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1], nil
+	case len(s) >= 2 && s[0] == '"':
+		return strconv.Unquote(s)
+	}
+	return "", fmt.Errorf("want a quoted string, got %s", s)
+}
+
+// ReadProject reads the project file at path
+func ReadProject(path string) (*Project, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParseProject(filepath.Dir(path), string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// FindProject returns the path of the project file in dir or the nearest
+// of its parents, and "" when there is none
+func FindProject(dir string) string {
+	return findUp(dir, ProjectFileName)
+}
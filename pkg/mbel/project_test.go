@@ -0,0 +1,68 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProject(t *testing.T) {
+	p, err := ParseProject("repo", `# mbel.toml
+[[generate]]
+locales = "locales"          # the catalog
+output = "internal/i18n/i18n_gen.go"
+source = 'pl'
+
+[generate]
+locales = "admin/locales"
+output = "admin/i18n.go"
+package = "admin"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GenerateTarget{
+		{Locales: "locales", Output: "internal/i18n/i18n_gen.go", Source: "pl"},
+		{Locales: "admin/locales", Output: "admin/i18n.go", Package: "admin"},
+	}
+	if !reflect.DeepEqual(p.Generate, want) {
+		t.Errorf("targets = %+v", p.Generate)
+	}
+
+	for src, msg := range map[string]string{
+		"locales = \"x\"\n":                     "line 1: locales outside a [generate] table",
+		"[generate]\nlocales = x\n":             "line 2: want a quoted string",
+		"[generate]\nlocale = \"x\"\n":          "line 2: unknown key locale",
+		"[build]\n":                             "line 1: unknown table [build]",
+		"[generate]\nlocales = \"x\"\n":         "locales and output are required",
+		"[generate]\noutput = \"a#b.go\" # c\n": "locales and output are required",
+	} {
+		if _, err := ParseProject(".", src); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("ParseProject(%q) = %v, want %q", src, err, msg)
+		}
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "internal", "i18n")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if FindProject(sub) != "" {
+		t.Fatal("found a project file that does not exist")
+	}
+	path := filepath.Join(root, ProjectFileName)
+	if err := os.WriteFile(path, []byte("[generate]\nlocales = \"locales\"\noutput = \"i18n.go\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindProject(sub); got != path {
+		t.Errorf("FindProject = %q, want %q", got, path)
+	}
+	p, err := ReadProject(path)
+	if err != nil || p.Dir != root {
+		t.Errorf("ReadProject = %+v, %v", p, err)
+	}
+}
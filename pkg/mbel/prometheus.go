@@ -0,0 +1,60 @@
+package mbel
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WritePrometheus writes the runtime metrics in the Prometheus text
+// exposition format, without depending on the Prometheus client library
+func WritePrometheus(w io.Writer) error {
+	m := GetMetrics()
+	var b strings.Builder
+
+	counter := func(name, help string, value int64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+	}
+	counter("mbel_get_calls_total", "Runtime Get calls.", m["get_calls"])
+	counter("mbel_interpolate_ops_total", "Interpolation operations.", m["interpolate_ops"])
+	counter("mbel_compile_cache_hits_total", "Compile cache hits.", m["cache_hits"])
+	counter("mbel_compile_cache_misses_total", "Compile cache misses.", m["cache_misses"])
+
+	labeled := func(dim string, labels map[string]LabelMetrics) {
+		names := make([]string, 0, len(labels))
+		for l := range labels {
+			names = append(names, l)
+		}
+		sort.Strings(names)
+
+		for _, metric := range []struct {
+			name, help string
+			value      func(LabelMetrics) int64
+		}{
+			{"lookups", "Manager lookups", func(c LabelMetrics) int64 { return c.Gets }},
+			{"missing_keys", "Lookups of keys missing in every candidate locale", func(c LabelMetrics) int64 { return c.Misses }},
+			{"fallbacks", "Lookups served from a fallback locale", func(c LabelMetrics) int64 { return c.Fallbacks }},
+		} {
+			name := "mbel_" + dim + "_" + metric.name + "_total"
+			fmt.Fprintf(&b, "# HELP %s %s by %s.\n# TYPE %s counter\n", name, metric.help, dim, name)
+			for _, l := range names {
+				fmt.Fprintf(&b, "%s{%s=%q} %d\n", name, dim, l, metric.value(labels[l]))
+			}
+		}
+	}
+	labeled("locale", LocaleMetrics())
+	labeled("namespace", NamespaceMetrics())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MetricsHandler serves WritePrometheus output, for mounting at /metrics
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WritePrometheus(w)
+	})
+}
@@ -0,0 +1,283 @@
+package mbel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Translation QA. Translated messages of a ReviewSheet are checked for
+// placeholder damage locally and, when a model is configured, sent in
+// batches with their source, AI_Context and AI_Tone to an LLM that flags
+// mistranslations and tone mismatches.
+
+// QA finding kinds
+const (
+	QAPlaceholder    = "placeholder"    // a {placeholder} or {-term} lost, added or broken
+	QAMistranslation = "mistranslation" // meaning differs from the source
+	QATone           = "tone"           // register does not match AI_Tone or the source
+)
+
+// QAFinding is one suspected problem with a translation
+type QAFinding struct {
+	Key        string `json:"key"`
+	Kind       string `json:"kind"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// QAModel reviews translations; rows are the messages of one batch
+type QAModel interface {
+	ReviewTranslations(ctx context.Context, sourceLang, targetLang string, rows []ReviewRow) ([]QAFinding, error)
+}
+
+// RunQA checks the translated rows of sheet (missing ones are skipped):
+// placeholders locally, meaning and tone with model, batchSize rows per
+// request. A nil model runs the local checks only. Findings are sorted by
+// key.
+func RunQA(ctx context.Context, sheet *ReviewSheet, model QAModel, batchSize int) ([]QAFinding, error) {
+	var rows []ReviewRow
+	for _, r := range sheet.Rows {
+		if r.Status != ReviewMissing {
+			rows = append(rows, r)
+		}
+	}
+	findings := CheckPlaceholders(rows)
+
+	if model != nil {
+		if batchSize <= 0 {
+			batchSize = 20
+		}
+		for start := 0; start < len(rows); start += batchSize {
+			batch := rows[start:min(start+batchSize, len(rows))]
+			found, err := model.ReviewTranslations(ctx, sheet.SourceLang, sheet.TargetLang, batch)
+			if err != nil {
+				return findings, err
+			}
+			findings = append(findings, qaKnown(found, batch)...)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Key < findings[j].Key })
+	return findings, nil
+}
+
+// qaKnown drops findings for keys outside batch and files unknown kinds
+// as mistranslations
+func qaKnown(found []QAFinding, batch []ReviewRow) []QAFinding {
+	keys := make(map[string]bool, len(batch))
+	for _, r := range batch {
+		keys[r.Key] = true
+	}
+	var out []QAFinding
+	for _, f := range found {
+		if !keys[f.Key] || f.Message == "" {
+			continue
+		}
+		switch f.Kind {
+		case QAPlaceholder, QAMistranslation, QATone:
+		default:
+			f.Kind = QAMistranslation
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// CheckPlaceholders reports translations whose {placeholders} and
+// {-term} references differ from the source, or whose braces do not
+// balance. Cases of a logic block are compared as a whole, since a case
+// may legitimately spell out its number ("1 item").
+func CheckPlaceholders(rows []ReviewRow) []QAFinding {
+	type sides struct {
+		source, target map[string]bool
+		broken         bool
+	}
+	byKey := make(map[string]*sides)
+	var order []string
+	for _, r := range rows {
+		key, _ := splitReviewKey(r.Key)
+		s := byKey[key]
+		if s == nil {
+			s = &sides{source: map[string]bool{}, target: map[string]bool{}}
+			byKey[key] = s
+			order = append(order, key)
+		}
+		for _, p := range placeholders(r.Source) {
+			s.source[p] = true
+		}
+		for _, p := range placeholders(r.Target) {
+			s.target[p] = true
+		}
+		if !bracesBalance(r.Target) && bracesBalance(r.Source) {
+			s.broken = true
+		}
+	}
+
+	var out []QAFinding
+	for _, key := range order {
+		s := byKey[key]
+		for _, p := range sortedSet(s.source) {
+			if !s.target[p] {
+				out = append(out, QAFinding{Key: key, Kind: QAPlaceholder, Message: "missing " + p})
+			}
+		}
+		for _, p := range sortedSet(s.target) {
+			if !s.source[p] {
+				out = append(out, QAFinding{Key: key, Kind: QAPlaceholder, Message: "unexpected " + p + " (not in the source)"})
+			}
+		}
+		if s.broken {
+			out = append(out, QAFinding{Key: key, Kind: QAPlaceholder, Message: "unbalanced braces"})
+		}
+	}
+	return out
+}
+
+// placeholders lists the {name} and {-term} references of s
+func placeholders(s string) []string {
+	var out []string
+	for _, loc := range placeholderIndexes(argRe, s) {
+		out = append(out, "{"+s[loc[2]:loc[3]]+"}")
+	}
+	for _, loc := range placeholderIndexes(termRe, s) {
+		out = append(out, s[loc[0]:loc[1]])
+	}
+	return out
+}
+
+// bracesBalance reports whether s has as many { as }, escaped pairs
+// aside
+func bracesBalance(s string) bool {
+	s = strings.NewReplacer("{{", "", "}}", "").Replace(s)
+	return strings.Count(s, "{") == strings.Count(s, "}")
+}
+
+func sortedSet(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// OpenAIModel is a QAModel backed by an OpenAI-compatible chat
+// completions API
+type OpenAIModel struct {
+	BaseURL string       // API root, "" = https://api.openai.com/v1
+	APIKey  string       // Bearer token
+	Model   string       // e.g. "gpt-4o-mini"
+	Client  *http.Client // nil = http.DefaultClient
+}
+
+const qaPrompt = `You review software UI translations from %s to %s.
+For each message you get the key, the source text, the translation, and optionally the context, the intended tone and a maximum length.
+Report only real problems:
+- "mistranslation": the meaning differs from the source, text is missing or added, or it is not in the target language
+- "tone": the register does not match the intended tone or the source (e.g. informal vs formal address)
+- "placeholder": a {placeholder} or {-term} was translated, renamed or dropped
+Placeholders in braces must be kept verbatim. Do not report style preferences.
+Answer with a JSON object {"findings": [{"key": "...", "kind": "...", "message": "...", "suggestion": "..."}]} where suggestion is a corrected translation. Return {"findings": []} when everything is fine.`
+
+// qaMessage is a row as sent to the model
+type qaMessage struct {
+	Key       string `json:"key"`
+	Source    string `json:"source"`
+	Target    string `json:"translation"`
+	Context   string `json:"context,omitempty"`
+	Tone      string `json:"tone,omitempty"`
+	MaxLength int    `json:"max_length,omitempty"`
+}
+
+// ReviewTranslations sends one batch to the chat completions endpoint
+func (m *OpenAIModel) ReviewTranslations(ctx context.Context, sourceLang, targetLang string, rows []ReviewRow) ([]QAFinding, error) {
+	msgs := make([]qaMessage, len(rows))
+	for i, r := range rows {
+		msgs[i] = qaMessage{r.Key, r.Source, r.Target, r.Context, r.Tone, r.MaxLength}
+	}
+	user, err := json.Marshal(map[string]interface{}{"messages": msgs})
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := m.complete(ctx, fmt.Sprintf(qaPrompt, sourceLang, targetLang), string(user))
+	if err != nil {
+		return nil, err
+	}
+
+	var answer struct {
+		Findings []QAFinding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(content), &answer); err != nil {
+		return nil, fmt.Errorf("model: answer is not the requested JSON: %w", err)
+	}
+	return answer.Findings, nil
+}
+
+// complete sends a system and a user message and returns the model's
+// JSON answer
+func (m *OpenAIModel) complete(ctx context.Context, system, user string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": m.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": user},
+		},
+		"response_format": map[string]string{"type": "json_object"},
+		"temperature":     0,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	base := m.BaseURL
+	if base == "" {
+		base = "https://api.openai.com/v1"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(base, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.APIKey)
+	}
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("model: %s: %s", resp.Status, strings.TrimSpace(string(raw)))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(raw, &completion); err != nil {
+		return "", fmt.Errorf("model: invalid response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("model: empty response")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
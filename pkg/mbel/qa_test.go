@@ -0,0 +1,75 @@
+package mbel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckPlaceholders(t *testing.T) {
+	got := CheckPlaceholders([]ReviewRow{
+		{Key: "greet", Source: "Hi {name}, welcome to {-brand}", Target: "Cześć {imie}, witaj w {-brand}"},
+		{Key: "items[one]", Source: "{n} item", Target: "1 przedmiot"},
+		{Key: "items[other]", Source: "{n} items", Target: "{n} przedmiotów"},
+		{Key: "broken", Source: "Total: {total}", Target: "Razem: {total}}"},
+	})
+	want := []QAFinding{
+		{Key: "greet", Kind: QAPlaceholder, Message: "missing {name}"},
+		{Key: "greet", Kind: QAPlaceholder, Message: "unexpected {imie} (not in the source)"},
+		{Key: "broken", Kind: QAPlaceholder, Message: "unbalanced braces"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckPlaceholders = %+v", got)
+	}
+}
+
+func TestRunQAWithModel(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if user := req.Messages[1].Content; req.Model != "test-model" || strings.Contains(user, `"cta"`) && !strings.Contains(user, `"tone":"Formal"`) {
+			t.Errorf("unexpected request %+v", req)
+		}
+
+		answer := `{"findings": [
+			{"key": "cta", "kind": "tone", "message": "informal address", "suggestion": "Kup teraz"},
+			{"key": "invented", "kind": "tone", "message": "not in the batch"}
+		]}`
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": map[string]string{"content": answer}}},
+		})
+	}))
+	defer srv.Close()
+
+	sheet := &ReviewSheet{SourceLang: "en", TargetLang: "pl", Rows: []ReviewRow{
+		{Key: "cta", Source: "Buy now", Target: "Kupuj teraz, ziomek", Tone: "Formal", Status: ReviewOK},
+		{Key: "title", Source: "Shop {name}", Target: "Sklep", Status: ReviewOK},
+		{Key: "todo", Source: "Later", Status: ReviewMissing},
+	}}
+	model := &OpenAIModel{BaseURL: srv.URL + "/v1", APIKey: "secret", Model: "test-model"}
+	got, err := RunQA(context.Background(), sheet, model, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []QAFinding{
+		{Key: "cta", Kind: QATone, Message: "informal address", Suggestion: "Kup teraz"},
+		{Key: "title", Kind: QAPlaceholder, Message: "missing {name}"},
+	}
+	if !reflect.DeepEqual(got, want) || requests != 2 {
+		t.Errorf("RunQA = %+v after %d requests", got, requests)
+	}
+}
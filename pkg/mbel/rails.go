@@ -0,0 +1,122 @@
+package mbel
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rails i18n YAML mapping. Each root key is a locale whose nested hashes
+// become dotted keys, %{name} interpolations become {name} and hashes of
+// plural categories become blocks on count:
+//
+//	en:
+//	  cart:
+//	    items:
+//	      one: "%{count} item"
+//	      other: "%{count} items"
+//
+// A zero form maps to the exact [0] case, as Rails picks it for a count
+// of 0. Arrays, numbers, booleans and symbols are not messages and are
+// skipped.
+
+// railsInterpolationRe matches %% and Rails' %{name} and %<name>d forms
+var railsInterpolationRe = regexp.MustCompile(`%%|%\{([a-zA-Z_][a-zA-Z0-9_]*)\}|%<([a-zA-Z_][a-zA-Z0-9_]*)>[-+ 0#]*\d*(?:\.\d+)?[a-zA-Z]`)
+
+// ReadRailsYAML decodes a Rails locale file into compiled data per
+// locale, usually just one ("en" for config/locales/en.yml)
+func ReadRailsYAML(r io.Reader) (map[string]map[string]interface{}, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseYAML(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("rails: %w", err)
+	}
+
+	out := make(map[string]map[string]interface{}, len(root))
+	for lang, tree := range root {
+		node, ok := tree.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("rails: root key %s is not a locale", lang)
+		}
+		data := make(map[string]interface{})
+		railsFlatten(data, "", node)
+		data["__schema"] = SchemaVersion
+		out[lang] = data
+	}
+	return out, nil
+}
+
+// railsFlatten collects the messages of a nested hash under dotted keys
+func railsFlatten(dst map[string]interface{}, prefix string, node map[string]interface{}) {
+	keys := make([]string, 0, len(node))
+	for k := range node {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := node[k].(type) {
+		case string:
+			if strings.HasPrefix(v, ":") {
+				continue // symbol: a reference, not a message
+			}
+			dst[key] = fromRails(v)
+		case map[string]interface{}:
+			if rb, ok := railsPlural(v); ok {
+				dst[key] = rb
+				continue
+			}
+			railsFlatten(dst, key, v)
+		}
+	}
+}
+
+// railsPlural folds a hash of plural categories with an other form into
+// a block on count
+func railsPlural(node map[string]interface{}) (*RuntimeBlock, bool) {
+	if _, ok := node["other"].(string); !ok {
+		return nil, false
+	}
+	rb := &RuntimeBlock{Argument: i18nextCount, Cases: make(map[string]string, len(node)), RangeCases: []RangeCase{}}
+	for cat, v := range node {
+		text, ok := v.(string)
+		if !ok || !containsString(i18nextSuffixes, cat) {
+			return nil, false
+		}
+		if cat == "zero" {
+			cat = "0"
+		}
+		rb.Cases[cat] = fromRails(text)
+	}
+	return rb, true
+}
+
+// fromRails rewrites %{name} and %<name>d interpolations as {name} and
+// escapes literal braces
+func fromRails(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range railsInterpolationRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(EscapeBraces(s[last:loc[0]]))
+		switch {
+		case loc[2] >= 0:
+			b.WriteString("{" + s[loc[2]:loc[3]] + "}")
+		case loc[4] >= 0:
+			b.WriteString("{" + s[loc[4]:loc[5]] + "}")
+		default:
+			b.WriteString("%") // %%
+		}
+		last = loc[1]
+	}
+	b.WriteString(EscapeBraces(s[last:]))
+	return b.String()
+}
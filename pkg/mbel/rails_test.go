@@ -0,0 +1,91 @@
+package mbel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRailsYAML(t *testing.T) {
+	src := `# config/locales/en.yml
+---
+en:
+  activerecord:
+    errors:
+      messages:
+        blank: "can't be blank" # inline comment
+        taken: 'has already been taken, it''s %{value}'
+  cart:
+    items:
+      zero: No items
+      one: "%{count} item"
+      other: "%{count} items"
+  date:
+    day_names: [Sunday, Monday]
+    order:
+      - :year
+      - :month
+  discount: "%<pct>d%% off"
+  terms: |
+    Line one
+    line two
+
+  number:
+    precision: 2
+    unit: :unit
+pl:
+  cart:
+    items:
+      one: "%{count} produkt"
+      few: "%{count} produkty"
+      many: "%{count} produktów"
+      other: "%{count} produktu"
+`
+	locales, err := ReadRailsYAML(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locales) != 2 {
+		t.Fatalf("locales = %v", locales)
+	}
+
+	en := locales["en"]
+	want := map[string]interface{}{
+		"activerecord.errors.messages.blank": "can't be blank",
+		"activerecord.errors.messages.taken": "has already been taken, it's {value}",
+		"cart.items": &RuntimeBlock{Argument: "count", Cases: map[string]string{
+			"0": "No items", "one": "{count} item", "other": "{count} items",
+		}, RangeCases: []RangeCase{}},
+		"discount": "{pct}% off",
+		"terms":    "Line one\nline two\n",
+		"__schema": SchemaVersion,
+	}
+	if !reflect.DeepEqual(en, want) {
+		t.Errorf("en =\n%#v\nwant\n%#v", en, want)
+	}
+	if rb, ok := locales["pl"]["cart.items"].(*RuntimeBlock); !ok || rb.Cases["few"] != "{count} produkty" {
+		t.Errorf("pl cart.items = %#v", locales["pl"]["cart.items"])
+	}
+
+	// The imported plural block compiles back to the same runtime form
+	out, _, err := CompileSource([]byte(FormatData(en)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out["cart.items"], en["cart.items"]) {
+		t.Errorf("re-compiled cart.items = %#v", out["cart.items"])
+	}
+}
+
+func TestReadRailsYAMLErrors(t *testing.T) {
+	for name, src := range map[string]string{
+		"alias":        "en:\n  a: &x hi\n  b: *x\n",
+		"unterminated": "en:\n  a: \"open\n",
+		"indentation":  "en:\n  a: x\n    b: y\n",
+		"not a locale": "en: hello\n",
+	} {
+		if _, err := ReadRailsYAML(strings.NewReader(src)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
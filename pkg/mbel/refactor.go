@@ -0,0 +1,94 @@
+package mbel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var placeholderNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParsePlaceholderRenames parses "old=new,old2=new2" into a map from old
+// to new placeholder names
+func ParsePlaceholderRenames(s string) (map[string]string, error) {
+	renames := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !placeholderNameRe.MatchString(from) || !placeholderNameRe.MatchString(to) {
+			return nil, fmt.Errorf("invalid rename %q (want old=new)", pair)
+		}
+		if _, dup := renames[from]; dup {
+			return nil, fmt.Errorf("%s renamed twice", from)
+		}
+		renames[from] = to
+	}
+	return renames, nil
+}
+
+// RenamePlaceholders renames the {placeholders} of src's keys for which
+// match (called with section-qualified keys) returns true, in every case
+// of logic blocks, and the argument of blocks named in renames. Quoting
+// and layout are kept. It returns the new source and the keys changed.
+func RenamePlaceholders(src []byte, renames map[string]string, match func(key string) bool) ([]byte, []string) {
+	f := &sourceFile{content: string(src)}
+	var changed []string
+
+	rename := func(tok Token) bool {
+		start := f.offset(tok.Line, tok.Column)
+		end := f.offset(tok.EndLine, tok.EndColumn) + 1
+		raw := f.content[start:end]
+		out := replacePlaceholders(argRe, raw, func(m string) string {
+			sub := argRe.FindStringSubmatchIndex(m)
+			if to, ok := renames[m[sub[2]:sub[3]]]; ok {
+				return m[:sub[2]] + to + m[sub[3]:]
+			}
+			return m
+		})
+		if out == raw {
+			return false
+		}
+		f.splices = append(f.splices, splice{start, end, out})
+		return true
+	}
+
+	walkKeyMeta(NewParser(NewLexer(f.content)).ParseProgram(), func(key string, a *AssignStatement, _ map[string]*MetadataStatement) {
+		if !match(key) {
+			return
+		}
+		did := false
+		switch v := a.Value.(type) {
+		case *StringLiteral:
+			did = rename(v.Token)
+		case *BlockExpression:
+			for _, bc := range v.Cases {
+				did = rename(bc.ValueToken) || did
+			}
+			if to, ok := renames[v.Argument]; ok && v.Argument != variantArgument {
+				did = f.renameArgument(a, v.Argument, to) || did
+			}
+		}
+		if did {
+			changed = append(changed, key)
+		}
+	})
+	return []byte(f.apply()), changed
+}
+
+// renameArgument renames the argument of the block assigned by a:
+// "items(n) {" becomes "items(count) {"
+func (f *sourceFile) renameArgument(a *AssignStatement, from, to string) bool {
+	off := f.offset(a.Token.Line, a.Token.Column) + len(a.Name)
+	open := strings.IndexByte(f.content[off:], '(')
+	if open < 0 {
+		return false
+	}
+	start := off + open + 1
+	for start < len(f.content) && (f.content[start] == ' ' || f.content[start] == '\t') {
+		start++
+	}
+	if !strings.HasPrefix(f.content[start:], from) {
+		return false
+	}
+	f.splices = append(f.splices, splice{start, start + len(from), to})
+	return true
+}
@@ -0,0 +1,58 @@
+package mbel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenamePlaceholders(t *testing.T) {
+	src := `[profile]
+hello = "Hi {name}, {{name}} is literal"
+note = """Say "hi" to {name} on {d, date}"""
+items( n ) {
+    [one] => "{n} item for {name}"
+    [other] => "{n} items"
+}
+other = "{username}"
+
+[admin]
+hello = "Hello {name}"
+`
+	out, changed := RenamePlaceholders([]byte(src), map[string]string{"name": "userName", "n": "count"}, func(key string) bool {
+		return strings.HasPrefix(key, "profile.")
+	})
+	want := `[profile]
+hello = "Hi {userName}, {{name}} is literal"
+note = """Say "hi" to {userName} on {d, date}"""
+items( count ) {
+    [one] => "{count} item for {userName}"
+    [other] => "{count} items"
+}
+other = "{username}"
+
+[admin]
+hello = "Hello {name}"
+`
+	if string(out) != want {
+		t.Errorf("source =\n%s\nwant\n%s", out, want)
+	}
+	if !reflect.DeepEqual(changed, []string{"profile.hello", "profile.note", "profile.items"}) {
+		t.Errorf("changed = %v", changed)
+	}
+	if p := NewParser(NewLexer(string(out))); p.ParseProgram() != nil && len(p.Errors()) > 0 {
+		t.Errorf("output does not parse: %v", p.Errors())
+	}
+}
+
+func TestParsePlaceholderRenames(t *testing.T) {
+	got, err := ParsePlaceholderRenames("name=userName, n=count")
+	if err != nil || !reflect.DeepEqual(got, map[string]string{"name": "userName", "n": "count"}) {
+		t.Errorf("renames = %v, %v", got, err)
+	}
+	for _, bad := range []string{"name", "name=", "a=b,a=c", "na-me=x"} {
+		if _, err := ParsePlaceholderRenames(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package mbel
+
+import (
+	"strings"
+	"time"
+)
+
+// Regional overrides the built-in conventions of a locale for one
+// deployment, e.g. British dates and Monday weeks for the en catalog of
+// an enterprise customer:
+//
+//	mbel.Config{Regional: map[string]mbel.Regional{"en": {Like: "en-GB"}}}
+//
+// Zero fields keep the locale's built-in behaviour.
+type Regional struct {
+	// Like takes the built-in plural rules, date layouts and first day
+	// of the week of another locale; the fields below apply on top
+	Like string
+
+	Plural         PluralRule    // category of the counts of logic blocks
+	DateLayout     string        // Go layout of {d, date}, e.g. "02/01/2006"
+	TimeLayout     string        // Go layout of {d, time}, e.g. "15:04"
+	FirstDayOfWeek *time.Weekday // nil = the locale's own
+}
+
+// regional returns the overrides configured for lang, or for its base
+// language (en-US -> en)
+func (m *Manager) regional(lang string) (Regional, bool) {
+	if r, ok := m.regionals[lang]; ok {
+		return r, true
+	}
+	if len(lang) > 2 {
+		r, ok := m.regionals[lang[:2]]
+		return r, ok
+	}
+	return Regional{}, false
+}
+
+// applyRegional sets the plural rule and date layouts of r, serving
+// lang, from the manager's overrides
+func (m *Manager) applyRegional(r *Runtime, lang string) {
+	reg, ok := m.regional(lang)
+	if !ok {
+		return
+	}
+	conventions := lang
+	if reg.Like != "" {
+		conventions = reg.Like
+	}
+
+	if reg.Plural != nil {
+		r.plural = reg.Plural
+	} else if reg.Like != "" {
+		r.plural = func(n int) string { return ResolvePluralCategory(conventions, n) }
+	}
+	if reg.Like != "" || reg.DateLayout != "" || reg.TimeLayout != "" {
+		l := dateLayoutsFor(conventions)
+		if reg.DateLayout != "" {
+			l.date = reg.DateLayout
+		}
+		if reg.TimeLayout != "" {
+			l.time = reg.TimeLayout
+		}
+		r.dates = &l
+	}
+}
+
+// FirstDayOfWeek returns the first day of the week in lang, with the
+// manager's Regional overrides applied
+func (m *Manager) FirstDayOfWeek(lang string) time.Weekday {
+	reg, ok := m.regional(lang)
+	switch {
+	case !ok:
+		return FirstDayOfWeek(lang)
+	case reg.FirstDayOfWeek != nil:
+		return *reg.FirstDayOfWeek
+	case reg.Like != "":
+		return FirstDayOfWeek(reg.Like)
+	}
+	return FirstDayOfWeek(lang)
+}
+
+// FirstDayOfWeek returns the first day of the week in the Localizer's
+// locale, for calendars and date pickers
+func (l *Localizer) FirstDayOfWeek() time.Weekday {
+	if l.m == nil {
+		return FirstDayOfWeek(l.lang)
+	}
+	return l.m.FirstDayOfWeek(l.lang)
+}
+
+// regionWeekStarts are the regions whose week does not start on Monday
+// (CLDR weekData, abridged)
+var regionWeekStarts = map[string]time.Weekday{
+	"us": time.Sunday, "ca": time.Sunday, "mx": time.Sunday, "br": time.Sunday,
+	"jp": time.Sunday, "kr": time.Sunday, "tw": time.Sunday, "hk": time.Sunday,
+	"ph": time.Sunday, "in": time.Sunday, "il": time.Sunday, "za": time.Sunday,
+	"sa": time.Sunday,
+	"eg": time.Saturday, "dz": time.Saturday, "ir": time.Saturday, "af": time.Saturday,
+}
+
+// languageWeekStarts are the same for locales without a region, where a
+// language stands for its most common region: en for en-US, pt for pt-BR
+var languageWeekStarts = map[string]time.Weekday{
+	"en": time.Sunday, "pt": time.Sunday, "ja": time.Sunday, "ko": time.Sunday,
+	"he": time.Sunday, "hi": time.Sunday,
+	"ar": time.Saturday, "fa": time.Saturday,
+}
+
+// FirstDayOfWeek returns the built-in first day of the week of a locale:
+// its region's if it names one (en-GB is Monday), else its language's
+func FirstDayOfWeek(lang string) time.Weekday {
+	parts := strings.Split(localeKey(lang), "-")
+	for _, p := range parts[1:] {
+		if len(p) == 2 {
+			if d, ok := regionWeekStarts[p]; ok {
+				return d
+			}
+			return time.Monday
+		}
+	}
+	if d, ok := languageWeekStarts[parts[0]]; ok {
+		return d
+	}
+	return time.Monday
+}
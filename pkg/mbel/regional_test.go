@@ -0,0 +1,66 @@
+package mbel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirstDayOfWeek(t *testing.T) {
+	tests := map[string]time.Weekday{
+		"en":    time.Sunday,
+		"en-GB": time.Monday,
+		"en_US": time.Sunday,
+		"pl":    time.Monday,
+		"pt-PT": time.Monday,
+		"pt":    time.Sunday,
+		"ar-EG": time.Saturday,
+		"af":    time.Monday,
+	}
+	for lang, want := range tests {
+		if got := FirstDayOfWeek(lang); got != want {
+			t.Errorf("FirstDayOfWeek(%s) = %s, want %s", lang, got, want)
+		}
+	}
+}
+
+func TestRegionalOverrides(t *testing.T) {
+	items := &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "{n} item", "few": "{n} items (few)", "other": "{n} items"}}
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"due": "Due {d, datetime}", "items": items},
+		"de": {"due": "Fällig {d, date}"},
+		"pl": {"items": items},
+	})
+	sunday := time.Sunday
+	m, err := NewManagerWithRepo(repo, Config{Regional: map[string]Regional{
+		"en": {Like: "en-GB"},
+		"de": {DateLayout: "2006-01-02", FirstDayOfWeek: &sunday},
+		"pl": {Plural: func(n int) string { return "other" }},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := time.Date(2026, 3, 4, 17, 30, 0, 0, time.UTC)
+
+	if got := m.Get("en", "due", Vars{"d": d}); got != "Due 04/03/2026, 17:30" {
+		t.Errorf("en like en-GB: %q", got)
+	}
+	if got := m.Get("de", "due", Vars{"d": d}); got != "Fällig 2026-03-04" {
+		t.Errorf("de date layout: %q", got)
+	}
+	if got := m.Get("pl", "items", 3); got != "3 items" {
+		t.Errorf("pl plural rule: %q", got)
+	}
+	if got := m.Get("en", "items", 1); got != "1 item" {
+		t.Errorf("en plural: %q", got)
+	}
+
+	if got := m.FirstDayOfWeek("en-US"); got != time.Monday {
+		t.Errorf("en-US inherits the en override: %s", got)
+	}
+	if got := m.For("de").FirstDayOfWeek(); got != time.Sunday {
+		t.Errorf("de: %s", got)
+	}
+	if got := m.FirstDayOfWeek("fr"); got != time.Monday {
+		t.Errorf("fr: %s", got)
+	}
+}
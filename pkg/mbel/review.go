@@ -0,0 +1,559 @@
+package mbel
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Reviewer spreadsheets. Translators who do not edit .mbel files get a
+// table with one row per message, source and target side by side; each
+// case of a logic block is a row keyed "key[condition]", as in PO files.
+// The edited table is applied back to the target locale's files.
+
+// Review statuses
+const (
+	ReviewMissing      = "missing"      // no target text yet
+	ReviewUntranslated = "untranslated" // target identical to the source
+	ReviewTooLong      = "too long"     // target over its AI_MaxLength budget
+	ReviewOK           = "ok"
+)
+
+// ReviewRow is one message of a reviewer spreadsheet
+type ReviewRow struct {
+	Key       string
+	Source    string
+	Target    string
+	Context   string // AI_Context of the source message
+	Tone      string // AI_Tone of the source message (not part of the sheet)
+	MaxLength int    // AI_MaxLength budget, 0 = none
+	Status    string
+	Links     []string // AI_Screenshot and AI_Figma URLs of the source message
+}
+
+// ReviewSheet is a reviewer spreadsheet for one source/target pair
+type ReviewSheet struct {
+	SourceLang string
+	TargetLang string
+	Rows       []ReviewRow
+}
+
+// NewReviewSheet builds the rows for every key of the source locale,
+// sorted by key. Annotations are read from the files found through loc,
+// the target's own AI_MaxLength winning over the source's.
+func NewReviewSheet(langData map[string]map[string]interface{}, loc SourceLocator, source, target string) (*ReviewSheet, error) {
+	src, ok := langData[source]
+	if !ok {
+		return nil, fmt.Errorf("source locale %q: %w", source, ErrLocaleNotFound)
+	}
+	tgt := langData[target]
+	ix := newAnnotationIndex(loc)
+
+	sheet := &ReviewSheet{SourceLang: source, TargetLang: target}
+	for _, key := range SortedKeys(src) {
+		anns, err := ix.of(source, key)
+		if err != nil {
+			return nil, err
+		}
+		context := annotation(anns, "Context")
+		tone := annotation(anns, "Tone")
+		links := ContextURLs(anns)
+		budget, _ := maxLength(anns)
+		if own, err := ix.of(target, key); err != nil {
+			return nil, err
+		} else if b, ok := maxLength(own); ok {
+			budget = b
+		}
+
+		add := func(key, source, target string, present bool) {
+			sheet.Rows = append(sheet.Rows, ReviewRow{
+				Key:       key,
+				Source:    source,
+				Target:    target,
+				Context:   context,
+				Tone:      tone,
+				MaxLength: budget,
+				Status:    reviewStatus(source, target, present, budget),
+				Links:     links,
+			})
+		}
+		switch v := src[key].(type) {
+		case string:
+			t, ok := tgt[key].(string)
+			add(key, v, t, ok)
+		case *RuntimeBlock:
+			tb, _ := tgt[key].(*RuntimeBlock)
+			targets := map[string]string{}
+			if tb != nil {
+				for _, e := range blockEntries(tb) {
+					targets[e.cond] = e.value
+				}
+			}
+			for _, e := range blockEntries(v) {
+				t, ok := targets[e.cond]
+				add(key+"["+e.cond+"]", e.value, t, ok)
+			}
+		}
+	}
+	return sheet, nil
+}
+
+func reviewStatus(source, target string, present bool, budget int) string {
+	switch {
+	case !present || target == "":
+		return ReviewMissing
+	case target == source && hasLetters(target):
+		return ReviewUntranslated
+	case budget > 0 && utf8.RuneCountInString(target) > budget:
+		return ReviewTooLong
+	}
+	return ReviewOK
+}
+
+type blockEntry struct {
+	cond, value string
+}
+
+// blockEntries lists the cases of a block, keyword and number cases
+// sorted, then ranges ("2..4") in source order
+func blockEntries(rb *RuntimeBlock) []blockEntry {
+	entries := make([]blockEntry, 0, len(rb.Cases)+len(rb.RangeCases))
+	for c, v := range rb.Cases {
+		entries = append(entries, blockEntry{c, v})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].cond < entries[j].cond })
+	for _, rc := range rb.RangeCases {
+		entries = append(entries, blockEntry{fmt.Sprintf("%d..%d", rc.Start, rc.End), rc.Value})
+	}
+	return entries
+}
+
+// splitReviewKey splits "key[cond]" into its parts
+func splitReviewKey(k string) (key, cond string) {
+	if open := strings.IndexByte(k, '['); open > 0 && strings.HasSuffix(k, "]") {
+		return k[:open], k[open+1 : len(k)-1]
+	}
+	return k, ""
+}
+
+// table renders the sheet as rows of cells, header first
+func (s *ReviewSheet) table() [][]string {
+	out := [][]string{{
+		"Key",
+		"Source (" + s.SourceLang + ")",
+		"Target (" + s.TargetLang + ")",
+		"AI_Context",
+		"Max Length",
+		"Status",
+		"Screenshot",
+	}}
+	for _, r := range s.Rows {
+		budget := ""
+		if r.MaxLength > 0 {
+			budget = strconv.Itoa(r.MaxLength)
+		}
+		out = append(out, []string{r.Key, r.Source, r.Target, r.Context, budget, r.Status, strings.Join(r.Links, "\n")})
+	}
+	return out
+}
+
+// parseReviewTable reads a table written by table. Columns are found by
+// their header, so reviewers may reorder or add columns.
+func parseReviewTable(rows [][]string) (*ReviewSheet, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty review sheet")
+	}
+	sheet := &ReviewSheet{}
+	col := map[string]int{}
+	for i, h := range rows[0] {
+		name, lang, _ := strings.Cut(strings.TrimSpace(h), "(")
+		name = strings.ToLower(strings.TrimSpace(name))
+		lang = strings.TrimSuffix(strings.TrimSpace(lang), ")")
+		switch name {
+		case "source":
+			sheet.SourceLang = lang
+		case "target":
+			sheet.TargetLang = lang
+		}
+		if _, dup := col[name]; !dup {
+			col[name] = i
+		}
+	}
+	for _, required := range []string{"key", "target"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("review sheet has no %q column", required)
+		}
+	}
+	if sheet.TargetLang == "" {
+		return nil, fmt.Errorf(`review sheet header does not name the target locale ("Target (pl)")`)
+	}
+
+	cell := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	for n, row := range rows[1:] {
+		r := ReviewRow{
+			Key:     strings.TrimSpace(cell(row, "key")),
+			Source:  cell(row, "source"),
+			Target:  cell(row, "target"),
+			Context: cell(row, "ai_context"),
+			Status:  cell(row, "status"),
+		}
+		for _, l := range strings.Split(cell(row, "screenshot"), "\n") {
+			if l = strings.TrimSpace(l); l != "" {
+				r.Links = append(r.Links, l)
+			}
+		}
+		if r.Key == "" {
+			continue
+		}
+		if b := strings.TrimSpace(cell(row, "max length")); b != "" {
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid max length %q", n+2, b)
+			}
+			r.MaxLength = v
+		}
+		sheet.Rows = append(sheet.Rows, r)
+	}
+	return sheet, nil
+}
+
+// WriteReviewCSV writes the sheet as CSV
+func WriteReviewCSV(w io.Writer, sheet *ReviewSheet) error {
+	return csv.NewWriter(w).WriteAll(sheet.table())
+}
+
+// ReadReviewCSV reads a sheet written by WriteReviewCSV
+func ReadReviewCSV(r io.Reader) (*ReviewSheet, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return parseReviewTable(rows)
+}
+
+// ReviewResult reports what ApplyReview changed
+type ReviewResult struct {
+	Updated []string // keys whose target text was replaced in place
+	Added   []string // keys (or block cases) new to the target locale
+	Skipped []string // "key: reason" for rows that could not be written
+
+	Changes []FileChange // the files rewritten, sorted by path
+}
+
+// ApplyReview writes the target texts of an edited sheet into the target
+// locale's files under repo.RootPath. Existing values are replaced where
+// they stand; keys the locale lacks are appended to the file mirroring
+// the source file ("en/shop.mbel" -> "pl/shop.mbel"). Empty and
+// unchanged cells are ignored. The rest of each file is left untouched.
+func ApplyReview(repo *FileRepository, sheet *ReviewSheet) (*ReviewResult, error) {
+	res, err := PlanReview(repo, sheet)
+	if err != nil {
+		return nil, err
+	}
+	return res, WriteChanges(res.Changes)
+}
+
+// PlanReview is ApplyReview without writing: res.Changes holds the new
+// content of every file it would rewrite
+func PlanReview(repo *FileRepository, sheet *ReviewSheet) (*ReviewResult, error) {
+	langData, err := repo.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	tgt := langData[sheet.TargetLang]
+
+	var keys []string
+	rows := make(map[string][]ReviewRow)
+	for _, r := range sheet.Rows {
+		if r.Target == "" {
+			continue
+		}
+		key, _ := splitReviewKey(r.Key)
+		if _, seen := rows[key]; !seen {
+			keys = append(keys, key)
+		}
+		rows[key] = append(rows[key], r)
+	}
+
+	res := &ReviewResult{}
+	ed := &sourceEdits{files: make(map[string]*sourceFile)}
+	skip := func(key, reason string) { res.Skipped = append(res.Skipped, key+": "+reason) }
+
+	for _, key := range keys {
+		if _, ok := tgt[key]; !ok {
+			if err := ed.addKey(repo, langData, sheet, key, rows[key], res); err != nil {
+				skip(key, err.Error())
+			}
+			continue
+		}
+
+		loc, _ := repo.Locate(sheet.TargetLang, key)
+		f, err := ed.file(loc.File)
+		if err != nil {
+			return nil, err
+		}
+		stmt := f.assignment(loc)
+		if stmt == nil {
+			skip(key, "definition not found in "+loc.File)
+			continue
+		}
+		for _, r := range rows[key] {
+			_, cond := splitReviewKey(r.Key)
+			switch v := stmt.Value.(type) {
+			case *StringLiteral:
+				if cond != "" {
+					skip(r.Key, "target is a plain string, not a block")
+				} else if v.Value != r.Target {
+					f.replace(v.Token, r.Target)
+					res.Updated = append(res.Updated, r.Key)
+				}
+			case *BlockExpression:
+				if cond == "" {
+					skip(r.Key, "target is a logic block")
+					continue
+				}
+				bc := findCase(v, cond)
+				switch {
+				case bc == nil:
+					f.insertLine(v.EndLine, fmt.Sprintf("    [%s] => %s\n", cond, quoteValue(r.Target)))
+					res.Added = append(res.Added, r.Key)
+				case bc.Value != r.Target:
+					f.replace(bc.ValueToken, r.Target)
+					res.Updated = append(res.Updated, r.Key)
+				}
+			}
+		}
+	}
+
+	res.Changes = ed.changes()
+	return res, nil
+}
+
+func findCase(b *BlockExpression, cond string) *BlockCase {
+	for _, bc := range b.Cases {
+		if bc.Condition == cond {
+			return bc
+		}
+	}
+	return nil
+}
+
+// addKey appends a key missing from the target locale to the file
+// mirroring the one that defines it in the source locale
+func (ed *sourceEdits) addKey(repo *FileRepository, langData map[string]map[string]interface{}, sheet *ReviewSheet, key string, rows []ReviewRow, res *ReviewResult) error {
+	srcLoc, ok := repo.Locate(sheet.SourceLang, key)
+	if !ok {
+		return fmt.Errorf("not defined in source locale %s", sheet.SourceLang)
+	}
+	rel, err := filepath.Rel(repo.RootPath, srcLoc.File)
+	if err != nil {
+		return err
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch parts[0] {
+	case sheet.SourceLang:
+		parts[0] = sheet.TargetLang
+	case sheet.SourceLang + ".mbel":
+		parts[0] = sheet.TargetLang + ".mbel"
+	default:
+		return fmt.Errorf("cannot mirror %s into locale %s", rel, sheet.TargetLang)
+	}
+
+	name := key
+	if ns := fileNamespace(parts); ns != "" {
+		name = strings.TrimPrefix(key, ns+".")
+	}
+	section, local, inSection := strings.Cut(name, ".")
+	if !inSection {
+		section, local = "", name
+	}
+	if strings.Contains(local, ".") {
+		return fmt.Errorf("cannot place nested key %q", name)
+	}
+
+	stmt := &AssignStatement{Name: local}
+	if rb, ok := langData[sheet.SourceLang][key].(*RuntimeBlock); ok {
+		block := &BlockExpression{Argument: rb.Argument}
+		for _, r := range rows {
+			if _, cond := splitReviewKey(r.Key); cond != "" {
+				block.Cases = append(block.Cases, &BlockCase{Condition: cond, Value: r.Target})
+			}
+		}
+		stmt.Value = block
+	} else {
+		stmt.Value = &StringLiteral{Value: rows[len(rows)-1].Target}
+	}
+
+	path := filepath.Join(repo.RootPath, filepath.FromSlash(strings.Join(parts, "/")))
+	f, err := ed.file(path)
+	if err != nil {
+		return err
+	}
+	if f.section != section {
+		if section == "" {
+			return fmt.Errorf("%s ends inside [%s]; add the key by hand", path, f.section)
+		}
+		f.appendText("\n[" + section + "]\n")
+		f.section = section
+	}
+	f.appendText(formatAssign(stmt).text)
+	for _, r := range rows {
+		res.Added = append(res.Added, r.Key)
+	}
+	return nil
+}
+
+// sourceEdits collects text splices per file and writes them in one go
+type sourceEdits struct {
+	files map[string]*sourceFile
+}
+
+type sourceFile struct {
+	content string
+	orig    string // content as read, before appended text
+	program *Program
+	section string // section in effect at the end of the file
+	splices []splice
+	grown   bool // text was appended
+	removed bool // the file is deleted
+}
+
+// splice replaces content[start:end] with text
+type splice struct {
+	start, end int
+	text       string
+}
+
+// file loads path (an empty file when it does not exist yet)
+func (ed *sourceEdits) file(path string) (*sourceFile, error) {
+	if f, ok := ed.files[path]; ok {
+		return f, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f := &sourceFile{content: string(content), orig: string(content), program: NewParser(NewLexer(string(content))).ParseProgram()}
+	for _, stmt := range f.program.Statements {
+		if s, ok := stmt.(*SectionStatement); ok {
+			f.section = s.Name
+		}
+	}
+	ed.files[path] = f
+	return f, nil
+}
+
+// changes lists the changed files, sorted by path
+func (ed *sourceEdits) changes() []FileChange {
+	var out []FileChange
+	for path, f := range ed.files {
+		switch {
+		case f.removed:
+			out = append(out, FileChange{Path: path, Before: f.orig, Removed: true})
+		case len(f.splices) > 0 || f.grown:
+			out = append(out, FileChange{Path: path, Before: f.orig, After: f.apply()})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// WriteChanges writes the new content of every change, creating missing
+// directories, and deletes removed files
+func WriteChanges(changes []FileChange) error {
+	for _, c := range changes {
+		if c.Removed {
+			if err := os.Remove(c.Path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.Path, []byte(c.After), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply returns the content with the splices applied, back to front so
+// offsets stay valid
+func (f *sourceFile) apply() string {
+	// At one offset, deletions go before insertions
+	sort.SliceStable(f.splices, func(i, j int) bool {
+		a, b := f.splices[i], f.splices[j]
+		if a.start != b.start {
+			return a.start > b.start
+		}
+		return a.end > b.end
+	})
+	out := f.content
+	for _, s := range f.splices {
+		out = out[:s.start] + s.text + out[s.end:]
+	}
+	return out
+}
+
+// assignment returns the statement defined at loc
+func (f *sourceFile) assignment(loc SourceLocation) *AssignStatement {
+	for _, stmt := range f.program.Statements {
+		if s, ok := stmt.(*AssignStatement); ok && s.Token.Line == loc.Line && s.Token.Column == loc.Column {
+			return s
+		}
+	}
+	return nil
+}
+
+// offset converts a 1-based line and byte column to an offset
+func (f *sourceFile) offset(line, column int) int {
+	off := 0
+	if strings.HasPrefix(f.content, bom) {
+		off = len(bom) // not counted in first-line columns
+	}
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(f.content[off:], '\n')
+		if i < 0 {
+			return len(f.content)
+		}
+		off += i + 1
+	}
+	return off + column - 1
+}
+
+// replace swaps the string token tok (quotes included) for value
+func (f *sourceFile) replace(tok Token, value string) {
+	start := f.offset(tok.Line, tok.Column)
+	end := f.offset(tok.EndLine, tok.EndColumn) + 1
+	f.splices = append(f.splices, splice{start, end, quoteValue(value)})
+}
+
+// insertLine inserts text at the start of line, in the file's line endings
+func (f *sourceFile) insertLine(line int, text string) {
+	off := f.offset(line, 1)
+	f.splices = append(f.splices, splice{off, off, NormalizeLineEndings(text, DetectLineEnding(f.content))})
+}
+
+// appendText adds text at the end of the file, on a line of its own and
+// in the file's line endings
+func (f *sourceFile) appendText(text string) {
+	if n := len(f.content); n > 0 && f.content[n-1] != '\n' {
+		text = "\n" + text
+	}
+	f.content += NormalizeLineEndings(text, DetectLineEnding(f.content))
+	f.grown = true
+}
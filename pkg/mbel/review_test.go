@@ -0,0 +1,208 @@
+package mbel
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeReviewFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"en/app.mbel": `# AI_Context: Login button
+# AI_MaxLength: 10
+# AI_Screenshot: https://cdn.example.com/login.png
+# AI_Figma: https://www.figma.com/file/Xyz/App?node-id=1-2
+login = "Sign in"
+brand = "Acme"
+items(n) {
+    [one] => "{n} item"
+    [other] => "{n} items"
+}
+welcome = "Welcome"
+`,
+		"pl/app.mbel": `# Reviewed 2024
+login = "Zaloguj się teraz"
+brand = "Acme"
+items(n) {
+    [one] => "{n} przedmiot"
+}
+`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReviewSheet(t *testing.T) {
+	repo := &FileRepository{RootPath: writeReviewFixture(t)}
+	data, err := repo.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := NewReviewSheet(data, repo, "en", "pl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ReviewRow{
+		{Key: "app.brand", Source: "Acme", Target: "Acme", Status: ReviewUntranslated},
+		{Key: "app.items[one]", Source: "{n} item", Target: "{n} przedmiot", Status: ReviewOK},
+		{Key: "app.items[other]", Source: "{n} items", Status: ReviewMissing},
+		{Key: "app.login", Source: "Sign in", Target: "Zaloguj się teraz", Context: "Login button", MaxLength: 10, Status: ReviewTooLong,
+			Links: []string{"https://cdn.example.com/login.png", "https://www.figma.com/file/Xyz/App?node-id=1-2"}},
+		{Key: "app.welcome", Source: "Welcome", Status: ReviewMissing},
+	}
+	if !reflect.DeepEqual(sheet.Rows, want) {
+		t.Fatalf("rows = %+v\nwant %+v", sheet.Rows, want)
+	}
+
+	var x bytes.Buffer
+	if err := WriteReviewXLSX(&x, sheet); err != nil {
+		t.Fatal(err)
+	}
+	fromXLSX, err := ReadReviewXLSX(bytes.NewReader(x.Bytes()), int64(x.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromXLSX, sheet) {
+		t.Errorf("xlsx round trip = %+v", fromXLSX)
+	}
+
+	var c bytes.Buffer
+	if err := WriteReviewCSV(&c, sheet); err != nil {
+		t.Fatal(err)
+	}
+	fromCSV, err := ReadReviewCSV(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromCSV, sheet) {
+		t.Errorf("csv round trip = %+v", fromCSV)
+	}
+}
+
+func TestReadReviewCSVReorderedColumns(t *testing.T) {
+	sheet, err := ReadReviewCSV(strings.NewReader("Notes,Target (de),Key\nok,Hallo,hello\n,,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.TargetLang != "de" || len(sheet.Rows) != 1 || !reflect.DeepEqual(sheet.Rows[0], ReviewRow{Key: "hello", Target: "Hallo"}) {
+		t.Errorf("sheet = %+v", sheet)
+	}
+	if _, err := ReadReviewCSV(strings.NewReader("Key,Source (en)\n")); err == nil {
+		t.Error("expected an error without a target column")
+	}
+}
+
+func TestApplyReview(t *testing.T) {
+	dir := writeReviewFixture(t)
+	repo := &FileRepository{RootPath: dir}
+	sheet := &ReviewSheet{SourceLang: "en", TargetLang: "pl", Rows: []ReviewRow{
+		{Key: "app.login", Target: "Zaloguj"},
+		{Key: "app.brand", Target: "Acme"},
+		{Key: "app.items[one]", Target: "{n} przedmiot"},
+		{Key: "app.items[other]", Target: "{n} przedmiotów"},
+		{Key: "app.welcome", Target: `Witaj "gościu"!`},
+		{Key: "app.unknown", Target: "?"},
+	}}
+
+	res, err := ApplyReview(repo, sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Updated, []string{"app.login"}) ||
+		!reflect.DeepEqual(res.Added, []string{"app.items[other]", "app.welcome"}) ||
+		len(res.Skipped) != 1 || !strings.HasPrefix(res.Skipped[0], "app.unknown:") {
+		t.Fatalf("result = %+v", res)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "pl", "app.mbel"))
+	want := `# Reviewed 2024
+login = "Zaloguj"
+brand = "Acme"
+items(n) {
+    [one] => "{n} przedmiot"
+    [other] => "{n} przedmiotów"
+}
+welcome = """Witaj "gościu"!"""
+`
+	if string(content) != want {
+		t.Errorf("pl/app.mbel =\n%s\nwant\n%s", content, want)
+	}
+
+	data, err := (&FileRepository{RootPath: dir}).LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["pl"]["app.welcome"] != `Witaj "gościu"!` {
+		t.Errorf("welcome = %q", data["pl"]["app.welcome"])
+	}
+}
+
+func TestApplyReviewCreatesMirroredFile(t *testing.T) {
+	dir := writeReviewFixture(t)
+	sheet := &ReviewSheet{SourceLang: "en", TargetLang: "de", Rows: []ReviewRow{
+		{Key: "app.login", Target: "Anmelden"},
+	}}
+	plan, err := PlanReview(&FileRepository{RootPath: dir}, sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Before != "" {
+		t.Fatalf("changes = %+v", plan.Changes)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "de")); !os.IsNotExist(err) {
+		t.Fatalf("PlanReview wrote files: %v", err)
+	}
+
+	if _, err := ApplyReview(&FileRepository{RootPath: dir}, sheet); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "de", "app.mbel"))
+	if err != nil || string(content) != "login = \"Anmelden\"\n" {
+		t.Errorf("de/app.mbel = %q, %v", content, err)
+	}
+}
+
+func TestXLSXColumns(t *testing.T) {
+	for i, ref := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != ref {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, ref)
+		}
+		if got := xlsxColumnIndex(ref + "12"); got != i {
+			t.Errorf("xlsxColumnIndex(%s12) = %d, want %d", ref, got, i)
+		}
+		if got := xlsxColumnIndex(strings.ToLower(ref) + "12"); got != i {
+			t.Errorf("xlsxColumnIndex(%s12) = %d, want %d", strings.ToLower(ref), got, i)
+		}
+	}
+}
+
+func TestReadReviewXLSXRejectsBadRefs(t *testing.T) {
+	for _, row := range []string{
+		`<row r="1"><c r="7"><v>x</v></c></row>`,
+		`<row r="-3"><c r="A1"><v>x</v></c></row>`,
+		`<row r="99999999"><c r="A1"><v>x</v></c></row>`,
+		`<row r="1"><c r="ZZZZZZZZZZZZZ1"><v>x</v></c></row>`,
+	} {
+		var b bytes.Buffer
+		zw := zip.NewWriter(&b)
+		f, _ := zw.Create("xl/worksheets/sheet1.xml")
+		f.Write([]byte(`<worksheet><sheetData>` + row + `</sheetData></worksheet>`))
+		zw.Close()
+		if _, err := ReadReviewXLSX(bytes.NewReader(b.Bytes()), int64(b.Len())); err == nil {
+			t.Errorf("%s: no error", row)
+		}
+	}
+}
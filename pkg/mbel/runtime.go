@@ -2,22 +2,36 @@ package mbel
 
 import (
 	"fmt"
-	"html"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 var (
 	termRe = regexp.MustCompile(`\{-([a-zA-Z_][a-zA-Z0-9_-]*)\}`)
-	argRe  = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+	argRe  = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)(?:,\s*(date|time|datetime))?\}`)
 )
 
 // Runtime provides string resolution with interpolation
 type Runtime struct {
-	Data       map[string]interface{}
-	Terms      map[string]string
-	Language   string
-	escapeHTML bool // Enable HTML escaping for interpolated values
+	Data      map[string]interface{}
+	Terms     map[string]string
+	Language  string
+	escape    EscapeProfile // how interpolated values are escaped by default
+	templates sync.Map      // message -> *template, filled on first use or by Preload
+	bundle    *Bundle       // optional lazily decoded backing store for keys not in Data
+
+	genderStrategy GenderStrategy // how blocks with a [neutral] case resolve
+	counts         sync.Map       // *RuntimeBlock -> *countTable, filled on first use
+
+	onMissingVar func(key, name string) // reports {placeholders} without a value
+	location     *time.Location         // zone of {d, date} placeholders (nil = the value's own)
+	plural       PluralRule             // plural categories of counts (nil = the language's)
+	dates        *dateLayouts           // layouts of date placeholders (nil = the language's)
+	schedules    map[string]Schedule    // windows of scheduled keys (nil = none)
 }
 
 // NewRuntime creates a runtime from compiled data
@@ -28,10 +42,12 @@ func NewRuntime(data map[string]interface{}) *Runtime {
 // NewRuntimeWithOptions creates a runtime with custom options
 func NewRuntimeWithOptions(data map[string]interface{}, escapeHTML bool) *Runtime {
 	r := &Runtime{
-		Data:       data,
-		Terms:      make(map[string]string),
-		Language:   "en",
-		escapeHTML: escapeHTML,
+		Data:     data,
+		Terms:    make(map[string]string),
+		Language: "en",
+	}
+	if escapeHTML {
+		r.escape = EscapeHTML
 	}
 
 	// Extract terms
@@ -39,6 +55,8 @@ func NewRuntimeWithOptions(data map[string]interface{}, escapeHTML bool) *Runtim
 		r.Terms = terms
 	}
 
+	r.schedules = Schedules(data)
+
 	// Extract language from metadata
 	if meta, ok := data["__meta"].(map[string]string); ok {
 		if lang, exists := meta["lang"]; exists {
@@ -49,47 +67,238 @@ func NewRuntimeWithOptions(data map[string]interface{}, escapeHTML bool) *Runtim
 	return r
 }
 
+// NewRuntimeFromBundle creates a runtime that decodes messages from a
+// binary bundle on first use instead of holding them all in memory
+func NewRuntimeFromBundle(b *Bundle) *Runtime {
+	data := make(map[string]interface{})
+	for _, key := range []string{"__terms", "__meta", scheduleDataKey} {
+		if v, ok := b.Get(key); ok {
+			data[key] = v
+		}
+	}
+	r := NewRuntime(data)
+	r.bundle = b
+	return r
+}
+
+// value looks key up in Data, then in the backing bundle. Scheduled
+// keys outside their window are missing.
+func (r *Runtime) value(key string) (interface{}, bool) {
+	if s, ok := r.schedules[key]; ok && !s.Active(time.Now()) {
+		return nil, false
+	}
+	if val, ok := r.Data[key]; ok {
+		return val, true
+	}
+	if r.bundle != nil {
+		return r.bundle.Get(key)
+	}
+	return nil, false
+}
+
+// SortedKeys returns the translation keys of compiled data in sorted
+// order, omitting internal "__" entries. Exporters iterate with it so
+// generated artifacts are byte-for-byte reproducible.
+func SortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if !strings.HasPrefix(k, "__") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// OrderedKeys returns the runtime's translation keys, sorted (bundle
+// backed keys included)
+func (r *Runtime) OrderedKeys() []string {
+	if r.bundle == nil {
+		return SortedKeys(r.Data)
+	}
+
+	seen := make(map[string]interface{}, len(r.Data))
+	for k, v := range r.Data {
+		seen[k] = v
+	}
+	for _, k := range r.bundle.keys {
+		seen[k] = nil
+	}
+	return SortedKeys(seen)
+}
+
 // EscapeHTML enables or disables HTML escaping for interpolated values
 func (r *Runtime) SetEscapeHTML(escape bool) {
-	r.escapeHTML = escape
+	if escape {
+		r.escape = EscapeHTML
+	} else {
+		r.escape = EscapeNone
+	}
+}
+
+// SetEscape sets the profile interpolated values are escaped with when
+// the arguments do not choose one (see EscapeVar)
+func (r *Runtime) SetEscape(p EscapeProfile) {
+	r.escape = p
 }
 
 // Get retrieves a string by key with optional arguments for interpolation
 func (r *Runtime) Get(key string, args ...interface{}) string {
 	recordGetCall()
 
-	val, exists := r.Data[key]
+	val, exists := r.value(key)
 	if !exists {
-		return key // Fallback to key itself
+		alt := r.schedules[key].Alternate
+		if alt == "" {
+			return key // Fallback to key itself
+		}
+		// Outside its window: serve the alternate (not its own alternate)
+		if val, exists = r.value(alt); !exists {
+			return key
+		}
+		key = alt
 	}
 
 	switch v := val.(type) {
 	case string:
+		// Fast path: nothing to interpolate or unescape
+		if strings.IndexByte(v, '{') < 0 && strings.IndexByte(v, '}') < 0 {
+			return v
+		}
 		if len(args) > 0 {
-			return r.interpolate(v, args[0])
+			return r.interpolate(key, v, args[0])
 		}
-		return r.interpolate(v, nil)
+		return r.interpolate(key, v, nil)
 	case *RuntimeBlock:
+		if v.IsVariant() {
+			var arg interface{}
+			if len(args) > 0 {
+				arg = args[0]
+			}
+			return r.interpolate(key, pickVariant(key, v, arg), arg)
+		}
+		if neutral, ok := v.Cases[neutralCase]; ok && r.genderStrategy == GenderNeutral {
+			if len(args) > 0 {
+				return r.interpolate(key, neutral, args[0])
+			}
+			return r.interpolate(key, neutral, nil)
+		}
 		if len(args) > 0 {
-			result := v.ResolveWithLang(args[0], r.Language)
-			return r.interpolate(result, args[0])
+			result := r.resolveBlock(v, args[0])
+			return r.interpolate(key, result, args[0])
 		}
-		return r.interpolate(v.Resolve("other"), nil)
+		return r.interpolate(key, v.Resolve("other"), nil)
 	default:
 		return fmt.Sprintf("%v", v)
 	}
 }
 
-// interpolate replaces {placeholders} and {-term-refs}
-func (r *Runtime) interpolate(s string, arg interface{}) string {
+// Preload parses the messages of key ahead of the first Get
+func (r *Runtime) Preload(key string) {
+	val, _ := r.value(key)
+	switch v := val.(type) {
+	case string:
+		r.template(v)
+	case *RuntimeBlock:
+		for _, val := range v.Cases {
+			r.template(val)
+		}
+		for _, rc := range v.RangeCases {
+			r.template(rc.Value)
+		}
+	}
+}
+
+// interpolate replaces {placeholders} and {-term-refs} in message s of key
+func (r *Runtime) interpolate(key, s string, arg interface{}) string {
 	recordInterpolate()
 
 	if s == "" {
 		return s
 	}
 
+	t := r.template(s)
+
+	// Without an argument only term references are replaced
+	if arg == nil || len(t.args) == 0 {
+		return t.text
+	}
+
+	// Replace argument placeholder {n}, {count}, etc.
+	var b strings.Builder
+	last := 0
+	for _, a := range t.args {
+		b.WriteString(t.text[last:a.start])
+		b.WriteString(r.argValue(key, t.text[a.start:a.end], a, arg))
+		last = a.end
+	}
+	b.WriteString(t.text[last:])
+	return b.String()
+}
+
+// argValue renders the value for placeholder match (a) in the message
+// of key
+func (r *Runtime) argValue(key, match string, a templateArg, arg interface{}) string {
+	name := a.name
+	// Accept both named type Vars and raw map[string]interface{}
+	var val interface{}
+	var exists bool
+	switch m := arg.(type) {
+	case Vars:
+		val, exists = m[name]
+	case map[string]interface{}:
+		val, exists = m[name]
+	default:
+		// Scalar (primitive): replace all placeholders with this value
+		val, exists = arg, true
+	}
+
+	if !exists {
+		if r.onMissingVar != nil {
+			r.onMissingVar(key, name)
+		}
+		return match // Keep {placeholder} if not found in map
+	}
+
+	var valStr string
+	if t, ok := val.(time.Time); ok && a.style != "" {
+		valStr = r.formatTime(t, a.style, arg)
+	} else if m, ok := val.(Money); ok {
+		meta, _ := r.Data["__meta"].(map[string]string)
+		valStr = m.format(r.Language, numberSymbolsFor(r.Language).withMeta(meta))
+	} else {
+		valStr = fmt.Sprintf("%v", val)
+	}
+	p := r.escape
+	if ep, ok := escapeOf(arg); ok {
+		p = ep
+	}
+	return p.Escape(valStr)
+}
+
+// template is a message with term references already substituted and
+// the positions of its {placeholders} pre-computed
+type template struct {
+	text   string
+	source string // text before unescaping: {{ and }} kept as written
+	args   []templateArg
+}
+
+type templateArg struct {
+	start, end int
+	name       string
+	style      string // "date", "time", "datetime" or ""
+}
+
+// template returns the parsed form of s, parsing it on first use.
+// Terms are fixed for the lifetime of a Runtime, so results are cached.
+func (r *Runtime) template(s string) *template {
+	if t, ok := r.templates.Load(s); ok {
+		return t.(*template)
+	}
+
 	// Replace term references {-term-name}
-	s = termRe.ReplaceAllStringFunc(s, func(match string) string {
+	text := replacePlaceholders(termRe, s, func(match string) string {
 		termName := match[2 : len(match)-1] // Extract "term-name" from "{-term-name}"
 		if val, exists := r.Terms[termName]; exists {
 			return val
@@ -97,61 +306,62 @@ func (r *Runtime) interpolate(s string, arg interface{}) string {
 		return match // Keep original if not found
 	})
 
-	// Replace argument placeholder {n}, {count}, etc.
-	if arg != nil {
-		s = argRe.ReplaceAllStringFunc(s, func(match string) string {
-			key := match[1 : len(match)-1]
-
-			// Accept both named type Vars and raw map[string]interface{}
-			switch m := arg.(type) {
-			case Vars:
-				if val, exists := m[key]; exists {
-					valStr := fmt.Sprintf("%v", val)
-					if r.escapeHTML {
-						valStr = html.EscapeString(valStr)
-					}
-					return valStr
-				}
-				return match // Keep {placeholder} if not found in map
-			case map[string]interface{}:
-				if val, exists := m[key]; exists {
-					valStr := fmt.Sprintf("%v", val)
-					if r.escapeHTML {
-						valStr = html.EscapeString(valStr)
-					}
-					return valStr
-				}
-				return match // Keep {placeholder} if not found in map
-			default:
-				// Scalar (primitive): replace all placeholders with this value
-				valStr := fmt.Sprintf("%v", arg)
-				if r.escapeHTML {
-					valStr = html.EscapeString(valStr)
-				}
-				return valStr
-			}
-		})
+	// Locate {placeholders}, turning the {{ }} escapes around them into
+	// literal braces
+	var b strings.Builder
+	var args []templateArg
+	last := 0
+	for _, loc := range placeholderIndexes(argRe, text) {
+		b.WriteString(UnescapeBraces(text[last:loc[0]]))
+		a := templateArg{start: b.Len(), name: text[loc[2]:loc[3]]}
+		if loc[4] >= 0 {
+			a.style = text[loc[4]:loc[5]]
+		}
+		b.WriteString(text[loc[0]:loc[1]])
+		a.end = b.Len()
+		args = append(args, a)
+		last = loc[1]
 	}
+	b.WriteString(UnescapeBraces(text[last:]))
 
-	return s
+	t := &template{text: b.String(), source: text, args: args}
+	r.templates.Store(s, t)
+	return t
 }
 
-// ResolveWithLang finds the matching value using language-specific plural rules
-func (rb *RuntimeBlock) ResolveWithLang(arg interface{}, lang string) string {
-	valToMatch := arg
-
-	// If argument is a map, try to extract the specific argument for this block
+// argument returns the value the block selects on: the block's
+// argument when arg is a map of variables holding it, else arg itself
+func (rb *RuntimeBlock) argument(arg interface{}) interface{} {
+	if vars, ok := arg.(Vars); ok {
+		arg = map[string]interface{}(vars)
+	}
 	if m, ok := arg.(map[string]interface{}); ok && rb.Argument != "" {
 		if v, exists := m[rb.Argument]; exists {
-			valToMatch = v
+			return v
 		}
 	}
+	return arg
+}
+
+// ResolveWithLang finds the matching value using language-specific plural rules
+func (rb *RuntimeBlock) ResolveWithLang(arg interface{}, lang string) string {
+	return rb.resolve(arg, lang, nil)
+}
+
+// resolve is ResolveWithLang with the plural categories chosen by rule
+// instead, unless it is nil
+func (rb *RuntimeBlock) resolve(arg interface{}, lang string, rule PluralRule) string {
+	valToMatch := rb.argument(arg)
 
-	// Try string match first
+	// Try string match first; genders without a case of their own
+	// prefer [neutral] to [other]
 	if strArg, ok := valToMatch.(string); ok {
 		if val, exists := rb.Cases[strArg]; exists {
 			return val
 		}
+		if val, exists := rb.Cases[neutralCase]; exists {
+			return val
+		}
 	}
 
 	// Try numeric match
@@ -181,7 +391,12 @@ func (rb *RuntimeBlock) ResolveWithLang(arg interface{}, lang string) string {
 	}
 
 	// Check plural categories with language
-	pluralCat := ResolvePluralCategory(lang, numArg)
+	var pluralCat string
+	if rule != nil {
+		pluralCat = rule(numArg)
+	} else {
+		pluralCat = ResolvePluralCategory(lang, numArg)
+	}
 	if val, exists := rb.Cases[pluralCat]; exists {
 		return val
 	}
@@ -0,0 +1,198 @@
+package mbel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scheduled messages are served only inside a time window, set with
+// per-key metadata lines above them (like @status):
+//
+//	@valid_from: "2025-12-01"
+//	@valid_until: "2025-12-26"
+//	@alternate: banner.default
+//	banner.xmas = "Merry Christmas!"
+//
+// Dates are UTC days, @valid_until inclusive; RFC 3339 times
+// ("2025-12-24T18:00:00+01:00") give exact instants. Outside the window
+// the key counts as missing in its locale, so the usual fallback
+// applies, unless @alternate names a key to serve instead.
+const (
+	validFromMeta  = "valid_from"
+	validUntilMeta = "valid_until"
+	alternateMeta  = "alternate"
+)
+
+// scheduleDataKey holds the compiled windows as "key@field" -> value
+const scheduleDataKey = "__schedule"
+
+// keyMetaKeys are metadata lines applying to the key directly below them
+// rather than to the file
+var keyMetaKeys = map[string]bool{
+	statusMetaKey: true, audienceMeta: true,
+	validFromMeta: true, validUntilMeta: true, alternateMeta: true,
+}
+
+// Schedule is the time window of a message
+type Schedule struct {
+	From      time.Time // zero = no start
+	Until     time.Time // exclusive; zero = no end
+	Alternate string    // key served outside the window ("" = none)
+}
+
+// Active reports whether t falls inside the window
+func (s Schedule) Active(t time.Time) bool {
+	return (s.From.IsZero() || !t.Before(s.From)) && (s.Until.IsZero() || t.Before(s.Until))
+}
+
+// parseScheduleTime parses an RFC 3339 time or a date; until moves a
+// date to the end of that day
+func parseScheduleTime(s string, until bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (want 2006-01-02 or an RFC 3339 time)", s)
+	}
+	if until {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// compileSchedules returns the per-key schedule metadata of p as
+// "key@field" entries, or nil when there is none
+func compileSchedules(p *Program) map[string]string {
+	var out map[string]string
+	walkKeyMeta(p, func(key string, a *AssignStatement, meta map[string]*MetadataStatement) {
+		for _, field := range []string{validFromMeta, validUntilMeta, alternateMeta} {
+			if ms := meta[field]; ms != nil {
+				if out == nil {
+					out = make(map[string]string)
+				}
+				out[key+"@"+field] = ms.Value
+			}
+		}
+	})
+	return out
+}
+
+// Schedules returns the windows of the scheduled keys of compiled data.
+// Invalid dates are ignored, as the lint rule reports them.
+func Schedules(data map[string]interface{}) map[string]Schedule {
+	raw, _ := data[scheduleDataKey].(map[string]string)
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string]Schedule)
+	for k, v := range raw {
+		i := strings.LastIndexByte(k, '@')
+		if i < 0 {
+			continue
+		}
+		key, field := k[:i], k[i+1:]
+		s := out[key]
+		switch field {
+		case validFromMeta:
+			s.From, _ = parseScheduleTime(v, false)
+		case validUntilMeta:
+			s.Until, _ = parseScheduleTime(v, true)
+		case alternateMeta:
+			s.Alternate = v
+		}
+		out[key] = s
+	}
+	return out
+}
+
+// walkKeyMeta calls fn for every assignment with the per-key metadata
+// lines directly above it, by name
+func walkKeyMeta(p *Program, fn func(key string, a *AssignStatement, meta map[string]*MetadataStatement)) {
+	pending := map[string]*MetadataStatement{}
+	section := ""
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *MetadataStatement:
+			if keyMetaKeys[s.Key] {
+				pending[s.Key] = s
+				continue
+			}
+		case *SectionStatement:
+			section = s.Name
+		case *AssignStatement:
+			key := s.Name
+			if section != "" {
+				key = section + "." + s.Name
+			}
+			fn(key, s, pending)
+		}
+		if len(pending) > 0 {
+			pending = map[string]*MetadataStatement{}
+		}
+	}
+}
+
+// ScheduleRule returns a lint rule reporting invalid or misplaced
+// @valid_from, @valid_until and @alternate lines, and warning about
+// messages whose window has passed, which can be deleted
+func ScheduleRule(now time.Time) LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		used := make(map[*MetadataStatement]bool)
+
+		walkKeyMeta(p, func(key string, a *AssignStatement, meta map[string]*MetadataStatement) {
+			for _, ms := range meta {
+				used[ms] = true
+			}
+			var s Schedule
+			for _, field := range []string{validFromMeta, validUntilMeta} {
+				ms := meta[field]
+				if ms == nil {
+					continue
+				}
+				t, err := parseScheduleTime(ms.Value, field == validUntilMeta)
+				if err != nil {
+					out = append(out, DiagnosticAt(ms.ValueToken, SeverityError, err.Error()))
+					return
+				}
+				if field == validFromMeta {
+					s.From = t
+				} else {
+					s.Until = t
+				}
+			}
+			switch {
+			case !s.From.IsZero() && !s.Until.IsZero() && !s.From.Before(s.Until):
+				out = append(out, DiagnosticAt(a.Token, SeverityError, fmt.Sprintf("key %s: @valid_from is not before @valid_until", key)))
+			case !s.Until.IsZero() && !now.Before(s.Until):
+				out = append(out, DiagnosticAt(a.Token, SeverityWarning, fmt.Sprintf("key %s expired on %s", key, meta[validUntilMeta].Value)))
+			}
+		})
+
+		for _, stmt := range p.Statements {
+			ms, ok := stmt.(*MetadataStatement)
+			if ok && (ms.Key == validFromMeta || ms.Key == validUntilMeta || ms.Key == alternateMeta) && !used[ms] {
+				out = append(out, DiagnosticAt(ms.Token, SeverityError, fmt.Sprintf("@%s must be directly above a key", ms.Key)))
+			}
+		}
+		return out
+	}
+}
+
+// formatKeyMeta renders the per-key metadata lines of key from compiled
+// data
+func formatKeyMeta(data map[string]interface{}, key string) string {
+	var b strings.Builder
+	if tags, _ := data[audienceDataKey].(map[string]string); tags[key] != "" {
+		fmt.Fprintf(&b, "@%s: %s\n", audienceMeta, formatMetaValue(tags[key]))
+	}
+	raw, _ := data[scheduleDataKey].(map[string]string)
+	for _, field := range []string{validFromMeta, validUntilMeta, alternateMeta} {
+		if v, ok := raw[key+"@"+field]; ok {
+			fmt.Fprintf(&b, "@%s: %s\n", field, formatMetaValue(v))
+		}
+	}
+	return b.String()
+}
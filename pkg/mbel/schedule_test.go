@@ -0,0 +1,110 @@
+package mbel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const scheduleSource = `[banner]
+@valid_from: "2020-12-01"
+@valid_until: "2020-12-26"
+@alternate: banner.default
+xmas = "Merry Christmas!"
+default = "Welcome"
+@status: reviewed
+@valid_from: "2999-01-01T00:00:00Z"
+launch = "Coming soon"
+@valid_until: "2999-12-31"
+sale = "Sale!"
+`
+
+func TestScheduledMessages(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "en"), 0755)
+	os.MkdirAll(filepath.Join(dir, "pl"), 0755)
+	os.WriteFile(filepath.Join(dir, "en", "home.mbel"), []byte(scheduleSource), 0644)
+	os.WriteFile(filepath.Join(dir, "pl", "home.mbel"), []byte("[banner]\nlaunch = \"Wkrótce\"\n@valid_until: \"2001-01-01\"\nsale = \"Promocja!\"\n"), 0644)
+
+	m, err := NewManager(dir, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ lang, key, want string }{
+		{"en", "home.banner.xmas", "Welcome"},              // expired: the alternate, namespaced like the key
+		{"en", "home.banner.launch", "home.banner.launch"}, // not started, no alternate
+		{"en", "home.banner.sale", "Sale!"},                // inside the window
+		{"pl", "home.banner.launch", "Wkrótce"},            // pl has no window
+		{"pl", "home.banner.sale", "Sale!"},                // pl copy expired: falls back to en
+	} {
+		if got := m.Get(tc.lang, tc.key); got != tc.want {
+			t.Errorf("%s %s = %q, want %q", tc.lang, tc.key, got, tc.want)
+		}
+	}
+
+	bundle, _ := m.bundle("en", "home.banner.")
+	if !reflect.DeepEqual(bundle, map[string]interface{}{"home.banner.xmas": "Welcome", "home.banner.default": "Welcome", "home.banner.sale": "Sale!"}) {
+		t.Errorf("bundle = %v", bundle)
+	}
+
+	data, _, _ := CompileSource([]byte(scheduleSource), nil)
+	if meta, _ := data["__meta"].(map[string]string); len(meta) != 0 {
+		t.Errorf("schedule lines leaked into __meta: %v", meta)
+	}
+	var streamed map[string]string
+	CompileStream(strings.NewReader(scheduleSource), func(key string, v interface{}) error {
+		if key == scheduleDataKey {
+			streamed = v.(map[string]string)
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(streamed, data[scheduleDataKey]) {
+		t.Errorf("CompileStream schedules = %v, want %v", streamed, data[scheduleDataKey])
+	}
+
+	back, _, _ := CompileSource([]byte(FormatData(data)), nil)
+	if !reflect.DeepEqual(back[scheduleDataKey], data[scheduleDataKey]) {
+		t.Errorf("FormatData lost schedules:\n%s", FormatData(data))
+	}
+}
+
+func TestScheduleRule(t *testing.T) {
+	src := scheduleSource + "@valid_from: \"2025-02-30\"\nbad = \"x\"\n@valid_from: \"2025-02-01\"\n@valid_until: \"2025-01-01\"\nreversed = \"x\"\n@alternate: x\n"
+	diags := ScheduleRule(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))(NewParser(NewLexer(src)).ParseProgram(), LintContext{})
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Message)
+	}
+	want := []string{
+		"key banner.xmas expired on 2020-12-26",
+		`invalid date "2025-02-30" (want 2006-01-02 or an RFC 3339 time)`,
+		"key banner.reversed: @valid_from is not before @valid_until",
+		"@alternate must be directly above a key",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %q", got)
+	}
+}
+
+func TestReservedKeys(t *testing.T) {
+	for _, src := range []string{"__schedule = \"x\"\n", "[__audience]\nx = \"y\"\n"} {
+		_, _, err := CompileSource([]byte(src), nil)
+		var ce *CompileError
+		if !errors.As(err, &ce) || !errors.Is(err, ErrCompile) {
+			t.Errorf("%q: err = %v, want a *CompileError", src, err)
+		}
+	}
+
+	// Data of other origins is not trusted to hold maps either
+	r := &FileRepository{}
+	langData := make(map[string]map[string]interface{})
+	r.merge(langData, make(map[string]map[string]keyOrigin), "en", "home", "home.mbel", map[string]interface{}{scheduleDataKey: "x", "k": "v"})
+	if langData["en"]["home.k"] != "v" {
+		t.Errorf("merge = %v", langData["en"])
+	}
+}
@@ -0,0 +1,169 @@
+package mbel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SchemaVersion is the version of the compiled output layout, stored
+// under "__schema" in every compiled map. Bump it and add a step to
+// schemaMigrations whenever the layout changes.
+//
+//	1  unversioned output of MBEL <= 1.2
+//	2  adds "__schema"
+//	3  adds "__schedule" and "__audience"
+//	4  "{{" and "}}" in messages stand for literal braces
+const SchemaVersion = 4
+
+// ErrUnsupportedSchema is returned for compiled data written by a newer
+// MBEL than this one
+var ErrUnsupportedSchema = errors.New("mbel: unsupported compiled schema version")
+
+// schemaMigrations[v] upgrades data from version v to v+1 in place
+var schemaMigrations = map[int]func(data map[string]interface{}) error{
+	1: func(data map[string]interface{}) error { return nil },
+	2: func(data map[string]interface{}) error { return nil },
+	3: func(data map[string]interface{}) error {
+		// Braces were literal unless they formed a placeholder; escape
+		// them so the messages render as before
+		for k, v := range data {
+			switch {
+			case k == "__terms":
+				if terms, ok := v.(map[string]string); ok {
+					for name, t := range terms {
+						terms[name] = escapeLegacyBraces(t)
+					}
+				}
+			case strings.HasPrefix(k, "__"):
+			default:
+				data[k] = mapMessages(v, escapeLegacyBraces)
+			}
+		}
+		return nil
+	},
+}
+
+// escapeLegacyBraces doubles the braces of s that do not belong to a
+// placeholder or term reference
+func escapeLegacyBraces(s string) string {
+	if !strings.ContainsAny(s, "{}") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if m := placeholderAt(s[i:]); m != "" {
+			b.WriteString(m)
+			i += len(m)
+			continue
+		}
+		if s[i] == '{' || s[i] == '}' {
+			b.WriteByte(s[i])
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// SchemaOf returns the schema version of compiled data (1 when unversioned)
+func SchemaOf(data map[string]interface{}) (int, error) {
+	switch v := data["__schema"].(type) {
+	case nil:
+		return 1, nil
+	case int:
+		return v, nil
+	case float64: // decoded from JSON
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n), nil
+		}
+	}
+	return 0, fmt.Errorf("mbel: invalid __schema value %v", data["__schema"])
+}
+
+// MigrateCompiled upgrades compiled data in place to SchemaVersion and
+// returns the version it started from. Data from a newer MBEL is
+// rejected with ErrUnsupportedSchema.
+func MigrateCompiled(data map[string]interface{}) (from int, err error) {
+	from, err = SchemaOf(data)
+	if err != nil {
+		return 0, err
+	}
+	if from > SchemaVersion {
+		return from, fmt.Errorf("%w: %d (this build supports up to %d)", ErrUnsupportedSchema, from, SchemaVersion)
+	}
+
+	for v := from; v < SchemaVersion; v++ {
+		if err := schemaMigrations[v](data); err != nil {
+			return from, fmt.Errorf("mbel: migrating schema %d to %d: %w", v, v+1, err)
+		}
+	}
+	data["__schema"] = SchemaVersion
+	return from, nil
+}
+
+// DecodeCompiled parses compiled JSON (as written by `mbel compile`),
+// upgrading older schemas, into the value types the Runtime expects
+func DecodeCompiled(raw []byte) (map[string]interface{}, error) {
+	var generic map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{}, len(generic))
+	for k, v := range generic {
+		var err error
+		switch k {
+		case "__schema":
+			var n int
+			err = json.Unmarshal(v, &n)
+			data[k] = n
+		case "__meta", "__terms", scheduleDataKey, audienceDataKey:
+			m := map[string]string{}
+			err = json.Unmarshal(v, &m)
+			data[k] = m
+		case "__imports":
+			var l []string
+			err = json.Unmarshal(v, &l)
+			data[k] = l
+		case "__ai":
+			ai := map[string][]map[string]string{}
+			err = json.Unmarshal(v, &ai)
+			data[k] = ai
+		default:
+			data[k], err = decodeCompiledValue(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mbel: decoding %q: %w", k, err)
+		}
+	}
+
+	if _, err := MigrateCompiled(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func decodeCompiledValue(v json.RawMessage) (interface{}, error) {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return s, nil
+	}
+
+	rb := &RuntimeBlock{}
+	if err := json.Unmarshal(v, rb); err != nil {
+		return nil, err
+	}
+	if rb.Cases == nil {
+		rb.Cases = make(map[string]string)
+	}
+	if rb.RangeCases == nil {
+		rb.RangeCases = []RangeCase{}
+	}
+	return rb, nil
+}
@@ -0,0 +1,68 @@
+package mbel
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCompiledUpgradesUnversioned(t *testing.T) {
+	p := NewParser(NewLexer("@lang: pl\ntitle = \"Cześć\"\nitems(n) {\n    [one] => \"1 rzecz\"\n    [other] => \"{n} rzeczy\"\n}\n"))
+	res, err := NewCompiler().Compile(p.ParseProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := res.Data
+	if want["__schema"] != SchemaVersion {
+		t.Fatalf("compiler should stamp __schema, got %v", want["__schema"])
+	}
+
+	v1 := make(map[string]interface{})
+	for k, v := range want {
+		v1[k] = v
+	}
+	delete(v1, "__schema")
+	raw, _ := json.Marshal(v1)
+
+	got, err := DecodeCompiled(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+	if NewRuntime(got).Get("items", 3) != "3 rzeczy" {
+		t.Error("decoded block does not resolve")
+	}
+}
+
+func TestDecodeCompiledRejectsNewerSchema(t *testing.T) {
+	_, err := DecodeCompiled([]byte(`{"__schema": 99, "title": "x"}`))
+	if !errors.Is(err, ErrUnsupportedSchema) {
+		t.Errorf("expected ErrUnsupportedSchema, got %v", err)
+	}
+}
+
+func TestDecodeCompiledKeepsSchedulesAndAudiences(t *testing.T) {
+	want, _, err := CompileSource([]byte(scheduleSource+audienceSource), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want[scheduleDataKey] == nil || want[audienceDataKey] == nil {
+		t.Fatalf("compiled data lacks schedules or audiences: %v", want)
+	}
+	delete(want, "__ai")
+	raw, _ := json.Marshal(want)
+
+	got, err := DecodeCompiled(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+	if r := NewRuntime(got); r.Get("banner.launch") != "banner.launch" {
+		t.Errorf("schedule lost in the round trip: %q", r.Get("banner.launch"))
+	}
+}
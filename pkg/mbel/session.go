@@ -0,0 +1,71 @@
+package mbel
+
+import (
+	"context"
+	"sync"
+)
+
+// Session holds the locale of a long-lived connection (e.g. a WebSocket),
+// where the locale may change after the upgrade request's context is fixed.
+// It is safe for concurrent use from read and write loops.
+//
+//	sess := mbel.NewSession(r.Context()) // seeded by the middleware
+//	for msg := range messages {
+//		if msg.Type == "set_locale" {
+//			sess.SetLocale(msg.Lang)
+//		}
+//		conn.Write(sess.T("chat.joined", mbel.Vars{"name": msg.User}))
+//	}
+type Session struct {
+	mu      sync.RWMutex
+	lang    string
+	manager *Manager
+}
+
+// NewSession creates a session seeded with the locale and Manager found
+// in ctx (typically the upgrade request's context)
+func NewSession(ctx context.Context) *Session {
+	return &Session{
+		lang:    LocaleFromContext(ctx),
+		manager: ManagerFromContext(ctx),
+	}
+}
+
+// Locale returns the current locale of the session
+func (s *Session) Locale() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lang
+}
+
+// SetLocale changes the locale for all subsequent lookups
+func (s *Session) SetLocale(lang string) {
+	s.mu.Lock()
+	s.lang = lang
+	s.mu.Unlock()
+}
+
+// T translates a key using the session's current locale
+func (s *Session) T(key string, args ...interface{}) string {
+	s.mu.RLock()
+	lang, m := s.lang, s.manager
+	s.mu.RUnlock()
+
+	if m == nil {
+		return key
+	}
+	return m.Get(lang, key, args...)
+}
+
+// Context derives a context carrying the session's current locale and
+// Manager, for passing to code that uses T(ctx, ...)
+func (s *Session) Context(ctx context.Context) context.Context {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx = WithLocale(ctx, s.lang)
+	if s.manager != nil {
+		ctx = WithManager(ctx, s.manager)
+	}
+	return ctx
+}
@@ -0,0 +1,58 @@
+package mbel
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"joined": "{name} joined"},
+		"pl": {"joined": "{name} dołączył"},
+	}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := NewSession(WithManager(WithLocale(context.Background(), "pl"), m))
+	if sess.Locale() != "pl" {
+		t.Errorf("Locale = %q, want pl", sess.Locale())
+	}
+	if got := sess.T("joined", Vars{"name": "Ola"}); got != "Ola dołączył" {
+		t.Errorf("T = %q", got)
+	}
+	if got := NewSession(context.Background()).T("joined"); got != "joined" {
+		t.Errorf("T without a manager = %q", got)
+	}
+
+	// Read and write loops share the session
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(lang string) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sess.SetLocale(lang)
+			}
+		}([]string{"en", "pl"}[i%2])
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := sess.T("joined", Vars{"name": "Ola"}); got != "Ola joined" && got != "Ola dołączył" {
+					t.Errorf("concurrent T = %q", got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sess.SetLocale("en")
+	ctx := sess.Context(context.Background())
+	if LocaleFromContext(ctx) != "en" || ManagerFromContext(ctx) != m {
+		t.Errorf("Context carries %q and %p, want en and %p", LocaleFromContext(ctx), ManagerFromContext(ctx), m)
+	}
+	if got := T(ctx, "joined", Vars{"name": "Ola"}); got != "Ola joined" {
+		t.Errorf("T(ctx) = %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package mbel
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// snapshotMagic prefixes every snapshot; snapshotVersion is bumped when
+// the encoded layout changes
+const (
+	snapshotMagic   = "MBELSNAP"
+	snapshotVersion = 1
+)
+
+// ErrInvalidSnapshot is returned for data that is not a manager snapshot
+var ErrInvalidSnapshot = errors.New("mbel: invalid snapshot")
+
+type snapshot struct {
+	Version int
+	Data    map[string]map[string]interface{}
+}
+
+// Snapshot serializes the full compiled state of the manager. A new
+// replica can start from it with NewManagerFromSnapshot instead of
+// recompiling all sources at boot.
+func (m *Manager) Snapshot() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(snapshotMagic)
+	snap := snapshot{Version: snapshotVersion, Data: m.state.Load().allData}
+	if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+		return nil, fmt.Errorf("mbel: encoding snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RestoreSnapshot replaces the loaded catalog with the one in b. The
+// repository is not consulted; a later Load or hot-reload replaces it again.
+func (m *Manager) RestoreSnapshot(b []byte) error {
+	data, err := decodeSnapshot(b)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.install(data)
+	return nil
+}
+
+// NewManagerFromSnapshot creates a manager serving the catalog in snap
+// without loading repo. repo (may be nil) backs later Load calls and
+// Config.Watch.
+func NewManagerFromSnapshot(snap []byte, repo Repository, cfg Config) (*Manager, error) {
+	if _, err := ParseEscapeProfile(string(cfg.Escape)); err != nil {
+		return nil, err
+	}
+	m := newManager(repo, cfg)
+	if err := m.watch(cfg, func() error { return m.RestoreSnapshot(snap) }, true); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func decodeSnapshot(b []byte) (map[string]map[string]interface{}, error) {
+	if !bytes.HasPrefix(b, []byte(snapshotMagic)) {
+		return nil, ErrInvalidSnapshot
+	}
+
+	var snap snapshot
+	if err := gob.NewDecoder(bytes.NewReader(b[len(snapshotMagic):])).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, snap.Version)
+	}
+
+	if snap.Data == nil {
+		snap.Data = make(map[string]map[string]interface{})
+	}
+	for _, data := range snap.Data {
+		restoreEmptyCollections(data)
+	}
+	return snap.Data, nil
+}
@@ -0,0 +1,43 @@
+package mbel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	src, err := NewManager("../../examples/locales", Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := src.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewManagerFromSnapshot(snap, nil, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.state.Load().allData, src.state.Load().allData) {
+		t.Error("restored catalog differs from the original")
+	}
+	for _, lang := range src.Languages() {
+		for _, key := range src.Keys(lang) {
+			if got, want := m.Get(lang, key, 2), src.Get(lang, key, 2); got != want {
+				t.Errorf("%s/%s: got %q, want %q", lang, key, got, want)
+			}
+		}
+	}
+
+	if err := m.Load(context.Background()); err == nil {
+		t.Error("Load without a repository should fail")
+	}
+
+	if err := m.RestoreSnapshot([]byte("garbage")); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("expected ErrInvalidSnapshot, got %v", err)
+	}
+}
@@ -1,5 +1,10 @@
 package mbel
 
+import (
+	"fmt"
+	"os"
+)
+
 // SourceLocation represents a position in source code
 type SourceLocation struct {
 	File   string `json:"file"`
@@ -7,17 +12,82 @@ type SourceLocation struct {
 	Column int    `json:"column"`
 }
 
+// String formats the location as file:line ("" for the zero value)
+func (l SourceLocation) String() string {
+	if l.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// SourceLocator is implemented by repositories that can tell where a
+// loaded key was defined (FileRepository does)
+type SourceLocator interface {
+	Locate(lang, key string) (SourceLocation, bool)
+}
+
+// annotationIndex finds the AI annotations of loaded keys by re-parsing
+// the files they were defined in, each file once
+type annotationIndex struct {
+	loc    SourceLocator
+	byFile map[string]map[int][]*AIAnnotation // file -> assignment line -> annotations
+}
+
+func newAnnotationIndex(loc SourceLocator) *annotationIndex {
+	return &annotationIndex{loc: loc, byFile: make(map[string]map[int][]*AIAnnotation)}
+}
+
+// of returns the annotations of key in lang (nil when it cannot be located)
+func (ix *annotationIndex) of(lang, key string) ([]*AIAnnotation, error) {
+	src, ok := ix.loc.Locate(lang, key)
+	if !ok {
+		return nil, nil
+	}
+	lines, ok := ix.byFile[src.File]
+	if !ok {
+		content, err := os.ReadFile(src.File)
+		if err != nil {
+			return nil, err
+		}
+		p := NewParser(NewLexer(string(content))).ParseProgram()
+		lines = make(map[int][]*AIAnnotation)
+		for _, a := range Assignments(p) {
+			lines[a.Token.Line] = AnnotationsFor(p, a.Name)
+		}
+		ix.byFile[src.File] = lines
+	}
+	return lines[src.Line], nil
+}
+
+// annotation returns the value of the first annotation of the given type
+func annotation(anns []*AIAnnotation, typ string) string {
+	for _, ann := range anns {
+		if ann.Type == typ {
+			return ann.Value
+		}
+	}
+	return ""
+}
+
 // SourceMap maps keys to their source locations
 type SourceMap map[string]SourceLocation
 
-// BuildSourceMap creates a source map from a parsed program
+// BuildSourceMap creates a source map from a parsed program. Keys are
+// section-qualified, matching the compiled output.
 func BuildSourceMap(p *Program, filename string) SourceMap {
 	sm := make(SourceMap)
+	currentSection := ""
 
 	for _, stmt := range p.Statements {
 		switch s := stmt.(type) {
+		case *SectionStatement:
+			currentSection = s.Name
 		case *AssignStatement:
-			sm[s.Name] = SourceLocation{
+			key := s.Name
+			if currentSection != "" {
+				key = currentSection + "." + s.Name
+			}
+			sm[key] = SourceLocation{
 				File:   filename,
 				Line:   s.Token.Line,
 				Column: s.Token.Column,
@@ -33,3 +103,21 @@ func BuildSourceMap(p *Program, filename string) SourceMap {
 
 	return sm
 }
+
+// MissingVariable describes a {placeholder} left unfilled because the
+// arguments passed to T had no value for it
+type MissingVariable struct {
+	Lang   string
+	Key    string
+	Name   string         // placeholder name, without braces
+	Source SourceLocation // zero when the repository cannot locate keys
+}
+
+// Error formats the report as "file:line: key: no value for {name}"
+func (e MissingVariable) Error() string {
+	msg := fmt.Sprintf("%s: no value for {%s} (%s)", e.Key, e.Name, e.Lang)
+	if loc := e.Source.String(); loc != "" {
+		return loc + ": " + msg
+	}
+	return msg
+}
@@ -0,0 +1,44 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocateAndMissingVariable(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pl"), 0755)
+	src := "@lang: pl\n\n[form]\ngreeting = \"Cześć {name}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "pl", "auth.mbel"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var reports []MissingVariable
+	m, err := NewManager(dir, Config{
+		DefaultLocale:     "pl",
+		OnMissingVariable: func(mv MissingVariable) { reports = append(reports, mv) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc, ok := m.Locate("pl", "auth.form.greeting")
+	if !ok || loc.Line != 4 || !strings.HasSuffix(loc.File, filepath.Join("pl", "auth.mbel")) {
+		t.Fatalf("unexpected location %+v (ok=%v)", loc, ok)
+	}
+
+	m.Get("pl", "auth.form.greeting", Vars{"other": 1})
+	if len(reports) != 1 {
+		t.Fatalf("expected one missing-variable report, got %v", reports)
+	}
+	if got := reports[0].Error(); !strings.HasSuffix(got, "auth.mbel:4: auth.form.greeting: no value for {name} (pl)") {
+		t.Errorf("unexpected report %q", got)
+	}
+
+	m.Get("pl", "auth.form.greeting", Vars{"name": "Ala"})
+	if len(reports) != 1 {
+		t.Error("a filled placeholder must not be reported")
+	}
+}
@@ -0,0 +1,127 @@
+package mbel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyStatus is the review state of a key, set with a @status line
+// directly above it (AI annotation comments may sit in between):
+//
+//	@status: reviewed
+//	checkout_title = "Kasa"
+//
+// Keys without one are drafts. Unlike other metadata, @status is not
+// file-level and is not compiled into __meta.
+type KeyStatus string
+
+const (
+	StatusDraft    KeyStatus = "draft"
+	StatusReviewed KeyStatus = "reviewed"
+	StatusFinal    KeyStatus = "final"
+)
+
+const statusMetaKey = "status"
+
+var statusRank = map[KeyStatus]int{StatusDraft: 0, StatusReviewed: 1, StatusFinal: 2}
+
+// ParseKeyStatus validates a status name
+func ParseKeyStatus(s string) (KeyStatus, error) {
+	if _, ok := statusRank[KeyStatus(s)]; !ok {
+		return "", fmt.Errorf("invalid status %q (want draft, reviewed or final)", s)
+	}
+	return KeyStatus(s), nil
+}
+
+// AtLeast reports whether s is min or further along the workflow
+func (s KeyStatus) AtLeast(min KeyStatus) bool {
+	return statusRank[s] >= statusRank[min]
+}
+
+// KeyStatuses returns the status of every assignment in p, keyed like
+// Assignments. Invalid @status values count as drafts.
+func KeyStatuses(p *Program) map[string]KeyStatus {
+	result := make(map[string]KeyStatus)
+	walkStatuses(p, func(key string, a *AssignStatement, ms *MetadataStatement) {
+		status := StatusDraft
+		if ms != nil {
+			if s, err := ParseKeyStatus(ms.Value); err == nil {
+				status = s
+			}
+		}
+		result[key] = status
+	})
+	return result
+}
+
+// walkStatuses calls fn for every assignment with the @status line
+// above it, or nil
+func walkStatuses(p *Program, fn func(key string, a *AssignStatement, ms *MetadataStatement)) {
+	walkKeyMeta(p, func(key string, a *AssignStatement, meta map[string]*MetadataStatement) {
+		fn(key, a, meta[statusMetaKey])
+	})
+}
+
+// KeyStatusRule returns a lint rule reporting invalid or misplaced
+// @status lines and, unless min is "", keys whose status is below min
+// (to gate a release on every string being reviewed)
+func KeyStatusRule(min KeyStatus) LintRule {
+	return func(p *Program, ctx LintContext) []Diagnostic {
+		var out []Diagnostic
+		used := make(map[*MetadataStatement]bool)
+
+		walkStatuses(p, func(key string, a *AssignStatement, ms *MetadataStatement) {
+			status := StatusDraft
+			if ms != nil {
+				used[ms] = true
+				s, err := ParseKeyStatus(ms.Value)
+				if err != nil {
+					out = append(out, DiagnosticAt(ms.ValueToken, SeverityError, err.Error()))
+					return
+				}
+				status = s
+			}
+			if min != "" && !status.AtLeast(min) {
+				out = append(out, DiagnosticAt(a.Token, SeverityError, fmt.Sprintf("key %s is %s, %s required", key, status, min)))
+			}
+		})
+		for _, stmt := range p.Statements {
+			if ms, ok := stmt.(*MetadataStatement); ok && ms.Key == statusMetaKey && !used[ms] {
+				out = append(out, DiagnosticAt(ms.Token, SeverityError, "@status must be directly above a key"))
+			}
+		}
+		return out
+	}
+}
+
+// SetKeyStatus sets the status of the keys of src for which match
+// (called with section-qualified keys) returns true, rewriting or
+// inserting their @status lines. It returns the new source and the keys
+// whose status changed.
+func SetKeyStatus(src []byte, status KeyStatus, match func(key string) bool) ([]byte, []string) {
+	f := &sourceFile{content: string(src)}
+	var changed []string
+
+	walkStatuses(NewParser(NewLexer(f.content)).ParseProgram(), func(key string, a *AssignStatement, ms *MetadataStatement) {
+		if !match(key) {
+			return
+		}
+		switch {
+		case ms == nil:
+			start := f.offset(a.Token.Line, 1)
+			indent := f.content[start:f.offset(a.Token.Line, a.Token.Column)]
+			if strings.TrimSpace(indent) != "" {
+				return // not at the start of its line; leave it alone
+			}
+			f.insertLine(a.Token.Line, fmt.Sprintf("%s@%s: %s\n", indent, statusMetaKey, status))
+		case ms.Value != string(status):
+			start := f.offset(ms.ValueToken.Line, ms.ValueToken.Column)
+			end := f.offset(ms.ValueToken.EndLine, ms.ValueToken.EndColumn) + 1
+			f.splices = append(f.splices, splice{start, end, string(status)})
+		default:
+			return
+		}
+		changed = append(changed, key)
+	})
+	return []byte(f.apply()), changed
+}
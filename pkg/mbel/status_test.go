@@ -0,0 +1,84 @@
+package mbel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const statusSource = `@lang: pl
+@status: final
+title = "Tytuł"
+
+[cart]
+@status: reviewed
+# AI_Context: Empty cart banner
+empty = "Pusto"
+total = "Razem"
+@status: done
+checkout = "Kasa"
+`
+
+func TestKeyStatuses(t *testing.T) {
+	p := NewParser(NewLexer(statusSource)).ParseProgram()
+	want := map[string]KeyStatus{
+		"title":         StatusFinal,
+		"cart.empty":    StatusReviewed,
+		"cart.total":    StatusDraft,
+		"cart.checkout": StatusDraft,
+	}
+	if got := KeyStatuses(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeyStatuses = %v", got)
+	}
+
+	data, _, err := CompileSource([]byte(statusSource), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta := data["__meta"].(map[string]string); meta["status"] != "" || meta["lang"] != "pl" {
+		t.Errorf("@status leaked into __meta: %v", meta)
+	}
+}
+
+func TestKeyStatusRule(t *testing.T) {
+	src := statusSource + "@status: final\n"
+	diags := KeyStatusRule(StatusReviewed)(NewParser(NewLexer(src)).ParseProgram(), LintContext{})
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Message)
+	}
+	want := []string{
+		"key cart.total is draft, reviewed required",
+		`invalid status "done" (want draft, reviewed or final)`,
+		"@status must be directly above a key",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %q", got)
+	}
+
+	if diags := KeyStatusRule("")(NewParser(NewLexer(statusSource)).ParseProgram(), LintContext{}); len(diags) != 1 {
+		t.Errorf("validation only: %v", diags)
+	}
+}
+
+func TestSetKeyStatus(t *testing.T) {
+	out, changed := SetKeyStatus([]byte(statusSource), StatusFinal, func(key string) bool {
+		return strings.HasPrefix(key, "cart.")
+	})
+	if !reflect.DeepEqual(changed, []string{"cart.empty", "cart.total", "cart.checkout"}) {
+		t.Errorf("changed = %v", changed)
+	}
+	want := strings.NewReplacer(
+		"@status: reviewed", "@status: final",
+		"total = ", "@status: final\ntotal = ",
+		"@status: done", "@status: final",
+	).Replace(statusSource)
+	if string(out) != want {
+		t.Errorf("SetKeyStatus =\n%s\nwant\n%s", out, want)
+	}
+
+	if _, changed := SetKeyStatus(out, StatusFinal, func(string) bool { return true }); len(changed) != 0 {
+		t.Errorf("second run changed %v", changed)
+	}
+}
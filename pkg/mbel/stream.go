@@ -0,0 +1,119 @@
+package mbel
+
+import (
+	"fmt"
+	"io"
+)
+
+// CompileStream compiles MBEL source read from r one statement at a time,
+// calling emit with each key and compiled value as soon as it is parsed.
+// Neither the source nor the compiled catalog is held in memory, which
+// makes it suitable for very large machine-generated files.
+//
+// Keys are emitted in source order (a key defined twice is emitted twice).
+// Metadata, schedules, audiences, imports and the schema version are
+// emitted last as "__meta", "__schedule", "__audience", "__imports" and
+// "__schema"; AI annotations and comments are not retained. Syntax errors are collected and returned; keys from
+// well-formed statements are still emitted. A non-nil error comes from reading r or from emit.
+func CompileStream(r io.Reader, emit func(key string, value interface{}) error) ([]string, error) {
+	return CompileStreamWithStyle(r, InterpolationSingle, emit)
+}
+
+// CompileStreamWithStyle is CompileStream for a project whose files
+// without an @interpolation line are written in style
+func CompileStreamWithStyle(r io.Reader, style Interpolation, emit func(key string, value interface{}) error) ([]string, error) {
+	l := NewReaderLexer(r)
+	p := NewParser(l)
+	c := &Compiler{Interpolation: style}
+
+	program := &Program{Terms: make(map[string]*TermDefinition)}
+	metadata := make(map[string]string)
+	schedules := make(map[string]string)
+	audiences := make(map[string]string)
+	pending := make(map[string]string) // per-key metadata awaiting its key
+	currentSection := ""
+
+	for {
+		stmt, ok := p.nextStatement(program)
+		if !ok {
+			break
+		}
+
+		// Nothing is kept per statement
+		program.AIAnnotations = nil
+		p.comments = nil
+
+		switch s := stmt.(type) {
+		case *MetadataStatement:
+			if keyMetaKeys[s.Key] {
+				if s.Key != statusMetaKey {
+					pending[s.Key] = s.Value
+				}
+				continue
+			}
+			metadata[s.Key] = s.Value
+			switch s.Key {
+			case syntaxMetaKey:
+				c.icu = s.Value == "icu"
+			case interpolationMetaKey:
+				style, err := ParseInterpolation(s.Value)
+				if err != nil {
+					return p.Errors(), fmt.Errorf("%w: @%s: %v", ErrCompile, interpolationMetaKey, err)
+				}
+				c.style = style
+			}
+		case *SectionStatement:
+			currentSection = s.Name
+		case *AssignStatement:
+			key := s.Name
+			if currentSection != "" {
+				key = currentSection + "." + s.Name
+			}
+			val, err := c.compileAssign(key, s)
+			if err != nil {
+				return p.Errors(), err
+			}
+			for field, v := range pending {
+				if field == audienceMeta {
+					audiences[key] = v
+				} else {
+					schedules[key+"@"+field] = v
+				}
+			}
+			if err := emit(key, val); err != nil {
+				return p.Errors(), err
+			}
+		}
+		clear(pending)
+	}
+
+	if err := l.Err(); err != nil {
+		return p.Errors(), err
+	}
+
+	if len(metadata) > 0 {
+		if err := emit("__meta", metadata); err != nil {
+			return p.Errors(), err
+		}
+	}
+	if len(schedules) > 0 {
+		if err := emit(scheduleDataKey, schedules); err != nil {
+			return p.Errors(), err
+		}
+	}
+	if len(audiences) > 0 {
+		if err := emit(audienceDataKey, audiences); err != nil {
+			return p.Errors(), err
+		}
+	}
+	if len(program.Imports) > 0 {
+		if err := emit("__imports", program.Imports); err != nil {
+			return p.Errors(), err
+		}
+	}
+	if err := emit("__schema", SchemaVersion); err != nil {
+		return p.Errors(), err
+	}
+
+	return p.Errors(), nil
+}
@@ -0,0 +1,89 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderLexerMatchesStringLexer(t *testing.T) {
+	files, _ := filepath.Glob("../../examples/*.mbel")
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := NewLexer(string(src))
+		got := NewReaderLexer(iotest.OneByteReader(strings.NewReader(string(src))))
+		for {
+			w, g := want.NextToken(), got.NextToken()
+			if w != g {
+				t.Fatalf("%s: got %+v, want %+v", f, g, w)
+			}
+			if w.Type == TOKEN_EOF {
+				break
+			}
+		}
+	}
+}
+
+func TestCompileStreamMatchesCompile(t *testing.T) {
+	files, _ := filepath.Glob("../../examples/*.mbel")
+	if len(files) == 0 {
+		t.Skip("no example files")
+	}
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p := NewParser(NewLexer(string(src)))
+		res, err := NewCompiler().Compile(p.ParseProgram())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := res.Data
+		delete(want, "__ai")
+
+		got := make(map[string]interface{})
+		errs, err := CompileStream(iotest.HalfReader(strings.NewReader(string(src))), func(key string, value interface{}) error {
+			got[key] = value
+			return nil
+		})
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("%s: %v %v", f, errs, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: streamed output differs\ngot:  %v\nwant: %v", f, got, want)
+		}
+	}
+}
+
+func TestCompileStreamReportsErrors(t *testing.T) {
+	var keys []string
+	errs, err := CompileStream(strings.NewReader("a = \"1\"\n= \"x\"\nb = \"2\"\n"), func(key string, value interface{}) error {
+		if !strings.HasPrefix(key, "__") {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) == 0 {
+		t.Error("expected syntax errors")
+	}
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("got keys %v", keys)
+	}
+
+	_, err = CompileStream(iotest.ErrReader(os.ErrClosed), func(string, interface{}) error { return nil })
+	if err != os.ErrClosed {
+		t.Errorf("expected reader error, got %v", err)
+	}
+}
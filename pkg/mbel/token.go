@@ -8,6 +8,10 @@ const (
 	TOKEN_ILLEGAL TokenType = "ILLEGAL"
 	TOKEN_EOF     TokenType = "EOF"
 
+	// A "string missing its closing quote; it ends at the end of the line,
+	// so lexing resumes on the next one
+	TOKEN_UNTERMINATED_STRING TokenType = "UNTERMINATED_STRING"
+
 	// Identifiers & Literals
 	TOKEN_IDENT  TokenType = "IDENT"  // key_name
 	TOKEN_STRING TokenType = "STRING" // "value", """multiline"""
@@ -32,11 +36,15 @@ const (
 	TOKEN_NEWLINE TokenType = "NEWLINE"
 )
 
+// Token is a lexeme with its span: Line/Column is the first character,
+// EndLine/EndColumn the last (1-based; multi-line for """ strings)
 type Token struct {
-	Type    TokenType
-	Literal string
-	Line    int
-	Column  int
+	Type      TokenType
+	Literal   string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
 }
 
 func (t Token) String() string {
@@ -0,0 +1,237 @@
+package mbel
+
+import "strings"
+
+// TokenCategory classifies source for syntax highlighting. The names are
+// stable, for TextMate scopes, Tree-sitter queries and LSP semantic token
+// types built on top of them.
+type TokenCategory string
+
+const (
+	CategoryComment     TokenCategory = "comment"     // # text
+	CategoryAnnotation  TokenCategory = "annotation"  // # AI_Context: ...
+	CategoryMetadata    TokenCategory = "metadata"    // @ and the name of @lang: pl
+	CategorySection     TokenCategory = "section"     // the name of [section]
+	CategoryKey         TokenCategory = "key"         // key of an assignment or block
+	CategoryParameter   TokenCategory = "parameter"   // n of key(n) { ... }
+	CategoryKeyword     TokenCategory = "keyword"     // variant
+	CategorySelector    TokenCategory = "selector"    // one, 0, 2..4, a:50 inside [ ]
+	CategoryString      TokenCategory = "string"      // message text and metadata values
+	CategoryPlaceholder TokenCategory = "placeholder" // {name} in message text
+	CategoryTerm        TokenCategory = "term"        // {-brand} in message text
+	CategoryNumber      TokenCategory = "number"
+	CategoryOperator    TokenCategory = "operator"    // = => : ..
+	CategoryPunctuation TokenCategory = "punctuation" // ( ) { } [ ] ,
+	CategoryInvalid     TokenCategory = "invalid"     // illegal characters, unterminated strings
+)
+
+// SyntaxToken is a categorized span of source. Positions are 1-based
+// byte columns, inclusive, as in Token. Text is the lexeme as in
+// Token.Literal: message text without its quotes, comments without '#'.
+type SyntaxToken struct {
+	Category  TokenCategory
+	Text      string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+// Tokenize splits src into categorized tokens for syntax highlighters,
+// driven by the lexer the compiler uses, so editors highlight what MBEL
+// actually parses. Message strings are split into text and their
+// placeholders, in the file's @interpolation style. Line breaks and
+// whitespace produce no tokens; src does not need to be valid.
+func Tokenize(src string) []SyntaxToken {
+	return TokenizeWithStyle(src, InterpolationSingle)
+}
+
+// TokenizeWithStyle is Tokenize for a project whose files without an
+// @interpolation line are written in style
+func TokenizeWithStyle(src string, style Interpolation) []SyntaxToken {
+	style, err := ParseInterpolation(string(style))
+	if err != nil {
+		style = InterpolationSingle
+	}
+	var (
+		out []SyntaxToken
+
+		lineStart   = true
+		metadata    bool   // on an @key: value line
+		metaKey     string // its name, once read
+		section     bool   // inside a top-level [ ]
+		block       bool   // inside a block's { }
+		selector    bool   // inside a case's [ ]
+		param       bool   // after key(
+		afterAssign bool   // after key = (variant may follow)
+	)
+	add := func(cat TokenCategory, tok Token) {
+		out = append(out, SyntaxToken{cat, tok.Literal, tok.Line, tok.Column, tok.EndLine, tok.EndColumn})
+	}
+
+	l := NewLexer(src)
+	for tok := l.NextToken(); tok.Type != TOKEN_EOF; tok = l.NextToken() {
+		first := lineStart
+		lineStart = false
+		wasAssign := afterAssign
+		afterAssign = false
+
+		switch tok.Type {
+		case TOKEN_NEWLINE:
+			lineStart, metadata, metaKey, section = true, false, "", false
+		case TOKEN_COMMENT:
+			if strings.HasPrefix(strings.TrimSpace(tok.Literal), "AI_") {
+				add(CategoryAnnotation, tok)
+			} else {
+				add(CategoryComment, tok)
+			}
+		case TOKEN_ILLEGAL, TOKEN_UNTERMINATED_STRING:
+			add(CategoryInvalid, tok)
+		case TOKEN_AT:
+			metadata = first
+			add(CategoryMetadata, tok)
+		case TOKEN_IDENT:
+			switch {
+			case metadata && metaKey == "":
+				metaKey = tok.Literal
+				add(CategoryMetadata, tok)
+			case metadata:
+				if metaKey == interpolationMetaKey {
+					if s, err := ParseInterpolation(tok.Literal); err == nil {
+						style = s
+					}
+				}
+				add(CategoryString, tok)
+			case selector:
+				add(CategorySelector, tok)
+			case section:
+				add(CategorySection, tok)
+			case param:
+				add(CategoryParameter, tok)
+			case wasAssign && tok.Literal == variantKeyword:
+				add(CategoryKeyword, tok)
+			case first && !block:
+				add(CategoryKey, tok)
+			default:
+				add(CategoryInvalid, tok)
+			}
+		case TOKEN_NUMBER:
+			if selector {
+				add(CategorySelector, tok)
+			} else {
+				add(CategoryNumber, tok)
+			}
+		case TOKEN_STRING:
+			if metadata {
+				add(CategoryString, tok)
+			} else {
+				out = appendMessageTokens(out, tok, style)
+			}
+		case TOKEN_ASSIGN:
+			afterAssign = true
+			add(CategoryOperator, tok)
+		case TOKEN_ARROW, TOKEN_COLON, TOKEN_DOT_RANGE:
+			add(CategoryOperator, tok)
+		case TOKEN_LBRACKET:
+			if block {
+				selector = true
+			} else {
+				section = first
+			}
+			add(CategoryPunctuation, tok)
+		case TOKEN_RBRACKET:
+			selector, section = false, false
+			add(CategoryPunctuation, tok)
+		case TOKEN_LPAREN:
+			param = true
+			add(CategoryPunctuation, tok)
+		case TOKEN_RPAREN:
+			param = false
+			add(CategoryPunctuation, tok)
+		case TOKEN_LBRACE:
+			block = true
+			add(CategoryPunctuation, tok)
+		case TOKEN_RBRACE:
+			block = false
+			add(CategoryPunctuation, tok)
+		default:
+			add(CategoryPunctuation, tok)
+		}
+	}
+	return out
+}
+
+// appendMessageTokens appends the string token tok split into text and
+// the placeholders and term references it holds in style. The quotes go
+// with the text before the first placeholder and after the last one.
+func appendMessageTokens(out []SyntaxToken, tok Token, style Interpolation) []SyntaxToken {
+	lit := tok.Literal
+	spans := placeholderSpans(lit, style)
+	if len(spans) == 0 {
+		return append(out, SyntaxToken{CategoryString, lit, tok.Line, tok.Column, tok.EndLine, tok.EndColumn})
+	}
+
+	// Where each byte of lit sits in the source
+	quote := 1
+	if tok.EndLine > tok.Line || tok.EndColumn-tok.Column+1 == len(lit)+6 {
+		quote = 3 // """
+	}
+	lines := make([]int, len(lit)+1)
+	cols := make([]int, len(lit)+1)
+	line, col := tok.Line, tok.Column+quote
+	for i := 0; i <= len(lit); i++ {
+		lines[i], cols[i] = line, col
+		if i < len(lit) && lit[i] == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+
+	start, startLine, startCol := 0, tok.Line, tok.Column
+	for i, sp := range spans {
+		if i == 0 || sp[0] > start {
+			endLine, endCol := lines[sp[0]], cols[sp[0]]-1
+			if sp[0] > 0 {
+				endLine, endCol = lines[sp[0]-1], cols[sp[0]-1]
+			}
+			out = append(out, SyntaxToken{CategoryString, lit[start:sp[0]], startLine, startCol, endLine, endCol})
+		}
+		cat := CategoryPlaceholder
+		if strings.Contains(lit[sp[0]:sp[1]], "{-") {
+			cat = CategoryTerm
+		}
+		out = append(out, SyntaxToken{cat, lit[sp[0]:sp[1]], lines[sp[0]], cols[sp[0]], lines[sp[1]-1], cols[sp[1]-1]})
+		start, startLine, startCol = sp[1], lines[sp[1]], cols[sp[1]]
+	}
+	return append(out, SyntaxToken{CategoryString, lit[start:], startLine, startCol, tok.EndLine, tok.EndColumn})
+}
+
+// placeholderSpans returns the [start, end) byte ranges of the
+// placeholders and term references in message text written in style,
+// skipping escaped braces
+func placeholderSpans(s string, style Interpolation) [][2]int {
+	open, close := style.delimiters()
+	var out [][2]int
+	for i := 0; i < len(s); {
+		if style == InterpolationPercent && strings.HasPrefix(s[i:], "%%{") {
+			i += 3
+			continue
+		}
+		if style == InterpolationSingle && (strings.HasPrefix(s[i:], "{{") || strings.HasPrefix(s[i:], "}}")) {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(s[i:], open) {
+			rest := s[i+len(open):]
+			if loc := styledPlaceholderRe.FindStringIndex(rest); loc != nil && strings.HasPrefix(rest[loc[1]:], close) {
+				end := i + len(open) + loc[1] + len(close)
+				out = append(out, [2]int{i, end})
+				i = end
+				continue
+			}
+		}
+		i++
+	}
+	return out
+}
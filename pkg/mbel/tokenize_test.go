@@ -0,0 +1,102 @@
+package mbel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	src := `@lang: en
+# AI_Context: Shown in the header
+[checkout]
+title = "Hi {name}, {{literal}} from {-brand}"
+items(n) {
+    [one] => "{n} item"
+    [2..4] => "A few"
+}
+hero = variant {
+    [a:50] => "Buy"
+}
+notes = """
+Dear {name},
+bye"""
+bad = "oops
+`
+	var got []string
+	for _, tok := range Tokenize(src) {
+		got = append(got, fmt.Sprintf("%s %q %d:%d-%d:%d", tok.Category, tok.Text, tok.Line, tok.Column, tok.EndLine, tok.EndColumn))
+	}
+	want := []string{
+		`metadata "@" 1:1-1:1`,
+		`metadata "lang" 1:2-1:5`,
+		`operator ":" 1:6-1:6`,
+		`string "en" 1:8-1:9`,
+		`annotation " AI_Context: Shown in the header" 2:1-2:33`,
+		`punctuation "[" 3:1-3:1`,
+		`section "checkout" 3:2-3:9`,
+		`punctuation "]" 3:10-3:10`,
+		`key "title" 4:1-4:5`,
+		`operator "=" 4:7-4:7`,
+		`string "Hi " 4:9-4:12`,
+		`placeholder "{name}" 4:13-4:18`,
+		`string ", {{literal}} from " 4:19-4:37`,
+		`term "{-brand}" 4:38-4:45`,
+		`string "" 4:46-4:46`,
+		`key "items" 5:1-5:5`,
+		`punctuation "(" 5:6-5:6`,
+		`parameter "n" 5:7-5:7`,
+		`punctuation ")" 5:8-5:8`,
+		`punctuation "{" 5:10-5:10`,
+		`punctuation "[" 6:5-6:5`,
+		`selector "one" 6:6-6:8`,
+		`punctuation "]" 6:9-6:9`,
+		`operator "=>" 6:11-6:12`,
+		`string "" 6:14-6:14`,
+		`placeholder "{n}" 6:15-6:17`,
+		`string " item" 6:18-6:23`,
+		`punctuation "[" 7:5-7:5`,
+		`selector "2" 7:6-7:6`,
+		`operator ".." 7:7-7:8`,
+		`selector "4" 7:9-7:9`,
+		`punctuation "]" 7:10-7:10`,
+		`operator "=>" 7:12-7:13`,
+		`string "A few" 7:15-7:21`,
+		`punctuation "}" 8:1-8:1`,
+		`key "hero" 9:1-9:4`,
+		`operator "=" 9:6-9:6`,
+		`keyword "variant" 9:8-9:14`,
+		`punctuation "{" 9:16-9:16`,
+		`punctuation "[" 10:5-10:5`,
+		`selector "a" 10:6-10:6`,
+		`operator ":" 10:7-10:7`,
+		`selector "50" 10:8-10:9`,
+		`punctuation "]" 10:10-10:10`,
+		`operator "=>" 10:12-10:13`,
+		`string "Buy" 10:15-10:19`,
+		`punctuation "}" 11:1-11:1`,
+		`key "notes" 12:1-12:5`,
+		`operator "=" 12:7-12:7`,
+		`string "\nDear " 12:9-13:5`,
+		`placeholder "{name}" 13:6-13:11`,
+		`string ",\nbye" 13:12-14:6`,
+		`key "bad" 15:1-15:3`,
+		`operator "=" 15:5-15:5`,
+		`invalid "oops" 15:7-15:11`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Tokenize:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTokenizeInterpolationStyle(t *testing.T) {
+	var got []string
+	for _, tok := range Tokenize("@interpolation: double\nk = \"Hi {{name}} {x}\"\n") {
+		if tok.Category == CategoryPlaceholder {
+			got = append(got, fmt.Sprintf("%s %d:%d", tok.Text, tok.Column, tok.EndColumn))
+		}
+	}
+	if strings.Join(got, ",") != "{{name}} 9:16" {
+		t.Errorf("double-style placeholders = %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package mbel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	if d := UnifiedDiff("a.mbel", "x\n", "x\n"); d != "" {
+		t.Errorf("equal files: %q", d)
+	}
+
+	got := UnifiedDiff("en/app.mbel", "a\nb\nc\n", "a\nB\nc\nd\n")
+	want := `--- en/app.mbel
++++ en/app.mbel
+@@ -1,3 +1,4 @@
+ a
+-b
++B
+ c
++d
+`
+	if got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+
+	if got, want := UnifiedDiff("new.mbel", "", "x\n"), "--- new.mbel\n+++ new.mbel\n@@ -0,0 +1,1 @@\n+x\n"; got != want {
+		t.Errorf("new file diff = %q", got)
+	}
+
+	// Changes far apart get separate hunks
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprint(i))
+	}
+	before := strings.Join(lines, "\n") + "\n"
+	lines[1], lines[17] = "two", "eighteen"
+	got = UnifiedDiff("n", before, strings.Join(lines, "\n")+"\n")
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n") || !strings.Contains(got, "@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n") {
+		t.Errorf("hunks =\n%s", got)
+	}
+}
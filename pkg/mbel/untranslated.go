@@ -0,0 +1,63 @@
+package mbel
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Untranslated returns the keys whose value in target is byte-identical
+// to the value in source, sorted. Such copies are usually strings
+// somebody forgot to translate. Keys matching one of the allow patterns
+// (path.Match syntax, where * also spans dots: "brand.*", "*.url") and
+// values without letters ("{n}", "%", "—") are not reported.
+func Untranslated(source, target map[string]interface{}, allow []string) []string {
+	var keys []string
+	for _, key := range SortedKeys(target) {
+		src, ok := source[key]
+		if !ok || allowed(key, allow) || !hasLetters(target[key]) {
+			continue
+		}
+		if reflect.DeepEqual(src, target[key]) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// allowed reports whether key matches one of the patterns
+func allowed(key string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLetters reports whether a compiled value contains text worth
+// translating once placeholders are removed
+func hasLetters(v interface{}) bool {
+	var texts []string
+	switch val := v.(type) {
+	case string:
+		texts = []string{val}
+	case *RuntimeBlock:
+		for _, c := range val.Cases {
+			texts = append(texts, c)
+		}
+		for _, rc := range val.RangeCases {
+			texts = append(texts, rc.Value)
+		}
+	}
+
+	for _, t := range texts {
+		strip := func(string) string { return "" }
+		t = replacePlaceholders(argRe, replacePlaceholders(termRe, t, strip), strip)
+		if strings.IndexFunc(t, unicode.IsLetter) >= 0 {
+			return true
+		}
+	}
+	return false
+}
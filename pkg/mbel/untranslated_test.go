@@ -0,0 +1,29 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUntranslated(t *testing.T) {
+	en := map[string]interface{}{
+		"title":      "Hello",
+		"brand.name": "Acme",
+		"count":      "{n}",
+		"save":       "Save",
+		"items":      &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}},
+		"only_en":    "Only",
+	}
+	de := map[string]interface{}{
+		"title":      "Hello",
+		"brand.name": "Acme",
+		"count":      "{n}",
+		"save":       "Speichern",
+		"items":      &RuntimeBlock{Argument: "n", Cases: map[string]string{"one": "1 item", "other": "{n} items"}},
+	}
+
+	got := Untranslated(en, de, []string{"brand.*"})
+	if want := []string{"items", "title"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Untranslated = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package mbel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MessageWriter is implemented by repositories that can persist a single
+// message edited through Manager.Update. source is the manager's default
+// locale, which decides where a key new to lang is written.
+type MessageWriter interface {
+	WriteMessage(source, lang, key, value string) error
+}
+
+// Update sets the message of key in lang, for "edit this string" admin
+// screens: "key[case]" edits one case of a logic block ("cart.items[one]").
+// The value must balance its braces and use no placeholder the message
+// lacks in the default locale (or lang); errors wrap ErrInvalidMessage,
+// ErrLocaleNotFound or ErrKeyNotFound. Repositories implementing
+// MessageWriter persist the change (FileRepository rewrites the owning
+// .mbel file in place); the manager serves it immediately either way.
+func (m *Manager) Update(lang, key, value string) error {
+	return m.UpdateContext(context.Background(), lang, key, value)
+}
+
+// UpdateContext is Update with a context for the audit sink, e.g. one
+// carrying the editor's name from WithActor
+func (m *Manager) UpdateContext(ctx context.Context, lang, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cur := m.state.Load()
+	data, ok := cur.allData[lang]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLocaleNotFound, lang)
+	}
+	name, cond := splitReviewKey(key)
+	src, ok := cur.allData[m.defaultLang][name]
+	if !ok {
+		if src, ok = data[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+		}
+	}
+	next, err := withMessage(data[name], src, cond, value)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidMessage, key, err)
+	}
+	if err := checkMessage(src, value); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidMessage, key, err)
+	}
+
+	if w, ok := m.repo.(MessageWriter); ok {
+		if err := w.WriteMessage(m.defaultLang, lang, key, value); err != nil {
+			return err
+		}
+	}
+
+	updated := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		updated[k] = v
+	}
+	updated[name] = next
+	allData := make(map[string]map[string]interface{}, len(cur.allData))
+	for l, d := range cur.allData {
+		allData[l] = d
+	}
+	allData[lang] = updated
+	runtimes := make(map[string]*Runtime, len(cur.runtimes))
+	for l, r := range cur.runtimes {
+		runtimes[l] = r
+	}
+	delete(runtimes, lang)
+	if !m.lazyLoad {
+		runtimes[lang] = m.newRuntime(lang, updated)
+	}
+	m.state.Store(&catalog{runtimes: runtimes, allData: allData, gen: cur.gen + 1, namespaces: cur.namespaces})
+	if m.auditSink != nil {
+		m.audit(ctx, ChangeUpdate,
+			map[string]map[string]interface{}{lang: {name: data[name]}},
+			map[string]map[string]interface{}{lang: {name: next}})
+	}
+	return nil
+}
+
+// withMessage returns old (a string, *RuntimeBlock or nil when lang
+// lacks the key) with value set: the whole message, or the case cond of
+// a block. A new block takes src's argument.
+func withMessage(old, src interface{}, cond, value string) (interface{}, error) {
+	if value == "" {
+		return nil, errors.New("empty message")
+	}
+	block, isBlock := old.(*RuntimeBlock)
+	if old == nil {
+		// A key new to lang takes the shape of the source message
+		if sb, ok := src.(*RuntimeBlock); ok {
+			block, isBlock = &RuntimeBlock{Argument: sb.Argument}, true
+		}
+	}
+	switch {
+	case !isBlock && cond == "":
+		return value, nil
+	case !isBlock:
+		return nil, errors.New("a plain string, not a block")
+	case cond == "":
+		return nil, errors.New("a logic block; give the case, e.g. key[other]")
+	}
+
+	next := &RuntimeBlock{Argument: block.Argument, Cases: make(map[string]string, len(block.Cases)+1)}
+	for c, v := range block.Cases {
+		next.Cases[c] = v
+	}
+	next.RangeCases = append([]RangeCase(nil), block.RangeCases...)
+	if lo, hi, ok := strings.Cut(cond, ".."); ok {
+		start, err1 := strconv.Atoi(lo)
+		end, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid range case [%s]", cond)
+		}
+		for i, rc := range next.RangeCases {
+			if rc.Start == start && rc.End == end {
+				next.RangeCases[i].Value = value
+				return next, nil
+			}
+		}
+		next.RangeCases = append(next.RangeCases, RangeCase{Start: start, End: end, Value: value})
+		return next, nil
+	}
+	next.Cases[cond] = value
+	return next, nil
+}
+
+// checkMessage validates an edited value against the source message src
+func checkMessage(src interface{}, value string) error {
+	if !bracesBalance(value) {
+		return errors.New("unbalanced braces")
+	}
+	known := valuePlaceholders(src)
+	if b, ok := src.(*RuntimeBlock); ok {
+		known["{"+b.Argument+"}"] = true
+	}
+	for _, p := range placeholders(value) {
+		if !known[p] {
+			return fmt.Errorf("unexpected %s (not in the source)", p)
+		}
+	}
+	return nil
+}
+
+// WriteMessage rewrites the definition of key (or one case, "key[case]")
+// in lang's files in place, or appends a key lang lacks to the file
+// mirroring source's, like ApplyReview
+func (r *FileRepository) WriteMessage(source, lang, key, value string) error {
+	res, err := ApplyReview(r, &ReviewSheet{SourceLang: source, TargetLang: lang, Rows: []ReviewRow{{Key: key, Target: value}}})
+	if err != nil {
+		return err
+	}
+	if len(res.Skipped) > 0 {
+		return errors.New("mbel: " + res.Skipped[0])
+	}
+	return nil
+}
+
+// WriteMessage stores value under key (or one case, "key[case]") for
+// lang; source is consulted for the argument of a block lang lacks
+func (r *MemoryRepository) WriteMessage(source, lang, key, value string) error {
+	r.mu.Lock()
+	name, cond := splitReviewKey(key)
+	next, err := withMessage(r.data[lang][name], r.data[source][name], cond, value)
+	if err == nil {
+		r.lang(lang)[name] = next
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidMessage, key, err)
+	}
+	r.notify()
+	return nil
+}
@@ -0,0 +1,87 @@
+package mbel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManagerUpdate(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"en/shop.mbel": "# Shop copy\ntitle = \"Shop\"\nhello = \"Hi {name}\"\nitems(n) {\n    [one] => \"{n} item\"\n    [other] => \"{n} items\"\n}\n",
+		"pl/shop.mbel": "# Sklep\ntitle   =   \"Sklep\"\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := NewManager(dir, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, edit := range [][2]string{
+		{"shop.title", "Sklep online"},
+		{"shop.hello", "Cześć {name}"},
+		{"shop.items[one]", "{n} produkt"},
+		{"shop.items[few]", "{n} produkty"},
+		{"shop.items[many]", "{n} produktów"},
+	} {
+		if err := m.Update("pl", edit[0], edit[1]); err != nil {
+			t.Fatalf("Update(%s): %v", edit[0], err)
+		}
+	}
+	if got := m.Get("pl", "shop.title"); got != "Sklep online" {
+		t.Errorf("title = %q", got)
+	}
+	if got := m.Get("pl", "shop.items", 3); got != "3 produkty" {
+		t.Errorf("items(3) = %q", got)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "pl", "shop.mbel"))
+	want := "# Sklep\ntitle   =   \"Sklep online\"\nhello = \"Cześć {name}\"\nitems(n) {\n    [one] => \"{n} produkt\"\n    [few] => \"{n} produkty\"\n    [many] => \"{n} produktów\"\n}\n"
+	if string(content) != want {
+		t.Errorf("pl/shop.mbel =\n%s\nwant\n%s", content, want)
+	}
+	if err := m.Load(t.Context()); err != nil || m.Get("pl", "shop.hello", Vars{"name": "Ola"}) != "Cześć Ola" {
+		t.Errorf("after reload: %q, %v", m.Get("pl", "shop.hello", Vars{"name": "Ola"}), err)
+	}
+
+	for _, tc := range []struct {
+		lang, key, value string
+		want             error
+	}{
+		{"de", "shop.title", "Laden", ErrLocaleNotFound},
+		{"pl", "shop.nope", "?", ErrKeyNotFound},
+		{"pl", "shop.hello", "Cześć {user}", ErrInvalidMessage},
+		{"pl", "shop.title", "Sklep {", ErrInvalidMessage},
+		{"pl", "shop.items", "produkty", ErrInvalidMessage},
+		{"pl", "shop.title", "", ErrInvalidMessage},
+	} {
+		if err := m.Update(tc.lang, tc.key, tc.value); !errors.Is(err, tc.want) {
+			t.Errorf("Update(%s, %s, %q) = %v, want %v", tc.lang, tc.key, tc.value, err, tc.want)
+		}
+	}
+}
+
+func TestManagerUpdateMemoryRepository(t *testing.T) {
+	repo := NewMemoryRepository(map[string]map[string]interface{}{
+		"en": {"title": "Shop"},
+		"pl": {},
+	})
+	m, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en", LazyLoad: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Update("pl", "title", "Sklep"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := repo.LoadAll()
+	if m.Get("pl", "title") != "Sklep" || data["pl"]["title"] != "Sklep" {
+		t.Errorf("served %q, stored %v", m.Get("pl", "title"), data["pl"]["title"])
+	}
+}
@@ -0,0 +1,149 @@
+package mbel
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A variant block holds weighted copy variants for A/B tests:
+//
+//	cta = variant {
+//	    [a:50] => "Buy now"
+//	    [b:50] => "Get started"
+//	}
+//
+// The variant is picked by hashing ExperimentVar with the key, so one
+// user keeps seeing the same copy while traffic splits by weight.
+// Without an experiment ID the first variant by name is served.
+
+// ExperimentVar is the Vars key holding the experiment (bucketing) ID,
+// e.g. a user or session ID. It can never clash with a placeholder name.
+const ExperimentVar = "@experiment"
+
+const (
+	variantKeyword  = "variant"
+	variantArgument = "@variant" // Argument of compiled variant blocks
+)
+
+type experimentContextKey struct{}
+
+// WithExperiment sets the experiment ID variant blocks are bucketed by
+// for T calls made with the returned context
+func WithExperiment(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, experimentContextKey{}, id)
+}
+
+// ExperimentFromContext returns the ID set by WithExperiment, or ""
+func ExperimentFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(experimentContextKey{}).(string)
+	return id
+}
+
+// IsVariant reports whether rb is a variant block
+func (rb *RuntimeBlock) IsVariant() bool {
+	return rb.Argument == variantArgument
+}
+
+type variantCase struct {
+	name   string
+	weight int
+	value  string
+}
+
+// variants returns the cases of a variant block sorted by name
+func variants(rb *RuntimeBlock) ([]variantCase, error) {
+	out := make([]variantCase, 0, len(rb.Cases))
+	for cond, value := range rb.Cases {
+		name, w, ok := strings.Cut(cond, ":")
+		weight, err := strconv.Atoi(w)
+		if !ok || err != nil || name == "" || weight <= 0 {
+			return nil, fmt.Errorf("invalid variant [%s] (want [name:weight] with a positive weight)", cond)
+		}
+		out = append(out, variantCase{name, weight, value})
+	}
+	if len(rb.RangeCases) > 0 || len(out) == 0 {
+		return nil, fmt.Errorf("variant block needs [name:weight] cases")
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out, nil
+}
+
+// pickVariant returns the text of the variant key's experiment ID in arg
+// falls into
+func pickVariant(key string, rb *RuntimeBlock, arg interface{}) string {
+	vs, err := variants(rb)
+	if err != nil {
+		return ""
+	}
+	id := experimentOf(arg)
+	if id == "" {
+		return vs[0].value
+	}
+
+	total := 0
+	for _, v := range vs {
+		total += v.weight
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	bucket := int(h.Sum64() % uint64(total))
+	for _, v := range vs {
+		if bucket < v.weight {
+			return v.value
+		}
+		bucket -= v.weight
+	}
+	return vs[len(vs)-1].value
+}
+
+// experimentOf returns the ExperimentVar set in arg, or ""
+func experimentOf(arg interface{}) string {
+	var v interface{}
+	switch m := arg.(type) {
+	case Vars:
+		v = m[ExperimentVar]
+	case map[string]interface{}:
+		v = m[ExperimentVar]
+	}
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// withExperiment adds id to the variables in args unless they set one
+// already; without arguments it becomes the only variable
+func withExperiment(args []interface{}, id string) []interface{} {
+	if id == "" {
+		return args
+	}
+	if len(args) == 0 {
+		return []interface{}{Vars{ExperimentVar: id}}
+	}
+
+	var vars map[string]interface{}
+	switch m := args[0].(type) {
+	case Vars:
+		vars = m
+	case map[string]interface{}:
+		vars = m
+	default:
+		return args
+	}
+	if _, ok := vars[ExperimentVar]; ok {
+		return args
+	}
+
+	merged := make(Vars, len(vars)+1)
+	for k, v := range vars {
+		merged[k] = v
+	}
+	merged[ExperimentVar] = id
+	return append([]interface{}{merged}, args[1:]...)
+}
@@ -0,0 +1,56 @@
+package mbel
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const variantSource = `cta = variant {
+    [a:50] => "Buy now"
+    [b:50] => "Get started, {name}"
+}
+`
+
+func TestVariants(t *testing.T) {
+	data, errs, err := CompileSource([]byte(variantSource), nil)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("compile: %v %v", err, errs)
+	}
+	m, err := NewManagerWithRepo(NewMemoryRepository(map[string]map[string]interface{}{"en": data}), Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Get("en", "cta"); got != "Buy now" {
+		t.Errorf("without an experiment ID = %q", got)
+	}
+	seen := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		id := "user-" + string(rune('a'+i%26)) + strings.Repeat("x", i/26)
+		got := m.Get("en", "cta", Vars{ExperimentVar: id, "name": "Ola"})
+		if again := m.get(WithExperiment(context.Background(), id), "en", "cta", Vars{"name": "Ola"}); again != got {
+			t.Fatalf("%s: bucketing is not deterministic: %q vs %q", id, got, again)
+		}
+		seen[got]++
+	}
+	if a, b := seen["Buy now"], seen["Get started, Ola"]; a < 400 || b < 400 || a+b != 1000 {
+		t.Errorf("split = %v", seen)
+	}
+
+	if got, err := FormatSource(variantSource); err != nil || got != variantSource {
+		t.Errorf("FormatSource =\n%s", got)
+	}
+	if got := FormatData(data); !strings.Contains(got, variantSource) {
+		t.Errorf("FormatData =\n%s", got)
+	}
+
+	for _, src := range []string{
+		"cta = variant {\n    [a:0] => \"x\"\n}\n",
+		"cta = variant {\n    [one] => \"x\"\n}\n",
+	} {
+		if _, errs, err := CompileSource([]byte(src), nil); err == nil && len(errs) == 0 {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+}
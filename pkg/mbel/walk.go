@@ -0,0 +1,130 @@
+package mbel
+
+// Walk traverses the AST rooted at node in depth-first order. visitor is
+// called for every node; returning false skips that node's children.
+//
+//	mbel.Walk(program, func(n mbel.Node) bool {
+//		if bc, ok := n.(*mbel.BlockCase); ok {
+//			fmt.Println(bc.Condition)
+//		}
+//		return true
+//	})
+func Walk(node Node, visitor func(Node) bool) {
+	if node == nil || !visitor(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, stmt := range n.Statements {
+			Walk(stmt, visitor)
+		}
+	case *AssignStatement:
+		if n.Value != nil {
+			Walk(n.Value, visitor)
+		}
+	case *TermDefinition:
+		if n.Value != nil {
+			Walk(n.Value, visitor)
+		}
+	case *BlockExpression:
+		for _, bc := range n.Cases {
+			Walk(bc, visitor)
+		}
+	}
+}
+
+// Assignments returns every assignment in the program keyed by its
+// fully qualified name (section prefix included), as the compiler sees it
+func Assignments(p *Program) map[string]*AssignStatement {
+	result := make(map[string]*AssignStatement)
+	currentSection := ""
+
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *SectionStatement:
+			currentSection = s.Name
+		case *AssignStatement:
+			key := s.Name
+			if currentSection != "" {
+				key = currentSection + "." + s.Name
+			}
+			result[key] = s
+		}
+	}
+
+	return result
+}
+
+// OrderedKeys returns the fully qualified keys of the program in source
+// order; a key assigned more than once is listed at its first position
+func OrderedKeys(p *Program) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	currentSection := ""
+
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *SectionStatement:
+			currentSection = s.Name
+		case *AssignStatement:
+			key := s.Name
+			if currentSection != "" {
+				key = currentSection + "." + s.Name
+			}
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// Metadata returns the program's file-level @key: value pairs (per-key
+// lines such as @status excluded)
+func Metadata(p *Program) map[string]string {
+	result := make(map[string]string)
+	for _, stmt := range p.Statements {
+		if ms, ok := stmt.(*MetadataStatement); ok && !keyMetaKeys[ms.Key] {
+			result[ms.Key] = ms.Value
+		}
+	}
+	return result
+}
+
+// annotationKeys maps the AI annotations attached to assignments to the
+// section-qualified keys they describe; file-level ones are left out
+func annotationKeys(p *Program) map[*AIAnnotation]string {
+	out := make(map[*AIAnnotation]string)
+	anns := p.AIAnnotations
+	i := 0
+	section := ""
+	for _, stmt := range p.Statements {
+		switch s := stmt.(type) {
+		case *SectionStatement:
+			section = s.Name
+		case *AssignStatement:
+			// Annotations are in source order, each run before its key
+			for ; i < len(anns) && anns[i].Line < s.Token.Line; i++ {
+				if anns[i].ForKey == s.Name {
+					out[anns[i]] = joinKey(section, s.Name)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// AnnotationsFor returns the AI annotations attached to the key name
+// (as written in the file, without section prefix)
+func AnnotationsFor(p *Program, name string) []*AIAnnotation {
+	var result []*AIAnnotation
+	for _, ann := range p.AIAnnotations {
+		if ann.ForKey == name {
+			result = append(result, ann)
+		}
+	}
+	return result
+}
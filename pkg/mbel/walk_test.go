@@ -0,0 +1,61 @@
+package mbel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	input := `@lang: en
+title = "Hello"
+
+[cart]
+items(n) {
+	[one]   => "1 item"
+	[other] => "{n} items"
+}
+`
+	program := NewParser(NewLexer(input)).ParseProgram()
+
+	var conditions []string
+	assigns := 0
+	Walk(program, func(n Node) bool {
+		switch n := n.(type) {
+		case *AssignStatement:
+			assigns++
+		case *BlockCase:
+			conditions = append(conditions, n.Condition)
+		}
+		return true
+	})
+
+	if assigns != 2 {
+		t.Errorf("expected 2 assignments, got %d", assigns)
+	}
+	if len(conditions) != 2 || conditions[0] != "one" || conditions[1] != "other" {
+		t.Errorf("unexpected block conditions: %v", conditions)
+	}
+
+	if _, ok := Assignments(program)["cart.items"]; !ok {
+		t.Errorf("expected qualified key cart.items, got %v", Assignments(program))
+	}
+	if Metadata(program)["lang"] != "en" {
+		t.Errorf("expected lang metadata, got %v", Metadata(program))
+	}
+}
+
+func TestOrderedKeys(t *testing.T) {
+	program := NewParser(NewLexer("zeta = \"z\"\nalpha = \"a\"\n[auth]\nlogin = \"l\"\nzeta = \"again\"\n")).ParseProgram()
+
+	want := []string{"zeta", "alpha", "auth.login", "auth.zeta"}
+	if got := OrderedKeys(program); !reflect.DeepEqual(got, want) {
+		t.Errorf("source order: got %v, want %v", got, want)
+	}
+
+	res, _ := NewCompiler().Compile(program)
+	r := NewRuntime(res.Data)
+	sorted := []string{"alpha", "auth.login", "auth.zeta", "zeta"}
+	if got := r.OrderedKeys(); !reflect.DeepEqual(got, sorted) {
+		t.Errorf("runtime keys: got %v, want %v", got, sorted)
+	}
+}
@@ -0,0 +1,199 @@
+package mbel
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchFileRepositoryCancelAndRestart(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "en.mbel")
+	write := func(content string, mod time.Time) {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(file, mod, mod)
+	}
+	start := time.Now().Add(-time.Hour)
+	write("@lang: en\ntitle = \"One\"\n", start)
+
+	m, err := NewManager(dir, Config{DefaultLocale: "en", WatchInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Watch(ctx) }()
+
+	waitFor(t, func() bool { return m.watching.Load() })
+	if err := m.Watch(context.Background()); !errors.Is(err, ErrAlreadyWatching) {
+		t.Errorf("expected ErrAlreadyWatching, got %v", err)
+	}
+
+	write("@lang: en\ntitle = \"Two\"\n", start.Add(time.Minute))
+	waitFor(t, func() bool { return m.Get("en", "title") == "Two" })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	// Changes made while stopped are picked up on restart
+	write("@lang: en\ntitle = \"Three\"\n", start.Add(2*time.Minute))
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx)
+	waitFor(t, func() bool { return m.Get("en", "title") == "Three" })
+}
+
+func TestWatchReportsReloadErrors(t *testing.T) {
+	repo := &failingRepo{}
+	errs := make(chan error, 1)
+	m := newManager(repo, Config{OnReloadError: func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}})
+
+	if err := m.Watch(context.Background()); err == nil {
+		t.Fatal("expected error for a repository that cannot be watched")
+	}
+
+	repo2 := NewMemoryRepository(nil)
+	m = newManager(&failingNotifier{repo2}, Config{OnReloadError: func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx)
+
+	select {
+	case err := <-errs:
+		if err != errLoad {
+			t.Errorf("unexpected error %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload error was not reported")
+	}
+}
+
+var errLoad = errors.New("load failed")
+
+type failingRepo struct{}
+
+func (failingRepo) LoadAll() (map[string]map[string]interface{}, error) { return nil, errLoad }
+
+type failingNotifier struct{ *MemoryRepository }
+
+func (failingNotifier) LoadAll() (map[string]map[string]interface{}, error) { return nil, errLoad }
+
+func TestReloadQuarantinesBrokenFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mod time.Time) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mod, mod)
+	}
+	start := time.Now().Add(-time.Hour)
+	os.Mkdir(filepath.Join(dir, "en"), 0755)
+	write("en/app.mbel", "title = \"One\"\nfooter = \"Bye\"\n", start)
+	write("en/shop.mbel", "cart = \"Cart\"\n", start)
+
+	m, err := NewManager(dir, Config{DefaultLocale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("en/app.mbel", "title = \"Two\"\nbroken = \nfooter = \"Later\"\n", start.Add(time.Minute))
+	write("en/shop.mbel", "cart = \"Basket\"\n", start.Add(time.Minute))
+	err = m.Load(context.Background())
+	var q *QuarantineError
+	if !errors.As(err, &q) || len(q.Files) != 1 || q.Files[filepath.Join(dir, "en", "app.mbel")] == nil {
+		t.Fatalf("Load = %v, want app.mbel quarantined", err)
+	}
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("Load = %v, want it to wrap ErrParse", err)
+	}
+	for key, want := range map[string]string{"app.title": "One", "app.footer": "Bye", "shop.cart": "Basket"} {
+		if got := m.Get("en", key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	write("en/app.mbel", "title = \"Two\"\nfooter = \"Later\"\n", start.Add(2*time.Minute))
+	if err := m.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Get("en", "app.footer"); got != "Later" {
+		t.Errorf("fixed file not picked up: %q", got)
+	}
+}
+
+// countingObserver counts reloads
+type countingObserver struct{ reloads atomic.Int32 }
+
+func (o *countingObserver) MissingKey(context.Context, string, string)       {}
+func (o *countingObserver) Fallback(context.Context, string, string, string) {}
+func (o *countingObserver) Reload(context.Context) func(error) {
+	o.reloads.Add(1)
+	return func(error) {}
+}
+
+func TestConfigWatchLoadsOnce(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "en.mbel")
+	os.WriteFile(file, []byte("title = \"One\"\n"), 0644)
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(file, past, past)
+
+	files := &countingObserver{}
+	m, err := NewManager(dir, Config{DefaultLocale: "en", Watch: true, WatchInterval: 5 * time.Millisecond, Observer: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	repo := NewMemoryRepository(map[string]map[string]interface{}{"en": {"title": "One"}})
+	notified := &countingObserver{}
+	mm, err := NewManagerWithRepo(repo, Config{DefaultLocale: "en", Watch: true, Observer: notified})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mm.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := files.reloads.Load(); n != 1 {
+		t.Errorf("FileRepository loaded %d times at startup, want 1", n)
+	}
+	if n := notified.reloads.Load(); n != 1 {
+		t.Errorf("ChangeNotifier loaded %d times at startup, want 1", n)
+	}
+
+	// Both still pick up changes
+	os.WriteFile(file, []byte("title = \"Two\"\n"), 0644)
+	repo.Set("en", "title", "Two")
+	waitFor(t, func() bool { return m.Get("en", "title") == "Two" && mm.Get("en", "title") == "Two" })
+}
@@ -0,0 +1,124 @@
+package mbel
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorkspaceFileName is the workspace file at the root of a monorepo
+const WorkspaceFileName = "mbel.work"
+
+// Workspace lists the locale roots of a monorepo, one per app or
+// service, and the command-line settings they share:
+//
+//	# mbel.work
+//	use apps/web/locales web
+//	use services/api/locales api
+//
+//	source en
+//	snake-case true
+//
+// A "use" line names a locale root relative to the workspace file and,
+// optionally, the project it belongs to (the root by default). Every
+// other line sets a flag of the same name for the commands that have it.
+type Workspace struct {
+	Dir      string // directory of the workspace file
+	Projects []WorkspaceProject
+	Settings map[string]string // flag name -> value
+}
+
+// WorkspaceProject is one locale root of a workspace
+type WorkspaceProject struct {
+	Name string
+	Root string // relative to the workspace directory, slash-separated
+}
+
+// Path returns the project root as a path usable from the current
+// directory
+func (w *Workspace) Path(p WorkspaceProject) string {
+	return filepath.Join(w.Dir, filepath.FromSlash(p.Root))
+}
+
+// ParseWorkspace parses the content of a workspace file found in dir
+func ParseWorkspace(dir, src string) (*Workspace, error) {
+	w := &Workspace{Dir: dir, Settings: make(map[string]string)}
+	names := make(map[string]bool)
+	sc := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(src, bom)))
+	for line := 1; sc.Scan(); line++ {
+		text, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "use":
+			if len(fields) < 2 || len(fields) > 3 {
+				return nil, fmt.Errorf("line %d: want \"use <dir> [name]\"", line)
+			}
+			p := WorkspaceProject{Root: filepath.ToSlash(filepath.Clean(fields[1]))}
+			p.Name = p.Root
+			if len(fields) == 3 {
+				p.Name = fields[2]
+			}
+			if names[p.Name] {
+				return nil, fmt.Errorf("line %d: duplicate project %q", line, p.Name)
+			}
+			names[p.Name] = true
+			w.Projects = append(w.Projects, p)
+		case len(fields) == 2:
+			name := strings.TrimLeft(fields[0], "-")
+			if _, dup := w.Settings[name]; dup {
+				return nil, fmt.Errorf("line %d: %s set twice", line, name)
+			}
+			w.Settings[name] = fields[1]
+		default:
+			return nil, fmt.Errorf("line %d: want \"use <dir>\" or \"<flag> <value>\"", line)
+		}
+	}
+	if len(w.Projects) == 0 {
+		return nil, fmt.Errorf("no \"use\" lines")
+	}
+	return w, nil
+}
+
+// ReadWorkspace reads the workspace file at path
+func ReadWorkspace(path string) (*Workspace, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := ParseWorkspace(filepath.Dir(path), string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return w, nil
+}
+
+// FindWorkspace returns the path of the workspace file in dir or the
+// nearest of its parents, and "" when there is none
+func FindWorkspace(dir string) string {
+	return findUp(dir, WorkspaceFileName)
+}
+
+// findUp returns the path of the file name in dir or the nearest of its
+// parents, and "" when there is none
+func findUp(dir, name string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,69 @@
+package mbel
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWorkspace(t *testing.T) {
+	ws, err := ParseWorkspace("repo", `# monorepo
+use apps/web/locales web
+use ./services/api/locales/   # named after its root
+
+source en
+-snake-case true
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []WorkspaceProject{
+		{Name: "web", Root: "apps/web/locales"},
+		{Name: "services/api/locales", Root: "services/api/locales"},
+	}
+	if !reflect.DeepEqual(ws.Projects, want) {
+		t.Errorf("projects = %+v", ws.Projects)
+	}
+	if !reflect.DeepEqual(ws.Settings, map[string]string{"source": "en", "snake-case": "true"}) {
+		t.Errorf("settings = %v", ws.Settings)
+	}
+	if got := ws.Path(ws.Projects[0]); got != filepath.Join("repo", "apps", "web", "locales") {
+		t.Errorf("path = %s", got)
+	}
+
+	for src, msg := range map[string]string{
+		"source en\n":                   `no "use" lines`,
+		"use\n":                         "line 1:",
+		"use a x\nuse b x\n":            `duplicate project "x"`,
+		"use a\nsource en\nsource pl\n": "line 3: source set twice",
+		"use a\nstrict\n":               "line 2:",
+	} {
+		if _, err := ParseWorkspace(".", src); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: err = %v, want %q", src, err, msg)
+		}
+	}
+}
+
+func TestFindWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "apps", "web")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindWorkspace(nested); got != "" {
+		t.Fatalf("found %s without a workspace file", got)
+	}
+	path := filepath.Join(dir, WorkspaceFileName)
+	if err := os.WriteFile(path, []byte("use apps/web/locales web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindWorkspace(nested); got != path {
+		t.Fatalf("FindWorkspace = %q, want %q", got, path)
+	}
+	ws, err := ReadWorkspace(path)
+	if err != nil || ws.Dir != dir || len(ws.Projects) != 1 {
+		t.Errorf("ReadWorkspace = %+v, %v", ws, err)
+	}
+}
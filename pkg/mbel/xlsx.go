@@ -0,0 +1,235 @@
+package mbel
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Minimal Office Open XML spreadsheets, enough for reviewer sheets: one
+// worksheet of inline strings on write; shared, inline and plain cells on
+// read, so files saved by Excel, LibreOffice or Google Sheets load too.
+
+const xlsxMainNS = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+
+// Sheet size limits of Excel; larger references are rejected on read
+const (
+	xlsxMaxRows    = 1 << 20
+	xlsxMaxColumns = 1 << 14
+)
+
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<workbook xmlns="` + xlsxMainNS + `" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Review" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// Column widths of the review sheet, in characters
+var reviewColumnWidths = []int{32, 48, 48, 40, 12, 14, 48}
+
+// WriteReviewXLSX writes the sheet as an .xlsx workbook
+func WriteReviewXLSX(w io.Writer, sheet *ReviewSheet) error {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+part.body); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header + `<worksheet xmlns="` + xlsxMainNS + `"><cols>`)
+	for i, width := range reviewColumnWidths {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+	}
+	b.WriteString(`</cols><sheetData>`)
+	for r, row := range sheet.table() {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			if cell == "" {
+				continue
+			}
+			ref := xlsxColumn(c) + fmt.Sprint(r+1)
+			if c == 4 && r > 0 { // Max Length stays numeric
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, cell)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(&b, []byte(cell))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	if _, err := f.Write(b.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadReviewXLSX reads the first worksheet of an .xlsx workbook
+func ReadReviewXLSX(r io.ReaderAt, size int64) (*ReviewSheet, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %w", err)
+	}
+	parts := make(map[string]*zip.File)
+	var sheets []string
+	for _, f := range zr.File {
+		parts[f.Name] = f
+		if strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f.Name)
+		}
+	}
+	sheetName := "xl/worksheets/sheet1.xml"
+	if parts[sheetName] == nil {
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("xlsx file has no worksheet")
+		}
+		sheetName = sheets[0]
+	}
+
+	var shared []string
+	if f := parts["xl/sharedStrings.xml"]; f != nil {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeXLSXPart(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			shared = append(shared, si.String())
+		}
+	}
+
+	var ws struct {
+		Rows []struct {
+			Num   int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeXLSXPart(parts[sheetName], &ws); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for i, row := range ws.Rows {
+		num := row.Num
+		if num == 0 {
+			num = i + 1
+		}
+		if num < 1 || num > xlsxMaxRows {
+			return nil, fmt.Errorf("row %d out of range", row.Num)
+		}
+		for len(rows) < num {
+			rows = append(rows, nil)
+		}
+		cells := rows[num-1]
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				col = xlsxColumnIndex(c.Ref)
+			}
+			if col < 0 || col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("cell %q: invalid column", c.Ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch c.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscan(c.Value, &idx); err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("cell %s: invalid shared string %q", c.Ref, c.Value)
+				}
+				cells[col] = shared[idx]
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows[num-1] = cells
+	}
+	return parseReviewTable(rows)
+}
+
+// xlsxText is a string item: plain <t>, or rich text runs <r><t>
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	s := t.Text
+	for _, r := range t.Runs {
+		s += r.Text
+	}
+	return s
+}
+
+func decodeXLSXPart(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumn returns the letters of a 0-based column (0 -> A, 26 -> AA)
+func xlsxColumn(i int) string {
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
+
+// xlsxColumnIndex returns the 0-based column of a cell reference ("C7"
+// or "c7" -> 2), -1 if it has no column and xlsxMaxColumns past the last
+func xlsxColumnIndex(ref string) int {
+	n := 0
+	for i := 0; i < len(ref); i++ {
+		c := ref[i] | 0x20 // lower case
+		if c < 'a' || c > 'z' {
+			break
+		}
+		if n = n*26 + int(c-'a'+1); n > xlsxMaxColumns {
+			return xlsxMaxColumns
+		}
+	}
+	return n - 1
+}
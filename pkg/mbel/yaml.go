@@ -0,0 +1,292 @@
+package mbel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML decodes the YAML subset used by translation files: nested
+// block mappings with plain, quoted and block (| >) scalars, and
+// comments. Sequences are read as []interface{} of their scalar items,
+// flow collections as nil; anchors, aliases and tags are rejected.
+func parseYAML(src string) (map[string]interface{}, error) {
+	src = strings.TrimPrefix(strings.ReplaceAll(src, "\r\n", "\n"), "\ufeff")
+	y := &yamlParser{lines: strings.Split(src, "\n")}
+	y.skipBlank()
+	if y.i < len(y.lines) && strings.TrimSpace(y.lines[y.i]) == "---" {
+		y.i++
+	}
+	y.skipBlank()
+	if y.i == len(y.lines) {
+		return map[string]interface{}{}, nil
+	}
+	root, err := y.mapping(yamlIndent(y.lines[y.i]))
+	if err != nil {
+		return nil, err
+	}
+	if y.skipBlank(); y.i < len(y.lines) {
+		return nil, y.errorf("unexpected indentation")
+	}
+	return root, nil
+}
+
+type yamlParser struct {
+	lines []string
+	i     int // current line
+}
+
+func (y *yamlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", y.i+1, fmt.Sprintf(format, args...))
+}
+
+// skipBlank moves past empty and comment-only lines
+func (y *yamlParser) skipBlank() {
+	for y.i < len(y.lines) {
+		t := strings.TrimSpace(y.lines[y.i])
+		if t != "" && !strings.HasPrefix(t, "#") {
+			return
+		}
+		y.i++
+	}
+}
+
+func yamlIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// mapping reads the "key: value" lines indented by indent
+func (y *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+	for y.skipBlank(); y.i < len(y.lines); y.skipBlank() {
+		line := y.lines[y.i]
+		if ind := yamlIndent(line); ind < indent {
+			return out, nil
+		} else if ind > indent {
+			return nil, y.errorf("unexpected indentation")
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			return nil, y.errorf("sequence item in a mapping")
+		}
+
+		key, rest, err := y.splitKey(strings.TrimSpace(line))
+		if err != nil {
+			return nil, err
+		}
+		y.i++
+
+		var v interface{}
+		switch {
+		case rest == "":
+			y.skipBlank()
+			if y.i < len(y.lines) && yamlIndent(y.lines[y.i]) > indent {
+				child := yamlIndent(y.lines[y.i])
+				if t := strings.TrimSpace(y.lines[y.i]); t == "-" || strings.HasPrefix(t, "- ") {
+					v, err = y.sequence(child)
+				} else {
+					v, err = y.mapping(child)
+				}
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			v, err = y.blockScalar(rest, indent)
+		default:
+			v, err = y.scalar(rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// splitKey splits "key: rest" with an optionally quoted key
+func (y *yamlParser) splitKey(s string) (key, rest string, err error) {
+	if s[0] == '"' || s[0] == '\'' {
+		v, n, err := y.quoted(s)
+		if err != nil {
+			return "", "", err
+		}
+		after := strings.TrimLeft(s[n:], " ")
+		if !strings.HasPrefix(after, ":") {
+			return "", "", y.errorf("expected ':' after key")
+		}
+		return v, strings.TrimSpace(after[1:]), nil
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i == len(s)-1 || s[i+1] == ' ') {
+			return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), nil
+		}
+	}
+	return "", "", y.errorf("expected 'key: value'")
+}
+
+// scalar decodes an inline value
+func (y *yamlParser) scalar(s string) (interface{}, error) {
+	switch s[0] {
+	case '"', '\'':
+		v, n, err := y.quoted(s)
+		if err != nil {
+			return nil, err
+		}
+		if after := strings.TrimSpace(s[n:]); after != "" && after[0] != '#' {
+			return nil, y.errorf("unexpected text after quoted value")
+		}
+		return v, nil
+	case '&', '*', '!':
+		return nil, y.errorf("anchors, aliases and tags are not supported")
+	case '[', '{':
+		return nil, nil // flow collection: never a message
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return s, nil
+}
+
+// quoted decodes the quoted string at the start of s and returns its
+// length in s
+func (y *yamlParser) quoted(s string) (string, int, error) {
+	q := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == q:
+			return b.String(), i + 1, nil
+		case c == '\\' && q == '"' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+4 < len(s) {
+					if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+						b.WriteRune(rune(r))
+						i += 4
+						continue
+					}
+				}
+				return "", 0, y.errorf("invalid \\u escape")
+			default:
+				b.WriteByte(s[i]) // \" \\ \/
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, y.errorf("unterminated quoted value")
+}
+
+// blockScalar reads the lines of a | or > scalar below a key at indent
+func (y *yamlParser) blockScalar(header string, indent int) (string, error) {
+	if i := strings.Index(header, " #"); i >= 0 {
+		header = strings.TrimSpace(header[:i])
+	}
+	folded := header[0] == '>'
+	chomp := strings.TrimLeft(header[1:], "0123456789")
+
+	var lines []string
+	block := -1
+	for ; y.i < len(y.lines); y.i++ {
+		line := y.lines[y.i]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		ind := yamlIndent(line)
+		if ind <= indent {
+			break
+		}
+		if block < 0 {
+			block = ind
+		}
+		if ind < block {
+			return "", y.errorf("block scalar line indented less than its first line")
+		}
+		lines = append(lines, line[block:])
+	}
+	// Trailing blank lines belong to the next key, bar "keep" chomping
+	n := len(lines)
+	for n > 0 && lines[n-1] == "" {
+		n--
+	}
+	trailing := lines[n:]
+	lines = lines[:n]
+
+	var text string
+	if folded {
+		var b strings.Builder
+		for i, l := range lines {
+			switch {
+			case i == 0:
+			case l == "" || lines[i-1] == "" || strings.HasPrefix(l, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(l)
+		}
+		text = b.String()
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+
+	switch chomp {
+	case "-":
+		return text, nil
+	case "+":
+		return text + "\n" + strings.Repeat("\n", len(trailing)), nil
+	}
+	if text == "" {
+		return "", nil
+	}
+	return text + "\n", nil
+}
+
+// sequence reads the "- item" lines indented by indent
+func (y *yamlParser) sequence(indent int) ([]interface{}, error) {
+	var out []interface{}
+	for y.skipBlank(); y.i < len(y.lines); y.skipBlank() {
+		line := y.lines[y.i]
+		t := strings.TrimSpace(line)
+		if yamlIndent(line) != indent || t != "-" && !strings.HasPrefix(t, "- ") {
+			if yamlIndent(line) > indent {
+				return nil, y.errorf("unexpected indentation")
+			}
+			return out, nil
+		}
+		y.i++
+		item := strings.TrimSpace(strings.TrimPrefix(t, "-"))
+		if item == "" {
+			// Nested collection item: not a message, skip it
+			for y.skipBlank(); y.i < len(y.lines) && yamlIndent(y.lines[y.i]) > indent; y.skipBlank() {
+				y.i++
+			}
+			out = append(out, nil)
+			continue
+		}
+		v, err := y.scalar(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}